@@ -72,6 +72,10 @@ type Config struct {
 
 	// Features allows for finer control over optional features.
 	Features features `toml:"features,omitempty"`
+
+	// Policy defines rules that restrict the devices that a container may
+	// request.
+	Policy PolicyConfig `toml:"policy,omitempty"`
 }
 
 // GetConfigFilePath returns the path to the config file for the configured system
@@ -90,8 +94,17 @@ func GetConfigFilePath() string {
 // GetConfig sets up the config struct. Values are read from a toml file
 // or set via the environment.
 func GetConfig() (*Config, error) {
+	return GetConfigFrom("")
+}
+
+// GetConfigFrom sets up the config struct from the specified config file. If
+// no path is specified, the default config file is used.
+func GetConfigFrom(configFilePath string) (*Config, error) {
+	if configFilePath == "" {
+		configFilePath = GetConfigFilePath()
+	}
 	cfg, err := New(
-		WithConfigFile(GetConfigFilePath()),
+		WithConfigFile(configFilePath),
 	)
 	if err != nil {
 		return nil, err
@@ -127,6 +140,9 @@ func GetDefault() (*Config, error) {
 					AnnotationPrefixes: []string{cdi.AnnotationPrefix},
 					SpecDirs:           cdi.DefaultSpecDirs,
 				},
+				JitCDI: jitCDIModeConfig{
+					SpecCacheDir: defaultJitCDISpecCacheDir,
+				},
 				Legacy: legacyModeConfig{
 					CUDACompatMode: defaultCUDACompatMode,
 				},
@@ -145,6 +161,15 @@ func (c *Config) assertValid() error {
 	if err != nil {
 		return errors.Join(err, errInvalidConfig)
 	}
+	if err := c.Policy.assertValid(); err != nil {
+		return errors.Join(err, errInvalidConfig)
+	}
+	if err := c.NVIDIAContainerRuntimeHookConfig.assertValid(); err != nil {
+		return errors.Join(err, errInvalidConfig)
+	}
+	if err := c.NVIDIACTKConfig.assertValid(); err != nil {
+		return errors.Join(err, errInvalidConfig)
+	}
 	return nil
 }
 
@@ -93,6 +93,9 @@ func TestGetConfig(t *testing.T) {
 							AnnotationPrefixes: []string{"cdi.k8s.io/"},
 							SpecDirs:           []string{"/etc/cdi", "/var/run/cdi"},
 						},
+						JitCDI: jitCDIModeConfig{
+							SpecCacheDir: "/run/nvidia-container-toolkit/jit-cdi",
+						},
 						Legacy: legacyModeConfig{
 							CUDACompatMode: "ldconfig",
 						},
@@ -158,6 +161,9 @@ func TestGetConfig(t *testing.T) {
 								"/not/var/run/cdi",
 							},
 						},
+						JitCDI: jitCDIModeConfig{
+							SpecCacheDir: "/run/nvidia-container-toolkit/jit-cdi",
+						},
 						Legacy: legacyModeConfig{
 							CUDACompatMode: "mount",
 						},
@@ -205,6 +211,9 @@ func TestGetConfig(t *testing.T) {
 								"/var/run/cdi",
 							},
 						},
+						JitCDI: jitCDIModeConfig{
+							SpecCacheDir: "/run/nvidia-container-toolkit/jit-cdi",
+						},
 						Legacy: legacyModeConfig{
 							CUDACompatMode: "ldconfig",
 						},
@@ -280,6 +289,9 @@ func TestGetConfig(t *testing.T) {
 								"/not/var/run/cdi",
 							},
 						},
+						JitCDI: jitCDIModeConfig{
+							SpecCacheDir: "/run/nvidia-container-toolkit/jit-cdi",
+						},
 						Legacy: legacyModeConfig{
 							CUDACompatMode: "mount",
 						},
@@ -319,6 +331,9 @@ func TestGetConfig(t *testing.T) {
 							AnnotationPrefixes: []string{"cdi.k8s.io/"},
 							SpecDirs:           []string{"/etc/cdi", "/var/run/cdi"},
 						},
+						JitCDI: jitCDIModeConfig{
+							SpecCacheDir: "/run/nvidia-container-toolkit/jit-cdi",
+						},
 						Legacy: legacyModeConfig{
 							CUDACompatMode: "ldconfig",
 						},
@@ -361,6 +376,9 @@ func TestGetConfig(t *testing.T) {
 							AnnotationPrefixes: []string{"cdi.k8s.io/"},
 							SpecDirs:           []string{"/etc/cdi", "/var/run/cdi"},
 						},
+						JitCDI: jitCDIModeConfig{
+							SpecCacheDir: "/run/nvidia-container-toolkit/jit-cdi",
+						},
 						Legacy: legacyModeConfig{
 							CUDACompatMode: "ldconfig",
 						},
@@ -439,6 +457,85 @@ func TestAssertValid(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "invalid policy pattern is invalid",
+			config: &Config{
+				NVIDIAContainerCLIConfig: ContainerCLIConfig{
+					Ldconfig: "@/some/host/path",
+				},
+				Policy: PolicyConfig{
+					Rules: []PolicyRule{
+						{DeniedKinds: []string{"management.nvidia.com/["}},
+					},
+				},
+			},
+			expectedError: errInvalidConfig,
+		},
+		{
+			description: "native prestart mode is valid",
+			config: &Config{
+				NVIDIAContainerCLIConfig: ContainerCLIConfig{
+					Ldconfig: "@/some/host/path",
+				},
+				NVIDIAContainerRuntimeHookConfig: RuntimeHookConfig{
+					PrestartMode: PrestartModeNative,
+				},
+			},
+		},
+		{
+			description: "unknown prestart mode is invalid",
+			config: &Config{
+				NVIDIAContainerCLIConfig: ContainerCLIConfig{
+					Ldconfig: "@/some/host/path",
+				},
+				NVIDIAContainerRuntimeHookConfig: RuntimeHookConfig{
+					PrestartMode: "unknown",
+				},
+			},
+			expectedError: errInvalidConfig,
+		},
+		{
+			description: "hook timeout and failure policy are valid",
+			config: &Config{
+				NVIDIAContainerCLIConfig: ContainerCLIConfig{
+					Ldconfig: "@/some/host/path",
+				},
+				NVIDIACTKConfig: CTKConfig{
+					Hooks: map[string]CDIHookConfig{
+						"update-ldcache": {Timeout: "30s", FailurePolicy: "warn"},
+						"all":            {FailurePolicy: "fail"},
+					},
+				},
+			},
+		},
+		{
+			description: "invalid hook timeout is invalid",
+			config: &Config{
+				NVIDIAContainerCLIConfig: ContainerCLIConfig{
+					Ldconfig: "@/some/host/path",
+				},
+				NVIDIACTKConfig: CTKConfig{
+					Hooks: map[string]CDIHookConfig{
+						"update-ldcache": {Timeout: "30"},
+					},
+				},
+			},
+			expectedError: errInvalidConfig,
+		},
+		{
+			description: "unknown hook failure policy is invalid",
+			config: &Config{
+				NVIDIAContainerCLIConfig: ContainerCLIConfig{
+					Ldconfig: "@/some/host/path",
+				},
+				NVIDIACTKConfig: CTKConfig{
+					Hooks: map[string]CDIHookConfig{
+						"update-ldcache": {FailurePolicy: "ignore"},
+					},
+				},
+			},
+			expectedError: errInvalidConfig,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -16,6 +16,18 @@
 
 package config
 
+import "fmt"
+
+const (
+	// PrestartModeContainerCLI selects a prestart hook that invokes the
+	// nvidia-container-cli to modify the container.
+	PrestartModeContainerCLI = "nvidia-container-cli"
+	// PrestartModeNative selects a prestart hook that modifies the container
+	// using edits generated by the nvcdi package. This does not require the
+	// nvidia-container-cli.
+	PrestartModeNative = "native"
+)
+
 // RuntimeHookConfig stores the config options for the NVIDIA Container Runtime
 type RuntimeHookConfig struct {
 	// Path specifies the path to the NVIDIA Container Runtime hook binary.
@@ -23,4 +35,17 @@ type RuntimeHookConfig struct {
 	Path string `toml:"path"`
 	// SkipModeDetection disables the mode check for the runtime hook.
 	SkipModeDetection bool `toml:"skip-mode-detection"`
+	// PrestartMode selects how the prestart hook modifies a container. If this
+	// is empty, the nvidia-container-cli is used.
+	PrestartMode string `toml:"prestart-mode,omitempty"`
+}
+
+// assertValid checks that the configured prestart mode is supported.
+func (c RuntimeHookConfig) assertValid() error {
+	switch c.PrestartMode {
+	case "", PrestartModeContainerCLI, PrestartModeNative:
+		return nil
+	default:
+		return fmt.Errorf("invalid nvidia-container-runtime-hook.prestart-mode %q", c.PrestartMode)
+	}
 }
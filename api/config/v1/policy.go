@@ -0,0 +1,102 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// PolicyConfig defines the rules that restrict the devices that a container
+// may request.
+type PolicyConfig struct {
+	Rules []PolicyRule `toml:"rules,omitempty"`
+}
+
+// A PolicyRule restricts the devices that may be requested by the containers
+// that it applies to. A rule applies to a container if the container matches
+// the Match selector and does not match the Except selector.
+type PolicyRule struct {
+	// Name is used to identify the rule in log messages and errors.
+	Name   string         `toml:"name,omitempty"`
+	Match  PolicySelector `toml:"match,omitempty"`
+	Except PolicySelector `toml:"except,omitempty"`
+	// AllowedKinds, if set, restricts the CDI devices that may be requested
+	// to those with a matching kind. Glob patterns are supported.
+	AllowedKinds []string `toml:"allowed-kinds,omitempty"`
+	// DeniedKinds lists the kinds of CDI devices that may not be requested.
+	// Glob patterns are supported.
+	DeniedKinds []string `toml:"denied-kinds,omitempty"`
+	// MaxDevices, if set, limits the number of devices that may be requested.
+	// A request for all devices counts as the number of GPUs on the host.
+	MaxDevices *int `toml:"max-devices,omitempty"`
+	// DenyImexChannels prevents IMEX channels from being requested.
+	DenyImexChannels bool `toml:"deny-imex-channels,omitempty"`
+}
+
+// A PolicySelector selects containers based on their properties. All
+// specified properties must match for a container to be selected. An empty
+// selector matches all containers.
+type PolicySelector struct {
+	// Annotations maps annotation keys to glob patterns for their values.
+	Annotations map[string]string `toml:"annotations,omitempty"`
+	// Labels maps label keys to glob patterns for their values.
+	// Note that labels are only available when running as an NRI plugin or
+	// if the container engine records them in the OCI runtime specification
+	// (e.g. CRI-O).
+	Labels map[string]string `toml:"labels,omitempty"`
+	// Namespaces lists the kubernetes namespaces to match.
+	Namespaces []string `toml:"namespaces,omitempty"`
+	// CgroupsPaths lists glob patterns for the cgroups path of the container.
+	CgroupsPaths []string `toml:"cgroups-paths,omitempty"`
+}
+
+// IsEmpty checks whether no properties are specified for the selector.
+func (s PolicySelector) IsEmpty() bool {
+	return len(s.Annotations) == 0 && len(s.Labels) == 0 && len(s.Namespaces) == 0 && len(s.CgroupsPaths) == 0
+}
+
+// assertValid checks that the glob patterns in the policy rules are valid.
+func (p PolicyConfig) assertValid() error {
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		var patterns []string
+		patterns = append(patterns, rule.AllowedKinds...)
+		patterns = append(patterns, rule.DeniedKinds...)
+		for _, selector := range []PolicySelector{rule.Match, rule.Except} {
+			patterns = append(patterns, selector.CgroupsPaths...)
+			for _, pattern := range selector.Annotations {
+				patterns = append(patterns, pattern)
+			}
+			for _, pattern := range selector.Labels {
+				patterns = append(patterns, pattern)
+			}
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("policy rule %v: invalid pattern %q: %w", name, pattern, err)
+			}
+		}
+		if rule.MaxDevices != nil && *rule.MaxDevices < 0 {
+			return fmt.Errorf("policy rule %v: max-devices must not be negative", name)
+		}
+	}
+	return nil
+}
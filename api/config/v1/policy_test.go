@@ -0,0 +1,76 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/test/to"
+)
+
+func TestPolicyConfig(t *testing.T) {
+	defer setGetLdConfigPathForTest()()
+
+	contents := `
+[[policy.rules]]
+name = "management-devices"
+denied-kinds = ["management.nvidia.com/gpu"]
+[policy.rules.except]
+namespaces = ["gpu-operator"]
+
+[[policy.rules]]
+allowed-kinds = ["nvidia.com/*"]
+max-devices = 2
+deny-imex-channels = true
+[policy.rules.match]
+cgroups-paths = ["/kubepods/besteffort/*"]
+[policy.rules.match.annotations]
+"example.com/tenant" = "team-*"
+`
+	tomlCfg, err := loadConfigTomlFrom(strings.NewReader(contents))
+	require.NoError(t, err)
+
+	cfg, err := tomlCfg.Config()
+	require.NoError(t, err)
+
+	expected := PolicyConfig{
+		Rules: []PolicyRule{
+			{
+				Name:        "management-devices",
+				DeniedKinds: []string{"management.nvidia.com/gpu"},
+				Except: PolicySelector{
+					Namespaces: []string{"gpu-operator"},
+				},
+			},
+			{
+				AllowedKinds:     []string{"nvidia.com/*"},
+				MaxDevices:       to.Ptr(2),
+				DenyImexChannels: true,
+				Match: PolicySelector{
+					CgroupsPaths: []string{"/kubepods/besteffort/*"},
+					Annotations: map[string]string{
+						"example.com/tenant": "team-*",
+					},
+				},
+			},
+		},
+	}
+	require.EqualValues(t, expected, cfg.Policy)
+}
@@ -23,6 +23,13 @@ type RuntimeConfig struct {
 	DebugFilePath string `toml:"debug"`
 	// LogLevel defines the logging level for the application
 	LogLevel string `toml:"log-level"`
+	// LogFormat defines the format of the log records; one of [text, json].
+	// A format specified on the command line takes precedence.
+	LogFormat string `toml:"log-format,omitempty"`
+	// AuditLogFilePath, if set, specifies a file to which a record of the
+	// device injection decisions is appended for each container that is
+	// created.
+	AuditLogFilePath string `toml:"audit-log,omitempty"`
 	// Runtimes defines the candidates for the low-level runtime
 	Runtimes []string    `toml:"runtimes"`
 	Mode     string      `toml:"mode"`
@@ -51,8 +58,19 @@ type jitCDIModeConfig struct {
 	NVCDIFeatureFlags []nvcdi.FeatureFlag `toml:"nvcdi-feature-flags,omitempty"`
 	// NVCDIDisableHooks sets a list of nvcdi hooks to disable
 	NVCDIDisableHooks []nvcdi.HookName `toml:"nvcdi-disable-hooks,omitempty"`
+	// SpecCacheDir specifies a directory in which generated CDI specs are
+	// cached. A cached spec is reused for subsequent containers requesting the
+	// same devices until the driver or the devices on the system change.
+	// The default is a directory on a tmpfs so that the cache does not
+	// persist across reboots. If this is set to an empty string, specs are not
+	// cached.
+	SpecCacheDir string `toml:"spec-cache-dir"`
 }
 
+// defaultJitCDISpecCacheDir is the default directory in which the CDI specs
+// generated in jit-cdi mode are cached.
+const defaultJitCDISpecCacheDir = "/run/nvidia-container-toolkit/jit-cdi"
+
 type csvModeConfig struct {
 	MountSpecPath string `toml:"mount-spec-path"`
 	// CompatContainerRoot specifies the compat root used when the the standard
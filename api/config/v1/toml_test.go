@@ -76,6 +76,9 @@ spec-dirs = ["/etc/cdi", "/var/run/cdi"]
 [nvidia-container-runtime.modes.csv]
 mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+[nvidia-container-runtime.modes.jit-cdi]
+spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
 [nvidia-container-runtime.modes.legacy]
 cuda-compat-mode = "ldconfig"
 
@@ -132,6 +135,9 @@ spec-dirs = ["/etc/cdi", "/var/run/cdi"]
 [nvidia-container-runtime.modes.csv]
 mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+[nvidia-container-runtime.modes.jit-cdi]
+spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
 [nvidia-container-runtime.modes.legacy]
 cuda-compat-mode = "ldconfig"
 
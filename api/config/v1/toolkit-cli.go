@@ -16,7 +16,64 @@
 
 package config
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
 // CTKConfig stores the config options for the NVIDIA Container Toolkit CLI (nvidia-ctk)
 type CTKConfig struct {
 	Path string `toml:"path"`
+	// LogFormat defines the format of the log records for the nvidia-ctk and
+	// nvidia-cdi-hook CLIs; one of [text, json].
+	LogFormat string `toml:"log-format,omitempty"`
+	// Hooks sets the timeout and failure policy of the CDI hooks by hook name,
+	// for example update-ldcache. The settings for "all" apply to the hooks
+	// that are not listed.
+	Hooks map[string]CDIHookConfig `toml:"hooks,omitempty"`
+}
+
+// CDIHookConfig stores the config options for a CDI hook run by the
+// nvidia-cdi-hook.
+type CDIHookConfig struct {
+	// Timeout is the maximum time that the hook is allowed to run, for example
+	// "30s". If this is not set, the hook is not interrupted.
+	Timeout string `toml:"timeout,omitempty"`
+	// FailurePolicy defines how a hook that fails or times out is handled; one
+	// of [fail, warn, skip]. The default is fail.
+	FailurePolicy string `toml:"failure-policy,omitempty"`
+}
+
+// HookPolicies returns the configured timeouts and failure policies of the
+// CDI hooks.
+func (c CTKConfig) HookPolicies() (map[nvcdi.HookName]nvcdi.HookPolicy, error) {
+	if len(c.Hooks) == 0 {
+		return nil, nil
+	}
+	policies := make(map[nvcdi.HookName]nvcdi.HookPolicy)
+	for name, hook := range c.Hooks {
+		policy := nvcdi.HookPolicy{
+			FailurePolicy: nvcdi.HookFailurePolicy(hook.FailurePolicy),
+		}
+		if hook.Timeout != "" {
+			timeout, err := time.ParseDuration(hook.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout for hook %v: %w", name, err)
+			}
+			policy.Timeout = timeout
+		}
+		if err := policy.AssertValid(); err != nil {
+			return nil, fmt.Errorf("invalid config for hook %v: %w", name, err)
+		}
+		policies[nvcdi.HookName(name)] = policy
+	}
+	return policies, nil
+}
+
+// assertValid checks that the configured hook policies are valid.
+func (c CTKConfig) assertValid() error {
+	_, err := c.HookPolicies()
+	return err
 }
@@ -102,6 +102,13 @@ func ConfigureCDIHookCommand(logger logger.Interface, base *cli.Command) *cli.Co
 		},
 	}
 
+	// The timeout and failure policy are applied to each of the hooks.
+	policy := &hookPolicy{}
+	base.Flags = append(base.Flags, policy.flags()...)
+	for _, hook := range base.Commands {
+		hook.Action = policy.wrap(logger, hook.Action)
+	}
+
 	return base
 }
 
@@ -0,0 +1,105 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+// A hookPolicy defines the timeout and failure policy that is applied when
+// running a hook. These are set in the environment of the hooks in a
+// generated CDI specification.
+type hookPolicy struct {
+	timeout       time.Duration
+	failurePolicy string
+}
+
+// flags returns the flags used to configure the policy.
+func (p *hookPolicy) flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:        "timeout",
+			Usage:       "Specify the maximum time that a hook is allowed to run. If this is zero, the hook is not interrupted.",
+			Destination: &p.timeout,
+			Sources:     cli.EnvVars(discover.HookTimeoutEnvVar),
+		},
+		&cli.StringFlag{
+			Name:        "failure-policy",
+			Usage:       "Specify how a hook that fails or times out is handled; one of [fail, warn, skip]. If this is skip, the hook is not run.",
+			Value:       string(discover.HookFailurePolicyFail),
+			Destination: &p.failurePolicy,
+			Sources:     cli.EnvVars(discover.HookFailurePolicyEnvVar),
+		},
+	}
+}
+
+// wrap returns an action that runs the specified action of a hook according
+// to the policy.
+func (p *hookPolicy) wrap(logger logger.Interface, action cli.ActionFunc) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		failurePolicy := discover.HookFailurePolicy(p.failurePolicy)
+		if err := (discover.HookPolicy{Timeout: p.timeout, FailurePolicy: failurePolicy}).AssertValid(); err != nil {
+			logger.Warningf("Ignoring %v; the hook is run with the default policy", err)
+			p.timeout = 0
+			failurePolicy = discover.HookFailurePolicyFail
+		}
+
+		if failurePolicy == discover.HookFailurePolicySkip {
+			logger.Infof("Skipping hook %v", cmd.Name)
+			return nil
+		}
+
+		err := p.run(ctx, cmd, action)
+		if err == nil || failurePolicy != discover.HookFailurePolicyWarn {
+			return err
+		}
+		logger.Warningf("Ignoring failure of hook %v: %v", cmd.Name, err)
+		return nil
+	}
+}
+
+// run runs the specified action, returning an error if it does not complete
+// within the timeout. The action is not stopped when the timeout expires, but
+// since the process exits once the hook returns, the hook does not block the
+// creation of the container.
+func (p *hookPolicy) run(ctx context.Context, cmd *cli.Command, action cli.ActionFunc) error {
+	if p.timeout <= 0 {
+		return action(ctx, cmd)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- action(ctx, cmd)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("hook timed out after %v", p.timeout)
+	}
+}
@@ -0,0 +1,121 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestHookPolicy(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description   string
+		policy        hookPolicy
+		actionError   error
+		actionDelay   time.Duration
+		expectedRun   bool
+		expectedError string
+	}{
+		{
+			description: "successful hook",
+			policy:      hookPolicy{failurePolicy: "fail"},
+			expectedRun: true,
+		},
+		{
+			description:   "failure fails hook",
+			policy:        hookPolicy{failurePolicy: "fail"},
+			actionError:   errors.New("failed"),
+			expectedRun:   true,
+			expectedError: "failed",
+		},
+		{
+			description: "failure is ignored with warn policy",
+			policy:      hookPolicy{failurePolicy: "warn"},
+			actionError: errors.New("failed"),
+			expectedRun: true,
+		},
+		{
+			description: "hook is not run with skip policy",
+			policy:      hookPolicy{failurePolicy: "skip"},
+			actionError: errors.New("failed"),
+		},
+		{
+			description:   "timeout fails hook",
+			policy:        hookPolicy{timeout: 10 * time.Millisecond, failurePolicy: "fail"},
+			actionDelay:   time.Minute,
+			expectedRun:   true,
+			expectedError: "hook timed out after 10ms",
+		},
+		{
+			description: "timeout is ignored with warn policy",
+			policy:      hookPolicy{timeout: 10 * time.Millisecond, failurePolicy: "warn"},
+			actionDelay: time.Minute,
+			expectedRun: true,
+		},
+		{
+			description: "hook completes within timeout",
+			policy:      hookPolicy{timeout: time.Minute, failurePolicy: "fail"},
+			expectedRun: true,
+		},
+		{
+			description:   "invalid policy is replaced by default",
+			policy:        hookPolicy{failurePolicy: "unknown"},
+			actionError:   errors.New("failed"),
+			expectedRun:   true,
+			expectedError: "failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			run := make(chan struct{}, 1)
+			action := func(ctx context.Context, _ *cli.Command) error {
+				run <- struct{}{}
+				select {
+				case <-time.After(tc.actionDelay):
+				case <-ctx.Done():
+				}
+				return tc.actionError
+			}
+
+			policy := tc.policy
+			err := policy.wrap(logger, action)(context.Background(), &cli.Command{Name: "test-hook"})
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			if !tc.expectedRun {
+				require.Empty(t, run)
+				return
+			}
+			select {
+			case <-run:
+			case <-time.After(time.Second):
+				t.Fatal("hook was not run")
+			}
+		})
+	}
+}
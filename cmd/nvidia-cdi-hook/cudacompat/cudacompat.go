@@ -19,6 +19,7 @@ package cudacompat
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -191,59 +192,66 @@ func (m command) getContainerForwardCompatDir(containerRoot *root, o *options) (
 
 func (m command) useCompatLibraries(libcudaCompatFile *os.File, hostDriverVersion string, hostCUDAVersion string) (bool, error) {
 	compatDriverVersion := strings.TrimPrefix(filepath.Base(libcudaCompatFile.Name()), "libcuda.so.")
+	return UseCompatLibraries(m.logger, libcudaCompatFile, compatDriverVersion, hostDriverVersion, hostCUDAVersion), nil
+}
 
+// UseCompatLibraries checks whether the CUDA compat libraries in a container
+// should be used instead of the host driver libraries. The specified library
+// is the libcuda.so from the compat directory in the container and the compat
+// driver version is the version from its filename.
+func UseCompatLibraries(logger logger.Interface, libcudaCompat io.ReaderAt, compatDriverVersion string, hostDriverVersion string, hostCUDAVersion string) bool {
 	// Parse the version strings up front so that the checks below -- both against
 	// the ELF header and the fallback major-version comparison -- operate on
 	// well-formed semantic versions. If a version string cannot be parsed we log
 	// a warning and leave it unset; the checks below account for missing versions.
 	compatDriverSemver, err := semver.NewVersion(compatDriverVersion)
 	if err != nil {
-		m.logger.Warningf("failed to parse compat driver version %q: %v", compatDriverVersion, err)
+		logger.Warningf("failed to parse compat driver version %q: %v", compatDriverVersion, err)
 	}
 
 	hostDriverSemver, err := semver.NewVersion(hostDriverVersion)
 	if err != nil {
-		m.logger.Warningf("failed to parse host driver version %q: %v", hostDriverVersion, err)
+		logger.Warningf("failed to parse host driver version %q: %v", hostDriverVersion, err)
 	}
 
 	hostCUDASemver, err := semver.NewVersion(hostCUDAVersion)
 	if err != nil {
-		m.logger.Warningf("failed to parse host CUDA version %q: %v", hostCUDAVersion, err)
+		logger.Warningf("failed to parse host CUDA version %q: %v", hostCUDAVersion, err)
 	}
 
 	// First check the ELF header of the libcuda.so included in the compat directory.
 	// If this is present, we use the ELF header to determine whether the CUDA compat
 	// libraries in the container should be used over the host driver libraries.
-	cudaCompatHeader, err := GetCUDACompatElfHeaderFromReader(libcudaCompatFile)
+	cudaCompatHeader, err := GetCUDACompatElfHeaderFromReader(libcudaCompat)
 	if err != nil {
-		m.logger.Warningf("failed to get ELF header from CUDA compat library: %w", err)
+		logger.Warningf("failed to get ELF header from CUDA compat library: %w", err)
 	}
 	if cudaCompatHeader != nil {
-		return cudaCompatHeader.UseCompat(compatDriverSemver, hostDriverSemver, hostCUDASemver), nil
+		return cudaCompatHeader.UseCompat(compatDriverSemver, hostDriverSemver, hostCUDASemver)
 	}
 
 	// If the host CUDA version is specified, we need to inspect the ELF header
 	// of the compat libraries in the container to determine whether these
 	// should be used. Return early if we cannot read the ELF header.
 	if hostCUDAVersion != "" {
-		return false, nil
+		return false
 	}
 
 	// If we could not determine the host driver version or the compat driver
 	// version, we don't use the CUDA compat libraries in the container.
 	if hostDriverSemver == nil || compatDriverSemver == nil {
-		return false, nil
+		return false
 	}
 
 	// If we reach this point, it means we could not read the ELF header but
 	// the host driver version is specified. We fall back to comparing the major
 	// versions of the host driver and compat driver.
 	if hostDriverSemver.Major() < compatDriverSemver.Major() {
-		return true, nil
+		return true
 	}
 
-	m.logger.Debugf("Compat major version is not greater than the host driver major version (%v >= %v)", hostDriverVersion, compatDriverVersion)
-	return false, nil
+	logger.Debugf("Compat major version is not greater than the host driver major version (%v >= %v)", hostDriverVersion, compatDriverVersion)
+	return false
 }
 
 // createLdsoconfdFile creates a file at /etc/ld.so.conf.d/ in the specified root.
@@ -19,10 +19,14 @@ package main
 import (
 	"context"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+	loggerlib "github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 
 	cli "github.com/urfave/cli/v3"
 
@@ -36,10 +40,13 @@ type options struct {
 	Debug bool
 	// Quiet indicates whether the CLI is started in "quiet" mode
 	Quiet bool
+	// LogFormat specifies the format of the log records
+	LogFormat string
 }
 
 func main() {
 	logger := logrus.New()
+	start := time.Now()
 
 	// Create a options struct to hold the parsed environment variables or command line flags
 	opts := options{}
@@ -59,7 +66,13 @@ func main() {
 				logLevel = logrus.ErrorLevel
 			}
 			logger.SetLevel(logLevel)
-			return ctx, nil
+			return ctx, loggerlib.ConfigureFormat(logger, opts.LogFormat, getLogFormatFromConfig)
+		},
+		After: func(ctx context.Context, cmd *cli.Command) error {
+			logger.WithFields(logrus.Fields{
+				loggerlib.FieldElapsed: time.Since(start).String(),
+			}).Debugf("Completed %v", strings.Join(os.Args[1:], " "))
+			return nil
 		},
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
@@ -77,6 +90,12 @@ func main() {
 				// TODO: Support for NVIDIA_CDI_QUIET is deprecated and NVIDIA_CTK_QUIET should be used instead.
 				Sources: cli.EnvVars("NVIDIA_CTK_QUIET", "NVIDIA_CDI_QUIET"),
 			},
+			&cli.StringFlag{
+				Name:        "log-format",
+				Usage:       "Specify the format of the log records; one of [text, json]. If this is not specified, the value from the config file is used.",
+				Destination: &opts.LogFormat,
+				Sources:     cli.EnvVars("NVIDIA_CTK_LOG_FORMAT"),
+			},
 		},
 	})
 
@@ -87,3 +106,13 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// getLogFormatFromConfig returns the log format for the nvidia-cdi-hook from
+// the config file. If the config cannot be loaded, the default is returned.
+func getLogFormatFromConfig() string {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return ""
+	}
+	return cfg.NVIDIACTKConfig.LogFormat
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"runtime"
 
 	"github.com/moby/sys/reexec"
 	"github.com/urfave/cli/v3"
@@ -124,6 +125,11 @@ func (m command) run(_ *cli.Command, cfg *options) error {
 	if err != nil {
 		return err
 	}
+	// The runner is killed when the thread that started it exits. We lock
+	// the goroutine to its thread to ensure that the thread is not
+	// terminated before the runner has exited.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
 	return runner.Run()
 }
 
@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"os"
 	"path"
@@ -26,7 +25,9 @@ type nvidiaConfig struct {
 }
 
 type containerConfig struct {
+	ID     string
 	Pid    int
+	Bundle string
 	Rootfs string
 	Image  image.CUDA
 	Nvidia *nvidiaConfig
@@ -67,7 +68,8 @@ type Spec struct {
 
 // HookState holds state information about the hook
 type HookState struct {
-	Pid int `json:"pid,omitempty"`
+	ID  string `json:"id,omitempty"`
+	Pid int    `json:"pid,omitempty"`
 	// After 17.06, runc is using the runtime spec:
 	// github.com/docker/runc/blob/17.06/libcontainer/configs/config.go#L262-L263
 	// github.com/opencontainers/runtime-spec/blob/v1.0.0/specs-go/state.go#L3-L17
@@ -153,28 +155,11 @@ func getMigDevices(image image.CUDA, envvar string) *string {
 }
 
 func (hookConfig *hookConfig) getDriverCapabilities(cudaImage image.CUDA, legacyImage bool) image.DriverCapabilities {
-	// We use the default driver capabilities by default. This is filtered to only include the
-	// supported capabilities
 	supportedDriverCapabilities := image.NewDriverCapabilities(hookConfig.SupportedDriverCapabilities)
-	capabilities := supportedDriverCapabilities.Intersection(image.DefaultDriverCapabilities)
-
-	capsEnvSpecified := cudaImage.HasEnvvar(image.EnvVarNvidiaDriverCapabilities)
-	capsEnv := cudaImage.Getenv(image.EnvVarNvidiaDriverCapabilities)
-
-	if !capsEnvSpecified && legacyImage {
-		// Environment variable unset with legacy image: set all capabilities.
-		return supportedDriverCapabilities
-	}
-
-	if capsEnvSpecified && len(capsEnv) > 0 {
-		// If the envvironment variable is specified and is non-empty, use the capabilities value
-		envCapabilities := image.NewDriverCapabilities(capsEnv)
-		capabilities = supportedDriverCapabilities.Intersection(envCapabilities)
-		if !envCapabilities.IsAll() && len(capabilities) != len(envCapabilities) {
-			log.Panicln(fmt.Errorf("unsupported capabilities found in '%v' (allowed '%v')", envCapabilities, capabilities))
-		}
+	capabilities, err := cudaImage.ResolveDriverCapabilities(supportedDriverCapabilities, legacyImage)
+	if err != nil {
+		log.Panicln(err)
 	}
-
 	return capabilities
 }
 
@@ -258,7 +243,9 @@ func (hookConfig *hookConfig) getContainerConfig() (config *containerConfig) {
 	}
 
 	cc := containerConfig{
+		ID:     h.ID,
 		Pid:    h.Pid,
+		Bundle: b,
 		Rootfs: s.Root.Path,
 		Image:  i,
 		Nvidia: hookConfig.getNvidiaConfig(i, privileged),
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -16,6 +17,8 @@ import (
 	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/lookup"
 )
 
@@ -88,6 +91,13 @@ func doPrestart() {
 
 	rootfs := getRootfsPath(container)
 
+	if hook.NVIDIAContainerRuntimeHookConfig.PrestartMode == config.PrestartModeNative {
+		if err := hook.doNativePrestart(container, rootfs); err != nil {
+			log.Panicln("native prestart failed:", err)
+		}
+		return
+	}
+
 	args := []string{getCLIPath(cli)}
 	if cli.Root != "" {
 		args = append(args, fmt.Sprintf("--root=%s", cli.Root))
@@ -142,9 +152,27 @@ func doPrestart() {
 		args = append(args, capabilityToCLI(cap))
 	}
 
+	// Requirements that reference properties not supported by the
+	// nvidia-container-cli are checked here.
+	var extendedRequirements []string
 	for _, req := range nvidia.Requirements {
+		if !requirements.IsLegacy(req) {
+			extendedRequirements = append(extendedRequirements, req)
+			continue
+		}
 		args = append(args, fmt.Sprintf("--require=%s", req))
 	}
+	if len(extendedRequirements) > 0 {
+		logger := &prestartLogger{}
+		driver := root.New(
+			root.WithLogger(logger),
+			root.WithDriverRoot(cli.Root),
+			root.WithDevRoot(cli.Root),
+		)
+		if err := checkRequirements(logger, driver, extendedRequirements, nvidia.Devices); err != nil {
+			log.Panicln("requirements not met:", err)
+		}
+	}
 
 	args = append(args, fmt.Sprintf("--pid=%s", strconv.FormatUint(uint64(container.Pid), 10)))
 	args = append(args, rootfs)
@@ -155,6 +183,28 @@ func doPrestart() {
 	log.Panicln("exec failed:", err)
 }
 
+// checkRequirements checks the requirements of the container against the
+// properties of the host and the requested devices. If the requirements are
+// not met, a report describing the failure is logged.
+func checkRequirements(logger logger.Interface, driver *root.Driver, containerRequirements []string, devices []string) error {
+	if len(containerRequirements) == 0 {
+		return nil
+	}
+	r := requirements.New(logger, containerRequirements)
+	host := requirements.NewHost(
+		requirements.WithLogger(logger),
+		requirements.WithDriver(driver),
+	)
+	host.AddProperties(r, devices...)
+
+	err := r.Assert()
+	var unsatisfied *requirements.UnsatisfiedError
+	if errors.As(err, &unsatisfied) {
+		log.Print(unsatisfied.Report)
+	}
+	return err
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of nvidia-container-runtime-hook:\n")
 	flag.PrintDefaults()
@@ -0,0 +1,187 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/injector"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/modifier/cdi"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+// doNativePrestart modifies the container using the edits generated by the
+// nvcdi package instead of invoking the nvidia-container-cli. The
+// modifications are the same as those made to the container in CDI mode.
+func (c *hookConfig) doNativePrestart(container *containerConfig, rootfs string) error {
+	logger := &prestartLogger{}
+	cli := c.NVIDIAContainerCLIConfig
+	nvidia := container.Nvidia
+
+	warnOnNativeDriverCapabilities(logger, nvidia.DriverCapabilities, c.SupportedDriverCapabilities)
+	if cli.LoadKmods {
+		logger.Warningf("Ignoring nvidia-container-cli.load-kmods in native prestart mode")
+	}
+	if nvidia.MigConfigDevices != "" || nvidia.MigMonitorDevices != "" {
+		logger.Warningf("Ignoring MIG config and monitor devices in native prestart mode")
+	}
+
+	driver := root.New(
+		root.WithLogger(logger),
+		root.WithDriverRoot(cli.Root),
+		root.WithDevRoot(cli.Root),
+	)
+
+	if err := checkRequirements(logger, driver, nvidia.Requirements, nvidia.Devices); err != nil {
+		return fmt.Errorf("requirements not met: %w", err)
+	}
+
+	modifications := &specs.Spec{}
+	for _, request := range getNativeDeviceRequests(nvidia) {
+		if err := c.applyNativeDeviceRequest(logger, driver, request, modifications); err != nil {
+			return err
+		}
+	}
+
+	i, err := injector.New(
+		injector.WithLogger(logger),
+		injector.WithContainerState(specs.State{
+			Version: specs.Version,
+			ID:      container.ID,
+			Status:  specs.StateCreating,
+			Pid:     container.Pid,
+			Bundle:  container.Bundle,
+		}),
+		injector.WithRootfs(rootfs),
+		injector.WithNoCgroups(cli.NoCgroups),
+	)
+	if err != nil {
+		return err
+	}
+	return i.Inject(modifications)
+}
+
+// warnOnNativeDriverCapabilities logs a warning if the requested driver
+// capabilities do not include all of the configured supported capabilities.
+// As in CDI mode, the generated CDI edits include all driver files and the
+// files are not filtered by capability.
+func warnOnNativeDriverCapabilities(logger logger.Interface, driverCapabilities string, supportedDriverCapabilities string) {
+	requested := image.NewDriverCapabilities(driverCapabilities)
+	supported := image.NewDriverCapabilities(supportedDriverCapabilities)
+	if requested.IsAll() || requested.IsSuperset(supported) {
+		return
+	}
+	logger.Warningf("Injecting the driver files for all supported capabilities %q in native prestart mode; requested capabilities are %q", supported, requested)
+}
+
+// A nativeDeviceRequest defines the devices for which edits are generated
+// using the specified nvcdi mode.
+type nativeDeviceRequest struct {
+	mode nvcdi.Mode
+	ids  []string
+}
+
+// getNativeDeviceRequests returns the device requests for the requested GPUs
+// and IMEX channels.
+func getNativeDeviceRequests(nvidia *nvidiaConfig) []nativeDeviceRequest {
+	requests := []nativeDeviceRequest{
+		{mode: nvcdi.ModeAuto, ids: nvidia.Devices},
+	}
+	if len(nvidia.ImexChannels) > 0 {
+		requests = append(requests, nativeDeviceRequest{mode: nvcdi.ModeImex, ids: nvidia.ImexChannels})
+	}
+	return requests
+}
+
+// applyNativeDeviceRequest generates a CDI specification for the requested
+// devices and applies the edits for all devices in the specification.
+func (c *hookConfig) applyNativeDeviceRequest(logger logger.Interface, driver *root.Driver, request nativeDeviceRequest, modifications *specs.Spec) error {
+	jitCDI := c.NVIDIAContainerRuntimeConfig.Modes.JitCDI
+	hookPolicies, err := c.NVIDIACTKConfig.HookPolicies()
+	if err != nil {
+		return fmt.Errorf("invalid hook config: %w", err)
+	}
+	cdilib, err := nvcdi.New(
+		nvcdi.WithLogger(logger),
+		nvcdi.WithNVIDIACDIHookPath(c.NVIDIACTKConfig.Path),
+		nvcdi.WithDriverRoot(driver.Root),
+		nvcdi.WithDevRoot(driver.DevRoot),
+		nvcdi.WithLdconfigPath(c.getNativeLdconfigPath(logger)),
+		nvcdi.WithMode(request.mode),
+		nvcdi.WithFeatureFlags(jitCDI.NVCDIFeatureFlags...),
+		nvcdi.WithDisabledHooks(jitCDI.NVCDIDisableHooks...),
+		nvcdi.WithHookPolicies(hookPolicies),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to construct CDI library for mode %q: %w", request.mode, err)
+	}
+	spec, err := cdilib.GetSpec(request.ids...)
+	if err != nil {
+		return fmt.Errorf("failed to generate CDI spec for mode %q: %w", request.mode, err)
+	}
+
+	modifier, err := cdi.New(
+		cdi.WithLogger(logger),
+		cdi.WithSpec(spec.Raw()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to construct CDI modifier for mode %q: %w", request.mode, err)
+	}
+	return modifier.Modify(modifications)
+}
+
+// getNativeLdconfigPath returns the host path of the ldconfig binary used to
+// update the ldcache in the container. The nvcdi default is used if the
+// configured ldconfig is not a host path.
+func (c *hookConfig) getNativeLdconfigPath(logger logger.Interface) string {
+	ldconfigPath := c.NVIDIAContainerCLIConfig.NormalizeLDConfigPath()
+	if ldconfigPath == "" {
+		return ""
+	}
+	if !strings.HasPrefix(ldconfigPath, "@") {
+		logger.Warningf("Ignoring container ldconfig path %v in native prestart mode", ldconfigPath)
+		return ""
+	}
+	return strings.TrimPrefix(ldconfigPath, "@")
+}
+
+// A prestartLogger logs the messages of the native prestart hook. Debug
+// messages are only logged if the debug flag is set.
+type prestartLogger struct {
+	logInterceptor
+}
+
+func (l *prestartLogger) Debugf(format string, args ...any) {
+	if *debugflag {
+		log.Printf(format, args...)
+	}
+}
+
+func (l *prestartLogger) Warningf(format string, args ...any) {
+	log.Printf("WARNING: "+format, args...)
+}
+
+func (l *prestartLogger) Errorf(format string, args ...any) {
+	log.Printf("ERROR: "+format, args...)
+}
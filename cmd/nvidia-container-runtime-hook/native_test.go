@@ -0,0 +1,152 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+func TestGetNativeDeviceRequests(t *testing.T) {
+	testCases := []struct {
+		description string
+		nvidia      *nvidiaConfig
+		expected    []nativeDeviceRequest
+	}{
+		{
+			description: "devices only",
+			nvidia:      &nvidiaConfig{Devices: []string{"0", "GPU-1"}},
+			expected: []nativeDeviceRequest{
+				{mode: nvcdi.ModeAuto, ids: []string{"0", "GPU-1"}},
+			},
+		},
+		{
+			description: "devices and imex channels",
+			nvidia:      &nvidiaConfig{Devices: []string{"all"}, ImexChannels: []string{"0"}},
+			expected: []nativeDeviceRequest{
+				{mode: nvcdi.ModeAuto, ids: []string{"all"}},
+				{mode: nvcdi.ModeImex, ids: []string{"0"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, getNativeDeviceRequests(tc.nvidia))
+		})
+	}
+}
+
+func TestWarnOnNativeDriverCapabilities(t *testing.T) {
+	testCases := []struct {
+		description                 string
+		driverCapabilities          string
+		supportedDriverCapabilities string
+		expectedWarning             bool
+	}{
+		{
+			description:                 "all capabilities",
+			driverCapabilities:          "all",
+			supportedDriverCapabilities: "compat32,compute,display,graphics,ngx,utility,video",
+		},
+		{
+			description:                 "all supported capabilities",
+			driverCapabilities:          "compat32,compute,display,graphics,ngx,utility,video",
+			supportedDriverCapabilities: "compat32,compute,display,graphics,ngx,utility,video",
+		},
+		{
+			description:                 "default capabilities",
+			driverCapabilities:          "compute,utility",
+			supportedDriverCapabilities: "compat32,compute,display,graphics,ngx,utility,video",
+			expectedWarning:             true,
+		},
+		{
+			description:                 "default capabilities with narrowed supported capabilities",
+			driverCapabilities:          "compute,utility",
+			supportedDriverCapabilities: "compute,utility",
+		},
+		{
+			description:                 "all capabilities with narrowed supported capabilities",
+			driverCapabilities:          "all",
+			supportedDriverCapabilities: "compute,utility",
+		},
+		{
+			description:                 "no capabilities",
+			supportedDriverCapabilities: "compute,utility",
+			expectedWarning:             true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			logger, hook := testlog.NewNullLogger()
+			warnOnNativeDriverCapabilities(logger, tc.driverCapabilities, tc.supportedDriverCapabilities)
+			if tc.expectedWarning {
+				require.Len(t, hook.AllEntries(), 1)
+				require.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+				return
+			}
+			require.Empty(t, hook.AllEntries())
+		})
+	}
+}
+
+func TestGetNativeLdconfigPath(t *testing.T) {
+	testCases := []struct {
+		description string
+		ldconfig    string
+		expected    string
+	}{
+		{
+			description: "host path",
+			ldconfig:    "@/some/host/ldconfig",
+			expected:    "/some/host/ldconfig",
+		},
+		{
+			description: "container path is ignored",
+			ldconfig:    "/sbin/ldconfig",
+			expected:    "",
+		},
+		{
+			description: "empty path",
+			expected:    "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			toml, err := config.TreeFromMap(map[string]any{
+				"nvidia-container-cli": map[string]any{
+					"ldconfig": tc.ldconfig,
+				},
+			})
+			require.NoError(t, err)
+			c := &hookConfig{
+				Config: &config.Config{},
+			}
+			require.NoError(t, toml.Unmarshal(c.Config))
+			require.Equal(t, tc.expected, c.getNativeLdconfigPath(&logger.NullLogger{}))
+		})
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -29,7 +28,9 @@ import (
 	"github.com/containerd/nri/pkg/plugin"
 	"github.com/containerd/nri/pkg/stub"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/policy"
 )
 
 // Compile-time interface checks
@@ -41,6 +42,10 @@ const (
 	// nriCDIAnnotationDomain is the domain name used for CDI device annotations
 	nriCDIAnnotationDomain = "nvidia.cdi.k8s.io"
 
+	// managementCDIDeviceKind is the kind of the CDI devices that are only
+	// injected for pods in the allowed namespaces.
+	managementCDIDeviceKind = "management.nvidia.com/gpu"
+
 	// nriReconnectBackoff is the backoff time between retries when attempting to connect the NRI Plugin to the ttrpc server
 	nriReconnectBackoff = 2 * time.Second
 )
@@ -49,8 +54,8 @@ type Plugin struct {
 	ctx    context.Context
 	logger logger.Interface
 
-	namespaces []string
-	stub       stub.Stub
+	policy policy.Interface
+	stub   stub.Stub
 
 	// stopped is set before Stop() so OnClose does not reconnect during shutdown.
 	stopped atomic.Bool
@@ -58,15 +63,34 @@ type Plugin struct {
 	reconnectInProgress atomic.Bool
 }
 
-// NewPlugin creates a new NRI plugin for injecting CDI devices
+// NewPlugin creates a new NRI plugin for injecting CDI devices.
+// Management CDI devices are only injected for pods in the specified
+// namespaces.
 func NewPlugin(ctx context.Context, logger logger.Interface, namespaces []string) *Plugin {
 	return &Plugin{
-		ctx:        ctx,
-		logger:     logger,
-		namespaces: namespaces,
+		ctx:    ctx,
+		logger: logger,
+		policy: newManagementDevicePolicy(logger, namespaces),
 	}
 }
 
+// newManagementDevicePolicy creates a policy that denies management CDI
+// devices to pods outside of the specified namespaces.
+func newManagementDevicePolicy(logger logger.Interface, namespaces []string) policy.Interface {
+	return policy.New(
+		policy.WithLogger(logger),
+		policy.WithRules(
+			config.PolicyRule{
+				Name:        "management-devices",
+				DeniedKinds: []string{managementCDIDeviceKind},
+				Except: config.PolicySelector{
+					Namespaces: namespaces,
+				},
+			},
+		),
+	)
+}
+
 // CreateContainer handles container creation requests.
 func (p *Plugin) CreateContainer(_ context.Context, pod *api.PodSandbox, ctr *api.Container) (*api.ContainerAdjustment, []*api.ContainerUpdate, error) {
 	adjust := &api.ContainerAdjustment{}
@@ -107,15 +131,18 @@ func (p *Plugin) parseCDIDevices(pod *api.PodSandbox, key, container string) []s
 		return nil
 	}
 
-	if strings.Contains(cdiDeviceNames, "management.nvidia.com/gpu") {
-		if !slices.Contains(p.namespaces, pod.Namespace) {
-			p.logger.Infof("pod %s/%s is requesting one or more management CDI devices, but it is not in one of the allowed "+
-				"namespaces %s. Skipping CDI device injection...", pod.Namespace, pod.Name, strings.Join(p.namespaces, ", "))
-			return nil
-		}
+	cdiDevices := strings.Split(cdiDeviceNames, ",")
+
+	c := policy.Container{
+		Annotations: pod.Annotations,
+		Labels:      pod.Labels,
+		Namespace:   pod.Namespace,
+	}
+	if err := p.policy.Check(c, policy.Request{Devices: cdiDevices}); err != nil {
+		p.logger.Infof("pod %s/%s is requesting CDI devices that are not allowed: %v. Skipping CDI device injection...", pod.Namespace, pod.Name, err)
+		return nil
 	}
 
-	cdiDevices := strings.Split(cdiDeviceNames, ",")
 	return cdiDevices
 }
 
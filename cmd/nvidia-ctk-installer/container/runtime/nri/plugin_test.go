@@ -34,8 +34,8 @@ func (nullLogger) Tracef(string, ...any)   {}
 
 func newTestPlugin(namespaces []string) *Plugin {
 	return &Plugin{
-		logger:     nullLogger{},
-		namespaces: namespaces,
+		logger: nullLogger{},
+		policy: newManagementDevicePolicy(nullLogger{}, namespaces),
 	}
 }
 
@@ -83,6 +83,9 @@ swarm-resource = ""
     [nvidia-container-runtime.modes.csv]
       mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+    [nvidia-container-runtime.modes.jit-cdi]
+      spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
     [nvidia-container-runtime.modes.legacy]
       cuda-compat-mode = "ldconfig"
 
@@ -147,6 +150,9 @@ swarm-resource = ""
     [nvidia-container-runtime.modes.csv]
       mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+    [nvidia-container-runtime.modes.jit-cdi]
+      spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
     [nvidia-container-runtime.modes.legacy]
       cuda-compat-mode = "ldconfig"
 
@@ -214,6 +220,9 @@ swarm-resource = ""
     [nvidia-container-runtime.modes.csv]
       mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+    [nvidia-container-runtime.modes.jit-cdi]
+      spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
     [nvidia-container-runtime.modes.legacy]
       cuda-compat-mode = "ldconfig"
 
@@ -278,6 +287,9 @@ swarm-resource = ""
     [nvidia-container-runtime.modes.csv]
       mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+    [nvidia-container-runtime.modes.jit-cdi]
+      spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
     [nvidia-container-runtime.modes.legacy]
       cuda-compat-mode = "ldconfig"
 
@@ -367,6 +379,9 @@ swarm-resource = ""
     [nvidia-container-runtime.modes.csv]
       mount-spec-path = "/etc/nvidia-container-runtime/host-files-for-container.d"
 
+    [nvidia-container-runtime.modes.jit-cdi]
+      spec-cache-dir = "/run/nvidia-container-toolkit/jit-cdi"
+
     [nvidia-container-runtime.modes.legacy]
       cuda-compat-mode = "ldconfig"
 
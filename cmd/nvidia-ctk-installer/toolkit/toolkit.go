@@ -31,6 +31,7 @@ import (
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk-installer/toolkit/installer"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
 	transformroot "github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform/root"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/system/nvdevices"
 )
@@ -41,11 +42,14 @@ const (
 )
 
 type cdiOptions struct {
-	Enabled   bool
-	outputDir string
-	kind      string
-	vendor    string
-	class     string
+	Enabled              bool
+	outputDir            string
+	modes                []string
+	kind                 string
+	vendor               string
+	class                string
+	deviceNameStrategies []string
+	deviceNamers         []nvcdi.DeviceNamer
 }
 
 type Options struct {
@@ -181,6 +185,13 @@ func Flags(opts *Options) []cli.Flag {
 			Destination: &opts.CDI.outputDir,
 			Sources:     cli.EnvVars("CDI_OUTPUT_DIR"),
 		},
+		&cli.StringSliceFlag{
+			Name:        "cdi-mode",
+			Usage:       "the modes to generate CDI specifications for. A specification named after its kind is generated for each mode. If multiple modes are specified, the default class for each mode is used instead of the class specified in --cdi-kind.",
+			Value:       []string{string(nvcdi.ModeManagement)},
+			Destination: &opts.CDI.modes,
+			Sources:     cli.EnvVars("CDI_MODES"),
+		},
 		&cli.StringFlag{
 			Name:        "cdi-kind",
 			Usage:       "the vendor string to use for the generated CDI specification",
@@ -188,6 +199,13 @@ func Flags(opts *Options) []cli.Flag {
 			Destination: &opts.CDI.kind,
 			Sources:     cli.EnvVars("CDI_KIND"),
 		},
+		&cli.StringSliceFlag{
+			Name:        "cdi-device-name-strategy",
+			Usage:       "the strategies used to generate the device names in the generated CDI specifications. One of [index | uuid | type-index] or a Go template such as {{.PCIBusID}}",
+			Value:       []string{nvcdi.DeviceNameStrategyIndex},
+			Destination: &opts.CDI.deviceNameStrategies,
+			Sources:     cli.EnvVars("CDI_DEVICE_NAME_STRATEGY"),
+		},
 		&cli.BoolFlag{
 			Name:        "ignore-errors",
 			Usage:       "ignore errors when installing the NVIDIA Container toolkit. This is used for testing purposes only.",
@@ -260,6 +278,31 @@ func (t *Installer) ValidateOptions(opts *Options) error {
 	opts.CDI.vendor = vendor
 	opts.CDI.class = class
 
+	if len(opts.CDI.modes) == 0 {
+		opts.CDI.modes = []string{string(nvcdi.ModeManagement)}
+	}
+	for i, mode := range opts.CDI.modes {
+		mode = strings.ToLower(mode)
+		if !nvcdi.IsValidMode(mode) {
+			return fmt.Errorf("invalid --cdi-mode value: %v", mode)
+		}
+		opts.CDI.modes[i] = mode
+	}
+	if len(opts.CDI.modes) > 1 {
+		opts.CDI.class = ""
+	}
+
+	opts.CDI.deviceNamers = nil
+	// Templates that contain commas are split when the flag is parsed.
+	opts.CDI.deviceNameStrategies = nvcdi.JoinDeviceNameStrategies(opts.CDI.deviceNameStrategies)
+	for _, strategy := range opts.CDI.deviceNameStrategies {
+		deviceNamer, err := nvcdi.NewDeviceNamer(strategy)
+		if err != nil {
+			return fmt.Errorf("invalid --cdi-device-name-strategy value: %w", err)
+		}
+		opts.CDI.deviceNamers = append(opts.CDI.deviceNamers, deviceNamer)
+	}
+
 	if opts.CDI.Enabled && opts.CDI.outputDir == "" {
 		t.logger.Warningf("Skipping CDI spec generation (no output directory specified)")
 		opts.CDI.Enabled = false
@@ -523,28 +566,57 @@ func (t *Installer) createDeviceNodes(opts *Options) error {
 	return nil
 }
 
-// generateCDISpec generates a CDI spec for use in management containers
+// generateCDISpec generates a CDI spec for each of the configured modes. By
+// default a spec for use in management containers is generated.
 func (t *Installer) generateCDISpec(opts *Options, nvidiaCDIHookPath string) error {
 	if !opts.CDI.Enabled {
 		return nil
 	}
-	t.logger.Infof("Generating CDI spec for management containers")
+	modesByName := make(map[string]string)
+	for _, mode := range opts.CDI.modes {
+		spec, err := t.getCDISpecForMode(opts, mode, nvidiaCDIHookPath)
+		if err != nil {
+			return err
+		}
+
+		name, err := cdi.GenerateNameForSpec(spec.Raw())
+		if err != nil {
+			return fmt.Errorf("failed to generate CDI name for %v mode: %v", mode, err)
+		}
+		if other, ok := modesByName[name]; ok {
+			return fmt.Errorf("modes %v and %v both generate CDI spec %v", other, mode, name)
+		}
+		modesByName[name] = mode
+
+		err = spec.Save(filepath.Join(opts.CDI.outputDir, name))
+		if err != nil {
+			return fmt.Errorf("failed to save CDI spec for %v mode: %v", mode, err)
+		}
+	}
+	return nil
+}
+
+// getCDISpecForMode generates the CDI spec for the specified mode with the
+// paths transformed to the host driver and dev roots.
+func (t *Installer) getCDISpecForMode(opts *Options, mode string, nvidiaCDIHookPath string) (spec.Interface, error) {
+	t.logger.Infof("Generating CDI spec for %v mode", mode)
 	cdilib, err := nvcdi.New(
 		nvcdi.WithLogger(t.logger),
-		nvcdi.WithMode(nvcdi.ModeManagement),
+		nvcdi.WithMode(mode),
 		nvcdi.WithDriverRoot(opts.DriverRootCtrPath),
 		nvcdi.WithDevRoot(opts.DevRootCtrPath),
 		nvcdi.WithNVIDIACDIHookPath(nvidiaCDIHookPath),
 		nvcdi.WithVendor(opts.CDI.vendor),
 		nvcdi.WithClass(opts.CDI.class),
+		nvcdi.WithDeviceNamers(opts.CDI.deviceNamers...),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create CDI library for management containers: %v", err)
+		return nil, fmt.Errorf("failed to create CDI library for %v mode: %v", mode, err)
 	}
 
 	spec, err := cdilib.GetSpec()
 	if err != nil {
-		return fmt.Errorf("failed to genereate CDI spec for management containers: %v", err)
+		return nil, fmt.Errorf("failed to generate CDI spec for %v mode: %v", mode, err)
 	}
 
 	transformer := transformroot.NewDriverTransformer(
@@ -554,17 +626,8 @@ func (t *Installer) generateCDISpec(opts *Options, nvidiaCDIHookPath string) err
 		transformroot.WithTargetDevRoot(opts.DevRoot),
 	)
 	if err := transformer.Transform(spec.Raw()); err != nil {
-		return fmt.Errorf("failed to transform driver root in CDI spec: %v", err)
-	}
-
-	name, err := cdi.GenerateNameForSpec(spec.Raw())
-	if err != nil {
-		return fmt.Errorf("failed to generate CDI name for management containers: %v", err)
-	}
-	err = spec.Save(filepath.Join(opts.CDI.outputDir, name))
-	if err != nil {
-		return fmt.Errorf("failed to save CDI spec for management containers: %v", err)
+		return nil, fmt.Errorf("failed to transform driver root in CDI spec: %v", err)
 	}
 
-	return nil
+	return spec, nil
 }
@@ -19,9 +19,13 @@ package cdi
 import (
 	"github.com/urfave/cli/v3"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/diff"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/generate"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/list"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/merge"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/split"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/transform"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/validate"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
 
@@ -46,9 +50,13 @@ func (m command) build() *cli.Command {
 		Name:  "cdi",
 		Usage: "Provide tools for interacting with Container Device Interface specifications",
 		Commands: []*cli.Command{
+			diff.NewCommand(m.logger),
 			generate.NewCommand(m.logger, m.configFilePath),
 			list.NewCommand(m.logger),
+			merge.NewCommand(m.logger),
+			split.NewCommand(m.logger),
 			transform.NewCommand(m.logger),
+			validate.NewCommand(m.logger),
 		},
 	}
 
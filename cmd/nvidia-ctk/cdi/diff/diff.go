@@ -0,0 +1,324 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type options struct {
+	from string
+	to   string
+}
+
+// NewCommand constructs a cdi diff command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the command
+	c := cli.Command{
+		Name:      "diff",
+		Usage:     "Show the differences between two CDI specifications",
+		ArgsUsage: "FROM_SPEC_FILE TO_SPEC_FILE",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if cmd.Args().Len() != 2 {
+				return ctx, errors.New("exactly two CDI specification files must be specified")
+			}
+			opts.from = cmd.Args().Get(0)
+			opts.to = cmd.Args().Get(1)
+			return ctx, nil
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+	}
+
+	return &c
+}
+
+func (m command) run(opts *options) error {
+	from, err := m.load(opts.from)
+	if err != nil {
+		return err
+	}
+	to, err := m.load(opts.to)
+	if err != nil {
+		return err
+	}
+
+	differences := diff(from, to)
+	if len(differences) == 0 {
+		m.logger.Infof("No differences found")
+		return nil
+	}
+
+	fmt.Printf("--- %v\n+++ %v\n", opts.from, opts.to)
+	for _, difference := range differences {
+		fmt.Println(difference)
+	}
+	return fmt.Errorf("found %d difference(s) between the CDI specifications", len(differences))
+}
+
+// load reads the specified CDI specification and normalizes it by removing
+// duplicate edits and sorting its entities.
+func (m command) load(path string) (*specs.Spec, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CDI specification: %w", err)
+	}
+
+	raw, err := cdi.ParseSpec(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CDI specification %v: %w", path, err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("failed to parse CDI specification %v: no spec data", path)
+	}
+
+	dedupe, _ := transform.NewDedupe()
+	normalize := transform.Merge(
+		dedupe,
+		transform.NewSorter(),
+	)
+	if err := normalize.Transform(raw); err != nil {
+		return nil, fmt.Errorf("failed to normalize CDI specification %v: %w", path, err)
+	}
+	return raw, nil
+}
+
+// diff returns the differences between two CDI specifications.
+// Removed devices and entities are prefixed with a '-' and added devices and
+// entities are prefixed with a '+'. The entities of a device that exists in
+// both specifications are listed below a line prefixed with '~'.
+func diff(from *specs.Spec, to *specs.Spec) []string {
+	var differences []string
+
+	if from.Version != to.Version {
+		differences = append(differences,
+			fmt.Sprintf("- cdiVersion %v", from.Version),
+			fmt.Sprintf("+ cdiVersion %v", to.Version),
+		)
+	}
+	if from.Kind != to.Kind {
+		differences = append(differences,
+			fmt.Sprintf("- kind %v", from.Kind),
+			fmt.Sprintf("+ kind %v", to.Kind),
+		)
+	}
+	differences = append(differences, diffEntities(
+		map[string][]string{"annotation": annotations(from.Annotations)},
+		map[string][]string{"annotation": annotations(to.Annotations)},
+		"",
+	)...)
+
+	if changes := diffEdits(&from.ContainerEdits, &to.ContainerEdits); len(changes) > 0 {
+		differences = append(differences, "~ common edits")
+		differences = append(differences, changes...)
+	}
+
+	fromDevices := devicesByName(from)
+	toDevices := devicesByName(to)
+
+	var names []string
+	for name := range fromDevices {
+		names = append(names, name)
+	}
+	for name := range toDevices {
+		if _, ok := fromDevices[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fromDevice, inFrom := fromDevices[name]
+		toDevice, inTo := toDevices[name]
+		switch {
+		case !inTo:
+			differences = append(differences, fmt.Sprintf("- device %v", name))
+		case !inFrom:
+			differences = append(differences, fmt.Sprintf("+ device %v", name))
+		default:
+			if changes := diffDevices(fromDevice, toDevice); len(changes) > 0 {
+				differences = append(differences, fmt.Sprintf("~ device %v", name))
+				differences = append(differences, changes...)
+			}
+		}
+	}
+
+	return differences
+}
+
+// devicesByName returns the devices in the spec indexed by their
+// fully-qualified names.
+func devicesByName(spec *specs.Spec) map[string]*specs.Device {
+	vendor, class := parser.ParseQualifier(spec.Kind)
+	devices := make(map[string]*specs.Device)
+	for i, device := range spec.Devices {
+		devices[parser.QualifiedName(vendor, class, device.Name)] = &spec.Devices[i]
+	}
+	return devices
+}
+
+// entityTypes defines the order in which the differences for each type of
+// entity are reported.
+var entityTypes = []string{"annotation", "device node", "mount", "env", "hook", "additional gid", "net device", "intel rdt"}
+
+// diffDevices returns the entities that were removed or added between two
+// definitions of the same device.
+func diffDevices(from *specs.Device, to *specs.Device) []string {
+	fromEntities := entities(&from.ContainerEdits)
+	fromEntities["annotation"] = annotations(from.Annotations)
+	toEntities := entities(&to.ContainerEdits)
+	toEntities["annotation"] = annotations(to.Annotations)
+	return diffEntities(fromEntities, toEntities, "  ")
+}
+
+// diffEdits returns the entities that were removed or added between two sets
+// of container edits.
+func diffEdits(from *specs.ContainerEdits, to *specs.ContainerEdits) []string {
+	return diffEntities(entities(from), entities(to), "  ")
+}
+
+// diffEntities returns the entities that were removed or added between two
+// sets of entities indexed by entity type. Each change is indented by the
+// specified prefix.
+func diffEntities(from map[string][]string, to map[string][]string, indent string) []string {
+	var changes []string
+	for _, entityType := range entityTypes {
+		for _, e := range from[entityType] {
+			if !slices.Contains(to[entityType], e) {
+				changes = append(changes, fmt.Sprintf("%v- %v %v", indent, entityType, e))
+			}
+		}
+		for _, e := range to[entityType] {
+			if !slices.Contains(from[entityType], e) {
+				changes = append(changes, fmt.Sprintf("%v+ %v %v", indent, entityType, e))
+			}
+		}
+	}
+	return changes
+}
+
+// annotations returns the string representations of the specified
+// annotations sorted by key.
+func annotations(a map[string]string) []string {
+	var s []string
+	for _, key := range slices.Sorted(maps.Keys(a)) {
+		s = append(s, key+"="+a[key])
+	}
+	return s
+}
+
+// entities returns the string representations of the entities in the
+// container edits indexed by entity type.
+func entities(edits *specs.ContainerEdits) map[string][]string {
+	e := make(map[string][]string)
+	for _, dn := range edits.DeviceNodes {
+		e["device node"] = append(e["device node"], deviceNodeString(dn))
+	}
+	for _, m := range edits.Mounts {
+		e["mount"] = append(e["mount"], mountString(m))
+	}
+	e["env"] = append(e["env"], edits.Env...)
+	for _, h := range edits.Hooks {
+		e["hook"] = append(e["hook"], hookString(h))
+	}
+	for _, gid := range edits.AdditionalGIDs {
+		e["additional gid"] = append(e["additional gid"], fmt.Sprintf("%d", gid))
+	}
+	for _, nd := range edits.NetDevices {
+		e["net device"] = append(e["net device"], nd.HostInterfaceName+" -> "+nd.Name)
+	}
+	if edits.IntelRdt != nil {
+		rdt, _ := json.Marshal(edits.IntelRdt)
+		e["intel rdt"] = append(e["intel rdt"], string(rdt))
+	}
+	return e
+}
+
+func deviceNodeString(dn *specs.DeviceNode) string {
+	s := dn.Path
+	if dn.HostPath != "" && dn.HostPath != dn.Path {
+		s = dn.HostPath + " -> " + dn.Path
+	}
+	if dn.Permissions != "" {
+		s += " [" + dn.Permissions + "]"
+	}
+	if dn.Type != "" {
+		s += " type=" + dn.Type
+	}
+	if dn.Major != 0 || dn.Minor != 0 {
+		s += fmt.Sprintf(" major=%d minor=%d", dn.Major, dn.Minor)
+	}
+	if dn.FileMode != nil {
+		s += fmt.Sprintf(" fileMode=%#o", *dn.FileMode)
+	}
+	if dn.UID != nil {
+		s += fmt.Sprintf(" uid=%d", *dn.UID)
+	}
+	if dn.GID != nil {
+		s += fmt.Sprintf(" gid=%d", *dn.GID)
+	}
+	return s
+}
+
+func mountString(m *specs.Mount) string {
+	s := fmt.Sprintf("%v -> %v [%v]", m.HostPath, m.ContainerPath, strings.Join(m.Options, ","))
+	if m.Type != "" {
+		s += " type=" + m.Type
+	}
+	return s
+}
+
+func hookString(h *specs.Hook) string {
+	s := fmt.Sprintf("%v: %v %v", h.HookName, h.Path, strings.Join(h.Args, " "))
+	if len(h.Env) > 0 {
+		s += " env=[" + strings.Join(h.Env, ",") + "]"
+	}
+	if h.Timeout != nil {
+		s += fmt.Sprintf(" timeout=%d", *h.Timeout)
+	}
+	return s
+}
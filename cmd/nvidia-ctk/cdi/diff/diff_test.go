@@ -0,0 +1,288 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description         string
+		from                string
+		to                  string
+		expectedDifferences []string
+	}{
+		{
+			description: "reordered and duplicate entities are equal",
+			from: `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+    - path: /dev/nvidiactl
+- name: gpu1
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia1
+containerEdits:
+  env:
+  - FOO=bar
+  - BAR=foo
+`,
+			to: `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu1
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia1
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidiactl
+    - path: /dev/nvidia0
+    - path: /dev/nvidia0
+containerEdits:
+  env:
+  - BAR=foo
+  - FOO=bar
+`,
+		},
+		{
+			description: "added and removed devices are reported",
+			from: `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+- name: gpu1
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia1
+`,
+			to: `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu1
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia1
+- name: gpu2
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia2
+`,
+			expectedDifferences: []string{
+				"- device nvidia.com/gpu=gpu0",
+				"+ device nvidia.com/gpu=gpu2",
+			},
+		},
+		{
+			description: "changed edits are reported",
+			from: `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+containerEdits:
+  env:
+  - NVIDIA_VISIBLE_DEVICES=void
+  mounts:
+  - hostPath: /usr/lib/libcuda.so.550.54.15
+    containerPath: /usr/lib/libcuda.so.550.54.15
+    options: [ro, nosuid, nodev, rbind, rprivate]
+  hooks:
+  - hookName: createContainer
+    path: /usr/bin/nvidia-cdi-hook
+    args: [nvidia-cdi-hook, update-ldcache]
+`,
+			to: `cdiVersion: 0.6.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+    - path: /dev/dri/card1
+      permissions: rw
+containerEdits:
+  env:
+  - NVIDIA_VISIBLE_DEVICES=void
+  mounts:
+  - hostPath: /usr/lib/libcuda.so.570.86.10
+    containerPath: /usr/lib/libcuda.so.570.86.10
+    options: [ro, nosuid, nodev, rbind, rprivate]
+  hooks:
+  - hookName: createContainer
+    path: /usr/bin/nvidia-cdi-hook
+    args: [nvidia-cdi-hook, update-ldcache, --folder, /usr/lib]
+`,
+			expectedDifferences: []string{
+				"- cdiVersion 0.5.0",
+				"+ cdiVersion 0.6.0",
+				"~ common edits",
+				"  - mount /usr/lib/libcuda.so.550.54.15 -> /usr/lib/libcuda.so.550.54.15 [ro,nosuid,nodev,rbind,rprivate]",
+				"  + mount /usr/lib/libcuda.so.570.86.10 -> /usr/lib/libcuda.so.570.86.10 [ro,nosuid,nodev,rbind,rprivate]",
+				"  - hook createContainer: /usr/bin/nvidia-cdi-hook nvidia-cdi-hook update-ldcache",
+				"  + hook createContainer: /usr/bin/nvidia-cdi-hook nvidia-cdi-hook update-ldcache --folder /usr/lib",
+				"~ device nvidia.com/gpu=gpu0",
+				"  + device node /dev/dri/card1 [rw]",
+			},
+		},
+		{
+			description: "changed kind, annotations, and common edits are reported",
+			from: `cdiVersion: 0.7.0
+kind: nvidia.com/gpu
+annotations:
+  example.com/foo: bar
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+containerEdits:
+  mounts:
+  - hostPath: /usr/lib/libcuda.so.1
+    containerPath: /usr/lib/libcuda.so.1
+    options: [ro]
+  hooks:
+  - hookName: createContainer
+    path: /usr/bin/nvidia-cdi-hook
+    args: [nvidia-cdi-hook, update-ldcache]
+  additionalGids: [44]
+`,
+			to: `cdiVersion: 0.7.0
+kind: example.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+`,
+			expectedDifferences: []string{
+				"- kind nvidia.com/gpu",
+				"+ kind example.com/gpu",
+				"- annotation example.com/foo=bar",
+				"~ common edits",
+				"  - mount /usr/lib/libcuda.so.1 -> /usr/lib/libcuda.so.1 [ro]",
+				"  - hook createContainer: /usr/bin/nvidia-cdi-hook nvidia-cdi-hook update-ldcache",
+				"  - additional gid 44",
+				"+ device example.com/gpu=gpu0",
+				"- device nvidia.com/gpu=gpu0",
+			},
+		},
+		{
+			description: "changed device properties are reported",
+			from: `cdiVersion: 0.7.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  annotations:
+    gpu.nvidia.com/uuid: GPU-0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+      type: c
+      major: 195
+      minor: 0
+      fileMode: 0666
+      uid: 0
+      gid: 0
+    mounts:
+    - hostPath: /run/nvidia-persistenced/socket
+      containerPath: /run/nvidia-persistenced/socket
+      options: [ro]
+    hooks:
+    - hookName: createContainer
+      path: /usr/bin/nvidia-cdi-hook
+      args: [nvidia-cdi-hook, update-ldcache]
+`,
+			to: `cdiVersion: 0.7.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  annotations:
+    gpu.nvidia.com/uuid: GPU-1
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+      type: c
+      major: 195
+      minor: 1
+      fileMode: 0660
+      uid: 1000
+      gid: 44
+    mounts:
+    - hostPath: /run/nvidia-persistenced/socket
+      containerPath: /run/nvidia-persistenced/socket
+      options: [ro]
+      type: bind
+    hooks:
+    - hookName: createContainer
+      path: /usr/bin/nvidia-cdi-hook
+      args: [nvidia-cdi-hook, update-ldcache]
+      env: [FOO=bar]
+      timeout: 10
+    additionalGids: [44]
+`,
+			expectedDifferences: []string{
+				"~ device nvidia.com/gpu=gpu0",
+				"  - annotation gpu.nvidia.com/uuid=GPU-0",
+				"  + annotation gpu.nvidia.com/uuid=GPU-1",
+				"  - device node /dev/nvidia0 type=c major=195 minor=0 fileMode=0666 uid=0 gid=0",
+				"  + device node /dev/nvidia0 type=c major=195 minor=1 fileMode=0660 uid=1000 gid=44",
+				"  - mount /run/nvidia-persistenced/socket -> /run/nvidia-persistenced/socket [ro]",
+				"  + mount /run/nvidia-persistenced/socket -> /run/nvidia-persistenced/socket [ro] type=bind",
+				"  - hook createContainer: /usr/bin/nvidia-cdi-hook nvidia-cdi-hook update-ldcache",
+				"  + hook createContainer: /usr/bin/nvidia-cdi-hook nvidia-cdi-hook update-ldcache env=[FOO=bar] timeout=10",
+				"  + additional gid 44",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			specDir := t.TempDir()
+			fromPath := filepath.Join(specDir, "from.yaml")
+			toPath := filepath.Join(specDir, "to.yaml")
+			require.NoError(t, os.WriteFile(fromPath, []byte(tc.from), 0600))
+			require.NoError(t, os.WriteFile(toPath, []byte(tc.to), 0600))
+
+			c := command{logger: logger}
+			from, err := c.load(fromPath)
+			require.NoError(t, err)
+			to, err := c.load(toPath)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expectedDifferences, diff(from, to))
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
 
 	altsrc "github.com/urfave/cli-altsrc/v3"
 	"github.com/urfave/cli/v3"
@@ -68,6 +69,26 @@ func (c *configAsValueSource) Get(key string) any {
 	return c.Toml.Get(key)
 }
 
+// HookPolicies returns the timeouts and failure policies of the CDI hooks
+// from the config file. If the config file cannot be loaded, no policies are
+// returned.
+func (c *configAsValueSource) HookPolicies() (map[nvcdi.HookName]nvcdi.HookPolicy, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.loadFromConfig(); err != nil {
+		return nil, nil
+	}
+	cfg, err := c.Toml.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg.NVIDIACTKConfig.HookPolicies()
+}
+
 // loadFromConfig loads the config file if it has not already been loaded.
 // If the config file path is not specified, it uses the default config file path.
 func (c *configAsValueSource) loadFromConfig() error {
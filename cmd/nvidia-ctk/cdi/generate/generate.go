@@ -24,9 +24,11 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
+	cdiapi "tags.cncf.io/container-device-interface/pkg/cdi"
 	cdi "tags.cncf.io/container-device-interface/pkg/parser"
 	"tags.cncf.io/container-device-interface/specs-go"
 
@@ -35,6 +37,7 @@ import (
 	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/platform-support/tegra/csv"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/snapshot"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
@@ -52,13 +55,14 @@ type command struct {
 
 type options struct {
 	output               string
+	outputDir            string
 	format               string
 	deviceNameStrategies []string
 	driverRoot           string
 	devRoot              string
 	nvidiaCDIHookPath    string
 	ldconfigPath         string
-	mode                 string
+	modes                []string
 	vendor               string
 	class                string
 
@@ -68,6 +72,7 @@ type options struct {
 	enabledHooks       []string
 
 	featureFlags []string
+	deviceRules  []string
 
 	csv struct {
 		files               []string
@@ -75,8 +80,16 @@ type options struct {
 		CompatContainerRoot string
 	}
 
-	noAllDevice bool
-	deviceIDs   []string
+	mps mpsOptions
+
+	noAllDevice     bool
+	deviceIDs       []string
+	topologyDevices []string
+
+	watch         bool
+	watchInterval time.Duration
+
+	snapshot string
 
 	// the following are used for dependency injection during spec generation.
 	nvmllib nvml.Interface
@@ -105,6 +118,9 @@ func (m command) build() *cli.Command {
 			return ctx, m.validateFlags(cmd, &opts)
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if opts.watch {
+				return m.watch(ctx, &opts)
+			}
 			return m.run(&opts)
 		},
 		Flags: []cli.Flag{
@@ -115,8 +131,9 @@ func (m command) build() *cli.Command {
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_CONFIG_SEARCH_PATHS"),
 			},
 			&cli.StringFlag{
-				Name:        "output",
-				Usage:       "Specify the file to output the generated CDI specification to. If this is '' the specification is output to STDOUT",
+				Name: "output",
+				Usage: "Specify the file to output the generated CDI specification to. If this is '' the specification is output to STDOUT. " +
+					"If this is a directory (or ends with a '/') or multiple modes are specified, a specification named after its kind is written to the directory for each mode.",
 				Destination: &opts.output,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_OUTPUT_FILE_PATH"),
 			},
@@ -127,14 +144,15 @@ func (m command) build() *cli.Command {
 				Destination: &opts.format,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_OUTPUT_FORMAT"),
 			},
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name:    "mode",
 				Aliases: []string{"discovery-mode"},
 				Usage: "The mode to use when discovering the available entities. " +
 					"One of [" + strings.Join(nvcdi.AllModes[string](), " | ") + "]. " +
-					"If mode is set to 'auto' the mode will be determined based on the system configuration.",
-				Value:       string(nvcdi.ModeAuto),
-				Destination: &opts.mode,
+					"If mode is set to 'auto' the mode will be determined based on the system configuration. " +
+					"This can be specified multiple times to generate a specification for each mode.",
+				Value:       []string{string(nvcdi.ModeAuto)},
+				Destination: &opts.modes,
 				Sources: cli.NewValueSourceChain(
 					cli.EnvVar("NVIDIA_CTK_CDI_GENERATE_MODE"),
 				),
@@ -147,7 +165,7 @@ func (m command) build() *cli.Command {
 			},
 			&cli.StringSliceFlag{
 				Name:        "device-name-strategy",
-				Usage:       "Specify the strategy for generating device names. If this is specified multiple times, the devices will be duplicated for each strategy. One of [index | uuid | type-index]",
+				Usage:       "Specify the strategy for generating device names. If this is specified multiple times, the devices will be duplicated for each strategy. One of [index | uuid | type-index] or a Go template referencing the device properties such as {{.Product}}-{{.Index}} or numa{{.NUMANode}}-gpu{{.Index}}",
 				Value:       []string{nvcdi.DeviceNameStrategyIndex, nvcdi.DeviceNameStrategyUUID},
 				Destination: &opts.deviceNameStrategies,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_DEVICE_NAME_STRATEGIES"),
@@ -195,7 +213,7 @@ func (m command) build() *cli.Command {
 			&cli.StringFlag{
 				Name:        "class",
 				Aliases:     []string{"cdi-class"},
-				Usage:       "the class string to use for the generated CDI specification.",
+				Usage:       "the class string to use for the generated CDI specification. This cannot be specified if multiple modes are specified, in which case the default class for each mode is used.",
 				Value:       "gpu",
 				Destination: &opts.class,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_CLASS"),
@@ -219,6 +237,26 @@ func (m command) build() *cli.Command {
 				Destination: &opts.csv.CompatContainerRoot,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_CSV_CONTAINER_COMPAT_ROOT"),
 			},
+			&cli.StringSliceFlag{
+				Name: "mps.daemon",
+				Usage: "Specify an MPS control daemon to generate a device for in MPS mode as NAME:PIPE_DIRECTORY[:LOG_DIRECTORY]. " +
+					"This can be specified multiple times. If no daemons are specified, a 'default' device is generated for the daemon using " +
+					"the default pipe (" + nvcdi.DefaultMPSPipeDirectory + ") and log (" + nvcdi.DefaultMPSLogDirectory + ") directories if it exists.",
+				Destination: &opts.mps.daemons,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_MPS_DAEMONS"),
+			},
+			&cli.StringFlag{
+				Name:        "mps.active-thread-percentage",
+				Usage:       "Specify the CUDA_MPS_ACTIVE_THREAD_PERCENTAGE to set for the MPS devices.",
+				Destination: &opts.mps.activeThreadPercentage,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_MPS_ACTIVE_THREAD_PERCENTAGE"),
+			},
+			&cli.StringFlag{
+				Name:        "mps.pinned-device-memory-limit",
+				Usage:       "Specify the CUDA_MPS_PINNED_DEVICE_MEM_LIMIT (e.g. 0=1G,1=2G) to set for the MPS devices.",
+				Destination: &opts.mps.pinnedDeviceMemoryLimit,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_MPS_PINNED_DEVICE_MEMORY_LIMIT"),
+			},
 			&cli.StringSliceFlag{
 				Name:    "disable-hook",
 				Aliases: []string{"disable-hooks"},
@@ -243,6 +281,16 @@ func (m command) build() *cli.Command {
 				Destination: &opts.featureFlags,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_FEATURE_FLAGS"),
 			},
+			&cli.StringSliceFlag{
+				Name:    "device-rule",
+				Aliases: []string{"device-rules"},
+				Usage: "Enable or disable hooks or enable feature flags for the devices matching a selector as SELECTOR:ACTIONS. " +
+					"The SELECTOR is 'all' or a ';'-separated list of type=[gpu | mig], product=GLOB, or uuid=UUID terms. " +
+					"The ACTIONS are a ';'-separated list of enable-hook=HOOK, disable-hook=HOOK, or feature-flag=FLAG terms. " +
+					"Rules are applied in order and only apply to devices generated in nvml mode. This can be specified multiple times.",
+				Destination: &opts.deviceRules,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_DEVICE_RULES"),
+			},
 			&cli.BoolFlag{
 				Name:        "no-all-device",
 				Usage:       "Don't generate an `all` device for the resultant spec",
@@ -257,6 +305,34 @@ func (m command) build() *cli.Command {
 				Destination: &opts.deviceIDs,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_DEVICE_IDS"),
 			},
+			&cli.StringSliceFlag{
+				Name: "topology-device",
+				Usage: "Generate a merged device for each group of GPUs that share the specified topology. " +
+					"One of [numa | nvlink-clique | pcie-switch]. This can be specified multiple times.",
+				Destination: &opts.topologyDevices,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_TOPOLOGY_DEVICES"),
+			},
+			&cli.BoolFlag{
+				Name: "watch",
+				Usage: "Keep running and regenerate the CDI specification when a change to the driver or devices is detected. " +
+					"The specification is only written if its contents change. An output file must be specified.",
+				Destination: &opts.watch,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_WATCH"),
+			},
+			&cli.DurationFlag{
+				Name:        "watch-interval",
+				Usage:       "The interval at which the driver and devices are checked for changes when --watch is specified.",
+				Value:       10 * time.Second,
+				Destination: &opts.watchInterval,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_WATCH_INTERVAL"),
+			},
+			&cli.StringFlag{
+				Name: "snapshot",
+				Usage: "Generate the CDI specification from a snapshot recorded using 'nvidia-ctk system snapshot' instead of the current system. " +
+					"The driver-root and dev-root recorded in the snapshot are used.",
+				Destination: &opts.snapshot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_GENERATE_SNAPSHOT"),
+			},
 		},
 	}
 
@@ -272,11 +348,29 @@ func (m command) validateFlags(c *cli.Command, opts *options) error {
 		return fmt.Errorf("invalid output format: %v", opts.format)
 	}
 
-	opts.mode = strings.ToLower(opts.mode)
-	if !nvcdi.IsValidMode(opts.mode) {
-		return fmt.Errorf("invalid discovery mode: %v", opts.mode)
+	if len(opts.modes) == 0 {
+		return fmt.Errorf("at least one discovery mode must be specified")
+	}
+	for i, mode := range opts.modes {
+		mode = strings.ToLower(mode)
+		if !nvcdi.IsValidMode(mode) {
+			return fmt.Errorf("invalid discovery mode: %v", mode)
+		}
+		if slices.Contains(opts.modes[:i], mode) {
+			return fmt.Errorf("discovery mode %v specified more than once", mode)
+		}
+		opts.modes[i] = mode
+	}
+
+	if len(opts.modes) > 1 {
+		if c != nil && c.IsSet("class") {
+			return fmt.Errorf("a class cannot be specified when generating specifications for multiple modes")
+		}
+		opts.class = ""
 	}
 
+	// Templates that contain commas are split when the flag is parsed.
+	opts.deviceNameStrategies = nvcdi.JoinDeviceNameStrategies(opts.deviceNameStrategies)
 	for _, strategy := range opts.deviceNameStrategies {
 		_, err := nvcdi.NewDeviceNamer(strategy)
 		if err != nil {
@@ -286,6 +380,11 @@ func (m command) validateFlags(c *cli.Command, opts *options) error {
 
 	opts.nvidiaCDIHookPath = config.ResolveNVIDIACDIHookPath(m.logger, opts.nvidiaCDIHookPath)
 
+	if isOutputDir(opts.output, len(opts.modes) > 1) {
+		opts.outputDir = opts.output
+		opts.output = ""
+	}
+
 	if outputFileFormat := formatFromFilename(opts.output); outputFileFormat != "" {
 		m.logger.Debugf("Inferred output format as %q from output file name", outputFileFormat)
 		if !c.IsSet("format") {
@@ -298,14 +397,43 @@ func (m command) validateFlags(c *cli.Command, opts *options) error {
 	if err := cdi.ValidateVendorName(opts.vendor); err != nil {
 		return fmt.Errorf("invalid CDI vendor name: %v", err)
 	}
-	if err := cdi.ValidateClassName(opts.class); err != nil {
-		return fmt.Errorf("invalid CDI class name: %v", err)
+	if opts.class != "" {
+		if err := cdi.ValidateClassName(opts.class); err != nil {
+			return fmt.Errorf("invalid CDI class name: %v", err)
+		}
 	}
 
 	if slices.Contains(opts.enabledHooks, "all") {
 		return fmt.Errorf("enabling all hooks is not supported")
 	}
 
+	if _, err := opts.mps.getMPSDaemons(); err != nil {
+		return err
+	}
+
+	if _, err := opts.getDeviceRules(); err != nil {
+		return err
+	}
+
+	if err := validateTopologyGroupings(opts.topologyDevices); err != nil {
+		return err
+	}
+	if len(opts.topologyDevices) > 0 && !slices.Contains(opts.featureFlags, string(nvcdi.FeatureEnableTopologyAnnotations)) {
+		opts.featureFlags = append(opts.featureFlags, string(nvcdi.FeatureEnableTopologyAnnotations))
+	}
+
+	if opts.watch {
+		if opts.output == "" && opts.outputDir == "" {
+			return fmt.Errorf("an output file must be specified when --watch is set")
+		}
+		if opts.watchInterval <= 0 {
+			return fmt.Errorf("invalid watch interval: %v", opts.watchInterval)
+		}
+		if opts.snapshot != "" {
+			return fmt.Errorf("--watch cannot be specified with --snapshot")
+		}
+	}
+
 	if slices.Contains(opts.deviceIDs, "none") && !opts.noAllDevice {
 		m.logger.Warningf("Disabling generation of 'all' device")
 		opts.noAllDevice = true
@@ -321,7 +449,12 @@ func (m command) run(opts *options) error {
 
 	var errs error
 	for _, spec := range specs {
-		errs = errors.Join(errs, spec.Save(opts.output))
+		filename, err := spec.outputFilename(opts)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		errs = errors.Join(errs, spec.Save(filename))
 		// We query the raw spec version after calling spec.Save since this may
 		// update the spec version to the minimum required version.
 		m.logger.Infof("Generated CDI spec with version %v", spec.Raw().Version)
@@ -330,6 +463,20 @@ func (m command) run(opts *options) error {
 	return errs
 }
 
+// isOutputDir checks whether the specified output refers to a directory. This
+// is the case if the output is an existing directory, ends with a path
+// separator, or if multiple specs are generated.
+func isOutputDir(output string, multipleModes bool) bool {
+	if output == "" {
+		return false
+	}
+	if multipleModes || strings.HasSuffix(output, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(output)
+	return err == nil && info.IsDir()
+}
+
 func formatFromFilename(filename string) string {
 	ext := filepath.Ext(filename)
 	switch strings.ToLower(ext) {
@@ -347,9 +494,9 @@ type generatedSpecs struct {
 	filenameInfix string
 }
 
+// Save writes the spec to the specified file or to STDOUT if no filename is
+// specified.
 func (g *generatedSpecs) Save(filename string) error {
-	filename = g.updateFilename(filename)
-
 	if filename == "" {
 		_, err := g.WriteTo(os.Stdout)
 		if err != nil {
@@ -361,6 +508,20 @@ func (g *generatedSpecs) Save(filename string) error {
 	return g.Interface.Save(filename)
 }
 
+// outputFilename returns the file that the spec is written to for the
+// specified options. If an output directory is specified, the file is named
+// after the kind of the spec.
+func (g generatedSpecs) outputFilename(opts *options) (string, error) {
+	if opts.outputDir == "" {
+		return g.updateFilename(opts.output), nil
+	}
+	name, err := cdiapi.GenerateNameForSpec(g.Raw())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate name for CDI spec: %w", err)
+	}
+	return filepath.Join(opts.outputDir, name), nil
+}
+
 func (g generatedSpecs) updateFilename(filename string) string {
 	if g.filenameInfix == "" || filename == "" {
 		return filename
@@ -369,7 +530,75 @@ func (g generatedSpecs) updateFilename(filename string) string {
 	return strings.TrimSuffix(filename, ext) + g.filenameInfix + ext
 }
 
+// generateSpecs generates the CDI specs for each of the requested modes.
 func (m command) generateSpecs(opts *options) ([]generatedSpecs, error) {
+	var replayer *snapshot.Replayer
+	if opts.snapshot != "" {
+		s, err := snapshot.Load(opts.snapshot)
+		if err != nil {
+			return nil, err
+		}
+		replayer, err = snapshot.NewReplayer(m.logger, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay snapshot: %w", err)
+		}
+		defer func() {
+			if err := replayer.Close(); err != nil {
+				m.logger.Warningf("Failed to clean up snapshot: %v", err)
+			}
+		}()
+	}
+
+	var allSpecs []generatedSpecs
+	kinds := make(map[string]string)
+	for _, mode := range opts.modes {
+		if len(opts.modes) > 1 {
+			m.logger.Infof("Generating CDI specs for mode %v", mode)
+		}
+		modeSpecs, err := m.generateSpecsForMode(opts, mode, replayer)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range modeSpecs {
+			kind := s.Raw().Kind
+			if other, ok := kinds[kind]; ok {
+				return nil, fmt.Errorf("modes %v and %v both generate a CDI spec with kind %v", other, mode, kind)
+			}
+			kinds[kind] = mode
+		}
+		allSpecs = append(allSpecs, modeSpecs...)
+	}
+	return allSpecs, nil
+}
+
+// generateSpecsForMode generates the CDI specs for the specified mode. If a
+// replayer is specified, the specs are generated from the replayed snapshot.
+func (m command) generateSpecsForMode(opts *options, mode string, replayer *snapshot.Replayer) ([]generatedSpecs, error) {
+	if replayer != nil && mode == string(nvcdi.ModeAuto) {
+		// A snapshot always describes a system where NVML is available.
+		mode = string(nvcdi.ModeNvml)
+	}
+
+	class := opts.class
+	if class == "" {
+		class = nvcdi.DefaultClass(mode)
+	}
+
+	mpsDaemons, err := opts.mps.getMPSDaemons()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceRules, err := opts.getDeviceRules()
+	if err != nil {
+		return nil, err
+	}
+
+	hookPolicies, err := m.config.HookPolicies()
+	if err != nil {
+		return nil, err
+	}
+
 	var deviceNamers []nvcdi.DeviceNamer
 	for _, strategy := range opts.deviceNameStrategies {
 		deviceNamer, err := nvcdi.NewDeviceNamer(strategy)
@@ -386,18 +615,24 @@ func (m command) generateSpecs(opts *options) ([]generatedSpecs, error) {
 		nvcdi.WithNVIDIACDIHookPath(opts.nvidiaCDIHookPath),
 		nvcdi.WithLdconfigPath(opts.ldconfigPath),
 		nvcdi.WithDeviceNamers(deviceNamers...),
-		nvcdi.WithMode(opts.mode),
+		nvcdi.WithMode(mode),
 		nvcdi.WithConfigSearchPaths(opts.configSearchPaths),
 		nvcdi.WithLibrarySearchPaths(opts.librarySearchPaths),
 		nvcdi.WithCSVFiles(opts.csv.files),
 		nvcdi.WithCSVIgnorePatterns(opts.csv.ignorePatterns),
 		nvcdi.WithCSVCompatContainerRoot(opts.csv.CompatContainerRoot),
+		nvcdi.WithMPSDaemons(mpsDaemons...),
 		nvcdi.WithDisabledHooks(opts.disabledHooks...),
 		nvcdi.WithEnabledHooks(opts.enabledHooks...),
 		nvcdi.WithFeatureFlags(opts.featureFlags...),
+		nvcdi.WithDeviceRules(deviceRules...),
+		nvcdi.WithHookPolicies(hookPolicies),
 		// We set the following to allow for dependency injection:
 		nvcdi.WithNvmlLib(opts.nvmllib),
 	}
+	if replayer != nil {
+		cdiOptions = append(cdiOptions, replayer.Options()...)
+	}
 
 	cdilib, err := nvcdi.New(cdiOptions...)
 	if err != nil {
@@ -414,6 +649,20 @@ func (m command) generateSpecs(opts *options) ([]generatedSpecs, error) {
 		return nil, fmt.Errorf("failed to create edits common for entities: %v", err)
 	}
 
+	if replayer != nil {
+		// The paths in the generated specs refer to the replayed snapshot and
+		// are mapped to the paths that were recorded.
+		replayed := &specs.Spec{
+			Devices:        allDeviceSpecs,
+			ContainerEdits: *commonEdits.ContainerEdits,
+		}
+		if err := replayer.Transformer().Transform(replayed); err != nil {
+			return nil, fmt.Errorf("failed to transform replayed specs: %v", err)
+		}
+		allDeviceSpecs = replayed.Devices
+		*commonEdits.ContainerEdits = replayed.ContainerEdits
+	}
+
 	commonSpecOptions := []spec.Option{
 		spec.WithVendor(opts.vendor),
 		spec.WithEdits(*commonEdits.ContainerEdits),
@@ -430,12 +679,17 @@ func (m command) generateSpecs(opts *options) ([]generatedSpecs, error) {
 		)
 	}
 
-	fullSpec, err := spec.New(
-		append(commonSpecOptions,
-			spec.WithClass(opts.class),
-			spec.WithDeviceSpecs(allDeviceSpecs),
-		)...,
+	fullSpecOptions := append(slices.Clone(commonSpecOptions),
+		spec.WithClass(class),
+		spec.WithDeviceSpecs(allDeviceSpecs),
 	)
+	for _, topologyDevice := range getTopologyDevices(opts.topologyDevices, allDeviceSpecs) {
+		fullSpecOptions = append(fullSpecOptions,
+			spec.WithAdditionalMergedDevice(topologyDevice.mergedDeviceOptions()...),
+		)
+	}
+
+	fullSpec, err := spec.New(fullSpecOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -449,7 +703,7 @@ func (m command) generateSpecs(opts *options) ([]generatedSpecs, error) {
 		infix := ".coherent"
 		coherentSpecs, err := spec.New(
 			append(commonSpecOptions,
-				spec.WithClass(opts.class+infix),
+				spec.WithClass(class+infix),
 				spec.WithDeviceSpecs(coherentDeviceSpecs),
 			)...,
 		)
@@ -463,7 +717,7 @@ func (m command) generateSpecs(opts *options) ([]generatedSpecs, error) {
 		infix := ".noncoherent"
 		noncoherentSpecs, err := spec.New(
 			append(commonSpecOptions,
-				spec.WithClass(opts.class+infix),
+				spec.WithClass(class+infix),
 				spec.WithDeviceSpecs(noncoherentDeviceSpecs),
 			)...,
 		)
@@ -502,3 +756,16 @@ func (d deviceSpecs) splitOnAnnotation(key string) map[string][]specs.Device {
 
 	return splitSpecs
 }
+
+// getDeviceRules parses the device rules specified on the command line.
+func (o *options) getDeviceRules() ([]nvcdi.DeviceRule, error) {
+	var rules []nvcdi.DeviceRule
+	for _, r := range o.deviceRules {
+		rule, err := nvcdi.ParseDeviceRule(r)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, nil
+}
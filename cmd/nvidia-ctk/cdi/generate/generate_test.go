@@ -32,6 +32,7 @@ import (
 
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/test"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
 )
 
 func TestGenerateSpec(t *testing.T) {
@@ -55,7 +56,7 @@ func TestGenerateSpec(t *testing.T) {
 			description: "invalid device id",
 			options: options{
 				format:     "yaml",
-				mode:       "nvml",
+				modes:      []string{"nvml"},
 				vendor:     "example.com",
 				class:      "device",
 				deviceIDs:  []string{"99"},
@@ -63,7 +64,7 @@ func TestGenerateSpec(t *testing.T) {
 			},
 			expectedOptions: options{
 				format:            "yaml",
-				mode:              "nvml",
+				modes:             []string{"nvml"},
 				vendor:            "example.com",
 				class:             "device",
 				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
@@ -76,14 +77,14 @@ func TestGenerateSpec(t *testing.T) {
 			description: "default",
 			options: options{
 				format:     "yaml",
-				mode:       "nvml",
+				modes:      []string{"nvml"},
 				vendor:     "example.com",
 				class:      "device",
 				driverRoot: driverRoot,
 			},
 			expectedOptions: options{
 				format:            "yaml",
-				mode:              "nvml",
+				modes:             []string{"nvml"},
 				vendor:            "example.com",
 				class:             "device",
 				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
@@ -176,7 +177,7 @@ containerEdits:
 			description: "disableHooks1",
 			options: options{
 				format:        "yaml",
-				mode:          "nvml",
+				modes:         []string{"nvml"},
 				vendor:        "example.com",
 				class:         "device",
 				driverRoot:    driverRoot,
@@ -184,7 +185,7 @@ containerEdits:
 			},
 			expectedOptions: options{
 				format:            "yaml",
-				mode:              "nvml",
+				modes:             []string{"nvml"},
 				vendor:            "example.com",
 				class:             "device",
 				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
@@ -270,7 +271,7 @@ containerEdits:
 			description: "disableHooks2",
 			options: options{
 				format:        "yaml",
-				mode:          "nvml",
+				modes:         []string{"nvml"},
 				vendor:        "example.com",
 				class:         "device",
 				driverRoot:    driverRoot,
@@ -278,7 +279,7 @@ containerEdits:
 			},
 			expectedOptions: options{
 				format:            "yaml",
-				mode:              "nvml",
+				modes:             []string{"nvml"},
 				vendor:            "example.com",
 				class:             "device",
 				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
@@ -353,7 +354,7 @@ containerEdits:
 			description: "disableHooksAll",
 			options: options{
 				format:        "yaml",
-				mode:          "nvml",
+				modes:         []string{"nvml"},
 				vendor:        "example.com",
 				class:         "device",
 				driverRoot:    driverRoot,
@@ -361,7 +362,7 @@ containerEdits:
 			},
 			expectedOptions: options{
 				format:            "yaml",
-				mode:              "nvml",
+				modes:             []string{"nvml"},
 				vendor:            "example.com",
 				class:             "device",
 				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
@@ -412,7 +413,7 @@ containerEdits:
 			description: "enableChmodHook",
 			options: options{
 				format:        "yaml",
-				mode:          "management",
+				modes:         []string{"management"},
 				vendor:        "example.com",
 				class:         "device",
 				driverRoot:    driverRoot,
@@ -421,7 +422,7 @@ containerEdits:
 			},
 			expectedOptions: options{
 				format:            "yaml",
-				mode:              "management",
+				modes:             []string{"management"},
 				vendor:            "example.com",
 				class:             "device",
 				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
@@ -493,6 +494,24 @@ containerEdits:
             - rprivate
 `,
 		},
+		{
+			description: "multiple modes with the same default class",
+			options: options{
+				format:     "yaml",
+				modes:      []string{"nvml", "management"},
+				vendor:     "example.com",
+				class:      "gpu",
+				driverRoot: driverRoot,
+			},
+			expectedOptions: options{
+				format:            "yaml",
+				modes:             []string{"nvml", "management"},
+				vendor:            "example.com",
+				nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
+				driverRoot:        driverRoot,
+			},
+			expectedError: fmt.Errorf("modes nvml and management both generate a CDI spec with kind example.com/gpu"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -544,6 +563,60 @@ containerEdits:
 	}
 }
 
+func TestOutputFilename(t *testing.T) {
+	testCases := []struct {
+		description      string
+		options          options
+		infix            string
+		expectedFilename string
+	}{
+		{
+			description:      "stdout",
+			options:          options{},
+			infix:            ".coherent",
+			expectedFilename: "",
+		},
+		{
+			description:      "output file",
+			options:          options{output: "/etc/cdi/nvidia.yaml"},
+			expectedFilename: "/etc/cdi/nvidia.yaml",
+		},
+		{
+			description:      "output file with infix",
+			options:          options{output: "/etc/cdi/nvidia.yaml"},
+			infix:            ".coherent",
+			expectedFilename: "/etc/cdi/nvidia.coherent.yaml",
+		},
+		{
+			description:      "output directory",
+			options:          options{outputDir: "/etc/cdi"},
+			expectedFilename: "/etc/cdi/example.com-device",
+		},
+		{
+			description:      "output directory with infix",
+			options:          options{outputDir: "/etc/cdi"},
+			infix:            ".coherent",
+			expectedFilename: "/etc/cdi/example.com-device.coherent",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			s, err := spec.New(
+				spec.WithVendor("example.com"),
+				spec.WithClass("device"+tc.infix),
+				spec.WithDeviceSpecs([]specs.Device{{Name: "0"}}),
+			)
+			require.NoError(t, err)
+
+			g := generatedSpecs{Interface: s, filenameInfix: tc.infix}
+			filename, err := g.outputFilename(&tc.options)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedFilename, filename)
+		})
+	}
+}
+
 func TestSplitOnAnnotation(t *testing.T) {
 	testCases := []struct {
 		description            string
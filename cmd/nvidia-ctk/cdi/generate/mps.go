@@ -0,0 +1,78 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package generate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+type mpsOptions struct {
+	daemons                 []string
+	activeThreadPercentage  string
+	pinnedDeviceMemoryLimit string
+}
+
+// getMPSDaemons returns the MPS control daemons for the specified options.
+// Each daemon is specified as NAME:PIPE_DIRECTORY[:LOG_DIRECTORY]. The active
+// thread percentage and pinned device memory limit apply to all daemons.
+func (o *mpsOptions) getMPSDaemons() ([]nvcdi.MPSDaemon, error) {
+	if o.activeThreadPercentage != "" {
+		percentage, err := strconv.Atoi(o.activeThreadPercentage)
+		if err != nil || percentage < 1 || percentage > 100 {
+			return nil, fmt.Errorf("invalid MPS active thread percentage %q: must be an integer in the range [1, 100]", o.activeThreadPercentage)
+		}
+	}
+
+	var daemons []nvcdi.MPSDaemon
+	seen := make(map[string]bool)
+	for _, daemon := range o.daemons {
+		parts := strings.Split(daemon, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid MPS control daemon %q: expected NAME:PIPE_DIRECTORY[:LOG_DIRECTORY]", daemon)
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("MPS control daemon %q specified more than once", parts[0])
+		}
+		seen[parts[0]] = true
+
+		d := nvcdi.MPSDaemon{
+			Name:                    parts[0],
+			PipeDirectory:           parts[1],
+			ActiveThreadPercentage:  o.activeThreadPercentage,
+			PinnedDeviceMemoryLimit: o.pinnedDeviceMemoryLimit,
+		}
+		if len(parts) == 3 {
+			d.LogDirectory = parts[2]
+		}
+		daemons = append(daemons, d)
+	}
+
+	if len(daemons) == 0 && (o.activeThreadPercentage != "" || o.pinnedDeviceMemoryLimit != "") {
+		daemons = append(daemons, nvcdi.MPSDaemon{
+			Name:                    "default",
+			PipeDirectory:           nvcdi.DefaultMPSPipeDirectory,
+			LogDirectory:            nvcdi.DefaultMPSLogDirectory,
+			ActiveThreadPercentage:  o.activeThreadPercentage,
+			PinnedDeviceMemoryLimit: o.pinnedDeviceMemoryLimit,
+		})
+	}
+	return daemons, nil
+}
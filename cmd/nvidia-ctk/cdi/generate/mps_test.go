@@ -0,0 +1,105 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+func TestGetMPSDaemons(t *testing.T) {
+	testCases := []struct {
+		description     string
+		options         mpsOptions
+		expectedDaemons []nvcdi.MPSDaemon
+		expectedError   string
+	}{
+		{
+			description: "no options",
+		},
+		{
+			description: "daemons",
+			options: mpsOptions{
+				daemons:                 []string{"a:/mps/a/pipe", "b:/mps/b/pipe:/mps/b/log"},
+				pinnedDeviceMemoryLimit: "0=1G,1=2G",
+			},
+			expectedDaemons: []nvcdi.MPSDaemon{
+				{
+					Name:                    "a",
+					PipeDirectory:           "/mps/a/pipe",
+					PinnedDeviceMemoryLimit: "0=1G,1=2G",
+				},
+				{
+					Name:                    "b",
+					PipeDirectory:           "/mps/b/pipe",
+					LogDirectory:            "/mps/b/log",
+					PinnedDeviceMemoryLimit: "0=1G,1=2G",
+				},
+			},
+		},
+		{
+			description: "limits for the default daemon",
+			options: mpsOptions{
+				activeThreadPercentage: "25",
+			},
+			expectedDaemons: []nvcdi.MPSDaemon{
+				{
+					Name:                   "default",
+					PipeDirectory:          "/tmp/nvidia-mps",
+					LogDirectory:           "/var/log/nvidia-mps",
+					ActiveThreadPercentage: "25",
+				},
+			},
+		},
+		{
+			description: "missing pipe directory",
+			options: mpsOptions{
+				daemons: []string{"a"},
+			},
+			expectedError: `invalid MPS control daemon "a": expected NAME:PIPE_DIRECTORY[:LOG_DIRECTORY]`,
+		},
+		{
+			description: "duplicate daemon",
+			options: mpsOptions{
+				daemons: []string{"a:/mps/a/pipe", "a:/mps/b/pipe"},
+			},
+			expectedError: `MPS control daemon "a" specified more than once`,
+		},
+		{
+			description: "invalid active thread percentage",
+			options: mpsOptions{
+				activeThreadPercentage: "101",
+			},
+			expectedError: `invalid MPS active thread percentage "101": must be an integer in the range [1, 100]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			daemons, err := tc.options.getMPSDaemons()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDaemons, daemons)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package generate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
+)
+
+const (
+	topologyNUMA         = "numa"
+	topologyNVLinkClique = "nvlink-clique"
+	topologyPCIeSwitch   = "pcie-switch"
+
+	// topologyMembersAnnotation lists the names of the devices that are
+	// included in a topology device.
+	topologyMembersAnnotation = "gpu.nvidia.com/members"
+)
+
+// topologyAnnotations maps the supported topology groupings to the device
+// annotation that defines the group that a device belongs to.
+var topologyAnnotations = map[string]string{
+	topologyNUMA:         nvcdi.AnnotationNUMANode,
+	topologyNVLinkClique: nvcdi.AnnotationNVLinkClique,
+	topologyPCIeSwitch:   nvcdi.AnnotationPCIeSwitch,
+}
+
+// A topologyDevice is a merged device that includes the devices in the same
+// topology group.
+type topologyDevice struct {
+	name        string
+	members     []string
+	annotations map[string]string
+}
+
+// getTopologyDevices returns the merged devices for the topology groups of
+// the specified devices. The devices of a group are identified by the value
+// of the topology annotation associated with the grouping. For NUMA nodes
+// this value is used to name the device (e.g. numa0). For other groupings
+// the devices are named by their index in the ordered list of values (e.g.
+// nvlink-clique0).
+// If multiple device naming strategies are used, a GPU is included in a group
+// once using the first of its names.
+func getTopologyDevices(groupings []string, deviceSpecs []specs.Device) []topologyDevice {
+	var topologyDevices []topologyDevice
+	for _, grouping := range groupings {
+		key := topologyAnnotations[grouping]
+
+		membersByValue := make(map[string][]string)
+		seen := make(map[string]bool)
+		for _, deviceSpec := range deviceSpecs {
+			value, ok := deviceSpec.Annotations[key]
+			if !ok {
+				continue
+			}
+			id := deviceSpec.Annotations[nvcdi.AnnotationUUID]
+			if id == "" {
+				id = deviceSpec.Name
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			membersByValue[value] = append(membersByValue[value], deviceSpec.Name)
+		}
+
+		var values []string
+		for value := range membersByValue {
+			values = append(values, value)
+		}
+		slices.SortFunc(values, compareTopologyValues)
+
+		for i, value := range values {
+			suffix := strconv.Itoa(i)
+			if grouping == topologyNUMA {
+				suffix = value
+			}
+			members := membersByValue[value]
+			topologyDevices = append(topologyDevices, topologyDevice{
+				name:    grouping + suffix,
+				members: members,
+				annotations: map[string]string{
+					key:                       value,
+					topologyMembersAnnotation: strings.Join(members, ","),
+				},
+			})
+		}
+	}
+	return topologyDevices
+}
+
+// compareTopologyValues orders numeric values such as NUMA nodes numerically
+// and other values lexicographically.
+func compareTopologyValues(a string, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return x - y
+	}
+	return strings.Compare(a, b)
+}
+
+// mergedDeviceOptions returns the options for generating the topology device.
+func (d topologyDevice) mergedDeviceOptions() []transform.MergedDeviceOption {
+	return []transform.MergedDeviceOption{
+		transform.WithName(d.name),
+		transform.WithMemberDevices(d.members...),
+		transform.WithAnnotations(d.annotations),
+		transform.WithSkipIfExists(true),
+	}
+}
+
+func validateTopologyGroupings(groupings []string) error {
+	for _, grouping := range groupings {
+		if _, ok := topologyAnnotations[grouping]; !ok {
+			return fmt.Errorf("invalid topology device grouping: %v", grouping)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package generate
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+func TestGetTopologyDevices(t *testing.T) {
+	deviceSpecs := []specs.Device{
+		{
+			Name: "0",
+			Annotations: map[string]string{
+				nvcdi.AnnotationNUMANode:   "0",
+				nvcdi.AnnotationPCIeSwitch: "0000:07:00.0",
+			},
+		},
+		{
+			Name: "1",
+			Annotations: map[string]string{
+				nvcdi.AnnotationNUMANode:   "0",
+				nvcdi.AnnotationPCIeSwitch: "0000:07:00.0",
+			},
+		},
+		{
+			Name: "2",
+			Annotations: map[string]string{
+				nvcdi.AnnotationNUMANode: "1",
+			},
+		},
+		{
+			Name: "3",
+			Annotations: map[string]string{
+				nvcdi.AnnotationNUMANode:   "10",
+				nvcdi.AnnotationPCIeSwitch: "0000:0f:00.0",
+			},
+		},
+		{
+			Name: "all",
+		},
+	}
+
+	testCases := []struct {
+		description     string
+		groupings       []string
+		expectedDevices []topologyDevice
+	}{
+		{
+			description: "no groupings",
+		},
+		{
+			description: "numa",
+			groupings:   []string{"numa"},
+			expectedDevices: []topologyDevice{
+				{
+					name:    "numa0",
+					members: []string{"0", "1"},
+					annotations: map[string]string{
+						"gpu.nvidia.com/numa-node": "0",
+						"gpu.nvidia.com/members":   "0,1",
+					},
+				},
+				{
+					name:    "numa1",
+					members: []string{"2"},
+					annotations: map[string]string{
+						"gpu.nvidia.com/numa-node": "1",
+						"gpu.nvidia.com/members":   "2",
+					},
+				},
+				{
+					name:    "numa10",
+					members: []string{"3"},
+					annotations: map[string]string{
+						"gpu.nvidia.com/numa-node": "10",
+						"gpu.nvidia.com/members":   "3",
+					},
+				},
+			},
+		},
+		{
+			description: "pcie-switch",
+			groupings:   []string{"pcie-switch"},
+			expectedDevices: []topologyDevice{
+				{
+					name:    "pcie-switch0",
+					members: []string{"0", "1"},
+					annotations: map[string]string{
+						"gpu.nvidia.com/pcie-switch": "0000:07:00.0",
+						"gpu.nvidia.com/members":     "0,1",
+					},
+				},
+				{
+					name:    "pcie-switch1",
+					members: []string{"3"},
+					annotations: map[string]string{
+						"gpu.nvidia.com/pcie-switch": "0000:0f:00.0",
+						"gpu.nvidia.com/members":     "3",
+					},
+				},
+			},
+		},
+		{
+			description: "no annotated devices",
+			groupings:   []string{"nvlink-clique"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			devices := getTopologyDevices(tc.groupings, deviceSpecs)
+			require.EqualValues(t, tc.expectedDevices, devices)
+		})
+	}
+}
+
+func TestGetTopologyDevicesWithMultipleNamingStrategies(t *testing.T) {
+	// The devices are generated using the index and uuid naming strategies.
+	var deviceSpecs []specs.Device
+	for i, uuid := range []string{"GPU-0", "GPU-1"} {
+		annotations := map[string]string{
+			nvcdi.AnnotationUUID:     uuid,
+			nvcdi.AnnotationNUMANode: "0",
+		}
+		deviceSpecs = append(deviceSpecs,
+			specs.Device{Name: strconv.Itoa(i), Annotations: annotations},
+			specs.Device{Name: uuid, Annotations: annotations},
+		)
+	}
+	deviceSpecs = append(deviceSpecs, specs.Device{Name: "all"})
+
+	devices := getTopologyDevices([]string{"numa"}, deviceSpecs)
+	require.EqualValues(t, []topologyDevice{
+		{
+			name:    "numa0",
+			members: []string{"0", "1"},
+			annotations: map[string]string{
+				"gpu.nvidia.com/numa-node": "0",
+				"gpu.nvidia.com/members":   "0,1",
+			},
+		},
+	}, devices)
+}
+
+func TestValidateTopologyGroupings(t *testing.T) {
+	require.NoError(t, validateTopologyGroupings([]string{"numa", "nvlink-clique", "pcie-switch"}))
+	require.EqualError(t, validateTopologyGroupings([]string{"socket"}), "invalid topology device grouping: socket")
+}
@@ -0,0 +1,195 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package generate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/atomicfile"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+)
+
+// watch regenerates the CDI specifications each time a change to the driver
+// or the devices on the system is detected. The specifications are only
+// written if their contents change.
+// Since procfs does not support inotify, the system state is polled at the
+// configured interval.
+func (m command) watch(ctx context.Context, opts *options) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	s := &systemState{
+		logger:             m.logger,
+		driverRoot:         opts.driverRoot,
+		devRoot:            opts.devRoot,
+		librarySearchPaths: opts.librarySearchPaths,
+		procRoot:           "/",
+	}
+
+	m.logger.Infof("Watching for changes to the driver and devices every %v", opts.watchInterval)
+	ticker := time.NewTicker(opts.watchInterval)
+	defer ticker.Stop()
+
+	var lastState string
+	for initial := true; ; initial = false {
+		if state := s.get(); initial || state != lastState {
+			m.logger.Debugf("Detected change in system state; regenerating CDI specifications")
+			if err := m.regenerate(opts); err != nil {
+				// We do not update the last state so that the generation is
+				// retried on the next tick.
+				m.logger.Warningf("Failed to regenerate CDI specifications: %v", err)
+			} else {
+				lastState = state
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Infof("Stopping watch")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// regenerate generates the CDI specifications and writes each of them if its
+// contents have changed.
+func (m command) regenerate(opts *options) error {
+	specs, err := m.generateSpecs(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate CDI spec: %w", err)
+	}
+
+	for _, spec := range specs {
+		filename, err := spec.outputFilename(opts)
+		if err != nil {
+			return err
+		}
+		path, updated, err := spec.saveIfChanged(filename, opts.format)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			m.logger.Debugf("CDI spec %v is up to date", path)
+			continue
+		}
+		m.logger.Infof("Updated CDI spec %v", path)
+	}
+	return nil
+}
+
+// saveIfChanged writes the spec to the specified file if its contents differ
+// from the existing file. The file is replaced atomically to ensure that
+// readers do not observe a partially-written spec.
+func (g *generatedSpecs) saveIfChanged(path string, format string) (string, bool, error) {
+	if formatFromFilename(path) == "" {
+		path += "." + format
+	}
+
+	contents := &bytes.Buffer{}
+	if _, err := g.WriteTo(contents); err != nil {
+		return path, false, fmt.Errorf("failed to generate contents of %v: %w", path, err)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, contents.Bytes()) {
+		return path, false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return path, false, fmt.Errorf("failed to create directory for %v: %w", path, err)
+	}
+	if err := atomicfile.WriteFile(path, contents.Bytes(), 0644); err != nil {
+		return path, false, err
+	}
+	return path, true, nil
+}
+
+// systemState captures the state of the system that determines the contents
+// of the generated CDI specifications.
+type systemState struct {
+	logger             logger.Interface
+	driverRoot         string
+	devRoot            string
+	librarySearchPaths []string
+	procRoot           string
+}
+
+// get returns a fingerprint of the current system state. This includes:
+//   - the paths and modification times of the driver libraries
+//   - the NVIDIA device nodes
+//   - the GPUs and MIG capabilities in /proc/driver/nvidia
+func (s *systemState) get() string {
+	var entries []string
+
+	driver := root.New(
+		root.WithLogger(s.logger),
+		root.WithDriverRoot(s.driverRoot),
+		root.WithLibrarySearchPaths(s.librarySearchPaths...),
+	)
+	for _, pattern := range []string{"libcuda.so.*.*", "libnvidia-ml.so.*.*"} {
+		libraries, _ := driver.Libraries().Locate(pattern)
+		for _, library := range libraries {
+			entries = append(entries, withModTime(library))
+		}
+	}
+
+	devRoot := s.devRoot
+	if devRoot == "" {
+		devRoot = s.driverRoot
+	}
+	if devRoot == "" {
+		devRoot = "/"
+	}
+	for _, pattern := range []string{"dev/nvidia*", "dev/nvidia-caps/*", "dev/nvidia-caps-imex-channels/*"} {
+		deviceNodes, _ := filepath.Glob(filepath.Join(devRoot, pattern))
+		entries = append(entries, deviceNodes...)
+	}
+
+	procDriverPath := filepath.Join(s.procRoot, "proc/driver/nvidia")
+	gpus, _ := filepath.Glob(filepath.Join(procDriverPath, "gpus/*"))
+	entries = append(entries, gpus...)
+	_ = filepath.WalkDir(filepath.Join(procDriverPath, "capabilities"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, path)
+		return nil
+	})
+
+	slices.Sort(entries)
+	return strings.Join(entries, "\n")
+}
+
+func withModTime(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path
+	}
+	return fmt.Sprintf("%v@%v", path, info.ModTime().UnixNano())
+}
@@ -0,0 +1,126 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
+)
+
+func TestSaveIfChanged(t *testing.T) {
+	outputDir := t.TempDir()
+	opts := &options{
+		output: filepath.Join(outputDir, "nvidia"),
+	}
+
+	newSpec := func(deviceName string) *generatedSpecs {
+		s, err := spec.New(
+			spec.WithVendor("example.com"),
+			spec.WithClass("device"),
+			spec.WithFormat(spec.FormatYAML),
+			spec.WithPermissions(0644),
+			spec.WithDeviceSpecs([]specs.Device{
+				{
+					Name: deviceName,
+					ContainerEdits: specs.ContainerEdits{
+						DeviceNodes: []*specs.DeviceNode{{Path: "/dev/" + deviceName}},
+					},
+				},
+			}),
+		)
+		require.NoError(t, err)
+		return &generatedSpecs{Interface: s, filenameInfix: ".infix"}
+	}
+	output, err := newSpec("device0").outputFilename(opts)
+	require.NoError(t, err)
+
+	path, updated, err := newSpec("device0").saveIfChanged(output, spec.FormatYAML)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.Equal(t, filepath.Join(outputDir, "nvidia.infix.yaml"), path)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	_, updated, err = newSpec("device0").saveIfChanged(output, spec.FormatYAML)
+	require.NoError(t, err)
+	require.False(t, updated)
+
+	_, updated, err = newSpec("device1").saveIfChanged(output, spec.FormatYAML)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	modified, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotEqual(t, original, modified)
+	require.Contains(t, string(modified), "/dev/device1")
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Supported extensions are not modified.
+	for _, filename := range []string{"nvidia.json", "nvidia.yaml", "nvidia.yml", "nvidia.YAML"} {
+		output := filepath.Join(outputDir, filename)
+		path, _, err := newSpec("device0").saveIfChanged(output, spec.FormatYAML)
+		require.NoError(t, err)
+		require.Equal(t, output, path)
+	}
+}
+
+func TestSystemState(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	testRoot := t.TempDir()
+
+	s := &systemState{
+		logger:     logger,
+		driverRoot: testRoot,
+		procRoot:   testRoot,
+	}
+
+	createFile := func(path string) {
+		path = filepath.Join(testRoot, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+	}
+
+	createFile("dev/nvidia0")
+	initial := s.get()
+	require.Equal(t, initial, s.get())
+
+	createFile("dev/nvidia1")
+	withDevice := s.get()
+	require.NotEqual(t, initial, withDevice)
+
+	createFile("proc/driver/nvidia/capabilities/gpu0/mig/gi1/access")
+	require.NotEqual(t, withDevice, s.get())
+
+	createFile("dev/not-nvidia")
+	require.Contains(t, s.get(), filepath.Join(testRoot, "dev/nvidia1"))
+	require.NotContains(t, s.get(), "not-nvidia")
+}
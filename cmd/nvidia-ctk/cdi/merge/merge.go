@@ -0,0 +1,138 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package merge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type options struct {
+	inputs []string
+	output string
+	format string
+}
+
+// NewCommand constructs a cdi merge command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:      "merge",
+		Usage:     "Merge CDI specifications of the same kind into a single specification",
+		ArgsUsage: "SPEC_FILE [SPEC_FILE ...]",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if cmd.Args().Len() < 2 {
+				return ctx, errors.New("at least two CDI specification files must be specified")
+			}
+			opts.inputs = cmd.Args().Slice()
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "output",
+				Usage:       "Specify the file to output the merged CDI specification to. If this is '' the specification is output to STDOUT",
+				Destination: &opts.output,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "The output format for the merged spec [json | yaml]. This is only used if no output file is specified.",
+				Value:       spec.FormatYAML,
+				Destination: &opts.format,
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	opts.format = strings.ToLower(opts.format)
+	switch opts.format {
+	case spec.FormatJSON, spec.FormatYAML:
+	default:
+		return fmt.Errorf("invalid output format: %v", opts.format)
+	}
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	var inputs []*specs.Spec
+	for _, input := range opts.inputs {
+		contents, err := os.ReadFile(input)
+		if err != nil {
+			return fmt.Errorf("failed to read CDI specification: %w", err)
+		}
+		raw, err := cdi.ParseSpec(contents)
+		if err != nil {
+			return fmt.Errorf("failed to parse CDI specification %v: %w", input, err)
+		}
+		if raw == nil {
+			return fmt.Errorf("failed to parse CDI specification %v: no spec data", input)
+		}
+		inputs = append(inputs, raw)
+	}
+
+	combined, err := transform.CombineSpecs(inputs...)
+	if err != nil {
+		return fmt.Errorf("failed to merge CDI specifications: %w", err)
+	}
+
+	// The version of the merged spec is set to the minimum version required
+	// for its contents when it is saved.
+	merged, err := spec.New(
+		spec.WithRawSpec(combined),
+		spec.WithFormat(opts.format),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create merged CDI specification: %w", err)
+	}
+
+	if opts.output == "" {
+		if _, err := merged.WriteTo(os.Stdout); err != nil {
+			return fmt.Errorf("failed to write CDI spec to STDOUT: %v", err)
+		}
+		return nil
+	}
+	return merged.Save(opts.output)
+}
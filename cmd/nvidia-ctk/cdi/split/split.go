@@ -0,0 +1,260 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package split
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
+)
+
+const (
+	byDevice    = "device"
+	byMIGParent = "mig-parent"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type options struct {
+	input          string
+	outputDir      string
+	by             string
+	excludeDevices []string
+}
+
+// NewCommand constructs a cdi split command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:      "split",
+		Usage:     "Split a CDI specification into one specification per device",
+		ArgsUsage: "SPEC_FILE",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if cmd.Args().Len() != 1 {
+				return ctx, errors.New("exactly one CDI specification file must be specified")
+			}
+			opts.input = cmd.Args().First()
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "output-dir",
+				Usage:       "Specify the directory to write the CDI specifications to",
+				Destination: &opts.outputDir,
+			},
+			&cli.StringFlag{
+				Name: "by",
+				Usage: "Specify how the devices are grouped into specifications. One of [device | mig-parent]. " +
+					"If this is mig-parent, MIG devices are included in the specification for their parent GPU.",
+				Value:       byDevice,
+				Destination: &opts.by,
+			},
+			&cli.StringSliceFlag{
+				Name: "exclude-device",
+				Usage: "Specify the names of devices that are not included in the output specifications. " +
+					"Names may be glob patterns. This is used to skip merged devices such as the 'all' device.",
+				Value:       []string{"all"},
+				Destination: &opts.excludeDevices,
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	if opts.outputDir == "" {
+		return fmt.Errorf("an output directory must be specified")
+	}
+	switch opts.by {
+	case byDevice, byMIGParent:
+	default:
+		return fmt.Errorf("invalid --by value: %v", opts.by)
+	}
+	for _, pattern := range opts.excludeDevices {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid device name pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	contents, err := os.ReadFile(opts.input)
+	if err != nil {
+		return fmt.Errorf("failed to read CDI specification: %w", err)
+	}
+	raw, err := cdi.ParseSpec(contents)
+	if err != nil {
+		return fmt.Errorf("failed to parse CDI specification %v: %w", opts.input, err)
+	}
+	if raw == nil {
+		return fmt.Errorf("failed to parse CDI specification %v: no spec data", opts.input)
+	}
+
+	groups, keys := m.groupDevices(raw.Devices, opts)
+	if len(keys) == 0 {
+		return fmt.Errorf("no devices to split in %v", opts.input)
+	}
+
+	if err := os.MkdirAll(opts.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	vendor, class := parser.ParseQualifier(raw.Kind)
+	ext := filepath.Ext(opts.input)
+	if ext != ".json" {
+		ext = ".yaml"
+	}
+	prefix := strings.TrimSuffix(filepath.Base(opts.input), filepath.Ext(opts.input))
+
+	// Each specification includes a copy of the common edits so that it can be
+	// used on its own. This means that devices from more than one of the
+	// output specifications must not be requested for the same container,
+	// since the common edits would be applied for each specification.
+	for _, key := range keys {
+		s, err := spec.New(
+			spec.WithVersion(raw.Version),
+			spec.WithVendor(vendor),
+			spec.WithClass(class),
+			spec.WithEdits(raw.ContainerEdits),
+			spec.WithDeviceSpecs(groups[key]),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create CDI specification for %v: %w", key, err)
+		}
+
+		filename := filepath.Join(opts.outputDir, prefix+"-"+strings.ReplaceAll(key, ":", "_")+ext)
+		if err := s.Save(filename); err != nil {
+			return fmt.Errorf("failed to save CDI specification for %v: %w", key, err)
+		}
+		m.logger.Infof("Wrote CDI specification for %v to %v", key, filename)
+	}
+	return nil
+}
+
+// groupDevices groups the devices by the key that determines the output
+// specification. The keys are returned in the order that they are first
+// encountered.
+func (m command) groupDevices(devices []specs.Device, opts *options) (map[string][]specs.Device, []string) {
+	parents := make(map[string]string)
+	for _, device := range devices {
+		if uuid := gpuUUID(device); uuid != "" {
+			if _, ok := parents[uuid]; !ok {
+				parents[uuid] = device.Name
+			}
+		}
+	}
+
+	groups := make(map[string][]specs.Device)
+	var keys []string
+	for _, device := range devices {
+		if matchesAny(opts.excludeDevices, device.Name) {
+			m.logger.Debugf("Skipping excluded device %v", device.Name)
+			continue
+		}
+		key := device.Name
+		if opts.by == byMIGParent {
+			key = migParent(device, parents)
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], device)
+	}
+	return groups, keys
+}
+
+// migParent returns the identifier of the parent GPU for a MIG device. For a
+// full GPU the device is its own parent. If the UUID of the parent is known
+// from the parent-uuid annotation or the device itself is a full GPU with a
+// known UUID, the UUID is resolved to the name of the GPU using the specified
+// map of GPU UUIDs to device names. Otherwise the parent is inferred from the
+// index-based device names such as 0, 0:1, gpu0, and mig0:1.
+func migParent(device specs.Device, parents map[string]string) string {
+	uuid, ok := device.Annotations[nvcdi.AnnotationParentUUID]
+	if !ok {
+		uuid = gpuUUID(device)
+	}
+	if uuid != "" {
+		if name, ok := parents[uuid]; ok {
+			return name
+		}
+		return uuid
+	}
+
+	name := device.Name
+	if gpu, _, isMIG := strings.Cut(name, ":"); isMIG {
+		return strings.TrimPrefix(gpu, "mig")
+	}
+	if index := strings.TrimPrefix(name, "gpu"); index != name && index != "" && strings.Trim(index, "0123456789") == "" {
+		return index
+	}
+	return name
+}
+
+// gpuUUID returns the UUID of a full GPU device. This is taken from the uuid
+// annotation or from the device name if the uuid naming strategy was used. An
+// empty string is returned for MIG devices and devices with an unknown UUID.
+func gpuUUID(device specs.Device) string {
+	if _, ok := device.Annotations[nvcdi.AnnotationParentUUID]; ok {
+		return ""
+	}
+	if uuid, ok := device.Annotations[nvcdi.AnnotationUUID]; ok {
+		return uuid
+	}
+	if strings.HasPrefix(device.Name, "GPU-") {
+		return device.Name
+	}
+	return ""
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package split
+
+import (
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestGroupDevices(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	devices := []specs.Device{
+		{Name: "0"},
+		{Name: "0:1"},
+		{Name: "gpu1"},
+		{Name: "mig1:0"},
+		{Name: "GPU-1234"},
+		{Name: "MIG-5678", Annotations: map[string]string{"gpu.nvidia.com/parent-uuid": "GPU-1234"}},
+		{Name: "all"},
+	}
+
+	testCases := []struct {
+		description    string
+		options        options
+		expectedGroups map[string][]string
+		expectedKeys   []string
+	}{
+		{
+			description: "by device",
+			options: options{
+				by:             byDevice,
+				excludeDevices: []string{"all"},
+			},
+			expectedGroups: map[string][]string{
+				"0":        {"0"},
+				"0:1":      {"0:1"},
+				"gpu1":     {"gpu1"},
+				"mig1:0":   {"mig1:0"},
+				"GPU-1234": {"GPU-1234"},
+				"MIG-5678": {"MIG-5678"},
+			},
+			expectedKeys: []string{"0", "0:1", "gpu1", "mig1:0", "GPU-1234", "MIG-5678"},
+		},
+		{
+			description: "by MIG parent",
+			options: options{
+				by:             byMIGParent,
+				excludeDevices: []string{"all"},
+			},
+			expectedGroups: map[string][]string{
+				"0":        {"0", "0:1"},
+				"1":        {"gpu1", "mig1:0"},
+				"GPU-1234": {"GPU-1234", "MIG-5678"},
+			},
+			expectedKeys: []string{"0", "1", "GPU-1234"},
+		},
+		{
+			description: "glob exclude",
+			options: options{
+				by:             byMIGParent,
+				excludeDevices: []string{"all", "*-*"},
+			},
+			expectedGroups: map[string][]string{
+				"0": {"0", "0:1"},
+				"1": {"gpu1", "mig1:0"},
+			},
+			expectedKeys: []string{"0", "1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			groups, keys := command{logger: logger}.groupDevices(devices, &tc.options)
+
+			names := make(map[string][]string)
+			for key, group := range groups {
+				for _, device := range group {
+					names[key] = append(names[key], device.Name)
+				}
+			}
+			require.EqualValues(t, tc.expectedGroups, names)
+			require.EqualValues(t, tc.expectedKeys, keys)
+		})
+	}
+}
+
+func TestGroupDevicesByMIGParent(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description    string
+		devices        []specs.Device
+		expectedGroups map[string][]string
+		expectedKeys   []string
+	}{
+		{
+			description: "annotated index names",
+			devices: []specs.Device{
+				{Name: "0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "GPU-0"}},
+				{Name: "0:0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "MIG-0", "gpu.nvidia.com/parent-uuid": "GPU-0"}},
+				{Name: "1", Annotations: map[string]string{"gpu.nvidia.com/uuid": "GPU-1"}},
+				{Name: "1:0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "MIG-1", "gpu.nvidia.com/parent-uuid": "GPU-1"}},
+			},
+			expectedGroups: map[string][]string{
+				"0": {"0", "0:0"},
+				"1": {"1", "1:0"},
+			},
+			expectedKeys: []string{"0", "1"},
+		},
+		{
+			description: "annotated uuid names",
+			devices: []specs.Device{
+				{Name: "GPU-0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "GPU-0"}},
+				{Name: "MIG-0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "MIG-0", "gpu.nvidia.com/parent-uuid": "GPU-0"}},
+			},
+			expectedGroups: map[string][]string{
+				"GPU-0": {"GPU-0", "MIG-0"},
+			},
+			expectedKeys: []string{"GPU-0"},
+		},
+		{
+			description: "multiple naming strategies use the first name",
+			devices: []specs.Device{
+				{Name: "0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "GPU-0"}},
+				{Name: "GPU-0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "GPU-0"}},
+				{Name: "MIG-0", Annotations: map[string]string{"gpu.nvidia.com/uuid": "MIG-0", "gpu.nvidia.com/parent-uuid": "GPU-0"}},
+			},
+			expectedGroups: map[string][]string{
+				"0": {"0", "GPU-0", "MIG-0"},
+			},
+			expectedKeys: []string{"0"},
+		},
+		{
+			description: "unannotated uuid names",
+			devices: []specs.Device{
+				{Name: "GPU-0"},
+				{Name: "MIG-0", Annotations: map[string]string{"gpu.nvidia.com/parent-uuid": "GPU-0"}},
+			},
+			expectedGroups: map[string][]string{
+				"GPU-0": {"GPU-0", "MIG-0"},
+			},
+			expectedKeys: []string{"GPU-0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			opts := options{by: byMIGParent}
+			groups, keys := command{logger: logger}.groupDevices(tc.devices, &opts)
+
+			names := make(map[string][]string)
+			for key, group := range groups {
+				for _, device := range group {
+					names[key] = append(names[key], device.Name)
+				}
+			}
+			require.EqualValues(t, tc.expectedGroups, names)
+			require.EqualValues(t, tc.expectedKeys, keys)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/spec"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type options struct {
+	input    string
+	output   string
+	pipeline string
+}
+
+// NewCommand constructs a transform apply command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:                   "apply",
+		Usage:                  "Apply a pipeline of transforms defined in a file to a CDI specification",
+		UseShortOptionHandling: true,
+		EnableShellCompletion:  true,
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "pipeline",
+				Usage:       "Specify the file (YAML or JSON) that defines the ordered list of transforms to apply",
+				Destination: &opts.pipeline,
+			},
+			&cli.StringFlag{
+				Name:        "input",
+				Usage:       "Specify the file to read the CDI specification from. If this is '-' the specification is read from STDIN",
+				Value:       "-",
+				Destination: &opts.input,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Usage:       "Specify the file to output the generated CDI specification to. If this is '' the specification is output to STDOUT",
+				Destination: &opts.output,
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	if opts.pipeline == "" {
+		return fmt.Errorf("a pipeline file must be specified")
+	}
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	pipeline, err := loadPipeline(opts.pipeline)
+	if err != nil {
+		return err
+	}
+	transformer, err := pipeline.transformer()
+	if err != nil {
+		return err
+	}
+
+	spec, err := opts.load()
+	if err != nil {
+		return fmt.Errorf("failed to load CDI specification: %w", err)
+	}
+
+	if err := transformer.Transform(spec.Raw()); err != nil {
+		return fmt.Errorf("failed to transform CDI specification: %w", err)
+	}
+
+	return opts.save(spec)
+}
+
+// load loads the input CDI specification
+func (o options) load() (spec.Interface, error) {
+	contents, err := o.getContents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec contents: %v", err)
+	}
+
+	raw, err := cdi.ParseSpec(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CDI spec: %v", err)
+	}
+
+	return spec.New(
+		spec.WithRawSpec(raw),
+	)
+}
+
+func (o options) getContents() ([]byte, error) {
+	if o.input == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(o.input)
+}
+
+// save saves the CDI specification to the output file
+func (o options) save(s spec.Interface) error {
+	if o.output == "" {
+		_, err := s.WriteTo(os.Stdout)
+		if err != nil {
+			return fmt.Errorf("failed to write CDI spec to STDOUT: %v", err)
+		}
+		return nil
+	}
+
+	return s.Save(o.output)
+}
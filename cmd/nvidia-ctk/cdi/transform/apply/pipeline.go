@@ -0,0 +1,153 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package apply
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
+	transformroot "github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform/root"
+)
+
+// A pipeline defines an ordered list of transforms to apply to a CDI spec.
+//
+// For example:
+//
+//	transforms:
+//	- type: filter-devices
+//	  exclude: ["mig*"]
+//	- type: drop-hooks
+//	  hooks: ["update-ldcache"]
+//	- type: merged-device
+//	  name: all
+type pipeline struct {
+	Transforms []step `json:"transforms"`
+}
+
+// A step defines a single transform in a pipeline. The fields that apply
+// depend on the type of the transform.
+type step struct {
+	Type string `json:"type"`
+
+	// Kind is the new kind for the rename-kind transform.
+	Kind string `json:"kind,omitempty"`
+	// Include and Exclude are the device name patterns for the
+	// filter-devices transform.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// Hooks are the names of the hooks removed by the drop-hooks transform.
+	Hooks []string `json:"hooks,omitempty"`
+	// Env are the KEY=VALUE environment variables for the set-env transform.
+	Env []string `json:"env,omitempty"`
+	// ContainerPath, Add, and Remove configure the mount-options transform.
+	ContainerPath string   `json:"containerPath,omitempty"`
+	Add           []string `json:"add,omitempty"`
+	Remove        []string `json:"remove,omitempty"`
+	// Name, Devices, and Annotations configure the merged-device transform.
+	Name        string            `json:"name,omitempty"`
+	Devices     []string          `json:"devices,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// From, To, and RelativeTo configure the root transform.
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	RelativeTo string `json:"relativeTo,omitempty"`
+}
+
+// loadPipeline reads a pipeline from the specified YAML or JSON file.
+func loadPipeline(path string) (*pipeline, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline: %w", err)
+	}
+
+	var p pipeline
+	if err := yaml.UnmarshalStrict(contents, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline %v: %w", path, err)
+	}
+	if len(p.Transforms) == 0 {
+		return nil, fmt.Errorf("pipeline %v does not define any transforms", path)
+	}
+	return &p, nil
+}
+
+// transformer returns a transformer that applies the steps of the pipeline
+// in order.
+func (p pipeline) transformer() (transform.Transformer, error) {
+	var transformers []transform.Transformer
+	for i, s := range p.Transforms {
+		t, err := s.transformer()
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform %d: %w", i, err)
+		}
+		transformers = append(transformers, t)
+	}
+	return transform.Merge(transformers...), nil
+}
+
+func (s step) transformer() (transform.Transformer, error) {
+	switch s.Type {
+	case "dedupe":
+		return transform.NewDedupe()
+	case "simplify":
+		return transform.NewSimplifier(), nil
+	case "sort":
+		return transform.NewSorter(), nil
+	case "rename-kind":
+		return transform.NewKindRenamer(s.Kind)
+	case "filter-devices":
+		return transform.NewDeviceFilter(
+			transform.WithIncludedDevices(s.Include...),
+			transform.WithExcludedDevices(s.Exclude...),
+		)
+	case "drop-hooks":
+		return transform.NewHookRemover(s.Hooks...), nil
+	case "set-env":
+		return transform.NewEnvSetter(s.Env...)
+	case "mount-options":
+		return transform.NewMountOptionsUpdater(
+			transform.WithContainerPathPattern(s.ContainerPath),
+			transform.WithAddedMountOptions(s.Add...),
+			transform.WithRemovedMountOptions(s.Remove...),
+		)
+	case "merged-device":
+		return transform.NewMergedDevice(
+			transform.WithName(s.Name),
+			transform.WithMemberDevices(s.Devices...),
+			transform.WithAnnotations(s.Annotations),
+		)
+	case "root":
+		relativeTo := s.RelativeTo
+		if relativeTo == "" {
+			relativeTo = "host"
+		}
+		if relativeTo != "host" && relativeTo != "container" {
+			return nil, fmt.Errorf("invalid relativeTo value: %v", relativeTo)
+		}
+		return transformroot.New(
+			transformroot.WithRoot(s.From),
+			transformroot.WithTargetRoot(s.To),
+			transformroot.WithRelativeTo(relativeTo),
+		), nil
+	case "":
+		return nil, fmt.Errorf("no transform type specified")
+	default:
+		return nil, fmt.Errorf("unsupported transform type %q", s.Type)
+	}
+}
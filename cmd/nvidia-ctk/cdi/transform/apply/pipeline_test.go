@@ -0,0 +1,162 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestPipeline(t *testing.T) {
+	testCases := []struct {
+		description   string
+		pipeline      string
+		expectedError string
+		expectedSpec  *specs.Spec
+	}{
+		{
+			description:   "empty pipeline",
+			pipeline:      "transforms: []",
+			expectedError: "does not define any transforms",
+		},
+		{
+			description: "unknown field",
+			pipeline: `transforms:
+- type: rename-kind
+  vendor: example.com
+`,
+			expectedError: `unknown field "vendor"`,
+		},
+		{
+			description: "unsupported transform",
+			pipeline: `transforms:
+- type: dedupe
+- type: compress
+`,
+			expectedError: `invalid transform 1: unsupported transform type "compress"`,
+		},
+		{
+			description: "transforms are applied in order",
+			pipeline: `transforms:
+- type: rename-kind
+  kind: example.com/device
+- type: filter-devices
+  exclude: ["all", "mig*"]
+- type: drop-hooks
+  hooks: ["update-ldcache"]
+- type: set-env
+  env: ["NVIDIA_VISIBLE_DEVICES=all"]
+- type: mount-options
+  containerPath: /usr/lib/*
+  add: ["noexec"]
+- type: merged-device
+  name: all
+`,
+			expectedSpec: &specs.Spec{
+				Version: "0.5.0",
+				Kind:    "example.com/device",
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{"NVIDIA_VISIBLE_DEVICES=all"},
+					Mounts: []*specs.Mount{
+						{HostPath: "/usr/lib/libcuda.so.1", ContainerPath: "/usr/lib/libcuda.so.1", Options: []string{"ro", "noexec"}},
+					},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}},
+						},
+					},
+					{
+						Name: "all",
+						ContainerEdits: specs.ContainerEdits{
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			pipelineFile := filepath.Join(t.TempDir(), "pipeline.yaml")
+			require.NoError(t, os.WriteFile(pipelineFile, []byte(tc.pipeline), 0600))
+
+			spec := &specs.Spec{
+				Version: "0.5.0",
+				Kind:    "nvidia.com/gpu",
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{"NVIDIA_VISIBLE_DEVICES=void"},
+					Hooks: []*specs.Hook{
+						{
+							HookName: "createContainer",
+							Path:     "/usr/bin/nvidia-cdi-hook",
+							Args:     []string{"nvidia-cdi-hook", "update-ldcache"},
+						},
+					},
+					Mounts: []*specs.Mount{
+						{HostPath: "/usr/lib/libcuda.so.1", ContainerPath: "/usr/lib/libcuda.so.1", Options: []string{"ro"}},
+					},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}},
+						},
+					},
+					{
+						Name: "mig0:0",
+						ContainerEdits: specs.ContainerEdits{
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia-caps/nvidia-cap12"}},
+						},
+					},
+					{
+						Name: "all",
+						ContainerEdits: specs.ContainerEdits{
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}, {Path: "/dev/nvidia-caps/nvidia-cap12"}},
+						},
+					},
+				},
+			}
+
+			err := func() error {
+				p, err := loadPipeline(pipelineFile)
+				if err != nil {
+					return err
+				}
+				transformer, err := p.transformer()
+				if err != nil {
+					return err
+				}
+				return transformer.Transform(spec)
+			}()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedSpec, spec)
+		})
+	}
+}
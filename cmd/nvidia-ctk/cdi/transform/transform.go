@@ -19,6 +19,7 @@ package transform
 import (
 	"github.com/urfave/cli/v3"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/transform/apply"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi/transform/root"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
@@ -41,6 +42,7 @@ func (m command) build() *cli.Command {
 		Name:  "transform",
 		Usage: "Apply a transform to a CDI specification",
 		Commands: []*cli.Command{
+			apply.NewCommand(m.logger),
 			root.NewCommand(m.logger),
 		},
 	}
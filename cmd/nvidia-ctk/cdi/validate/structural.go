@@ -0,0 +1,138 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+// validHookNames lists the hook names allowed by the CDI specification.
+var validHookNames = []string{
+	cdi.PrestartHook,
+	cdi.CreateRuntimeHook,
+	cdi.CreateContainerHook,
+	cdi.StartContainerHook,
+	cdi.PoststartHook,
+	cdi.PoststopHook,
+}
+
+// validDeviceNodeTypes lists the device node types allowed by the CDI
+// specification.
+var validDeviceNodeTypes = []string{"", "b", "c", "u", "p"}
+
+// structuralViolations is returned by the structuralValidator if a spec does
+// not pass its checks.
+type structuralViolations []string
+
+func (s structuralViolations) Error() string {
+	return strings.Join(s, "; ")
+}
+
+// A structuralValidator performs a set of structural checks on a CDI spec.
+// Note that this is not a validation against the CDI JSON schema and only the
+// following is checked:
+//   - the cdiVersion and kind are set and at least one device is defined;
+//   - each device has a name and non-empty container edits;
+//   - each device node has a path and a type of b, c, u, or p (or no type);
+//   - each mount has a hostPath and a containerPath;
+//   - each hook has a path and one of the hook names defined by the CDI
+//     specification.
+//
+// These checks apply to both the common and the device-specific container
+// edits. In contrast to the validation performed when a spec is loaded, all
+// violations are reported and not only the first one. The validator is
+// registered using cdi.SetSpecValidator.
+type structuralValidator struct{}
+
+// Validate returns a structuralViolations error if the spec does not pass the
+// structural checks.
+func (structuralValidator) Validate(raw *specs.Spec) error {
+	var violations structuralViolations
+	addViolation := func(format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	if raw.Version == "" {
+		addViolation("cdiVersion is required")
+	}
+	if raw.Kind == "" {
+		addViolation("kind is required")
+	}
+	if len(raw.Devices) == 0 {
+		addViolation("devices must contain at least one device")
+	}
+
+	checkEdits := func(field string, edits *specs.ContainerEdits) {
+		for i, dn := range edits.DeviceNodes {
+			if dn.Path == "" {
+				addViolation("%v.deviceNodes[%d].path is required", field, i)
+			}
+			if !slices.Contains(validDeviceNodeTypes, dn.Type) {
+				addViolation("%v.deviceNodes[%d].type %q must be one of [b, c, u, p]", field, i, dn.Type)
+			}
+		}
+		for i, m := range edits.Mounts {
+			if m.HostPath == "" {
+				addViolation("%v.mounts[%d].hostPath is required", field, i)
+			}
+			if m.ContainerPath == "" {
+				addViolation("%v.mounts[%d].containerPath is required", field, i)
+			}
+		}
+		for i, h := range edits.Hooks {
+			if !slices.Contains(validHookNames, h.HookName) {
+				addViolation("%v.hooks[%d].hookName %q must be one of %v", field, i, h.HookName, validHookNames)
+			}
+			if h.Path == "" {
+				addViolation("%v.hooks[%d].path is required", field, i)
+			}
+		}
+	}
+
+	checkEdits("containerEdits", &raw.ContainerEdits)
+	for i := range raw.Devices {
+		device := &raw.Devices[i]
+		field := fmt.Sprintf("devices[%d]", i)
+		if device.Name == "" {
+			addViolation("%v.name is required", field)
+		}
+		if isEmpty(&device.ContainerEdits) {
+			addViolation("%v.containerEdits must not be empty", field)
+		}
+		checkEdits(field+".containerEdits", &device.ContainerEdits)
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+func isEmpty(edits *specs.ContainerEdits) bool {
+	return len(edits.Env) == 0 &&
+		len(edits.DeviceNodes) == 0 &&
+		len(edits.NetDevices) == 0 &&
+		len(edits.Hooks) == 0 &&
+		len(edits.Mounts) == 0 &&
+		len(edits.AdditionalGIDs) == 0 &&
+		edits.IntelRdt == nil
+}
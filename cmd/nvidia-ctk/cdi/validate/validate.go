@@ -0,0 +1,418 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
+)
+
+type command struct {
+	logger logger.Interface
+	// getVersion returns the version of the specified hook executable.
+	getVersion func(string) (string, error)
+}
+
+type options struct {
+	cdiSpecDirs       []string
+	specPaths         []string
+	root              string
+	nvidiaCDIHookPath string
+}
+
+// NewCommand constructs a cdi validate command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger:     logger,
+		getVersion: getHookVersion,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the command
+	c := cli.Command{
+		Name:      "validate",
+		Usage:     "Validate CDI specifications",
+		ArgsUsage: "[SPEC_FILE_OR_DIR...]",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			opts.specPaths = cmd.Args().Slice()
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "spec-dir",
+				Usage:       "specify the directories to scan for CDI specifications. These are only used if no specification files or directories are specified as arguments",
+				Value:       cdi.DefaultSpecDirs,
+				Destination: &opts.cdiSpecDirs,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_SPEC_DIRS"),
+			},
+			&cli.StringFlag{
+				Name:        "root",
+				Usage:       "specify the root under which the host paths referenced in the CDI specifications are checked",
+				Value:       "/",
+				Destination: &opts.root,
+			},
+			&cli.StringFlag{
+				Name:    "nvidia-cdi-hook-path",
+				Aliases: []string{"nvidia-ctk-path"},
+				Usage: "Specify the path to the installed nvidia-cdi-hook executable. " +
+					"The version of the nvidia-cdi-hook referenced in the CDI specifications is compared to the version of this executable.",
+				Destination: &opts.nvidiaCDIHookPath,
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	if len(opts.specPaths) == 0 && len(opts.cdiSpecDirs) == 0 {
+		return errors.New("at least one CDI specification file or directory must be specified")
+	}
+	if opts.root == "" {
+		opts.root = "/"
+	}
+	opts.nvidiaCDIHookPath = config.ResolveNVIDIACDIHookPath(m.logger, opts.nvidiaCDIHookPath)
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	findings := m.validate(opts)
+
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("found %d issue(s) in the CDI specifications", len(findings))
+	}
+
+	m.logger.Infof("No issues found in the CDI specifications")
+	return nil
+}
+
+// validate loads the CDI specifications and returns a list of findings.
+// An empty list indicates that no issues were found.
+func (m command) validate(opts *options) []string {
+	cdi.SetSpecValidator(structuralValidator{})
+	defer cdi.SetSpecValidator(nil)
+
+	v := &validator{
+		command:        m,
+		options:        opts,
+		deviceSources:  make(map[string]string),
+		hookVersions:   make(map[string]string),
+		hookVersionErr: make(map[string]error),
+	}
+
+	specFiles, findings := m.getSpecFiles(opts)
+	for _, specFile := range specFiles {
+		findings = append(findings, v.validateSpecFile(specFile)...)
+	}
+	return findings
+}
+
+// getSpecFiles returns the CDI specification files to validate. If no paths
+// were specified as arguments, the configured spec dirs are scanned.
+func (m command) getSpecFiles(opts *options) ([]string, []string) {
+	var specFiles []string
+	var findings []string
+
+	if len(opts.specPaths) == 0 {
+		for _, dir := range opts.cdiSpecDirs {
+			files, err := specFilesInDir(dir)
+			if err != nil && !os.IsNotExist(err) {
+				findings = append(findings, fmt.Sprintf("%v: %v", dir, err))
+			}
+			specFiles = append(specFiles, files...)
+		}
+		return specFiles, findings
+	}
+
+	for _, path := range opts.specPaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			findings = append(findings, fmt.Sprintf("%v: %v", path, err))
+			continue
+		}
+		if !info.IsDir() {
+			specFiles = append(specFiles, path)
+			continue
+		}
+		files, err := specFilesInDir(path)
+		if err != nil {
+			findings = append(findings, fmt.Sprintf("%v: %v", path, err))
+		}
+		specFiles = append(specFiles, files...)
+	}
+	return specFiles, findings
+}
+
+// specFilesInDir returns the .json and .yaml files in the specified directory.
+func specFilesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var specFiles []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case ".json", ".yaml":
+			specFiles = append(specFiles, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return specFiles, nil
+}
+
+type validator struct {
+	command
+	*options
+
+	// deviceSources maps fully-qualified device names to the spec file that
+	// first defined them.
+	deviceSources map[string]string
+
+	hookVersions   map[string]string
+	hookVersionErr map[string]error
+}
+
+// validateSpecFile validates a single CDI specification file.
+func (v *validator) validateSpecFile(path string) []string {
+	var findings []string
+	addFinding := func(format string, args ...interface{}) {
+		findings = append(findings, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	// ReadSpec performs a strict parse of the specification and applies the
+	// checks of the registered structuralValidator before validating the
+	// version, the kind, the device names, and the container edits.
+	s, err := cdi.ReadSpec(path, 0)
+	var violations structuralViolations
+	if errors.As(err, &violations) {
+		for _, violation := range violations {
+			addFinding("structural violation: %v", violation)
+		}
+		return findings
+	}
+	if err != nil {
+		addFinding("invalid CDI specification: %v", err)
+		return findings
+	}
+	raw := s.Spec
+
+	for _, device := range raw.Devices {
+		name := parser.QualifiedName(s.GetVendor(), s.GetClass(), device.Name)
+		if source, ok := v.deviceSources[name]; ok {
+			addFinding("device %v is also defined in %v", name, source)
+			continue
+		}
+		v.deviceSources[name] = path
+	}
+
+	for _, edits := range allEdits(raw) {
+		for _, dn := range edits.DeviceNodes {
+			hostPath := dn.HostPath
+			if hostPath == "" {
+				hostPath = dn.Path
+			}
+			if !v.exists(hostPath) {
+				addFinding("device node %v does not exist", v.underRoot(hostPath))
+			}
+		}
+		for _, m := range edits.Mounts {
+			if !v.exists(m.HostPath) {
+				addFinding("mount %v does not exist", v.underRoot(m.HostPath))
+			}
+		}
+		for _, h := range edits.Hooks {
+			if err := v.checkHook(h); err != nil {
+				addFinding("%v hook %v: %v", h.HookName, h.Path, err)
+			}
+		}
+	}
+
+	for _, finding := range checkTransforms(raw) {
+		addFinding("%v", finding)
+	}
+
+	return findings
+}
+
+// allEdits returns the common container edits followed by the container
+// edits for each device in the spec.
+func allEdits(raw *specs.Spec) []*specs.ContainerEdits {
+	edits := []*specs.ContainerEdits{&raw.ContainerEdits}
+	for i := range raw.Devices {
+		edits = append(edits, &raw.Devices[i].ContainerEdits)
+	}
+	return edits
+}
+
+func (v *validator) underRoot(path string) string {
+	return filepath.Join(v.root, path)
+}
+
+func (v *validator) exists(path string) bool {
+	_, err := os.Stat(v.underRoot(path))
+	return err == nil
+}
+
+// checkHook checks that the hook path is executable. If the hook is an
+// nvidia-cdi-hook, its version is also compared to the installed version.
+func (v *validator) checkHook(h *specs.Hook) error {
+	hookPath := v.underRoot(h.Path)
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		return fmt.Errorf("path does not exist")
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return fmt.Errorf("path is not executable")
+	}
+
+	if !isNVIDIACDIHook(h) {
+		return nil
+	}
+
+	installedVersion, err := v.hookVersion(v.nvidiaCDIHookPath)
+	if err != nil {
+		v.logger.Warningf("Skipping version check for %v: failed to get version of installed nvidia-cdi-hook %v: %v", h.Path, v.nvidiaCDIHookPath, err)
+		return nil
+	}
+	version, err := v.hookVersion(hookPath)
+	if err != nil {
+		return fmt.Errorf("failed to get version: %w", err)
+	}
+	if version != installedVersion {
+		return fmt.Errorf("version %v does not match installed version %v", version, installedVersion)
+	}
+	return nil
+}
+
+// isNVIDIACDIHook checks whether the specified hook invokes the nvidia-cdi-hook
+// either directly or as the nvidia-ctk hook subcommand.
+func isNVIDIACDIHook(h *specs.Hook) bool {
+	switch filepath.Base(h.Path) {
+	case "nvidia-cdi-hook":
+		return true
+	case "nvidia-ctk":
+		return len(h.Args) > 1 && h.Args[1] == "hook"
+	}
+	return false
+}
+
+// hookVersion returns the (cached) version of the specified hook executable.
+func (v *validator) hookVersion(path string) (string, error) {
+	if err, ok := v.hookVersionErr[path]; ok {
+		return "", err
+	}
+	if version, ok := v.hookVersions[path]; ok {
+		return version, nil
+	}
+	version, err := v.getVersion(path)
+	if err != nil {
+		v.hookVersionErr[path] = err
+		return "", err
+	}
+	v.hookVersions[path] = version
+	return version, nil
+}
+
+// getHookVersion runs the specified executable with the --version flag and
+// extracts the version from the output.
+func getHookVersion(path string) (string, error) {
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	_, version, found := strings.Cut(firstLine, " version ")
+	if !found {
+		return "", fmt.Errorf("unexpected version output %q", firstLine)
+	}
+	return strings.TrimSpace(version), nil
+}
+
+// checkTransforms returns a finding for each of the deduplicate or simplify
+// transforms that would modify the spec. Since these transforms also sort the
+// entities in the spec, the results are compared to a sorted copy of the spec.
+func checkTransforms(raw *specs.Spec) []string {
+	sorted, err := transformed(raw, transform.NewSorter())
+	if err != nil {
+		return []string{fmt.Sprintf("failed to sort spec: %v", err)}
+	}
+
+	var findings []string
+	dedupe, _ := transform.NewDedupe()
+	deduplicated, err := transformed(raw, transform.Merge(dedupe, transform.NewSorter()))
+	if err != nil {
+		return []string{fmt.Sprintf("failed to deduplicate spec: %v", err)}
+	}
+	if !bytes.Equal(sorted, deduplicated) {
+		findings = append(findings, "spec contains duplicate container edits")
+	}
+
+	simplified, err := transformed(raw, transform.NewSimplifier())
+	if err != nil {
+		return append(findings, fmt.Sprintf("failed to simplify spec: %v", err))
+	}
+	if !bytes.Equal(deduplicated, simplified) {
+		findings = append(findings, "device container edits contain entries that are already included in the common container edits")
+	}
+	return findings
+}
+
+// transformed applies the transformer to a copy of the spec and returns the
+// JSON representation of the result.
+func transformed(raw *specs.Spec, t transform.Transformer) ([]byte, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var specCopy specs.Spec
+	if err := json.Unmarshal(data, &specCopy); err != nil {
+		return nil, err
+	}
+	if err := t.Transform(&specCopy); err != nil {
+		return nil, err
+	}
+	return json.Marshal(&specCopy)
+}
@@ -0,0 +1,228 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testRoot := t.TempDir()
+	for path, mode := range map[string]os.FileMode{
+		"dev/nvidia0":                 0600,
+		"usr/lib/libcuda.so.1":        0644,
+		"usr/bin/nvidia-cdi-hook":     0755,
+		"usr/bin/old/nvidia-cdi-hook": 0755,
+		"usr/bin/not-executable":      0644,
+	} {
+		path = filepath.Join(testRoot, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, mode))
+	}
+	versions := map[string]string{
+		filepath.Join(testRoot, "usr/bin/nvidia-cdi-hook"):     "1.18.0",
+		filepath.Join(testRoot, "usr/bin/old/nvidia-cdi-hook"): "1.17.0",
+	}
+
+	testCases := []struct {
+		description      string
+		specs            map[string]string
+		expectedFindings []string
+	}{
+		{
+			description: "valid spec has no findings",
+			specs: map[string]string{
+				"nvidia.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+containerEdits:
+  mounts:
+  - hostPath: /usr/lib/libcuda.so.1
+    containerPath: /usr/lib/libcuda.so.1
+  hooks:
+  - hookName: createContainer
+    path: /usr/bin/nvidia-cdi-hook
+    args: [nvidia-cdi-hook, update-ldcache]
+`,
+			},
+		},
+		{
+			description: "invalid spec is reported",
+			specs: map[string]string{
+				"nvidia.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  unknownField: value
+`,
+			},
+			expectedFindings: []string{
+				`nvidia.yaml: invalid CDI specification: failed to parse CDI Spec "nvidia.yaml": failed to unmarshal CDI Spec: error unmarshaling JSON: while decoding JSON: json: unknown field "unknownField"`,
+			},
+		},
+		{
+			description: "structural violations are reported",
+			specs: map[string]string{
+				"nvidia.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+      type: x
+- name: gpu1
+containerEdits:
+  mounts:
+  - hostPath: /usr/lib/libcuda.so.1
+  hooks:
+  - hookName: unknown
+`,
+			},
+			expectedFindings: []string{
+				"nvidia.yaml: structural violation: containerEdits.mounts[0].containerPath is required",
+				`nvidia.yaml: structural violation: containerEdits.hooks[0].hookName "unknown" must be one of [prestart createRuntime createContainer startContainer poststart poststop]`,
+				"nvidia.yaml: structural violation: containerEdits.hooks[0].path is required",
+				`nvidia.yaml: structural violation: devices[0].containerEdits.deviceNodes[0].type "x" must be one of [b, c, u, p]`,
+				"nvidia.yaml: structural violation: devices[1].containerEdits must not be empty",
+			},
+		},
+		{
+			description: "missing host paths are reported",
+			specs: map[string]string{
+				"nvidia.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu1
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia1
+containerEdits:
+  mounts:
+  - hostPath: /usr/lib/libcuda.so.2
+    containerPath: /usr/lib/libcuda.so.2
+`,
+			},
+			expectedFindings: []string{
+				"nvidia.yaml: mount " + filepath.Join(testRoot, "usr/lib/libcuda.so.2") + " does not exist",
+				"nvidia.yaml: device node " + filepath.Join(testRoot, "dev/nvidia1") + " does not exist",
+			},
+		},
+		{
+			description: "duplicate devices across files are reported",
+			specs: map[string]string{
+				"a.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+`,
+				"b.json": `{"cdiVersion":"0.5.0","kind":"nvidia.com/gpu","devices":[{"name":"gpu0","containerEdits":{"deviceNodes":[{"path":"/dev/nvidia0"}]}}]}`,
+			},
+			expectedFindings: []string{
+				"b.json: device nvidia.com/gpu=gpu0 is also defined in a.yaml",
+			},
+		},
+		{
+			description: "invalid hooks are reported",
+			specs: map[string]string{
+				"nvidia.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    hooks:
+    - hookName: createContainer
+      path: /usr/bin/not-executable
+    - hookName: createContainer
+      path: /usr/bin/missing
+    - hookName: createContainer
+      path: /usr/bin/old/nvidia-cdi-hook
+`,
+			},
+			expectedFindings: []string{
+				"nvidia.yaml: createContainer hook /usr/bin/not-executable: path is not executable",
+				"nvidia.yaml: createContainer hook /usr/bin/missing: path does not exist",
+				"nvidia.yaml: createContainer hook /usr/bin/old/nvidia-cdi-hook: version 1.17.0 does not match installed version 1.18.0",
+			},
+		},
+		{
+			description: "edits that can be simplified are reported",
+			specs: map[string]string{
+				"nvidia.yaml": `cdiVersion: 0.5.0
+kind: nvidia.com/gpu
+devices:
+- name: gpu0
+  containerEdits:
+    deviceNodes:
+    - path: /dev/nvidia0
+    - path: /dev/nvidia0
+    env:
+    - FOO=bar
+containerEdits:
+  env:
+  - FOO=bar
+`,
+			},
+			expectedFindings: []string{
+				"nvidia.yaml: spec contains duplicate container edits",
+				"nvidia.yaml: device container edits contain entries that are already included in the common container edits",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			specDir := t.TempDir()
+			for filename, contents := range tc.specs {
+				require.NoError(t, os.WriteFile(filepath.Join(specDir, filename), []byte(contents), 0600))
+			}
+
+			c := command{
+				logger: logger,
+				getVersion: func(path string) (string, error) {
+					return versions[path], nil
+				},
+			}
+			findings := c.validate(&options{
+				specPaths:         []string{specDir},
+				root:              testRoot,
+				nvidiaCDIHookPath: filepath.Join(testRoot, "usr/bin/nvidia-cdi-hook"),
+			})
+
+			// Strip the spec dir to simplify the expected findings.
+			for i, finding := range findings {
+				findings[i] = strings.ReplaceAll(finding, specDir+"/", "")
+			}
+			require.Equal(t, tc.expectedFindings, findings)
+		})
+	}
+}
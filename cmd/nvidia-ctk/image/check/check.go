@@ -0,0 +1,365 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package check
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-cdi-hook/cudacompat"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/snapshot"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+
+	// defaultCUDACompatDir is the directory in the container that is checked
+	// for CUDA forward compatibility libraries.
+	defaultCUDACompatDir = "/usr/local/cuda/compat"
+)
+
+type command struct {
+	logger         logger.Interface
+	configFilePath *string
+}
+
+type options struct {
+	image         string
+	format        string
+	driverRoot    string
+	devRoot       string
+	snapshot      string
+	driverVersion string
+	cudaVersion   string
+
+	// the following are used for dependency injection.
+	nvmllib nvml.Interface
+	output  io.Writer
+}
+
+// NewCommand constructs an image check command with the specified logger
+func NewCommand(logger logger.Interface, configFilePath *string) *cli.Command {
+	c := command{
+		logger:         logger,
+		configFilePath: configFilePath,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:      "check",
+		Usage:     "Check whether a GPU container would start for a container image on this node",
+		ArgsUsage: "IMAGE_CONFIG_OR_OCI_LAYOUT",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			if cmd.Args().Len() != 1 {
+				return ctx, errors.New("exactly one image config file or OCI image layout must be specified")
+			}
+			opts.image = cmd.Args().First()
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(cmd, &opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "The format to use for the generated report [text | json]",
+				Value:       formatText,
+				Destination: &opts.format,
+			},
+			&cli.StringFlag{
+				Name:        "driver-root",
+				Usage:       "Specify the NVIDIA GPU driver root to use. If this is not specified, the value from the config file is used.",
+				Destination: &opts.driverRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DRIVER_ROOT"),
+			},
+			&cli.StringFlag{
+				Name:        "dev-root",
+				Usage:       "Specify the root where `/dev` is located. If this is not specified, the driver-root is assumed.",
+				Destination: &opts.devRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DEV_ROOT"),
+			},
+			&cli.StringFlag{
+				Name: "snapshot",
+				Usage: "Specify a system snapshot to check the image against instead of the GPUs and driver on this node. " +
+					"A snapshot is captured using the 'nvidia-ctk system snapshot' command.",
+				Destination: &opts.snapshot,
+			},
+			&cli.StringFlag{
+				Name: "driver-version",
+				Usage: "Specify the driver version to check the image against. " +
+					"This overrides the version of the local driver or snapshot and allows images to be checked against other driver versions.",
+				Destination: &opts.driverVersion,
+			},
+			&cli.StringFlag{
+				Name: "cuda-version",
+				Usage: "Specify the CUDA driver version to check the NVIDIA_REQUIRE_* requirements and the use of " +
+					"the CUDA forward compatibility libraries against. " +
+					"This overrides the version reported by the local driver or snapshot.",
+				Destination: &opts.cudaVersion,
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	opts.format = strings.ToLower(opts.format)
+	switch opts.format {
+	case formatText, formatJSON:
+	default:
+		return fmt.Errorf("invalid output format: %v", opts.format)
+	}
+	if opts.output == nil {
+		opts.output = os.Stdout
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Command, opts *options) error {
+	var configFilePath string
+	if m.configFilePath != nil {
+		configFilePath = *m.configFilePath
+	}
+	cfg, err := config.GetConfigFrom(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !c.IsSet("driver-root") {
+		opts.driverRoot = cfg.NVIDIAContainerCLIConfig.Root
+	}
+	if opts.driverRoot == "" {
+		opts.driverRoot = "/"
+	}
+	if opts.devRoot == "" {
+		opts.devRoot = opts.driverRoot
+	}
+
+	if opts.snapshot != "" {
+		s, err := snapshot.Load(opts.snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		opts.nvmllib = s.NVML.Interface()
+		if opts.driverVersion == "" {
+			opts.driverVersion = s.NVML.DriverVersion
+		}
+	}
+
+	r, err := m.check(cfg, opts)
+	if err != nil {
+		return err
+	}
+	if err := r.writeTo(opts.output, opts.format); err != nil {
+		return err
+	}
+	if !r.WouldStart {
+		return fmt.Errorf("the container would not start")
+	}
+	return nil
+}
+
+// check evaluates the image against the driver and devices on the host.
+func (m command) check(cfg *config.Config, opts *options) (*report, error) {
+	img, err := loadImage(opts.image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image %v: %w", opts.image, err)
+	}
+
+	cudaImage, err := image.New(
+		image.WithLogger(m.logger),
+		image.WithEnv(img.env),
+		image.WithAcceptEnvvarUnprivileged(cfg.AcceptEnvvarUnprivileged),
+		image.WithDisableRequire(cfg.DisableRequire),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct container image: %w", err)
+	}
+
+	r := &report{
+		Image:      opts.image,
+		WouldStart: true,
+		Devices:    cudaImage.VisibleDevices(),
+	}
+	if len(r.Devices) == 0 {
+		m.logger.Infof("No devices are requested by the image")
+		return r, nil
+	}
+
+	driver := root.New(
+		root.WithLogger(m.logger),
+		root.WithDriverRoot(opts.driverRoot),
+		root.WithDevRoot(opts.devRoot),
+	)
+	r.DriverVersion = opts.driverVersion
+	if r.DriverVersion == "" {
+		r.DriverVersion, err = driver.Version()
+		if err != nil {
+			m.logger.Warningf("Failed to get driver version: %v", err)
+		}
+	}
+
+	host := requirements.NewHost(
+		requirements.WithLogger(m.logger),
+		requirements.WithDriver(driver),
+		requirements.WithNvmlLib(opts.nvmllib),
+	)
+
+	m.checkDevices(r, host)
+	m.checkDriverCapabilities(r, cfg, cudaImage)
+	if err := m.checkRequirements(r, host, cudaImage, opts); err != nil {
+		return nil, err
+	}
+	r.CUDACompat = m.checkCUDACompat(img, r.DriverVersion, opts.cudaVersion)
+
+	return r, nil
+}
+
+// checkDevices checks whether the requested devices exist on the host. Fully
+// qualified CDI device names are not checked.
+func (m command) checkDevices(r *report, host *requirements.Host) {
+	var ids []string
+	for _, id := range r.Devices {
+		if strings.Contains(id, "=") {
+			m.logger.Debugf("Skipping check for CDI device %v", id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	missing, err := host.MissingDevices(ids...)
+	if err != nil {
+		m.logger.Warningf("Failed to check requested devices: %v", err)
+		return
+	}
+	r.MissingDevices = missing
+	if len(missing) > 0 {
+		r.fail("requested devices not found: %v", strings.Join(missing, ","))
+	}
+}
+
+// checkDriverCapabilities determines the driver capabilities that are made
+// available to the container. This uses the same handling of the
+// NVIDIA_DRIVER_CAPABILITIES environment variable as the
+// nvidia-container-runtime-hook where a container that requests unsupported
+// capabilities fails to start.
+func (m command) checkDriverCapabilities(r *report, cfg *config.Config, i image.CUDA) {
+	supported := image.NewDriverCapabilities(cfg.SupportedDriverCapabilities)
+	capabilities, err := i.ResolveDriverCapabilities(supported, i.IsLegacy())
+	if err != nil {
+		r.fail("%v", err)
+		return
+	}
+	r.DriverCapabilities = capabilities.List()
+}
+
+// checkRequirements checks the NVIDIA_REQUIRE_* requirements of the image
+// against the properties of the host and the requested devices.
+func (m command) checkRequirements(r *report, host *requirements.Host, i image.CUDA, opts *options) error {
+	if i.HasDisableRequire() {
+		r.RequirementsDisabled = true
+		return nil
+	}
+
+	imageRequirements, err := i.GetRequirements()
+	if err != nil {
+		return fmt.Errorf("failed to get image requirements: %w", err)
+	}
+	if len(imageRequirements) == 0 {
+		return nil
+	}
+
+	reqs := requirements.New(m.logger, imageRequirements)
+	host.AddProperties(reqs, r.Devices...)
+	if opts.driverVersion != "" {
+		reqs.AddVersionProperty(requirements.DRIVER, opts.driverVersion)
+	}
+	if opts.cudaVersion != "" {
+		reqs.AddVersionProperty(requirements.CUDA, opts.cudaVersion)
+	}
+
+	report, err := reqs.Report()
+	if err != nil {
+		return fmt.Errorf("failed to check image requirements: %w", err)
+	}
+	r.Requirements = report
+	if !report.Satisfied() {
+		r.fail("NVIDIA_REQUIRE_* requirements not met")
+	}
+	return nil
+}
+
+// checkCUDACompat determines whether the CUDA forward compatibility libraries
+// in the image would be used with the specified driver and CUDA versions. This
+// matches the logic of the enable-cuda-compat hook. The CUDA version is
+// optional.
+func (m command) checkCUDACompat(img *ociImage, driverVersion string, cudaVersion string) compatReport {
+	libraries, err := img.findCompatLibraries(defaultCUDACompatDir)
+	if err != nil {
+		return compatReport{Detail: fmt.Sprintf("could not be determined: %v", err)}
+	}
+
+	c := compatReport{}
+	for _, l := range libraries {
+		c.Libraries = append(c.Libraries, l.path)
+	}
+	switch {
+	case len(libraries) == 0:
+		c.Detail = "no CUDA forward compatibility libraries in image"
+		return c
+	case len(libraries) > 1:
+		c.Detail = "unexpected number of CUDA forward compatibility libraries in image"
+		return c
+	case driverVersion == "":
+		c.Detail = "the driver version could not be determined"
+		return c
+	}
+
+	library := libraries[0]
+	compatDriverVersion := strings.TrimPrefix(path.Base(library.path), "libcuda.so.")
+	c.Used = cudacompat.UseCompatLibraries(m.logger, bytes.NewReader(library.contents), compatDriverVersion, driverVersion, cudaVersion)
+	if !c.Used {
+		c.Detail = fmt.Sprintf("driver version %v is not older than %v", driverVersion, compatDriverVersion)
+		if cudaVersion != "" {
+			c.Detail = fmt.Sprintf("driver version %v with CUDA version %v does not require the compat libraries for %v", driverVersion, cudaVersion, compatDriverVersion)
+		}
+		return c
+	}
+	c.Directory = path.Dir(library.path)
+	return c
+}
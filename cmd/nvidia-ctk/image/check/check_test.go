@@ -0,0 +1,270 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package check
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock/dgxa100"
+	mockserver "github.com/NVIDIA/go-nvml/pkg/nvml/mock/server"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+)
+
+// A layerEntry is a file in an image layer. Entries with a link target are
+// symlinks.
+type layerEntry struct {
+	name string
+	link string
+}
+
+func TestCheck(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	cudaLayer := []layerEntry{
+		{name: "usr/local/cuda", link: "cuda-12.6"},
+		{name: "usr/local/cuda-12.6/compat/libcuda.so.1", link: "libcuda.so.560.35.03"},
+		{name: "usr/local/cuda-12.6/compat/libcuda.so.560.35.03"},
+	}
+
+	testCases := []struct {
+		description      string
+		env              []string
+		layers           [][]layerEntry
+		configOnly       bool
+		driverVersion    string
+		cudaVersion      string
+		expectedStart    bool
+		expectedReasons  []string
+		expectedCompat   compatReport
+		expectedRequired bool
+	}{
+		{
+			description:   "image without devices",
+			env:           []string{"PATH=/usr/bin"},
+			configOnly:    true,
+			expectedStart: true,
+		},
+		{
+			description:   "compat libraries are not checked for image config",
+			env:           []string{"NVIDIA_VISIBLE_DEVICES=all"},
+			configOnly:    true,
+			expectedStart: true,
+			expectedCompat: compatReport{
+				Detail: "could not be determined: the image layers are not available",
+			},
+		},
+		{
+			description:   "compat libraries are used for older driver",
+			env:           []string{"NVIDIA_VISIBLE_DEVICES=0"},
+			layers:        [][]layerEntry{cudaLayer},
+			expectedStart: true,
+			expectedCompat: compatReport{
+				Libraries: []string{"/usr/local/cuda-12.6/compat/libcuda.so.560.35.03"},
+				Used:      true,
+				Directory: "/usr/local/cuda-12.6/compat",
+			},
+		},
+		{
+			description:   "compat libraries are not used for newer driver",
+			env:           []string{"NVIDIA_VISIBLE_DEVICES=0"},
+			layers:        [][]layerEntry{cudaLayer},
+			driverVersion: "570.86.15",
+			expectedStart: true,
+			expectedCompat: compatReport{
+				Libraries: []string{"/usr/local/cuda-12.6/compat/libcuda.so.560.35.03"},
+				Detail:    "driver version 570.86.15 is not older than 560.35.03",
+			},
+		},
+		{
+			description:   "compat libraries are checked against the cuda version",
+			env:           []string{"NVIDIA_VISIBLE_DEVICES=0"},
+			layers:        [][]layerEntry{cudaLayer},
+			cudaVersion:   "12.4",
+			expectedStart: true,
+			expectedCompat: compatReport{
+				Libraries: []string{"/usr/local/cuda-12.6/compat/libcuda.so.560.35.03"},
+				Detail:    "driver version 550.54.15 with CUDA version 12.4 does not require the compat libraries for 560.35.03",
+			},
+		},
+		{
+			description: "whiteout removes compat libraries",
+			env:         []string{"NVIDIA_VISIBLE_DEVICES=0"},
+			layers: [][]layerEntry{
+				cudaLayer,
+				{{name: "usr/local/cuda-12.6/.wh.compat"}},
+			},
+			expectedStart: true,
+			expectedCompat: compatReport{
+				Detail: "no CUDA forward compatibility libraries in image",
+			},
+		},
+		{
+			description:     "requirements are not met",
+			env:             []string{"NVIDIA_VISIBLE_DEVICES=all", "NVIDIA_REQUIRE_CUDA=cuda>=12.6"},
+			layers:          [][]layerEntry{cudaLayer},
+			expectedReasons: []string{"NVIDIA_REQUIRE_* requirements not met"},
+			expectedCompat: compatReport{
+				Libraries: []string{"/usr/local/cuda-12.6/compat/libcuda.so.560.35.03"},
+				Used:      true,
+				Directory: "/usr/local/cuda-12.6/compat",
+			},
+			expectedRequired: true,
+		},
+		{
+			description:      "requirements are met",
+			env:              []string{"NVIDIA_VISIBLE_DEVICES=all", "NVIDIA_REQUIRE_CUDA=cuda>=12.4 brand=tesla"},
+			configOnly:       true,
+			expectedStart:    true,
+			expectedRequired: true,
+			expectedCompat: compatReport{
+				Detail: "could not be determined: the image layers are not available",
+			},
+		},
+		{
+			description:     "missing devices and unsupported capabilities",
+			env:             []string{"NVIDIA_VISIBLE_DEVICES=0,9", "NVIDIA_DRIVER_CAPABILITIES=compute,unknown"},
+			configOnly:      true,
+			expectedReasons: []string{"requested devices not found: 9", `unsupported driver capabilities requested in "compute,unknown" (supported "compat32,compute,display,graphics,ngx,utility,video")`},
+			expectedCompat: compatReport{
+				Detail: "could not be determined: the image layers are not available",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			server := dgxa100.New()
+			for _, d := range server.Devices {
+				(d.(*mockserver.Device)).IsMigDeviceHandleFunc = func() (bool, nvml.Return) {
+					return false, nvml.SUCCESS
+				}
+				(d.(*mockserver.Device)).GetGpuFabricInfoFunc = func() (nvml.GpuFabricInfo, nvml.Return) {
+					return nvml.GpuFabricInfo{}, nvml.ERROR_NOT_SUPPORTED
+				}
+			}
+
+			imagePath := writeImage(t, tc.env, tc.layers, tc.configOnly)
+			driverVersion := tc.driverVersion
+			if driverVersion == "" {
+				driverVersion = "550.54.15"
+			}
+			opts := &options{
+				image:         imagePath,
+				format:        formatText,
+				driverRoot:    t.TempDir(),
+				driverVersion: driverVersion,
+				cudaVersion:   tc.cudaVersion,
+				nvmllib:       server,
+			}
+			cfg, err := config.GetDefault()
+			require.NoError(t, err)
+
+			c := command{logger: logger}
+			r, err := c.check(cfg, opts)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expectedStart, r.WouldStart)
+			require.Equal(t, tc.expectedReasons, r.Reasons)
+			require.Equal(t, tc.expectedCompat, r.CUDACompat)
+			require.Equal(t, tc.expectedRequired, r.Requirements != nil)
+
+			var output bytes.Buffer
+			require.NoError(t, r.writeTo(&output, formatText))
+			if tc.expectedStart {
+				require.Contains(t, output.String(), "The container would start.")
+			} else {
+				require.Contains(t, output.String(), "The container would not start:")
+			}
+		})
+	}
+}
+
+// writeImage writes an image with the specified environment and layers. If
+// configOnly is set, only the image config is written. Otherwise an OCI image
+// layout is created with gzip-compressed layers.
+func writeImage(t *testing.T, env []string, layers [][]layerEntry, configOnly bool) string {
+	dir := t.TempDir()
+
+	var config ociImageConfig
+	config.Config.Env = env
+	configContents, err := json.Marshal(config)
+	require.NoError(t, err)
+	if configOnly {
+		configPath := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(configPath, configContents, 0600))
+		return configPath
+	}
+
+	manifest := ociManifest{
+		Config: writeBlob(t, dir, configContents),
+	}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, writeBlob(t, dir, newLayer(t, layer)))
+	}
+	manifestContents, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	index := ociManifest{
+		Manifests: []ociDescriptor{writeBlob(t, dir, manifestContents)},
+	}
+	indexContents, err := json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.json"), indexContents, 0600))
+
+	return dir
+}
+
+func writeBlob(t *testing.T, dir string, contents []byte) ociDescriptor {
+	digest := fmt.Sprintf("%x", sha256.Sum256(contents))
+	blobPath := filepath.Join(dir, "blobs", "sha256", digest)
+	require.NoError(t, os.MkdirAll(filepath.Dir(blobPath), 0755))
+	require.NoError(t, os.WriteFile(blobPath, contents, 0600))
+	return ociDescriptor{Digest: "sha256:" + digest}
+}
+
+func newLayer(t *testing.T, entries []layerEntry) []byte {
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		header := &tar.Header{
+			Name:     e.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+		}
+		if e.link != "" {
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = e.link
+		}
+		require.NoError(t, tw.WriteHeader(header))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return b.Bytes()
+}
@@ -0,0 +1,392 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package check
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSymlinkDepth is the maximum number of symlinks that are followed when
+	// resolving a path in the image filesystem.
+	maxSymlinkDepth = 40
+	// whiteoutPrefix marks a file in a layer that removes the file with the
+	// same name from the lower layers.
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaque marks a directory in a layer whose contents in the lower
+	// layers are removed.
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// An ociDescriptor references a blob in an OCI image layout.
+type ociDescriptor struct {
+	MediaType string       `json:"mediaType"`
+	Digest    string       `json:"digest"`
+	Platform  *ociPlatform `json:"platform,omitempty"`
+}
+
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// An ociManifest holds the fields of an image index or an image manifest
+// that are required to locate the image config and layers.
+type ociManifest struct {
+	Manifests []ociDescriptor `json:"manifests,omitempty"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers,omitempty"`
+}
+
+// An ociImageConfig holds the fields of an image config that are relevant for
+// a GPU container.
+type ociImageConfig struct {
+	Config struct {
+		Env []string `json:"Env"`
+	} `json:"config"`
+}
+
+// An ociImage is an image loaded from an image config or an OCI image layout.
+// The layers are only available for an image layout.
+type ociImage struct {
+	env    []string
+	layout string
+	layers []ociDescriptor
+}
+
+// loadImage loads the image from the specified path. This is either an image
+// config file or a local OCI image layout directory. For an image layout with
+// multiple images, the image for the current platform is selected.
+func loadImage(imagePath string) (*ociImage, error) {
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		config, err := readConfig(imagePath)
+		if err != nil {
+			return nil, err
+		}
+		return &ociImage{env: config.Config.Env}, nil
+	}
+
+	i := &ociImage{layout: imagePath}
+	var index ociManifest
+	if err := readJSON(filepath.Join(imagePath, "index.json"), &index); err != nil {
+		return nil, fmt.Errorf("failed to read image index: %w", err)
+	}
+	manifest, err := i.resolveManifest(&index, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath, err := i.blobPath(manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+	config, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	i.env = config.Config.Env
+	i.layers = manifest.Layers
+	return i, nil
+}
+
+// resolveManifest returns the image manifest for the current platform from
+// the specified index. Nested indexes are followed.
+func (i *ociImage) resolveManifest(index *ociManifest, depth int) (*ociManifest, error) {
+	if len(index.Manifests) == 0 {
+		return index, nil
+	}
+	if depth > 1 {
+		return nil, fmt.Errorf("too many levels of nested image indexes")
+	}
+
+	descriptor, err := selectManifest(index.Manifests)
+	if err != nil {
+		return nil, err
+	}
+	blobPath, err := i.blobPath(*descriptor)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ociManifest
+	if err := readJSON(blobPath, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to read image manifest: %w", err)
+	}
+	return i.resolveManifest(&manifest, depth+1)
+}
+
+// selectManifest selects the manifest for the current platform. If a single
+// manifest is specified, this is selected regardless of its platform.
+func selectManifest(manifests []ociDescriptor) (*ociDescriptor, error) {
+	if len(manifests) == 1 {
+		return &manifests[0], nil
+	}
+	for _, m := range manifests {
+		if m.Platform == nil {
+			continue
+		}
+		if m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+			return &m, nil
+		}
+	}
+	return nil, fmt.Errorf("no image found for platform linux/%v", runtime.GOARCH)
+}
+
+// blobPath returns the path of the blob with the specified descriptor in the
+// image layout.
+func (i *ociImage) blobPath(d ociDescriptor) (string, error) {
+	algorithm, encoded, ok := strings.Cut(d.Digest, ":")
+	if !ok || algorithm == "" || encoded == "" || strings.ContainsAny(d.Digest, `/\`) {
+		return "", fmt.Errorf("invalid digest %q", d.Digest)
+	}
+	return filepath.Join(i.layout, "blobs", algorithm, encoded), nil
+}
+
+func readConfig(configPath string) (*ociImageConfig, error) {
+	var config ociImageConfig
+	if err := readJSON(configPath, &config); err != nil {
+		return nil, fmt.Errorf("failed to read image config: %w", err)
+	}
+	return &config, nil
+}
+
+func readJSON(path string, v any) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(contents, v)
+}
+
+// A compatLibrary is a CUDA forward compatibility library in the image.
+type compatLibrary struct {
+	// path is the path of the library in the image.
+	path string
+	// contents are the contents of the library.
+	contents []byte
+}
+
+// imageFiles holds the files in the image filesystem that are required to
+// locate the CUDA forward compatibility libraries.
+type imageFiles struct {
+	symlinks  map[string]string
+	libraries map[string][]byte
+}
+
+// findCompatLibraries returns the CUDA forward compatibility libraries in the
+// specified directory of the image. The layers of the image are applied in
+// order so that files from upper layers replace or remove files from lower
+// layers.
+func (i *ociImage) findCompatLibraries(compatDir string) ([]compatLibrary, error) {
+	if i.layout == "" {
+		return nil, fmt.Errorf("the image layers are not available")
+	}
+
+	files := &imageFiles{
+		symlinks:  make(map[string]string),
+		libraries: make(map[string][]byte),
+	}
+	for _, layer := range i.layers {
+		if err := i.applyLayer(files, layer); err != nil {
+			return nil, fmt.Errorf("failed to read layer %v: %w", layer.Digest, err)
+		}
+	}
+
+	dir, err := files.resolve(strings.TrimPrefix(path.Clean("/"+compatDir), "/"), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var libraries []compatLibrary
+	for p, contents := range files.libraries {
+		if path.Dir(p) != dir {
+			continue
+		}
+		libraries = append(libraries, compatLibrary{path: "/" + p, contents: contents})
+	}
+	sort.Slice(libraries, func(i, j int) bool {
+		return libraries[i].path < libraries[j].path
+	})
+	return libraries, nil
+}
+
+// applyLayer applies the changes from the specified layer to the files.
+func (i *ociImage) applyLayer(files *imageFiles, layer ociDescriptor) error {
+	blobPath, err := i.blobPath(layer)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(blobPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := decompress(f)
+	if err != nil {
+		return err
+	}
+
+	added := &imageFiles{
+		symlinks:  make(map[string]string),
+		libraries: make(map[string][]byte),
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+		dir = path.Clean(dir)
+		switch {
+		case base == whiteoutOpaque:
+			files.removeTree(dir, false)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			files.removeTree(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), true)
+			continue
+		}
+
+		// A file in an upper layer replaces the file in the lower layers. The
+		// contents of a directory are merged with the lower layers.
+		if header.Typeflag == tar.TypeDir {
+			delete(files.symlinks, name)
+		} else {
+			files.removeTree(name, true)
+		}
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			added.symlinks[name] = header.Linkname
+		case tar.TypeReg:
+			if !isCompatLibrary(name) {
+				continue
+			}
+			contents, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			added.libraries[name] = contents
+		}
+	}
+
+	for p, target := range added.symlinks {
+		files.symlinks[p] = target
+	}
+	for p, contents := range added.libraries {
+		files.libraries[p] = contents
+	}
+	return nil
+}
+
+// decompress returns a reader for the uncompressed contents of a layer. Only
+// uncompressed and gzip-compressed layers are supported.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, fmt.Errorf("zstd-compressed layers are not supported")
+	}
+	return br, nil
+}
+
+// isCompatLibrary checks whether the specified path refers to a libcuda.so in
+// a compat directory.
+func isCompatLibrary(p string) bool {
+	if path.Base(path.Dir(p)) != "compat" {
+		return false
+	}
+	matched, _ := path.Match("libcuda.so.*.*", path.Base(p))
+	return matched
+}
+
+// removeTree removes the files under the specified path. If self is true, the
+// path itself is also removed.
+func (f *imageFiles) removeTree(p string, self bool) {
+	prefix := p + "/"
+	if p == "." {
+		prefix = ""
+	}
+	remove := func(name string) bool {
+		return (self && name == p) || strings.HasPrefix(name, prefix)
+	}
+	for name := range f.symlinks {
+		if remove(name) {
+			delete(f.symlinks, name)
+		}
+	}
+	for name := range f.libraries {
+		if remove(name) {
+			delete(f.libraries, name)
+		}
+	}
+}
+
+// resolve resolves the symlinks in the specified path relative to the root of
+// the image filesystem.
+func (f *imageFiles) resolve(p string, depth int) (string, error) {
+	if p == "." || p == "" {
+		return ".", nil
+	}
+	if depth > maxSymlinkDepth {
+		return "", fmt.Errorf("too many levels of symlinks for %v", p)
+	}
+
+	parent, err := f.resolve(path.Dir(p), depth)
+	if err != nil {
+		return "", err
+	}
+	resolved := path.Join(parent, path.Base(p))
+
+	target, ok := f.symlinks[resolved]
+	if !ok {
+		return resolved, nil
+	}
+	// Targets are resolved relative to the root of the image filesystem so
+	// that a symlink cannot refer to a path outside of the image.
+	if !path.IsAbs(target) {
+		target = path.Join(parent, target)
+	}
+	return f.resolve(strings.TrimPrefix(path.Clean("/"+target), "/"), depth+1)
+}
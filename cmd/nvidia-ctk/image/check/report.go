@@ -0,0 +1,144 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements"
+)
+
+// A report describes whether a GPU container would start for an image.
+type report struct {
+	Image      string   `json:"image"`
+	WouldStart bool     `json:"wouldStart"`
+	Reasons    []string `json:"reasons,omitempty"`
+
+	DriverVersion        string               `json:"driverVersion,omitempty"`
+	Devices              []string             `json:"devices"`
+	MissingDevices       []string             `json:"missingDevices,omitempty"`
+	DriverCapabilities   []string             `json:"driverCapabilities,omitempty"`
+	RequirementsDisabled bool                 `json:"requirementsDisabled,omitempty"`
+	Requirements         *requirements.Report `json:"requirements,omitempty"`
+	CUDACompat           compatReport         `json:"cudaCompat"`
+}
+
+// A compatReport describes the CUDA forward compatibility libraries in an
+// image and whether these would be used.
+type compatReport struct {
+	// Libraries are the CUDA forward compatibility libraries in the image.
+	Libraries []string `json:"libraries,omitempty"`
+	// Used indicates whether the libraries would be used instead of the
+	// libraries of the driver.
+	Used bool `json:"used"`
+	// Directory is the directory in the container that is added to the
+	// library search path if the libraries are used.
+	Directory string `json:"directory,omitempty"`
+	// Detail describes why the libraries would not be used.
+	Detail string `json:"detail,omitempty"`
+}
+
+// fail records a reason that the container would not start.
+func (r *report) fail(format string, args ...any) {
+	r.WouldStart = false
+	r.Reasons = append(r.Reasons, fmt.Sprintf(format, args...))
+}
+
+// writeTo writes the report to the specified writer in the requested format.
+func (r *report) writeTo(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		output, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", output)
+		return err
+	case formatText:
+		return r.writeText(w)
+	}
+	return fmt.Errorf("unsupported format: %v", format)
+}
+
+// writeText writes a human-readable representation of the report.
+func (r *report) writeText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Image: %v\n", r.Image)
+	if len(r.Devices) > 0 {
+		r.writeChecks(&b)
+	} else {
+		fmt.Fprintf(&b, "Devices: none\n")
+	}
+
+	if r.WouldStart {
+		fmt.Fprintf(&b, "The container would start.\n")
+	} else {
+		fmt.Fprintf(&b, "The container would not start:\n")
+		for _, reason := range r.Reasons {
+			fmt.Fprintf(&b, "  - %v\n", reason)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeChecks writes the results of the checks for the requested devices.
+func (r *report) writeChecks(b *strings.Builder) {
+	fmt.Fprintf(b, "Driver version: %v\n", valueOrUnknown(r.DriverVersion))
+	fmt.Fprintf(b, "Devices: %v\n", strings.Join(r.Devices, ","))
+	if len(r.MissingDevices) > 0 {
+		fmt.Fprintf(b, "Missing devices: %v\n", strings.Join(r.MissingDevices, ","))
+	}
+	fmt.Fprintf(b, "Driver capabilities: %v\n", valueOrNone(strings.Join(r.DriverCapabilities, ",")))
+
+	switch {
+	case r.RequirementsDisabled:
+		fmt.Fprintf(b, "Requirements: disabled\n")
+	case r.Requirements == nil:
+		fmt.Fprintf(b, "Requirements: none\n")
+	default:
+		fmt.Fprintf(b, "Requirements:\n")
+		for line := range strings.Lines(r.Requirements.String()) {
+			fmt.Fprintf(b, "  %v", line)
+		}
+	}
+
+	if r.CUDACompat.Used {
+		fmt.Fprintf(b, "CUDA forward compatibility: %v would be used\n", strings.Join(r.CUDACompat.Libraries, ","))
+	} else {
+		fmt.Fprintf(b, "CUDA forward compatibility: not used; %v\n", r.CUDACompat.Detail)
+	}
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
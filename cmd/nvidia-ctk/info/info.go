@@ -17,30 +17,130 @@
 package info
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/urfave/cli/v3"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
 
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
 type command struct {
-	logger logger.Interface
+	logger         logger.Interface
+	configFilePath *string
+}
+
+type options struct {
+	format     string
+	driverRoot string
+	devRoot    string
+
+	nvidiaCDIHookPath string
+	skipDiscovery     bool
 }
 
 // NewCommand constructs an info command with the specified logger
-func NewCommand(logger logger.Interface) *cli.Command {
+func NewCommand(logger logger.Interface, configFilePath *string) *cli.Command {
 	c := command{
-		logger: logger,
+		logger:         logger,
+		configFilePath: configFilePath,
 	}
 	return c.build()
 }
 
 // build
 func (m command) build() *cli.Command {
+	opts := options{}
+
 	// Create the 'info' command
 	info := cli.Command{
 		Name:  "info",
 		Usage: "Provide information about the system",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(cmd, &opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"output-format"},
+				Usage:       "The format to use for the generated report [text | json | yaml]",
+				Value:       formatText,
+				Destination: &opts.format,
+				Sources:     cli.EnvVars("NVIDIA_CTK_INFO_FORMAT"),
+			},
+			&cli.StringFlag{
+				Name:        "driver-root",
+				Usage:       "Specify the NVIDIA GPU driver root to use. If this is not specified, the value from the config file is used.",
+				Destination: &opts.driverRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DRIVER_ROOT"),
+			},
+			&cli.StringFlag{
+				Name:        "dev-root",
+				Usage:       "Specify the root where `/dev` is located. If this is not specified, the driver-root is assumed.",
+				Destination: &opts.devRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DEV_ROOT"),
+			},
+			&cli.StringFlag{
+				Name:        "nvidia-cdi-hook-path",
+				Usage:       "Specify the path to use for the nvidia-cdi-hook when discovering the required container edits.",
+				Destination: &opts.nvidiaCDIHookPath,
+				Sources:     cli.EnvVars("NVIDIA_CTK_CDI_HOOK_PATH"),
+			},
+			&cli.BoolFlag{
+				Name:        "skip-discovery",
+				Usage:       "Skip the discovery of driver mounts and device nodes.",
+				Destination: &opts.skipDiscovery,
+			},
+		},
 	}
 
 	return &info
 }
+
+func (m command) validateFlags(opts *options) error {
+	opts.format = strings.ToLower(opts.format)
+	switch opts.format {
+	case formatText, formatJSON, formatYAML:
+	default:
+		return fmt.Errorf("invalid output format: %v", opts.format)
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Command, opts *options) error {
+	var configFilePath string
+	if m.configFilePath != nil {
+		configFilePath = *m.configFilePath
+	}
+	cfg, err := config.GetConfigFrom(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !c.IsSet("driver-root") {
+		opts.driverRoot = cfg.NVIDIAContainerCLIConfig.Root
+	}
+	if opts.driverRoot == "" {
+		opts.driverRoot = "/"
+	}
+	opts.nvidiaCDIHookPath = config.ResolveNVIDIACDIHookPath(m.logger, opts.nvidiaCDIHookPath)
+
+	r, err := m.generateReport(cfg, opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	return r.writeTo(os.Stdout, opts.format)
+}
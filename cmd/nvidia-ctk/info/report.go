@@ -0,0 +1,298 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package info
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	nvinfo "github.com/NVIDIA/go-nvlib/pkg/nvlib/info"
+	"github.com/pelletier/go-toml"
+	"sigs.k8s.io/yaml"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/lookup"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+// A report captures the information about the system that is relevant to
+// the NVIDIA Container Toolkit.
+type report struct {
+	Version          string            `json:"version"`
+	Platform         string            `json:"platform"`
+	Driver           driverReport      `json:"driver"`
+	RuntimeMode      runtimeModeReport `json:"runtimeMode"`
+	LowLevelRuntimes []runtimeReport   `json:"lowLevelRuntimes"`
+	Discovery        *discoveryReport  `json:"discovery,omitempty"`
+	Config           map[string]any    `json:"config"`
+}
+
+type driverReport struct {
+	Root    string `json:"root"`
+	DevRoot string `json:"devRoot"`
+	Version string `json:"version,omitempty"`
+}
+
+type runtimeModeReport struct {
+	Configured string `json:"configured"`
+	Resolved   string `json:"resolved"`
+}
+
+type runtimeReport struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+}
+
+type discoveryReport struct {
+	Mounts      []string `json:"mounts"`
+	DeviceNodes []string `json:"deviceNodes"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// generateReport collects the information for the report using the specified
+// config and options.
+func (m command) generateReport(cfg *config.Config, opts *options) (*report, error) {
+	driver := root.New(
+		root.WithLogger(m.logger),
+		root.WithDriverRoot(opts.driverRoot),
+		root.WithDevRoot(opts.devRoot),
+	)
+
+	driverVersion, err := driver.Version()
+	if err != nil {
+		m.logger.Warningf("Failed to determine driver version: %v", err)
+	}
+
+	configAsMap, err := configToMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &report{
+		Version: strings.Join(info.GetVersionParts(), "; "),
+		Platform: string(nvinfo.New(
+			nvinfo.WithLogger(m.logger),
+			nvinfo.WithRoot(driver.Root),
+		).ResolvePlatform()),
+		Driver: driverReport{
+			Root:    driver.Root,
+			DevRoot: driver.DevRoot,
+			Version: driverVersion,
+		},
+		RuntimeMode: runtimeModeReport{
+			Configured: cfg.NVIDIAContainerRuntimeConfig.Mode,
+			Resolved:   string(m.resolveRuntimeMode(cfg)),
+		},
+		LowLevelRuntimes: m.locateLowLevelRuntimes(cfg.NVIDIAContainerRuntimeConfig.Runtimes),
+		Config:           configAsMap,
+	}
+
+	if !opts.skipDiscovery {
+		r.Discovery = m.discover(driver, opts)
+	}
+
+	return r, nil
+}
+
+// resolveRuntimeMode returns the mode that the NVIDIA Container Runtime would
+// select for a container requesting all devices.
+func (m command) resolveRuntimeMode(cfg *config.Config) info.RuntimeMode {
+	cudaImage, err := image.New(
+		image.WithLogger(m.logger),
+		image.WithEnvMap(map[string]string{
+			image.EnvVarNvidiaVisibleDevices: "all",
+		}),
+	)
+	if err != nil {
+		m.logger.Warningf("Failed to construct image for mode resolution: %v", err)
+	}
+
+	return info.NewRuntimeModeResolver(
+		info.WithLogger(m.logger),
+		info.WithImage(&cudaImage),
+	).ResolveRuntimeMode(cfg.NVIDIAContainerRuntimeConfig.Mode)
+}
+
+// locateLowLevelRuntimes resolves the path for each of the configured
+// low-level runtime candidates. The path is left empty for candidates that
+// could not be located.
+func (m command) locateLowLevelRuntimes(candidates []string) []runtimeReport {
+	locator := lookup.NewExecutableLocator(m.logger, "/")
+
+	var runtimes []runtimeReport
+	for _, candidate := range candidates {
+		r := runtimeReport{
+			Name: candidate,
+		}
+		if targets, err := locator.Locate(candidate); err == nil && len(targets) > 0 {
+			r.Path = targets[0]
+		}
+		runtimes = append(runtimes, r)
+	}
+	return runtimes
+}
+
+// discover returns the host paths of the mounts (e.g. libraries, binaries, and
+// firmware) and device nodes that are discovered for the driver. Errors in discovery are included in the report instead of being
+// returned since a partial report is still useful.
+func (m command) discover(driver *root.Driver, opts *options) *discoveryReport {
+	d := &discoveryReport{}
+
+	cdilib, err := nvcdi.New(
+		nvcdi.WithLogger(m.logger),
+		nvcdi.WithDriverRoot(driver.Root),
+		nvcdi.WithDevRoot(driver.DevRoot),
+		nvcdi.WithNVIDIACDIHookPath(opts.nvidiaCDIHookPath),
+	)
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("failed to create CDI library: %v", err))
+		return d
+	}
+
+	var edits []specs.ContainerEdits
+	commonEdits, err := cdilib.GetCommonEdits()
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("failed to discover common edits: %v", err))
+	} else {
+		edits = append(edits, *commonEdits.ContainerEdits)
+	}
+
+	deviceSpecs, err := cdilib.GetDeviceSpecsByID("all")
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("failed to discover devices: %v", err))
+	}
+	for _, deviceSpec := range deviceSpecs {
+		edits = append(edits, deviceSpec.ContainerEdits)
+	}
+
+	for _, e := range edits {
+		for _, mount := range e.Mounts {
+			d.Mounts = append(d.Mounts, mount.HostPath)
+		}
+		for _, deviceNode := range e.DeviceNodes {
+			path := deviceNode.HostPath
+			if path == "" {
+				path = deviceNode.Path
+			}
+			d.DeviceNodes = append(d.DeviceNodes, path)
+		}
+	}
+
+	slices.Sort(d.Mounts)
+	d.Mounts = slices.Compact(d.Mounts)
+	slices.Sort(d.DeviceNodes)
+	d.DeviceNodes = slices.Compact(d.DeviceNodes)
+
+	return d
+}
+
+// configToMap converts the config to a map keyed by the TOML field names.
+// This ensures that the keys in the JSON and YAML output match the config
+// file.
+func configToMap(cfg *config.Config) (map[string]any, error) {
+	contents, err := toml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	tree, err := toml.LoadBytes(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return tree.ToMap(), nil
+}
+
+// writeTo writes the report to the specified writer in the requested format.
+func (r *report) writeTo(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		output, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", output)
+		return err
+	case formatYAML:
+		output, err := yaml.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, err = w.Write(output)
+		return err
+	case formatText:
+		return r.writeText(w)
+	}
+	return fmt.Errorf("unsupported format: %v", format)
+}
+
+// writeText writes a human-readable representation of the report.
+func (r *report) writeText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Version: %v\n", r.Version)
+	fmt.Fprintf(&b, "Platform: %v\n", r.Platform)
+	fmt.Fprintf(&b, "Driver:\n")
+	fmt.Fprintf(&b, "  Root: %v\n", r.Driver.Root)
+	fmt.Fprintf(&b, "  Dev root: %v\n", r.Driver.DevRoot)
+	fmt.Fprintf(&b, "  Version: %v\n", valueOrUnknown(r.Driver.Version))
+	fmt.Fprintf(&b, "Runtime mode: %v (resolved as %v)\n", r.RuntimeMode.Configured, r.RuntimeMode.Resolved)
+	fmt.Fprintf(&b, "Low-level runtimes:\n")
+	for _, runtime := range r.LowLevelRuntimes {
+		fmt.Fprintf(&b, "  %v: %v\n", runtime.Name, valueOrUnknown(runtime.Path))
+	}
+	if r.Discovery != nil {
+		fmt.Fprintf(&b, "Mounts:\n")
+		for _, mount := range r.Discovery.Mounts {
+			fmt.Fprintf(&b, "  %v\n", mount)
+		}
+		fmt.Fprintf(&b, "Device nodes:\n")
+		for _, deviceNode := range r.Discovery.DeviceNodes {
+			fmt.Fprintf(&b, "  %v\n", deviceNode)
+		}
+		if len(r.Discovery.Errors) > 0 {
+			fmt.Fprintf(&b, "Discovery errors:\n")
+			for _, err := range r.Discovery.Errors {
+				fmt.Fprintf(&b, "  %v\n", err)
+			}
+		}
+	}
+
+	configTree, err := toml.TreeFromMap(r.Config)
+	if err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+	fmt.Fprintf(&b, "Config:\n")
+	for line := range strings.Lines(configTree.String()) {
+		fmt.Fprintf(&b, "  %v", line)
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
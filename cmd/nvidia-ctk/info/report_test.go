@@ -0,0 +1,123 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package info
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportWriteTo(t *testing.T) {
+	r := &report{
+		Version:  "1.2.3",
+		Platform: "nvml",
+		Driver: driverReport{
+			Root:    "/",
+			DevRoot: "/",
+			Version: "999.88.77",
+		},
+		RuntimeMode: runtimeModeReport{
+			Configured: "auto",
+			Resolved:   "jit-cdi",
+		},
+		LowLevelRuntimes: []runtimeReport{
+			{Name: "runc", Path: "/usr/bin/runc"},
+			{Name: "crun"},
+		},
+		Discovery: &discoveryReport{
+			Mounts:      []string{"/usr/lib/libcuda.so.999.88.77"},
+			DeviceNodes: []string{"/dev/nvidia0"},
+		},
+		Config: map[string]any{
+			"disable-require": false,
+		},
+	}
+
+	testCases := []struct {
+		description    string
+		format         string
+		expectedError  bool
+		expectedOutput string
+	}{
+		{
+			description: "text",
+			format:      formatText,
+			expectedOutput: `Version: 1.2.3
+Platform: nvml
+Driver:
+  Root: /
+  Dev root: /
+  Version: 999.88.77
+Runtime mode: auto (resolved as jit-cdi)
+Low-level runtimes:
+  runc: /usr/bin/runc
+  crun: unknown
+Mounts:
+  /usr/lib/libcuda.so.999.88.77
+Device nodes:
+  /dev/nvidia0
+Config:
+  disable-require = false
+`,
+		},
+		{
+			description: "yaml",
+			format:      formatYAML,
+			expectedOutput: `config:
+  disable-require: false
+discovery:
+  deviceNodes:
+  - /dev/nvidia0
+  mounts:
+  - /usr/lib/libcuda.so.999.88.77
+driver:
+  devRoot: /
+  root: /
+  version: 999.88.77
+lowLevelRuntimes:
+- name: runc
+  path: /usr/bin/runc
+- name: crun
+platform: nvml
+runtimeMode:
+  configured: auto
+  resolved: jit-cdi
+version: 1.2.3
+`,
+		},
+		{
+			description:   "unsupported format",
+			format:        "xml",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := r.writeTo(output, tc.format)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output.String())
+		})
+	}
+}
@@ -22,14 +22,16 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	toolkitconfig "github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/cdi"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/config"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/hook"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/image"
 	infoCLI "github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/info"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/runtime"
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/system"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
-	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	loggerlib "github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 
 	cli "github.com/urfave/cli/v3"
 )
@@ -43,6 +45,8 @@ type options struct {
 	Quiet bool
 	// Config specifies the path to the config file
 	Config string
+	// LogFormat specifies the format of the log records
+	LogFormat string
 }
 
 func main() {
@@ -70,7 +74,9 @@ func main() {
 			}
 			logger.SetLevel(logLevel)
 
-			return ctx, nil
+			return ctx, loggerlib.ConfigureFormat(logger, opts.LogFormat, func() string {
+				return getLogFormatFromConfig(opts.Config)
+			})
 		},
 		// Define the subcommands
 		Commands: getCommands(logger, &opts.Config),
@@ -94,6 +100,12 @@ func main() {
 				Destination: &opts.Config,
 				Sources:     cli.EnvVars("NVIDIA_CTK_CONFIG"),
 			},
+			&cli.StringFlag{
+				Name:        "log-format",
+				Usage:       "Specify the format of the log records; one of [text, json]. If this is not specified, the value from the config file is used.",
+				Destination: &opts.LogFormat,
+				Sources:     cli.EnvVars("NVIDIA_CTK_LOG_FORMAT"),
+			},
 		},
 	}
 
@@ -105,13 +117,24 @@ func main() {
 	}
 }
 
-func getCommands(logger logger.Interface, configFilePath *string) []*cli.Command {
+// getLogFormatFromConfig returns the log format for the nvidia-ctk CLI from
+// the config file. If the config cannot be loaded, the default is returned.
+func getLogFormatFromConfig(configFilePath string) string {
+	cfg, err := toolkitconfig.GetConfigFrom(configFilePath)
+	if err != nil {
+		return ""
+	}
+	return cfg.NVIDIACTKConfig.LogFormat
+}
+
+func getCommands(logger loggerlib.Interface, configFilePath *string) []*cli.Command {
 	return []*cli.Command{
 		hook.NewCommand(logger),
-		runtime.NewCommand(logger),
-		infoCLI.NewCommand(logger),
+		runtime.NewCommand(logger, configFilePath),
+		infoCLI.NewCommand(logger, configFilePath),
 		cdi.NewCommand(logger, configFilePath),
-		system.NewCommand(logger),
+		system.NewCommand(logger, configFilePath),
 		config.NewCommand(logger),
+		image.NewCommand(logger, configFilePath),
 	}
 }
@@ -0,0 +1,393 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/engine"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/engine/containerd"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/engine/crio"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/engine/docker"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/toml"
+)
+
+const (
+	defaultRuntime = "docker"
+
+	defaultContainerdConfigFilePath = "/etc/containerd/config.toml"
+	defaultCrioConfigFilePath       = "/etc/crio/crio.conf"
+	defaultDockerConfigFilePath     = "/etc/docker/daemon.json"
+
+	defaultContainerdDropInConfigFilePath = "/etc/containerd/conf.d/99-nvidia.toml"
+	defaultCrioDropInConfigFilePath       = "/etc/crio/crio.conf.d/99-nvidia.toml"
+
+	configSourceCommand = "command"
+	configSourceFile    = "file"
+
+	runtimeSpecificDefault = "RUNTIME_SPECIFIC_DEFAULT"
+)
+
+type command struct {
+	logger         logger.Interface
+	configFilePath *string
+}
+
+type options struct {
+	runtime          string
+	configFilePath   string
+	dropInConfigPath string
+	executablePath   string
+	configSource     string
+
+	expectedDefaultRuntime string
+
+	// nvidiaContainerRuntimeMode is the mode of the NVIDIA Container Runtime
+	// as read from the NVIDIA Container Toolkit config.
+	nvidiaContainerRuntimeMode string
+}
+
+// NewCommand constructs a doctor command with the specified logger
+func NewCommand(logger logger.Interface, configFilePath *string) *cli.Command {
+	c := command{
+		logger:         logger,
+		configFilePath: configFilePath,
+	}
+	return c.build()
+}
+
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	// Create the 'doctor' command
+	doctor := cli.Command{
+		Name:  "doctor",
+		Usage: "Check the config of the specified container engine against the NVIDIA Container Toolkit installation",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "runtime",
+				Usage:       "the target runtime engine; one of [containerd, crio, docker]",
+				Value:       defaultRuntime,
+				Destination: &opts.runtime,
+			},
+			&cli.StringFlag{
+				Name:        "config",
+				Usage:       "path to the config file for the target runtime",
+				Destination: &opts.configFilePath,
+			},
+			&cli.StringFlag{
+				Name:        "drop-in-config",
+				Usage:       "path to the NVIDIA-specific config file for the target runtime",
+				Value:       runtimeSpecificDefault,
+				Destination: &opts.dropInConfigPath,
+			},
+			&cli.StringFlag{
+				Name:        "executable-path",
+				Usage:       "The path to the runtime executable. This is used to extract the current config",
+				Destination: &opts.executablePath,
+			},
+			&cli.StringFlag{
+				Name:        "config-source",
+				Usage:       "the source to retrieve the container runtime configuration; one of [command, file]",
+				Destination: &opts.configSource,
+				Value:       configSourceFile,
+			},
+			&cli.StringFlag{
+				Name:        "expected-default-runtime",
+				Usage:       "the name of the runtime that is expected to be set as the default. If this is not specified, the default runtime is not checked",
+				Destination: &opts.expectedDefaultRuntime,
+			},
+		},
+	}
+
+	return &doctor
+}
+
+func (m command) validateFlags(opts *options) error {
+	switch opts.runtime {
+	case "containerd", "crio", "docker":
+	default:
+		return fmt.Errorf("unrecognized runtime '%v'", opts.runtime)
+	}
+
+	switch opts.configSource {
+	case configSourceCommand:
+		if opts.runtime == "docker" {
+			m.logger.Warningf("A %v Config Source is not supported for %v; using %v", opts.configSource, opts.runtime, configSourceFile)
+			opts.configSource = configSourceFile
+		}
+	case configSourceFile:
+	default:
+		return fmt.Errorf("unrecognized Config Source: %v", opts.configSource)
+	}
+
+	if opts.configFilePath == "" {
+		switch opts.runtime {
+		case "containerd":
+			opts.configFilePath = defaultContainerdConfigFilePath
+		case "crio":
+			opts.configFilePath = defaultCrioConfigFilePath
+		case "docker":
+			opts.configFilePath = defaultDockerConfigFilePath
+		}
+	}
+
+	if opts.dropInConfigPath == runtimeSpecificDefault {
+		switch opts.runtime {
+		case "containerd":
+			opts.dropInConfigPath = defaultContainerdDropInConfigFilePath
+		case "crio":
+			opts.dropInConfigPath = defaultCrioDropInConfigFilePath
+		case "docker":
+			opts.dropInConfigPath = ""
+		}
+	}
+
+	if opts.dropInConfigPath != "" && opts.runtime == "docker" {
+		return fmt.Errorf("runtime %v does not support drop-in configs", opts.runtime)
+	}
+
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	var toolkitConfigFilePath string
+	if m.configFilePath != nil {
+		toolkitConfigFilePath = *m.configFilePath
+	}
+	toolkitConfig, err := config.GetConfigFrom(toolkitConfigFilePath)
+	if err != nil {
+		m.logger.Warningf("Failed to load the NVIDIA Container Toolkit config; skipping mode-specific checks: %v", err)
+	} else {
+		opts.nvidiaContainerRuntimeMode = toolkitConfig.NVIDIAContainerRuntimeConfig.Mode
+	}
+
+	findings, err := m.diagnose(opts)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("found %d issue(s) in the %v config", len(findings), opts.runtime)
+	}
+
+	m.logger.Infof("No issues found in the %v config", opts.runtime)
+	return nil
+}
+
+// diagnose loads the container engine config and returns a list of findings.
+// An empty list indicates that no issues were found.
+func (m command) diagnose(opts *options) ([]string, error) {
+	cfg, err := m.loadEngineConfig(opts, opts.configFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load config for runtime %v: %w", opts.runtime, err)
+	}
+
+	var dropInConfig engine.Interface
+	if m.dropInConfigExists(opts) && opts.configSource == configSourceFile {
+		// When the config is read from a file, the drop-in config is not
+		// included and needs to be loaded separately.
+		dropInConfig, err = m.loadEngineConfig(opts, opts.dropInConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load drop-in config for runtime %v: %w", opts.runtime, err)
+		}
+	}
+
+	var findings []string
+	findings = append(findings, m.checkNVIDIARuntimes(cfg, dropInConfig)...)
+	findings = append(findings, m.checkCDIEnabled(opts, cfg, dropInConfig)...)
+	findings = append(findings, m.checkDefaultRuntime(opts, cfg, dropInConfig)...)
+	findings = append(findings, m.checkDropInImported(opts, cfg)...)
+
+	return findings, nil
+}
+
+// checkNVIDIARuntimes checks that the binary for each nvidia* runtime handler
+// exists and is executable.
+func (m command) checkNVIDIARuntimes(configs ...engine.Interface) []string {
+	runtimeConfigs := make(map[string]engine.RuntimeConfig)
+	for _, cfg := range configs {
+		if cfg == nil {
+			continue
+		}
+		for _, name := range cfg.GetRuntimeNames() {
+			if !strings.HasPrefix(name, "nvidia") {
+				continue
+			}
+			runtimeConfig, err := cfg.GetRuntimeConfig(name)
+			if err != nil {
+				m.logger.Warningf("Failed to get config for runtime %q: %v", name, err)
+				continue
+			}
+			// Later configs override earlier ones.
+			runtimeConfigs[name] = runtimeConfig
+		}
+	}
+
+	if len(runtimeConfigs) == 0 {
+		return []string{"no nvidia runtime handlers are configured"}
+	}
+
+	var findings []string
+	for _, name := range slices.Sorted(maps.Keys(runtimeConfigs)) {
+		binaryPath := runtimeConfigs[name].GetBinaryPath()
+		if binaryPath == "" {
+			findings = append(findings, fmt.Sprintf("runtime %q does not specify a binary path", name))
+			continue
+		}
+		if err := checkExecutable(binaryPath); err != nil {
+			findings = append(findings, fmt.Sprintf("runtime %q: %v", name, err))
+		}
+	}
+	return findings
+}
+
+// checkCDIEnabled checks that CDI is enabled in the container engine if the
+// NVIDIA Container Runtime is configured in cdi mode.
+func (m command) checkCDIEnabled(opts *options, cfg engine.Interface, dropInConfig engine.Interface) []string {
+	if opts.nvidiaContainerRuntimeMode != string(info.CDIRuntimeMode) {
+		return nil
+	}
+	if cfg.IsCDIEnabled() {
+		return nil
+	}
+	if dropInConfig != nil && dropInConfig.IsCDIEnabled() {
+		return nil
+	}
+	return []string{fmt.Sprintf("CDI is not enabled in the %v config but the NVIDIA Container Runtime is configured in %v mode", opts.runtime, info.CDIRuntimeMode)}
+}
+
+// checkDefaultRuntime checks that the default runtime matches the expected
+// default runtime. The check is skipped if no default runtime is expected.
+func (m command) checkDefaultRuntime(opts *options, cfg engine.Interface, dropInConfig engine.Interface) []string {
+	if opts.expectedDefaultRuntime == "" {
+		return nil
+	}
+
+	defaultRuntime := cfg.DefaultRuntime()
+	if dropInConfig != nil && dropInConfig.DefaultRuntime() != "" {
+		defaultRuntime = dropInConfig.DefaultRuntime()
+	}
+	if defaultRuntime == opts.expectedDefaultRuntime {
+		return nil
+	}
+	if defaultRuntime == "" {
+		return []string{fmt.Sprintf("no default runtime is set; expected %q", opts.expectedDefaultRuntime)}
+	}
+	return []string{fmt.Sprintf("default runtime is %q; expected %q", defaultRuntime, opts.expectedDefaultRuntime)}
+}
+
+// checkDropInImported checks that the containerd drop-in config is included
+// in the imports of the top-level config.
+func (m command) checkDropInImported(opts *options, cfg engine.Interface) []string {
+	if opts.runtime != "containerd" || !m.dropInConfigExists(opts) {
+		return nil
+	}
+
+	cfgWithDropIn, ok := cfg.(*containerd.ConfigWithDropIn)
+	if !ok {
+		return []string{fmt.Sprintf("drop-in config %q exists but the config version of %q does not support imports", opts.dropInConfigPath, opts.configFilePath)}
+	}
+	if !cfgWithDropIn.IsDropInImported(opts.dropInConfigPath) {
+		return []string{fmt.Sprintf("drop-in config %q is not imported by %q", opts.dropInConfigPath, opts.configFilePath)}
+	}
+	return nil
+}
+
+func (m command) dropInConfigExists(opts *options) bool {
+	if opts.dropInConfigPath == "" {
+		return false
+	}
+	_, err := os.Stat(opts.dropInConfigPath)
+	return err == nil
+}
+
+// loadEngineConfig loads the config for the container engine from the
+// specified path.
+func (m command) loadEngineConfig(opts *options, path string) (engine.Interface, error) {
+	configSource := toml.FromFile(path)
+	if opts.configSource == configSourceCommand && path == opts.configFilePath {
+		switch opts.runtime {
+		case "containerd":
+			configSource = containerd.CommandLineSource("", opts.executablePath)
+		case "crio":
+			configSource = crio.CommandLineSource("", opts.executablePath)
+		}
+	}
+
+	switch opts.runtime {
+	case "containerd":
+		return containerd.New(
+			containerd.WithLogger(m.logger),
+			containerd.WithTopLevelConfigPath(path),
+			containerd.WithConfigSource(configSource),
+		)
+	case "crio":
+		return crio.New(
+			crio.WithLogger(m.logger),
+			crio.WithTopLevelConfigPath(path),
+			crio.WithConfigSource(configSource),
+		)
+	case "docker":
+		return docker.New(
+			docker.WithLogger(m.logger),
+			docker.WithPath(path),
+		)
+	}
+	return nil, fmt.Errorf("unrecognized runtime '%v'", opts.runtime)
+}
+
+// checkExecutable checks whether the specified path refers to an executable
+// file. Paths that are not absolute are resolved using the PATH.
+func checkExecutable(path string) error {
+	if !filepath.IsAbs(path) {
+		if _, err := exec.LookPath(path); err != nil {
+			return fmt.Errorf("binary %q could not be found in the PATH", path)
+		}
+		return nil
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("binary %q does not exist", path)
+	}
+	if fileInfo.IsDir() || fileInfo.Mode()&0111 == 0 {
+		return fmt.Errorf("binary %q is not executable", path)
+	}
+	return nil
+}
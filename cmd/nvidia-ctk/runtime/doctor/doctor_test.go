@@ -0,0 +1,169 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnose(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description      string
+		runtime          string
+		configFile       string
+		config           string
+		dropInConfig     string
+		toolkitMode      string
+		expectedDefault  string
+		expectedFindings []string
+	}{
+		{
+			description: "containerd: valid drop-in config",
+			runtime:     "containerd",
+			configFile:  "etc/containerd/config.toml",
+			config: `version = 2
+imports = ["{{ .testRoot }}/etc/containerd/conf.d/*.toml"]
+`,
+			dropInConfig: `version = 2
+[plugins."io.containerd.grpc.v1.cri"]
+  enable_cdi = true
+[plugins."io.containerd.grpc.v1.cri".containerd]
+  default_runtime_name = "nvidia"
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.nvidia.options]
+  BinaryName = "{{ .testRoot }}/usr/bin/nvidia-container-runtime"
+`,
+			toolkitMode:     "cdi",
+			expectedDefault: "nvidia",
+		},
+		{
+			description: "containerd: drop-in config not imported",
+			runtime:     "containerd",
+			configFile:  "etc/containerd/config.toml",
+			config: `version = 2
+`,
+			dropInConfig: `version = 2
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.nvidia.options]
+  BinaryName = "{{ .testRoot }}/usr/bin/nvidia-container-runtime"
+`,
+			toolkitMode:     "cdi",
+			expectedDefault: "nvidia",
+			expectedFindings: []string{
+				`CDI is not enabled in the containerd config but the NVIDIA Container Runtime is configured in cdi mode`,
+				`no default runtime is set; expected "nvidia"`,
+				`drop-in config "{{ .testRoot }}/etc/containerd/conf.d/99-nvidia.toml" is not imported by "{{ .testRoot }}/etc/containerd/config.toml"`,
+			},
+		},
+		{
+			description: "containerd: v3 config enables CDI by default",
+			runtime:     "containerd",
+			configFile:  "etc/containerd/config.toml",
+			config: `version = 3
+[plugins."io.containerd.cri.v1.runtime".containerd.runtimes.nvidia-cdi.options]
+  BinaryName = "{{ .testRoot }}/usr/bin/nvidia-container-runtime.cdi"
+`,
+			toolkitMode: "cdi",
+			expectedFindings: []string{
+				`runtime "nvidia-cdi": binary "{{ .testRoot }}/usr/bin/nvidia-container-runtime.cdi" does not exist`,
+			},
+		},
+		{
+			description: "docker: runtime binary is not executable",
+			runtime:     "docker",
+			configFile:  "etc/docker/daemon.json",
+			config: `{
+    "default-runtime": "runc",
+    "runtimes": {
+        "nvidia": {
+            "path": "{{ .testRoot }}/etc/docker/daemon.json"
+        }
+    }
+}`,
+			toolkitMode:     "cdi",
+			expectedDefault: "nvidia",
+			expectedFindings: []string{
+				`runtime "nvidia": binary "{{ .testRoot }}/etc/docker/daemon.json" is not executable`,
+				`CDI is not enabled in the docker config but the NVIDIA Container Runtime is configured in cdi mode`,
+				`default runtime is "runc"; expected "nvidia"`,
+			},
+		},
+		{
+			description: "docker: no nvidia runtimes",
+			runtime:     "docker",
+			configFile:  "etc/docker/daemon.json",
+			config: `{
+    "features": {"cdi": true}
+}`,
+			toolkitMode: "cdi",
+			expectedFindings: []string{
+				`no nvidia runtime handlers are configured`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			testRoot := t.TempDir()
+			render := func(s string) string {
+				return strings.ReplaceAll(s, "{{ .testRoot }}", testRoot)
+			}
+
+			runtimePath := filepath.Join(testRoot, "usr/bin/nvidia-container-runtime")
+			require.NoError(t, os.MkdirAll(filepath.Dir(runtimePath), 0755))
+			require.NoError(t, os.WriteFile(runtimePath, []byte{}, 0755))
+
+			configPath := filepath.Join(testRoot, tc.configFile)
+			require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0755))
+			require.NoError(t, os.WriteFile(configPath, []byte(render(tc.config)), 0600))
+
+			var dropInConfigPath string
+			if tc.dropInConfig != "" {
+				dropInConfigPath = filepath.Join(testRoot, "etc/containerd/conf.d/99-nvidia.toml")
+				require.NoError(t, os.MkdirAll(filepath.Dir(dropInConfigPath), 0755))
+				require.NoError(t, os.WriteFile(dropInConfigPath, []byte(render(tc.dropInConfig)), 0600))
+			}
+
+			m := command{
+				logger: logger,
+			}
+			opts := &options{
+				runtime:                    tc.runtime,
+				configFilePath:             configPath,
+				dropInConfigPath:           dropInConfigPath,
+				configSource:               configSourceFile,
+				expectedDefaultRuntime:     tc.expectedDefault,
+				nvidiaContainerRuntimeMode: tc.toolkitMode,
+			}
+
+			findings, err := m.diagnose(opts)
+			require.NoError(t, err)
+
+			var expectedFindings []string
+			for _, finding := range tc.expectedFindings {
+				expectedFindings = append(expectedFindings, render(finding))
+			}
+			require.EqualValues(t, expectedFindings, findings)
+		})
+	}
+}
@@ -20,17 +20,20 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/runtime/configure"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/runtime/doctor"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
 
 type runtimeCommand struct {
-	logger logger.Interface
+	logger         logger.Interface
+	configFilePath *string
 }
 
 // NewCommand constructs a runtime command with the specified logger
-func NewCommand(logger logger.Interface) *cli.Command {
+func NewCommand(logger logger.Interface, configFilePath *string) *cli.Command {
 	c := runtimeCommand{
-		logger: logger,
+		logger:         logger,
+		configFilePath: configFilePath,
 	}
 	return c.build()
 }
@@ -42,6 +45,7 @@ func (m runtimeCommand) build() *cli.Command {
 		Usage: "A collection of runtime-related utilities for the NVIDIA Container Toolkit",
 		Commands: []*cli.Command{
 			configure.NewCommand(m.logger),
+			doctor.NewCommand(m.logger, m.configFilePath),
 		},
 	}
 
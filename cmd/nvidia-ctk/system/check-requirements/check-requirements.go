@@ -0,0 +1,180 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package checkrequirements
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type options struct {
+	env          []string
+	requirements []string
+	devices      []string
+	driverRoot   string
+	devRoot      string
+
+	// the following are used for dependency injection.
+	nvmllib nvml.Interface
+	output  io.Writer
+}
+
+// NewCommand constructs a check-requirements command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:  "check-requirements",
+		Usage: "Check the NVIDIA_REQUIRE_* requirements of a container image against the GPUs and driver on this node",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name: "env",
+				Usage: "Specify an environment variable of the container image in the form NAME=VALUE. " +
+					"The NVIDIA_REQUIRE_* and NVIDIA_VISIBLE_DEVICES environment variables determine the requirements and the devices that are checked. " +
+					"This flag can be specified multiple times",
+				Destination: &opts.env,
+			},
+			&cli.StringSliceFlag{
+				Name:        "require",
+				Usage:       "Specify an additional requirement to check. This flag can be specified multiple times",
+				Destination: &opts.requirements,
+			},
+			&cli.StringSliceFlag{
+				Name: "device",
+				Usage: "Specify the devices to check the requirements against. " +
+					"If this is not specified, the devices requested by the container image or all devices are used",
+				Destination: &opts.devices,
+			},
+			&cli.StringFlag{
+				Name:        "driver-root",
+				Usage:       "Specify the NVIDIA GPU driver root to use when querying the driver",
+				Value:       "/",
+				Destination: &opts.driverRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DRIVER_ROOT"),
+			},
+			&cli.StringFlag{
+				Name:        "dev-root",
+				Usage:       "Specify the root where `/dev` is located. If this is not specified, the driver-root is assumed.",
+				Destination: &opts.devRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DEV_ROOT"),
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	if opts.devRoot == "" {
+		opts.devRoot = opts.driverRoot
+	}
+	if opts.output == nil {
+		opts.output = os.Stdout
+	}
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	report, err := m.check(opts)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		m.logger.Infof("No requirements specified")
+		return nil
+	}
+
+	if _, err := fmt.Fprint(opts.output, report); err != nil {
+		return err
+	}
+	if !report.Satisfied() {
+		return fmt.Errorf("requirements not met")
+	}
+	return nil
+}
+
+// check evaluates the requirements of the container image against the
+// properties of the host and the selected devices. If there are no
+// requirements, a nil report is returned.
+func (m command) check(opts *options) (*requirements.Report, error) {
+	i, err := image.New(
+		image.WithLogger(m.logger),
+		image.WithEnv(opts.env),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct container image: %w", err)
+	}
+
+	imageRequirements, err := i.GetRequirements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image requirements: %w", err)
+	}
+	imageRequirements = append(imageRequirements, opts.requirements...)
+	if len(imageRequirements) == 0 {
+		return nil, nil
+	}
+
+	devices := opts.devices
+	if len(devices) == 0 {
+		devices = i.VisibleDevices()
+	}
+	if len(devices) == 0 {
+		devices = []string{"all"}
+	}
+
+	r := requirements.New(m.logger, imageRequirements)
+	host := requirements.NewHost(
+		requirements.WithLogger(m.logger),
+		requirements.WithDriver(root.New(
+			root.WithLogger(m.logger),
+			root.WithDriverRoot(opts.driverRoot),
+			root.WithDevRoot(opts.devRoot),
+		)),
+		requirements.WithNvmlLib(opts.nvmllib),
+	)
+	host.AddProperties(r, devices...)
+
+	return r.Report()
+}
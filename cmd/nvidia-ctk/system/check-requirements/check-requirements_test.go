@@ -0,0 +1,117 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package checkrequirements
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock/dgxa100"
+	mockserver "github.com/NVIDIA/go-nvml/pkg/nvml/mock/server"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRequirements(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description    string
+		options        options
+		expectedError  bool
+		expectedOutput []string
+	}{
+		{
+			description: "no requirements",
+			options: options{
+				env: []string{"NVIDIA_VISIBLE_DEVICES=all"},
+			},
+		},
+		{
+			description: "image requirements are met",
+			options: options{
+				env: []string{"NVIDIA_REQUIRE_CUDA=cuda>=12.4", "NVIDIA_VISIBLE_DEVICES=0"},
+			},
+			expectedOutput: []string{
+				"requirements of the container are met",
+				"Device 0:",
+				"[1] met: cuda>=12.4 (cuda=12.4)",
+			},
+		},
+		{
+			description: "additional requirements are checked for all devices",
+			options: options{
+				env:          []string{"NVIDIA_REQUIRE_CUDA=cuda>=12.4"},
+				requirements: []string{"memory>=80G"},
+			},
+			expectedError: true,
+			expectedOutput: []string{
+				"requirements of the container are not met",
+				"Device 7:",
+				"[2] not met: memory>=80G (memory=40960)",
+			},
+		},
+		{
+			description: "devices override image devices",
+			options: options{
+				env:          []string{"NVIDIA_VISIBLE_DEVICES=all"},
+				requirements: []string{"gpus=2"},
+				devices:      []string{"0", "1"},
+			},
+			expectedOutput: []string{
+				"[1] met: gpus=2 (gpus=2)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			server := dgxa100.New()
+			for _, d := range server.Devices {
+				(d.(*mockserver.Device)).IsMigDeviceHandleFunc = func() (bool, nvml.Return) {
+					return false, nvml.SUCCESS
+				}
+				(d.(*mockserver.Device)).GetGpuFabricInfoFunc = func() (nvml.GpuFabricInfo, nvml.Return) {
+					return nvml.GpuFabricInfo{}, nvml.ERROR_NOT_SUPPORTED
+				}
+			}
+
+			output := &bytes.Buffer{}
+			opts := tc.options
+			opts.driverRoot = t.TempDir()
+			opts.nvmllib = server
+			opts.output = output
+
+			c := command{logger: logger}
+			require.NoError(t, c.validateFlags(&opts))
+
+			err := c.run(&opts)
+			if tc.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			for _, expected := range tc.expectedOutput {
+				require.Contains(t, output.String(), expected)
+			}
+			if len(tc.expectedOutput) == 0 {
+				require.Empty(t, output.String())
+			}
+		})
+	}
+}
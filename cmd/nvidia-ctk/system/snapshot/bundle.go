@@ -0,0 +1,374 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/ldcache"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/snapshot"
+)
+
+const (
+	defaultBundleFilename = "nvidia-ctk-snapshot.tar.gz"
+
+	// maxBundleFileSize is the maximum size of a file that is added to a
+	// bundle.
+	maxBundleFileSize = 4 * 1024 * 1024
+)
+
+var (
+	// procDirs are the procfs directories whose contents are added to a
+	// bundle.
+	procDirs = []string{
+		"/proc/driver/nvidia",
+		"/proc/driver/nvidia-caps",
+	}
+	// containerEngineConfigs are the config files of the supported container
+	// engines that are added to a bundle.
+	containerEngineConfigs = []string{
+		"/etc/docker/daemon.json",
+		"/etc/containerd/config.toml",
+		"/etc/containerd/conf.d/*",
+		"/etc/crio/crio.conf",
+		"/etc/crio/crio.conf.d/*",
+	}
+	// driverRootSkipDirs are the directories in the driver root that are not
+	// included in the file listing since they do not contain driver files.
+	driverRootSkipDirs = []string{
+		"/dev",
+		"/home",
+		"/mnt",
+		"/proc",
+		"/root",
+		"/run",
+		"/sys",
+		"/tmp",
+		"/var",
+	}
+)
+
+// A bundle is a tarball of the files that are relevant for debugging issues
+// with the NVIDIA Container Toolkit. Errors encountered while collecting the
+// files are recorded in the bundle instead of being returned so that a bundle
+// can also be created for a partially broken system.
+type bundle struct {
+	logger   logger.Interface
+	hostRoot string
+	modTime  time.Time
+	tw       *tar.Writer
+	errors   []string
+}
+
+// writeBundle creates a bundle at the specified output path. Since the bundle
+// includes the configs and logs of the host, it is only readable by its owner.
+func (m command) writeBundle(opts *options) (rerr error) {
+	if err := os.MkdirAll(filepath.Dir(opts.output), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	f, err := os.OpenFile(opts.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() {
+		rerr = errors.Join(rerr, f.Close())
+	}()
+	// The mode of an existing file is not changed by OpenFile.
+	if err := f.Chmod(0600); err != nil {
+		return fmt.Errorf("failed to set mode of bundle: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	b := &bundle{
+		logger:   m.logger,
+		hostRoot: opts.hostRoot,
+		modTime:  time.Now(),
+		tw:       tar.NewWriter(gz),
+	}
+	if err := m.collect(b, opts); err != nil {
+		return err
+	}
+	if err := b.tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	m.logger.Infof("Wrote bundle to %v", opts.output)
+	return nil
+}
+
+// collect adds the relevant files to the bundle. Only errors writing to the
+// bundle are returned.
+func (m command) collect(b *bundle, opts *options) error {
+	s, err := m.capture(opts)
+	if err != nil {
+		b.recordError("failed to capture snapshot: %v", err)
+	} else if contents, err := s.Marshal(snapshot.FormatYAML); err != nil {
+		b.recordError("%v", err)
+	} else if err := b.addContents("snapshot.yaml", contents); err != nil {
+		return err
+	}
+
+	for _, dir := range procDirs {
+		if err := b.addDir(b.hostPath(dir)); err != nil {
+			return err
+		}
+	}
+	if err := b.addFile(b.hostPath("/proc/devices"), false); err != nil {
+		return err
+	}
+
+	if err := b.addContents("ldcache.txt", listNVIDIALibraries(b, opts.driverRoot)); err != nil {
+		return err
+	}
+	if err := b.addContents("driver-root.txt", listDriverRoot(b, opts.driverRoot)); err != nil {
+		return err
+	}
+
+	cfg, err := m.loadConfig(b, opts.configFilePath)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range containerEngineConfigs {
+		if err := b.addGlob(b.hostPath(pattern), true); err != nil {
+			return err
+		}
+	}
+	for _, dir := range cfg.NVIDIAContainerRuntimeConfig.Modes.CDI.SpecDirs {
+		for _, ext := range []string{"*.json", "*.yaml"} {
+			if err := b.addGlob(filepath.Join(b.hostPath(dir), ext), true); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(b.errors) == 0 {
+		return nil
+	}
+	return b.addContents("errors.txt", []byte(strings.Join(b.errors, "\n")+"\n"))
+}
+
+// loadConfig adds the NVIDIA Container Toolkit config to the bundle and
+// returns the loaded config. If the config cannot be loaded, the default
+// config is returned.
+func (m command) loadConfig(b *bundle, configFilePath string) (*config.Config, error) {
+	if err := b.addFile(configFilePath, true); err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.GetConfigFrom(configFilePath)
+	if err != nil {
+		b.recordError("failed to load config %v: %v", configFilePath, err)
+		return config.GetDefault()
+	}
+	return cfg, nil
+}
+
+// listNVIDIALibraries returns the NVIDIA libraries in the ldcache of the
+// specified driver root.
+func listNVIDIALibraries(b *bundle, driverRoot string) []byte {
+	cache, err := ldcache.New(b.logger, driverRoot)
+	if err != nil {
+		b.recordError("failed to load ldcache: %v", err)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	libs32, libs64 := cache.List()
+	for _, libs := range [][]string{libs32, libs64} {
+		for _, lib := range libs {
+			if !isNVIDIAFile(filepath.Base(lib)) {
+				continue
+			}
+			fmt.Fprintln(&buf, lib)
+		}
+	}
+	return buf.Bytes()
+}
+
+// listDriverRoot returns a listing of the NVIDIA files in the specified driver
+// root.
+func listDriverRoot(b *bundle, driverRoot string) []byte {
+	var buf bytes.Buffer
+	err := filepath.WalkDir(driverRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			b.recordError("failed to list %v: %v", path, err)
+			return nil
+		}
+		rel, _ := filepath.Rel(driverRoot, path)
+		if d.IsDir() && slices.Contains(driverRootSkipDirs, filepath.Join("/", rel)) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !isNVIDIAFile(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			b.recordError("failed to get info for %v: %v", path, err)
+			return nil
+		}
+		fmt.Fprintf(&buf, "%v %12d %v", info.Mode(), info.Size(), path)
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if target, err := os.Readlink(path); err == nil {
+				fmt.Fprintf(&buf, " -> %v", target)
+			}
+		}
+		fmt.Fprintln(&buf)
+		return nil
+	})
+	if err != nil {
+		b.recordError("failed to list driver root: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// isNVIDIAFile checks whether the specified file name refers to an NVIDIA
+// driver file.
+func isNVIDIAFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "nvidia") ||
+		strings.Contains(name, "cuda") ||
+		strings.HasPrefix(name, "libnv")
+}
+
+// hostPath returns the path to the specified file on the host.
+func (b *bundle) hostPath(path string) string {
+	return filepath.Join(b.hostRoot, path)
+}
+
+// nameFor returns the name of the specified file in the bundle. Files on the
+// host are named relative to the host root.
+func (b *bundle) nameFor(path string) string {
+	if rel, err := filepath.Rel(b.hostRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return strings.TrimPrefix(filepath.Clean(path), "/")
+}
+
+func (b *bundle) recordError(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	b.logger.Warningf("%s", message)
+	b.errors = append(b.errors, message)
+}
+
+// addGlob adds the files matching the specified pattern to the bundle.
+func (b *bundle) addGlob(pattern string, redacted bool) error {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		b.recordError("failed to glob %v: %v", pattern, err)
+		return nil
+	}
+	for _, m := range matches {
+		if err := b.addFile(m, redacted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDir adds the regular files in the specified directory to the bundle.
+func (b *bundle) addDir(dir string) error {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				b.recordError("failed to read %v: %v", path, err)
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		b.recordError("failed to read %v: %v", dir, err)
+	}
+	for _, f := range files {
+		if err := b.addFile(f, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFile adds the specified file to the bundle. If redacted is true, the
+// values of sensitive keys in the file are redacted. Files that do not exist
+// are ignored.
+func (b *bundle) addFile(path string, redacted bool) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		b.logger.Debugf("Skipping %v since it does not exist", path)
+		return nil
+	}
+	if err != nil {
+		b.recordError("failed to get info for %v: %v", path, err)
+		return nil
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	// Files in procfs report a size of 0, so the size is only checked for
+	// other files.
+	if info.Size() > maxBundleFileSize {
+		b.recordError("skipping %v with size %d", path, info.Size())
+		return nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		b.recordError("failed to read %v: %v", path, err)
+		return nil
+	}
+	if redacted {
+		contents = redact(contents)
+	}
+	return b.addContents(b.nameFor(path), contents)
+}
+
+// addContents adds a file with the specified name and contents to the bundle.
+func (b *bundle) addContents(name string, contents []byte) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(contents)),
+		ModTime:  b.modTime,
+	}
+	if err := b.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header for %v: %w", name, err)
+	}
+	if _, err := b.tw.Write(contents); err != nil {
+		return fmt.Errorf("failed to write %v: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"regexp"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveKey matches keys such as passwords or tokens whose values should
+// not be included in a bundle. This also matches the names of environment
+// variables.
+const sensitiveKey = `((?i:[\w.-]*(?:password|passwd|secret|token|credential|auth|api[_-]?key|private[_-]?key)[\w.-]*)["']?\s*[:=]\s*)`
+
+var redactions = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	// Quoted values as used in JSON, TOML, and YAML files.
+	{regexp.MustCompile(sensitiveKey + `"[^"]*"`), `${1}"` + redacted + `"`},
+	{regexp.MustCompile(sensitiveKey + `'[^']*'`), `${1}'` + redacted + `'`},
+	// Unquoted values as used in YAML files or environment variables.
+	{regexp.MustCompile(sensitiveKey + `[^\s"',{}\[\]]+`), `${1}` + redacted},
+	// Credentials in URLs such as those of proxies or registry mirrors.
+	{regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s"']+@`), `${1}` + redacted + `@`},
+}
+
+// redact replaces the values of sensitive keys and the credentials in URLs
+// in the specified file contents.
+func redact(contents []byte) []byte {
+	for _, r := range redactions {
+		contents = r.re.ReplaceAll(contents, []byte(r.replacement))
+	}
+	return contents
+}
@@ -0,0 +1,166 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/urfave/cli/v3"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/snapshot"
+)
+
+type command struct {
+	logger         logger.Interface
+	configFilePath *string
+}
+
+type options struct {
+	output     string
+	format     string
+	driverRoot string
+	devRoot    string
+
+	bundle         bool
+	configFilePath string
+
+	// the following are used for dependency injection.
+	hostRoot string
+	nvmllib  nvml.Interface
+}
+
+// NewCommand constructs a snapshot command with the specified logger
+func NewCommand(logger logger.Interface, configFilePath *string) *cli.Command {
+	c := command{
+		logger:         logger,
+		configFilePath: configFilePath,
+	}
+	return c.build()
+}
+
+// build creates the CLI command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:  "snapshot",
+		Usage: "Record the NVIDIA devices and driver files on the system so that CDI specifications can be generated without access to the hardware",
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, m.validateFlags(&opts)
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return m.run(&opts)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "Specify the file to output the snapshot to. If this is not specified, the snapshot is printed to STDOUT or, if --bundle is specified, written to " + defaultBundleFilename,
+				Destination: &opts.output,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "The output format for the snapshot [yaml | json]. If not specified, this is determined by the output file extension",
+				Destination: &opts.format,
+			},
+			&cli.StringFlag{
+				Name:        "driver-root",
+				Usage:       "Specify the NVIDIA GPU driver root to use when recording the driver files",
+				Value:       "/",
+				Destination: &opts.driverRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DRIVER_ROOT"),
+			},
+			&cli.StringFlag{
+				Name:        "dev-root",
+				Usage:       "Specify the root where `/dev` is located. If this is not specified, the driver-root is assumed.",
+				Destination: &opts.devRoot,
+				Sources:     cli.EnvVars("NVIDIA_CTK_DEV_ROOT"),
+			},
+			&cli.BoolFlag{
+				Name: "bundle",
+				Usage: "Write a tarball that additionally includes the driver state in /proc, the NVIDIA libraries in the ldcache, a listing of the driver root, " +
+					"and the NVIDIA Container Toolkit, container engine, and CDI configs with secrets redacted. This is intended to be attached to bug reports",
+				Destination: &opts.bundle,
+			},
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(opts *options) error {
+	switch opts.format {
+	case "", snapshot.FormatYAML, snapshot.FormatJSON:
+	default:
+		return fmt.Errorf("invalid output format: %v", opts.format)
+	}
+	if opts.devRoot == "" {
+		opts.devRoot = opts.driverRoot
+	}
+	if opts.hostRoot == "" {
+		opts.hostRoot = "/"
+	}
+	if opts.bundle && opts.output == "" {
+		opts.output = defaultBundleFilename
+	}
+	opts.configFilePath = config.GetConfigFilePath()
+	if m.configFilePath != nil && *m.configFilePath != "" {
+		opts.configFilePath = *m.configFilePath
+	}
+	return nil
+}
+
+func (m command) run(opts *options) error {
+	if opts.bundle {
+		return m.writeBundle(opts)
+	}
+
+	s, err := m.capture(opts)
+	if err != nil {
+		return fmt.Errorf("failed to capture snapshot: %w", err)
+	}
+
+	if opts.output != "" {
+		if err := s.Save(opts.output, opts.format); err != nil {
+			return err
+		}
+		m.logger.Infof("Wrote snapshot to %v", opts.output)
+		return nil
+	}
+
+	contents, err := s.Marshal(opts.format)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(contents)
+	return err
+}
+
+func (m command) capture(opts *options) (*snapshot.Snapshot, error) {
+	return snapshot.Capture(
+		snapshot.WithLogger(m.logger),
+		snapshot.WithNvmlLib(opts.nvmllib),
+		snapshot.WithDriverRoot(opts.driverRoot),
+		snapshot.WithDevRoot(opts.devRoot),
+		snapshot.WithHostRoot(opts.hostRoot),
+	)
+}
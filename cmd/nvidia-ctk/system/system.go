@@ -19,19 +19,23 @@ package system
 import (
 	"github.com/urfave/cli/v3"
 
+	checkrequirements "github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/system/check-requirements"
 	devchar "github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/system/create-dev-char-symlinks"
 	devicenodes "github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/system/create-device-nodes"
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/system/snapshot"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
 
 type command struct {
-	logger logger.Interface
+	logger         logger.Interface
+	configFilePath *string
 }
 
 // NewCommand constructs a runtime command with the specified logger
-func NewCommand(logger logger.Interface) *cli.Command {
+func NewCommand(logger logger.Interface, configFilePath *string) *cli.Command {
 	c := command{
-		logger: logger,
+		logger:         logger,
+		configFilePath: configFilePath,
 	}
 	return c.build()
 }
@@ -42,8 +46,10 @@ func (m command) build() *cli.Command {
 		Name:  "system",
 		Usage: "A collection of system-related utilities for the NVIDIA Container Toolkit",
 		Commands: []*cli.Command{
+			checkrequirements.NewCommand(m.logger),
 			devchar.NewCommand(m.logger),
 			devicenodes.NewCommand(m.logger),
+			snapshot.NewCommand(m.logger, m.configFilePath),
 		},
 	}
 
@@ -0,0 +1,131 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+)
+
+// A Record describes the device injection decisions made when creating a
+// single container.
+type Record struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ContainerID string    `json:"containerID,omitempty"`
+	RuntimeMode string    `json:"runtimeMode"`
+	Privileged  bool      `json:"privileged"`
+	// Requests lists the device requests made by the container and whether
+	// each of these was accepted.
+	Requests []image.DeviceRequest `json:"requests"`
+	// InjectedDevices lists the fully-qualified names of the CDI devices that
+	// were injected into the container.
+	InjectedDevices []string `json:"injectedDevices"`
+	// Error is set if the modification of the container failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Interface defines the API for an audit sink.
+type Interface interface {
+	Record(*Record) error
+}
+
+type fileSink struct {
+	path        string
+	containerID string
+	now         func() time.Time
+}
+
+var _ Interface = (*fileSink)(nil)
+
+// Option is a functional option for constructing an audit sink.
+type Option func(*fileSink)
+
+// New creates an audit sink that appends one JSON record per line to the
+// specified file. If no path is specified, a nil sink is returned and no
+// records are written.
+func New(opts ...Option) Interface {
+	s := &fileSink{
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.path == "" {
+		return nil
+	}
+	return s
+}
+
+// WithContainerID sets the ID of the container for which records are written.
+func WithContainerID(containerID string) Option {
+	return func(s *fileSink) {
+		s.containerID = containerID
+	}
+}
+
+// WithPath sets the path of the file to which records are appended.
+func WithPath(path string) Option {
+	return func(s *fileSink) {
+		s.path = path
+	}
+}
+
+// Record appends the specified record to the audit log file.
+func (s *fileSink) Record(r *Record) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for audit log: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	return s.writeTo(f, r)
+}
+
+func (s *fileSink) writeTo(w io.Writer, r *Record) error {
+	record := *r
+	record.Timestamp = s.now().UTC()
+	if record.ContainerID == "" {
+		record.ContainerID = s.containerID
+	}
+	// We ensure that empty lists are explicitly included in the record.
+	if record.Requests == nil {
+		record.Requests = []image.DeviceRequest{}
+	}
+	if record.InjectedDevices == nil {
+		record.InjectedDevices = []string{}
+	}
+
+	// We write the record using a single call to ensure that records from
+	// concurrent container creations are not interleaved.
+	contents, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	if _, err := w.Write(append(contents, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
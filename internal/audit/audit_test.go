@@ -0,0 +1,84 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+)
+
+func TestNew(t *testing.T) {
+	require.Nil(t, New())
+	require.NotNil(t, New(WithPath("/some/audit.log")))
+}
+
+func TestRecord(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "log", "audit.log")
+
+	s := New(
+		WithPath(auditLogPath),
+		WithContainerID("container-id"),
+	).(*fileSink)
+	s.now = func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+
+	records := []*Record{
+		{
+			RuntimeMode: "cdi",
+			Requests: []image.DeviceRequest{
+				{
+					Source:  image.DeviceRequestSourceEnvvar,
+					Key:     "NVIDIA_VISIBLE_DEVICES",
+					Devices: []string{"all"},
+					Reason:  "environment variable requests are not accepted for unprivileged containers",
+				},
+			},
+		},
+		{
+			RuntimeMode: "jit-cdi",
+			Privileged:  true,
+			Requests: []image.DeviceRequest{
+				{
+					Source:   image.DeviceRequestSourceEnvvar,
+					Key:      "NVIDIA_VISIBLE_DEVICES",
+					Devices:  []string{"all"},
+					Accepted: true,
+				},
+			},
+			InjectedDevices: []string{"runtime.nvidia.com/gpu=all"},
+		},
+	}
+	for _, r := range records {
+		require.NoError(t, s.Record(r))
+	}
+
+	contents, err := os.ReadFile(auditLogPath)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"timestamp":"2024-01-02T03:04:05Z","containerID":"container-id","runtimeMode":"cdi","privileged":false,"requests":[{"source":"envvar","key":"NVIDIA_VISIBLE_DEVICES","devices":["all"],"accepted":false,"reason":"environment variable requests are not accepted for unprivileged containers"}],"injectedDevices":[]}
+{"timestamp":"2024-01-02T03:04:05Z","containerID":"container-id","runtimeMode":"jit-cdi","privileged":true,"requests":[{"source":"envvar","key":"NVIDIA_VISIBLE_DEVICES","devices":["all"],"accepted":true}],"injectedDevices":["runtime.nvidia.com/gpu=all"]}
+`,
+		string(contents),
+	)
+}
@@ -18,6 +18,7 @@ package image
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -109,7 +110,7 @@ func (i CUDA) IsPrivileged() bool {
 }
 
 // GetRequirements returns the requirements from all NVIDIA_REQUIRE_ environment
-// variables.
+// variables ordered by the name of the environment variable.
 func (i CUDA) GetRequirements() ([]string, error) {
 	if i.HasDisableRequire() {
 		return nil, nil
@@ -117,9 +118,9 @@ func (i CUDA) GetRequirements() ([]string, error) {
 
 	// All variables with the "NVIDIA_REQUIRE_" prefix are passed to nvidia-container-cli
 	var requirements []string
-	for name, value := range i.env {
+	for _, name := range slices.Sorted(maps.Keys(i.env)) {
 		if strings.HasPrefix(name, NvidiaRequirePrefix) && !strings.HasPrefix(name, EnvVarNvidiaRequireJetpack) {
-			requirements = append(requirements, value)
+			requirements = append(requirements, i.env[name])
 		}
 	}
 	if i.IsLegacy() {
@@ -178,6 +179,36 @@ func (i CUDA) devicesFromEnvvars(envVars ...string) []string {
 	return NewVisibleDevices(devices...).List()
 }
 
+// ResolveDriverCapabilities returns the driver capabilities that are made
+// available to a container for the image. These are the capabilities requested
+// in the NVIDIA_DRIVER_CAPABILITIES environment variable restricted to the
+// specified supported capabilities. If the environment variable is not set,
+// the default capabilities are used for modern images and all supported
+// capabilities for legacy images. An error is returned if unsupported
+// capabilities are requested.
+func (i CUDA) ResolveDriverCapabilities(supported DriverCapabilities, legacyImage bool) (DriverCapabilities, error) {
+	capabilities := supported.Intersection(DefaultDriverCapabilities)
+
+	capsEnvSpecified := i.HasEnvvar(EnvVarNvidiaDriverCapabilities)
+	capsEnv := i.Getenv(EnvVarNvidiaDriverCapabilities)
+
+	if !capsEnvSpecified && legacyImage {
+		// Environment variable unset with legacy image: set all capabilities.
+		return supported, nil
+	}
+
+	if capsEnvSpecified && len(capsEnv) > 0 {
+		// If the environment variable is specified and is non-empty, use the capabilities value
+		envCapabilities := NewDriverCapabilities(capsEnv)
+		capabilities = supported.Intersection(envCapabilities)
+		if !envCapabilities.IsAll() && len(capabilities) != len(envCapabilities) {
+			return nil, fmt.Errorf("unsupported driver capabilities requested in %q (supported %q)", capsEnv, supported)
+		}
+	}
+
+	return capabilities, nil
+}
+
 // GetDriverCapabilities returns the requested driver capabilities.
 func (i CUDA) GetDriverCapabilities() DriverCapabilities {
 	env := i.env[EnvVarNvidiaDriverCapabilities]
@@ -254,39 +285,18 @@ func (i CUDA) visibleEnvVars() []string {
 // In cases where environment variable requests required privileged containers,
 // such devices requests are ignored.
 func (i CUDA) VisibleDevices() []string {
-	// If annotation device requests are present, these are preferred.
-	annotationDeviceRequests := i.cdiDeviceRequestsFromAnnotations()
-	if len(annotationDeviceRequests) > 0 {
-		return annotationDeviceRequests
-	}
-
-	// If enabled, try and get the device list from volume mounts first
-	if i.acceptDeviceListAsVolumeMounts {
-		volumeMountDeviceRequests := i.visibleDevicesFromMounts()
-		if len(volumeMountDeviceRequests) > 0 {
-			return volumeMountDeviceRequests
+	var devices []string
+	for _, request := range i.DeviceRequests() {
+		if request.Accepted {
+			devices = append(devices, request.Devices...)
+			continue
+		}
+		// We log a warning if we are ignoring the environment variable requests.
+		if request.Reason == reasonEnvvarUnprivileged {
+			i.logger.Warningf("Ignoring devices requested by environment variable(s) in unprivileged container: %v", i.visibleEnvVars())
 		}
 	}
-
-	// Get the Fallback to reading from the environment variable if privileges are correct
-	envVarDeviceRequests := i.visibleDevicesFromEnvVar()
-	if len(envVarDeviceRequests) == 0 {
-		return nil
-	}
-
-	// If the container is privileged, or environment variable requests are
-	// allowed for unprivileged containers, these devices are returned.
-	if i.isPrivileged || i.acceptEnvvarUnprivileged {
-		return envVarDeviceRequests
-	}
-
-	// We log a warning if we are ignoring the environment variable requests.
-	envVars := i.visibleEnvVars()
-	if len(envVars) > 0 {
-		i.logger.Warningf("Ignoring devices requested by environment variable(s) in unprivileged container: %v", envVars)
-	}
-
-	return nil
+	return devices
 }
 
 // cdiDeviceRequestsFromAnnotations returns a list of devices specified in the
@@ -296,27 +306,12 @@ func (i CUDA) VisibleDevices() []string {
 // The format of the requested devices is not checked and the list is not
 // deduplicated.
 func (i CUDA) cdiDeviceRequestsFromAnnotations() []string {
-	if len(i.annotationsPrefixes) == 0 || len(i.annotations) == 0 {
-		return nil
-	}
-
-	var annotationKeys []string
-	for key := range i.annotations {
-		for _, prefix := range i.annotationsPrefixes {
-			if strings.HasPrefix(key, prefix) {
-				annotationKeys = append(annotationKeys, key)
-				// There is no need to check additional prefixes since we
-				// typically deduplicate devices in any case.
-				break
-			}
-		}
-	}
-	// We sort the annotationKeys for consistent results.
-	slices.Sort(annotationKeys)
-
 	var devices []string
-	for _, key := range annotationKeys {
-		devices = append(devices, strings.Split(i.annotations[key], ",")...)
+	for _, request := range i.annotationDeviceRequests() {
+		if !request.Accepted {
+			continue
+		}
+		devices = append(devices, request.Devices...)
 	}
 	return devices
 }
@@ -333,21 +328,12 @@ func (i CUDA) visibleDevicesFromEnvVar() []string {
 // visibleDevicesFromMounts returns the set of visible devices requested as mounts.
 func (i CUDA) visibleDevicesFromMounts() []string {
 	var devices []string
-	for _, device := range i.requestsFromMounts() {
-		switch {
-		case strings.HasPrefix(device, volumeMountDevicePrefixImex):
+	for _, request := range i.mountDeviceRequests() {
+		if !request.Accepted {
+			i.logger.Warningf("Ignoring invalid mount request for CDI device %v: %v", request.Devices, request.Reason)
 			continue
-		case strings.HasPrefix(device, volumeMountDevicePrefixCDI):
-			name, err := cdiDeviceMountRequest(device).qualifiedName()
-			if err != nil {
-				i.logger.Warningf("Ignoring invalid mount request for CDI device %v: %v", device, err)
-				continue
-			}
-			devices = append(devices, name)
-		default:
-			devices = append(devices, device)
 		}
-
+		devices = append(devices, request.Devices...)
 	}
 	return devices
 }
@@ -789,6 +789,71 @@ func TestCDIDeviceRequestsFromAnnotations(t *testing.T) {
 	}
 }
 
+func TestResolveDriverCapabilities(t *testing.T) {
+	supported := NewDriverCapabilities("compute,utility,video")
+	testCases := []struct {
+		description          string
+		env                  map[string]string
+		legacyImage          bool
+		expectedCapabilities DriverCapabilities
+		expectedError        string
+	}{
+		{
+			description:          "unset envvar returns default capabilities",
+			expectedCapabilities: NewDriverCapabilities("compute,utility"),
+		},
+		{
+			description:          "unset envvar with legacy image returns supported capabilities",
+			legacyImage:          true,
+			expectedCapabilities: supported,
+		},
+		{
+			description: "empty envvar with legacy image returns default capabilities",
+			env: map[string]string{
+				EnvVarNvidiaDriverCapabilities: "",
+			},
+			legacyImage:          true,
+			expectedCapabilities: NewDriverCapabilities("compute,utility"),
+		},
+		{
+			description: "requested capabilities are returned",
+			env: map[string]string{
+				EnvVarNvidiaDriverCapabilities: "video",
+			},
+			expectedCapabilities: NewDriverCapabilities("video"),
+		},
+		{
+			description: "all returns supported capabilities",
+			env: map[string]string{
+				EnvVarNvidiaDriverCapabilities: "all",
+			},
+			expectedCapabilities: supported,
+		},
+		{
+			description: "unsupported capabilities return an error",
+			env: map[string]string{
+				EnvVarNvidiaDriverCapabilities: "compute,graphics",
+			},
+			expectedError: `unsupported driver capabilities requested in "compute,graphics" (supported "compute,utility,video")`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			image, err := New(WithEnvMap(tc.env))
+			require.NoError(t, err)
+
+			capabilities, err := image.ResolveDriverCapabilities(supported, tc.legacyImage)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedCapabilities, capabilities)
+		})
+	}
+}
+
 func makeTestMounts(paths ...string) []specs.Mount {
 	var mounts []specs.Mount
 	for _, path := range paths {
@@ -0,0 +1,210 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package image
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+)
+
+// A DeviceRequestSource identifies the mechanism through which devices were
+// requested for a container.
+type DeviceRequestSource string
+
+const (
+	DeviceRequestSourceAnnotation = DeviceRequestSource("annotation")
+	DeviceRequestSourceEnvvar     = DeviceRequestSource("envvar")
+	DeviceRequestSourceMount      = DeviceRequestSource("mount")
+	DeviceRequestSourceSwarm      = DeviceRequestSource("swarm")
+)
+
+const (
+	reasonAnnotationPrefixNotAllowed = "annotation prefix is not allowed"
+	reasonEnvvarUnprivileged         = "environment variable requests are not accepted for unprivileged containers"
+	reasonMountsNotAccepted          = "volume mount requests are not accepted"
+	reasonOverriddenByAnnotations    = "overridden by annotation requests"
+	reasonOverriddenByMounts         = "overridden by volume mount requests"
+)
+
+// A DeviceRequest records the devices requested through a single source and
+// whether these were accepted.
+type DeviceRequest struct {
+	Source DeviceRequestSource `json:"source"`
+	// Key identifies the request within its source. This is the annotation
+	// key, the container path of the mount, or the environment variable(s).
+	Key      string   `json:"key"`
+	Devices  []string `json:"devices"`
+	Accepted bool     `json:"accepted"`
+	// Reason is set if the request was rejected.
+	Reason string `json:"reason,omitempty"`
+}
+
+// DeviceRequests returns the device requests made by the container image
+// along with whether each request is accepted. Requests are considered in
+// order of precedence: annotations, volume mounts, and environment variables.
+// The devices from accepted requests make up the visible devices.
+func (i CUDA) DeviceRequests() []DeviceRequest {
+	var requests []DeviceRequest
+
+	requests = append(requests, i.annotationDeviceRequests()...)
+	overriddenReason := ""
+	if hasAcceptedRequest(requests) {
+		overriddenReason = reasonOverriddenByAnnotations
+	}
+
+	mountRequests := i.mountDeviceRequests()
+	for idx := range mountRequests {
+		request := &mountRequests[idx]
+		if !request.Accepted {
+			continue
+		}
+		switch {
+		case !i.acceptDeviceListAsVolumeMounts:
+			request.reject(reasonMountsNotAccepted)
+		case overriddenReason != "":
+			request.reject(overriddenReason)
+		}
+	}
+	requests = append(requests, mountRequests...)
+	if overriddenReason == "" && hasAcceptedRequest(mountRequests) {
+		overriddenReason = reasonOverriddenByMounts
+	}
+
+	if envRequest := i.envvarDeviceRequest(); envRequest != nil {
+		switch {
+		case overriddenReason != "":
+			envRequest.reject(overriddenReason)
+		case !i.isPrivileged && !i.acceptEnvvarUnprivileged:
+			envRequest.reject(reasonEnvvarUnprivileged)
+		}
+		requests = append(requests, *envRequest)
+	}
+
+	return requests
+}
+
+// annotationDeviceRequests returns a request for each annotation that
+// contains CDI device names. Annotations with the standard CDI prefix that do
+// not match one of the configured prefixes are included as rejected requests.
+func (i CUDA) annotationDeviceRequests() []DeviceRequest {
+	if len(i.annotations) == 0 {
+		return nil
+	}
+
+	var keys []string
+	for key := range i.annotations {
+		keys = append(keys, key)
+	}
+	// We sort the annotation keys for consistent results.
+	slices.Sort(keys)
+
+	var requests []DeviceRequest
+	for _, key := range keys {
+		request := DeviceRequest{
+			Source:   DeviceRequestSourceAnnotation,
+			Key:      key,
+			Devices:  strings.Split(i.annotations[key], ","),
+			Accepted: true,
+		}
+		switch {
+		case i.hasAllowedAnnotationPrefix(key):
+		case strings.HasPrefix(key, cdi.AnnotationPrefix):
+			request.reject(reasonAnnotationPrefixNotAllowed)
+		default:
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+func (i CUDA) hasAllowedAnnotationPrefix(key string) bool {
+	for _, prefix := range i.annotationsPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountDeviceRequests returns a request for each device requested as a volume
+// mount. Invalid CDI device requests are marked as rejected. IMEX channel
+// requests are not included.
+func (i CUDA) mountDeviceRequests() []DeviceRequest {
+	var requests []DeviceRequest
+	for _, device := range i.requestsFromMounts() {
+		if strings.HasPrefix(device, volumeMountDevicePrefixImex) {
+			continue
+		}
+		request := DeviceRequest{
+			Source:   DeviceRequestSourceMount,
+			Key:      filepath.Join(DeviceListAsVolumeMountsRoot, device),
+			Devices:  []string{device},
+			Accepted: true,
+		}
+		if strings.HasPrefix(device, volumeMountDevicePrefixCDI) {
+			name, err := cdiDeviceMountRequest(device).qualifiedName()
+			if err != nil {
+				request.reject(err.Error())
+			} else {
+				request.Devices = []string{name}
+			}
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// envvarDeviceRequest returns the request made through environment variables.
+// If a swarm resource environment variable is set, the request is attributed
+// to swarm.
+func (i CUDA) envvarDeviceRequest() *DeviceRequest {
+	devices := i.visibleDevicesFromEnvVar()
+	if len(devices) == 0 {
+		return nil
+	}
+
+	envVars := i.visibleEnvVars()
+	source := DeviceRequestSourceEnvvar
+	if !slices.Equal(envVars, []string{EnvVarNvidiaVisibleDevices}) {
+		source = DeviceRequestSourceSwarm
+	}
+
+	return &DeviceRequest{
+		Source:   source,
+		Key:      strings.Join(envVars, ","),
+		Devices:  devices,
+		Accepted: true,
+	}
+}
+
+func (r *DeviceRequest) reject(reason string) {
+	r.Accepted = false
+	r.Reason = reason
+}
+
+func hasAcceptedRequest(requests []DeviceRequest) bool {
+	for _, request := range requests {
+		if request.Accepted {
+			return true
+		}
+	}
+	return false
+}
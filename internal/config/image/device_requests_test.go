@@ -0,0 +1,188 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceRequests(t *testing.T) {
+	testCases := []struct {
+		description      string
+		options          []Option
+		expectedRequests []DeviceRequest
+	}{
+		{
+			description: "no requests",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=void"}),
+			},
+		},
+		{
+			description: "unprivileged envvar request is rejected",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=0,1"}),
+				WithAcceptEnvvarUnprivileged(false),
+			},
+			expectedRequests: []DeviceRequest{
+				{
+					Source:  DeviceRequestSourceEnvvar,
+					Key:     "NVIDIA_VISIBLE_DEVICES",
+					Devices: []string{"0", "1"},
+					Reason:  reasonEnvvarUnprivileged,
+				},
+			},
+		},
+		{
+			description: "privileged envvar request is accepted",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=0,1"}),
+				WithAcceptEnvvarUnprivileged(false),
+				WithPrivileged(true),
+			},
+			expectedRequests: []DeviceRequest{
+				{
+					Source:   DeviceRequestSourceEnvvar,
+					Key:      "NVIDIA_VISIBLE_DEVICES",
+					Devices:  []string{"0", "1"},
+					Accepted: true,
+				},
+			},
+		},
+		{
+			description: "swarm resource request is attributed to swarm",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=all", "DOCKER_RESOURCE_GPU=GPU-1"}),
+				WithPreferredVisibleDevicesEnvVars("DOCKER_RESOURCE_GPU"),
+			},
+			expectedRequests: []DeviceRequest{
+				{
+					Source:   DeviceRequestSourceSwarm,
+					Key:      "DOCKER_RESOURCE_GPU",
+					Devices:  []string{"GPU-1"},
+					Accepted: true,
+				},
+			},
+		},
+		{
+			description: "mount requests override envvar requests",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=all"}),
+				WithMounts(makeTestMounts("GPU0", "cdi/nvidia.com/gpu/1", "cdi/invalid", "imex/0")),
+				WithAcceptDeviceListAsVolumeMounts(true),
+			},
+			expectedRequests: []DeviceRequest{
+				{
+					Source:   DeviceRequestSourceMount,
+					Key:      "/var/run/nvidia-container-devices/GPU0",
+					Devices:  []string{"GPU0"},
+					Accepted: true,
+				},
+				{
+					Source:   DeviceRequestSourceMount,
+					Key:      "/var/run/nvidia-container-devices/cdi/nvidia.com/gpu/1",
+					Devices:  []string{"nvidia.com/gpu=1"},
+					Accepted: true,
+				},
+				{
+					Source:  DeviceRequestSourceMount,
+					Key:     "/var/run/nvidia-container-devices/cdi/invalid",
+					Devices: []string{"cdi/invalid"},
+					Reason:  "invalid mount CDI device request: cdi/invalid",
+				},
+				{
+					Source:  DeviceRequestSourceEnvvar,
+					Key:     "NVIDIA_VISIBLE_DEVICES",
+					Devices: []string{"all"},
+					Reason:  reasonOverriddenByMounts,
+				},
+			},
+		},
+		{
+			description: "mount requests are rejected if not accepted",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=all"}),
+				WithMounts(makeTestMounts("GPU0")),
+			},
+			expectedRequests: []DeviceRequest{
+				{
+					Source:  DeviceRequestSourceMount,
+					Key:     "/var/run/nvidia-container-devices/GPU0",
+					Devices: []string{"GPU0"},
+					Reason:  reasonMountsNotAccepted,
+				},
+				{
+					Source:   DeviceRequestSourceEnvvar,
+					Key:      "NVIDIA_VISIBLE_DEVICES",
+					Devices:  []string{"all"},
+					Accepted: true,
+				},
+			},
+		},
+		{
+			description: "annotation requests override other requests",
+			options: []Option{
+				WithEnv([]string{"NVIDIA_VISIBLE_DEVICES=all"}),
+				WithMounts(makeTestMounts("GPU0")),
+				WithAcceptDeviceListAsVolumeMounts(true),
+				WithAnnotationsPrefixes("nvidia.cdi.k8s.io/"),
+				WithAnnotations(map[string]string{
+					"nvidia.cdi.k8s.io/foo": "nvidia.com/gpu=0",
+					"cdi.k8s.io/bar":        "nvidia.com/gpu=1",
+					"other":                 "nvidia.com/gpu=2",
+				}),
+			},
+			expectedRequests: []DeviceRequest{
+				{
+					Source:  DeviceRequestSourceAnnotation,
+					Key:     "cdi.k8s.io/bar",
+					Devices: []string{"nvidia.com/gpu=1"},
+					Reason:  reasonAnnotationPrefixNotAllowed,
+				},
+				{
+					Source:   DeviceRequestSourceAnnotation,
+					Key:      "nvidia.cdi.k8s.io/foo",
+					Devices:  []string{"nvidia.com/gpu=0"},
+					Accepted: true,
+				},
+				{
+					Source:  DeviceRequestSourceMount,
+					Key:     "/var/run/nvidia-container-devices/GPU0",
+					Devices: []string{"GPU0"},
+					Reason:  reasonOverriddenByAnnotations,
+				},
+				{
+					Source:  DeviceRequestSourceEnvvar,
+					Key:     "NVIDIA_VISIBLE_DEVICES",
+					Devices: []string{"all"},
+					Reason:  reasonOverriddenByAnnotations,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			image, err := New(tc.options...)
+			require.NoError(t, err)
+
+			require.EqualValues(t, tc.expectedRequests, image.DeviceRequests())
+		})
+	}
+}
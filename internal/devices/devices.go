@@ -27,6 +27,30 @@ import (
 
 type Device config.Device
 
+type host struct{}
+
+var _ Interface = (*host)(nil)
+
+// New returns an Interface that queries the device nodes on the host.
+func New() Interface {
+	return &host{}
+}
+
+// DeviceFromPath returns the device at the specified path on the host.
+func (h *host) DeviceFromPath(path string, permissions string) (*Device, error) {
+	return DeviceFromPath(path, permissions)
+}
+
+// AssertCharDevice checks whether the specified path is a char device.
+func (h *host) AssertCharDevice(path string) error {
+	return AssertCharDevice(path)
+}
+
+// IsOverrideApplied returns whether the device handling has been overridden.
+func (h *host) IsOverrideApplied() bool {
+	return IsOverrideApplied()
+}
+
 // DeviceFromPath is a wrapper for libcontainer/devices.DeviceFromPath.
 // It allows for overriding functionality during tests.
 func DeviceFromPath(path string, permissions string) (*Device, error) {
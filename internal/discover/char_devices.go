@@ -17,6 +17,7 @@
 package discover
 
 import (
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/lookup"
 )
@@ -27,13 +28,25 @@ type charDevices mounts
 var _ Discover = (*charDevices)(nil)
 
 // NewCharDeviceDiscoverer creates a discoverer which locates the specified set of device nodes.
-func NewCharDeviceDiscoverer(logger logger.Interface, devRoot string, devices []string) Discover {
+func NewCharDeviceDiscoverer(logger logger.Interface, devRoot string, deviceNodes []string) Discover {
+	return NewCharDeviceDiscovererWithDevicesLib(logger, devices.New(), devRoot, deviceNodes)
+}
+
+// NewCharDeviceDiscovererWithDevicesLib creates a discoverer which locates the
+// specified set of device nodes. The devices library is used to check whether
+// a located path is a char device. If this is nil, the device nodes on the
+// host are checked.
+func NewCharDeviceDiscovererWithDevicesLib(logger logger.Interface, deviceslib devices.Interface, devRoot string, deviceNodes []string) Discover {
+	if deviceslib == nil {
+		deviceslib = devices.New()
+	}
 	locator := lookup.NewCharDeviceLocator(
 		lookup.WithLogger(logger),
 		lookup.WithRoot(devRoot),
+		lookup.WithFilter(deviceslib.AssertCharDevice),
 	)
 
-	return (*charDevices)(newMounts(logger, locator, devRoot, devices))
+	return (*charDevices)(newMounts(logger, locator, devRoot, deviceNodes))
 }
 
 // Mounts returns the discovered mounts for the charDevices.
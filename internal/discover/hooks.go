@@ -19,6 +19,7 @@ package discover
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"tags.cncf.io/container-device-interface/pkg/cdi"
 )
@@ -67,6 +68,65 @@ const (
 	defaultNvidiaCDIHookPath = "/usr/bin/nvidia-cdi-hook"
 )
 
+// A HookFailurePolicy defines how the nvidia-cdi-hook handles a hook that
+// fails or times out.
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyFail fails the hook, and with it the creation of the
+	// container. This is the default.
+	HookFailurePolicyFail = HookFailurePolicy("fail")
+	// HookFailurePolicyWarn logs a warning and allows the container to be
+	// created.
+	HookFailurePolicyWarn = HookFailurePolicy("warn")
+	// HookFailurePolicySkip skips the hook without running it.
+	HookFailurePolicySkip = HookFailurePolicy("skip")
+)
+
+const (
+	// HookTimeoutEnvVar is the environment variable used to pass the timeout
+	// of a hook to the nvidia-cdi-hook.
+	HookTimeoutEnvVar = "NVIDIA_CTK_HOOK_TIMEOUT"
+	// HookFailurePolicyEnvVar is the environment variable used to pass the
+	// failure policy of a hook to the nvidia-cdi-hook.
+	HookFailurePolicyEnvVar = "NVIDIA_CTK_HOOK_FAILURE_POLICY"
+)
+
+// A HookPolicy defines the timeout and failure policy of a hook. A zero
+// timeout means that the hook is not interrupted and an empty failure policy
+// is equivalent to HookFailurePolicyFail.
+type HookPolicy struct {
+	Timeout       time.Duration
+	FailurePolicy HookFailurePolicy
+}
+
+// AssertValid checks whether the failure policy is supported and the timeout
+// is not negative.
+func (p HookPolicy) AssertValid() error {
+	if p.Timeout < 0 {
+		return fmt.Errorf("invalid hook timeout %v", p.Timeout)
+	}
+	switch p.FailurePolicy {
+	case "", HookFailurePolicyFail, HookFailurePolicyWarn, HookFailurePolicySkip:
+		return nil
+	default:
+		return fmt.Errorf("invalid hook failure policy %q", p.FailurePolicy)
+	}
+}
+
+// envVars returns the environment variables that pass the policy to the
+// nvidia-cdi-hook. No environment variables are returned for the defaults.
+func (p HookPolicy) envVars() []string {
+	var envVars []string
+	if p.Timeout > 0 {
+		envVars = append(envVars, fmt.Sprintf("%v=%v", HookTimeoutEnvVar, p.Timeout))
+	}
+	if p.FailurePolicy != "" && p.FailurePolicy != HookFailurePolicyFail {
+		envVars = append(envVars, fmt.Sprintf("%v=%v", HookFailurePolicyEnvVar, p.FailurePolicy))
+	}
+	return envVars
+}
+
 // defaultDisabledHooks defines hooks that are disabled by default.
 // These hooks can be explicitly enabled using the WithEnabledHooks option.
 var defaultDisabledHooks = []HookName{
@@ -107,6 +167,7 @@ type hookCreatorOptions struct {
 	ldconfigPath      string
 	disabledHooks     []HookName
 	enabledHooks      []HookName
+	hookPolicies      map[HookName]HookPolicy
 	debugLogging      bool
 }
 
@@ -116,6 +177,7 @@ type cdiHookCreator struct {
 	nvidiaCDIHookPath string
 	ldconfigPath      string
 	disabledHooks     map[HookName]bool
+	hookPolicies      map[HookName]HookPolicy
 
 	fixedArgs    []string
 	debugLogging bool
@@ -156,6 +218,22 @@ func WithEnabledHooks(hooks ...HookName) Option {
 	}
 }
 
+// WithHookPolicies sets the timeout and failure policy of the specified hooks.
+// The policy for AllHooks applies to hooks without a policy of their own.
+func WithHookPolicies(policies map[HookName]HookPolicy) Option {
+	return func(c *hookCreatorOptions) {
+		if len(policies) == 0 {
+			return
+		}
+		if c.hookPolicies == nil {
+			c.hookPolicies = make(map[HookName]HookPolicy)
+		}
+		for name, policy := range policies {
+			c.hookPolicies[name] = policy
+		}
+	}
+}
+
 func WithLdconfigPath(ldconfigPath string) Option {
 	return func(c *hookCreatorOptions) {
 		c.ldconfigPath = ldconfigPath
@@ -196,6 +274,7 @@ func NewHookCreator(opts ...Option) HookCreator {
 		nvidiaCDIHookPath: o.nvidiaCDIHookPath,
 		ldconfigPath:      o.ldconfigPath,
 		disabledHooks:     disabledHooks,
+		hookPolicies:      o.hookPolicies,
 		fixedArgs:         getFixedArgsForCDIHookCLI(o.nvidiaCDIHookPath),
 		debugLogging:      o.debugLogging,
 	}
@@ -214,8 +293,17 @@ func (c cdiHookCreator) Create(name HookName, args ...string) *Hook {
 		Lifecycle: string(c.getOCIHookType(name)),
 		Path:      c.nvidiaCDIHookPath,
 		Args:      append(c.requiredArgs(name), c.transformArgs(name, args...)...),
-		Env:       []string{fmt.Sprintf("NVIDIA_CTK_DEBUG=%v", c.debugLogging)},
+		Env:       append([]string{fmt.Sprintf("NVIDIA_CTK_DEBUG=%v", c.debugLogging)}, c.getHookPolicy(name).envVars()...),
+	}
+}
+
+// getHookPolicy returns the policy for the specified hook, falling back to
+// the policy for all hooks.
+func (c cdiHookCreator) getHookPolicy(name HookName) HookPolicy {
+	if policy, ok := c.hookPolicies[name]; ok {
+		return policy
 	}
+	return c.hookPolicies[AllHooks]
 }
 
 func (c cdiHookCreator) getOCIHookType(name HookName) OCIHookType {
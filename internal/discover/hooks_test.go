@@ -18,6 +18,7 @@ package discover
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -328,6 +329,50 @@ func TestCDIHookCreator_Create(t *testing.T) {
 				Env:       []string{"NVIDIA_CTK_DEBUG=true"},
 			},
 		},
+		{
+			name: "hook policy is set in environment",
+			hookCreator: NewHookCreator(WithHookPolicies(map[HookName]HookPolicy{
+				UpdateLDCacheHook: {Timeout: 30 * time.Second, FailurePolicy: HookFailurePolicyWarn},
+			})),
+			hookName: UpdateLDCacheHook,
+			args:     []string{},
+			expectedHook: &Hook{
+				Lifecycle: "createContainer",
+				Path:      defaultNvidiaCDIHookPath,
+				Args:      []string{"nvidia-cdi-hook", "update-ldcache"},
+				Env:       []string{"NVIDIA_CTK_DEBUG=false", "NVIDIA_CTK_HOOK_TIMEOUT=30s", "NVIDIA_CTK_HOOK_FAILURE_POLICY=warn"},
+			},
+		},
+		{
+			name: "hook policy overrides policy for all hooks",
+			hookCreator: NewHookCreator(WithHookPolicies(map[HookName]HookPolicy{
+				AllHooks:             {Timeout: time.Minute},
+				EnableCudaCompatHook: {FailurePolicy: HookFailurePolicySkip},
+			})),
+			hookName: EnableCudaCompatHook,
+			args:     []string{},
+			expectedHook: &Hook{
+				Lifecycle: "createContainer",
+				Path:      defaultNvidiaCDIHookPath,
+				Args:      []string{"nvidia-cdi-hook", "enable-cuda-compat"},
+				Env:       []string{"NVIDIA_CTK_DEBUG=false", "NVIDIA_CTK_HOOK_FAILURE_POLICY=skip"},
+			},
+		},
+		{
+			name: "policy for all hooks is applied",
+			hookCreator: NewHookCreator(WithHookPolicies(map[HookName]HookPolicy{
+				AllHooks:             {Timeout: time.Minute},
+				EnableCudaCompatHook: {FailurePolicy: HookFailurePolicySkip},
+			})),
+			hookName: UpdateLDCacheHook,
+			args:     []string{},
+			expectedHook: &Hook{
+				Lifecycle: "createContainer",
+				Path:      defaultNvidiaCDIHookPath,
+				Args:      []string{"nvidia-cdi-hook", "update-ldcache"},
+				Env:       []string{"NVIDIA_CTK_DEBUG=false", "NVIDIA_CTK_HOOK_TIMEOUT=1m0s"},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -21,8 +21,9 @@ import (
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/lookup"
 )
 
-// ipcMountOptions defines the mount options for IPC sockets.
-var ipcMountOptions = []string{
+// IPCMountOptions defines the mount options for IPC sockets and directories
+// such as those used by the MPS control daemon.
+var IPCMountOptions = []string{
 	"nosuid",
 	"nodev",
 	"rbind",
@@ -79,7 +80,7 @@ func (d *ipcMounts) Mounts() ([]Mount, error) {
 	var modifiedMounts []Mount
 	for _, m := range mounts {
 		mount := m
-		mount.Options = ipcMountOptions
+		mount.Options = IPCMountOptions
 		modifiedMounts = append(modifiedMounts, mount)
 	}
 
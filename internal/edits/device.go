@@ -29,6 +29,7 @@ import (
 
 type device struct {
 	discover.Device
+	deviceslib       devices.Interface
 	noAdditionalGIDs bool
 }
 
@@ -73,7 +74,11 @@ func (d device) fromPathOrDefault() *specs.DeviceNode {
 	if path == "" {
 		path = d.Path
 	}
-	dn, err := devices.DeviceFromPath(path, "rwm")
+	deviceslib := d.deviceslib
+	if deviceslib == nil {
+		deviceslib = devices.New()
+	}
+	dn, err := deviceslib.DeviceFromPath(path, "rwm")
 	if err != nil {
 		return &specs.DeviceNode{
 			HostPath: d.HostPath,
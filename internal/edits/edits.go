@@ -22,6 +22,7 @@ import (
 	"tags.cncf.io/container-device-interface/pkg/cdi"
 	"tags.cncf.io/container-device-interface/specs-go"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
@@ -41,6 +42,7 @@ type empty string
 
 type factory struct {
 	logger                         logger.Interface
+	deviceslib                     devices.Interface
 	noAdditionalGIDsForDeviceNodes bool
 }
 
@@ -56,6 +58,9 @@ func NewFactory(opts ...Option) Factory {
 	for _, opt := range opts {
 		opt(f)
 	}
+	if f.deviceslib == nil {
+		f.deviceslib = devices.New()
+	}
 	return f
 }
 
@@ -111,6 +116,7 @@ func (f *factory) FromDiscoverer(d discover.Discover) (*cdi.ContainerEdits, erro
 func (f *factory) device(d discover.Device) *device {
 	return &device{
 		Device:           d,
+		deviceslib:       f.deviceslib,
 		noAdditionalGIDs: f.noAdditionalGIDsForDeviceNodes,
 	}
 }
@@ -134,6 +140,14 @@ func WithLogger(logger logger.Interface) Option {
 	}
 }
 
+// WithDevicesLib sets the library used to query the properties of device
+// nodes.
+func WithDevicesLib(deviceslib devices.Interface) Option {
+	return func(f *factory) {
+		f.deviceslib = deviceslib
+	}
+}
+
 func WithNoAdditionalGIDsForDeviceNodes(noAdditionalGIDsForDeviceNodes bool) Option {
 	return func(f *factory) {
 		f.noAdditionalGIDsForDeviceNodes = noAdditionalGIDsForDeviceNodes
@@ -23,7 +23,13 @@ import (
 
 // GetDeviceNodesByBusID returns the DRM devices associated with the specified PCI bus ID
 func GetDeviceNodesByBusID(busID string) ([]string, error) {
-	drmRoot := filepath.Join("/sys/bus/pci/devices", busID, "drm")
+	return GetDeviceNodesByBusIDAtRoot("/", busID)
+}
+
+// GetDeviceNodesByBusIDAtRoot returns the DRM devices associated with the
+// specified PCI bus ID with sysfs queried under the specified root.
+func GetDeviceNodesByBusIDAtRoot(root string, busID string) ([]string, error) {
+	drmRoot := filepath.Join(root, "/sys/bus/pci/devices", busID, "drm")
 	matches, err := filepath.Glob(fmt.Sprintf("%s/*", drmRoot))
 	if err != nil {
 		return nil, err
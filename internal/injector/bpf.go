@@ -0,0 +1,173 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// The eBPF opcodes used in device filters.
+const (
+	bpfLoadWord     = 0x61 // BPF_LDX | BPF_MEM | BPF_W
+	bpfAnd          = 0x54 // BPF_ALU | BPF_AND | BPF_K
+	bpfRightShift   = 0x74 // BPF_ALU | BPF_RSH | BPF_K
+	bpfMove         = 0xb7 // BPF_ALU64 | BPF_MOV | BPF_K
+	bpfMoveRegister = 0xbf // BPF_ALU64 | BPF_MOV | BPF_X
+	bpfJumpEqual    = 0x15 // BPF_JMP | BPF_JEQ | BPF_K
+	bpfJumpNotEqual = 0x55 // BPF_JMP | BPF_JNE | BPF_K
+	bpfExit         = 0x95 // BPF_JMP | BPF_EXIT
+)
+
+// The device types and access types of a struct bpf_cgroup_dev_ctx.
+const (
+	devcgDevBlock = 1
+	devcgDevChar  = 2
+
+	devcgAccMknod = 1
+	devcgAccRead  = 2
+	devcgAccWrite = 4
+	devcgAccAll   = devcgAccMknod | devcgAccRead | devcgAccWrite
+)
+
+// A bpfInstruction is an eBPF instruction as defined by struct bpf_insn.
+type bpfInstruction struct {
+	Code uint8
+	// Registers holds the destination register in the lower and the source
+	// register in the upper four bits.
+	Registers uint8
+	Offset    int16
+	Immediate int32
+}
+
+func registers(dst uint8, src uint8) uint8 {
+	return dst | src<<4
+}
+
+// newDeviceFilter returns the instructions of a device filter that implements
+// the specified rules. As for the devices cgroup v1 controller, the last rule
+// that matches a device determines whether access is allowed. The rules are
+// thus checked in reverse order. Access to devices that match no rule is
+// denied.
+//
+// The context of a device filter (struct bpf_cgroup_dev_ctx) is referenced by
+// R1. Its first word holds the access type in the upper and the device type in
+// the lower 16 bits and is followed by the major and minor numbers.
+func newDeviceFilter(rules []specs.LinuxDeviceCgroup) ([]bpfInstruction, error) {
+	insns := []bpfInstruction{
+		{Code: bpfLoadWord, Registers: registers(2, 1), Offset: 0},
+		{Code: bpfAnd, Registers: registers(2, 0), Immediate: 0xffff},
+		{Code: bpfLoadWord, Registers: registers(3, 1), Offset: 0},
+		{Code: bpfRightShift, Registers: registers(3, 0), Immediate: 16},
+		{Code: bpfLoadWord, Registers: registers(4, 1), Offset: 4},
+		{Code: bpfLoadWord, Registers: registers(5, 1), Offset: 8},
+	}
+	for _, rule := range slices.Backward(rules) {
+		block, err := newDeviceRuleBlock(rule)
+		if err != nil {
+			return nil, err
+		}
+		insns = append(insns, block...)
+	}
+	insns = append(insns,
+		bpfInstruction{Code: bpfMove, Registers: registers(0, 0), Immediate: 0},
+		bpfInstruction{Code: bpfExit},
+	)
+	return insns, nil
+}
+
+// newDeviceRuleBlock returns the instructions that allow or deny access as
+// specified by the rule if a device matches the rule and otherwise jump to the
+// end of the block.
+func newDeviceRuleBlock(rule specs.LinuxDeviceCgroup) ([]bpfInstruction, error) {
+	var block []bpfInstruction
+	switch rule.Type {
+	case "", "a":
+	case "c":
+		block = append(block, bpfInstruction{Code: bpfJumpNotEqual, Registers: registers(2, 0), Immediate: devcgDevChar})
+	case "b":
+		block = append(block, bpfInstruction{Code: bpfJumpNotEqual, Registers: registers(2, 0), Immediate: devcgDevBlock})
+	default:
+		return nil, fmt.Errorf("unsupported device type %q", rule.Type)
+	}
+
+	access, err := parseDeviceAccess(rule.Access)
+	if err != nil {
+		return nil, err
+	}
+	// A rule that allows access matches if all requested access types are
+	// allowed, a rule that denies access if any requested access type is
+	// denied.
+	switch {
+	case access == devcgAccAll:
+	case !rule.Allow:
+		block = append(block,
+			bpfInstruction{Code: bpfMoveRegister, Registers: registers(0, 3)},
+			bpfInstruction{Code: bpfAnd, Registers: registers(0, 0), Immediate: int32(access)},
+			bpfInstruction{Code: bpfJumpEqual, Registers: registers(0, 0), Immediate: 0},
+		)
+	default:
+		block = append(block,
+			bpfInstruction{Code: bpfMoveRegister, Registers: registers(0, 3)},
+			bpfInstruction{Code: bpfAnd, Registers: registers(0, 0), Immediate: int32(^access & devcgAccAll)},
+			bpfInstruction{Code: bpfJumpNotEqual, Registers: registers(0, 0), Immediate: 0},
+		)
+	}
+	if rule.Major != nil && *rule.Major >= 0 {
+		block = append(block, bpfInstruction{Code: bpfJumpNotEqual, Registers: registers(4, 0), Immediate: int32(*rule.Major)}) //nolint:gosec
+	}
+	if rule.Minor != nil && *rule.Minor >= 0 {
+		block = append(block, bpfInstruction{Code: bpfJumpNotEqual, Registers: registers(5, 0), Immediate: int32(*rule.Minor)}) //nolint:gosec
+	}
+	var allow int32
+	if rule.Allow {
+		allow = 1
+	}
+	block = append(block,
+		bpfInstruction{Code: bpfMove, Registers: registers(0, 0), Immediate: allow},
+		bpfInstruction{Code: bpfExit},
+	)
+
+	for i := range block {
+		if block[i].Code == bpfJumpNotEqual || block[i].Code == bpfJumpEqual {
+			block[i].Offset = int16(len(block) - i - 1) //nolint:gosec
+		}
+	}
+	return block, nil
+}
+
+func parseDeviceAccess(access string) (int, error) {
+	if access == "" {
+		return devcgAccAll, nil
+	}
+	var a int
+	for _, c := range access {
+		switch c {
+		case 'r':
+			a |= devcgAccRead
+		case 'w':
+			a |= devcgAccWrite
+		case 'm':
+			a |= devcgAccMknod
+		default:
+			return 0, fmt.Errorf("invalid device access %q", access)
+		}
+	}
+	return a, nil
+}
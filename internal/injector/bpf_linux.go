@@ -0,0 +1,145 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxDeviceFilters is the maximum number of device filters attached to a
+// cgroup that are queried.
+const maxDeviceFilters = 64
+
+// queryDeviceFilters returns the IDs of the device filters attached to the
+// specified cgroup as well as the flags with which these were attached.
+func queryDeviceFilters(cgroupFd int) ([]uint32, uint32, error) {
+	ids := make([]uint32, maxDeviceFilters)
+	attr := struct {
+		targetFd    uint32
+		attachType  uint32
+		queryFlags  uint32
+		attachFlags uint32
+		progIDs     uint64
+		progCount   uint32
+		_           uint32
+	}{
+		targetFd:   uint32(cgroupFd), //nolint:gosec
+		attachType: unix.BPF_CGROUP_DEVICE,
+		progIDs:    uint64(uintptr(unsafe.Pointer(&ids[0]))),
+		progCount:  uint32(len(ids)),
+	}
+	_, err := bpf(unix.BPF_PROG_QUERY, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ids[:attr.progCount], attr.attachFlags, nil
+}
+
+// replaceDeviceFilter replaces the device filter with the specified ID by a
+// filter with the specified instructions.
+func replaceDeviceFilter(cgroupFd int, id uint32, attachFlags uint32, insns []bpfInstruction) error {
+	existing, err := getProgramFd(id)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(existing)
+
+	replacement, err := loadDeviceFilter(insns)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(replacement)
+
+	attr := struct {
+		targetFd     uint32
+		attachBpfFd  uint32
+		attachType   uint32
+		attachFlags  uint32
+		replaceBpfFd uint32
+	}{
+		targetFd:    uint32(cgroupFd),    //nolint:gosec
+		attachBpfFd: uint32(replacement), //nolint:gosec
+		attachType:  unix.BPF_CGROUP_DEVICE,
+		attachFlags: attachFlags,
+	}
+	// If multiple filters can be attached, the filter to replace must be
+	// specified explicitly. Otherwise the attached filter is replaced.
+	if attachFlags&unix.BPF_F_ALLOW_MULTI != 0 {
+		attr.attachFlags |= unix.BPF_F_REPLACE
+		attr.replaceBpfFd = uint32(existing) //nolint:gosec
+	}
+	if _, err := bpf(unix.BPF_PROG_ATTACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return fmt.Errorf("failed to attach device filter: %w", err)
+	}
+	return nil
+}
+
+func getProgramFd(id uint32) (int, error) {
+	attr := struct {
+		progID    uint32
+		nextID    uint32
+		openFlags uint32
+	}{
+		progID: id,
+	}
+	fd, err := bpf(unix.BPF_PROG_GET_FD_BY_ID, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if err != nil {
+		return -1, fmt.Errorf("failed to get device filter: %w", err)
+	}
+	return int(fd), nil
+}
+
+// loadDeviceFilter loads a device filter with the specified instructions.
+func loadDeviceFilter(insns []bpfInstruction) (int, error) {
+	license := []byte("Apache\x00")
+	attr := struct {
+		progType    uint32
+		insnCount   uint32
+		insns       uint64
+		license     uint64
+		logLevel    uint32
+		logSize     uint32
+		logBuf      uint64
+		kernVersion uint32
+		progFlags   uint32
+	}{
+		progType:  unix.BPF_PROG_TYPE_CGROUP_DEVICE,
+		insnCount: uint32(len(insns)), //nolint:gosec
+		insns:     uint64(uintptr(unsafe.Pointer(&insns[0]))),
+		license:   uint64(uintptr(unsafe.Pointer(&license[0]))),
+	}
+	fd, err := bpf(unix.BPF_PROG_LOAD, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(insns)
+	runtime.KeepAlive(license)
+	if err != nil {
+		return -1, fmt.Errorf("failed to load device filter: %w", err)
+	}
+	return int(fd), nil
+}
+
+func bpf(cmd uintptr, attr unsafe.Pointer, size uintptr) (uintptr, error) {
+	r, _, errno := unix.Syscall(unix.SYS_BPF, cmd, uintptr(attr), size)
+	if errno != 0 {
+		return 0, errno
+	}
+	return r, nil
+}
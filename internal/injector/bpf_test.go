@@ -0,0 +1,143 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceRuleBlock(t *testing.T) {
+	testCases := []struct {
+		description   string
+		rule          specs.LinuxDeviceCgroup
+		expected      []bpfInstruction
+		expectedError bool
+	}{
+		{
+			description: "char device with all access",
+			rule:        specs.LinuxDeviceCgroup{Allow: true, Type: "c", Major: ptr(195), Minor: ptr(255), Access: "rwm"},
+			expected: []bpfInstruction{
+				{Code: bpfJumpNotEqual, Registers: 0x02, Offset: 4, Immediate: devcgDevChar},
+				{Code: bpfJumpNotEqual, Registers: 0x04, Offset: 3, Immediate: 195},
+				{Code: bpfJumpNotEqual, Registers: 0x05, Offset: 2, Immediate: 255},
+				{Code: bpfMove, Immediate: 1},
+				{Code: bpfExit},
+			},
+		},
+		{
+			description: "restricted access and any minor",
+			rule:        specs.LinuxDeviceCgroup{Allow: true, Type: "c", Major: ptr(195), Access: "rw"},
+			expected: []bpfInstruction{
+				{Code: bpfJumpNotEqual, Registers: 0x02, Offset: 6, Immediate: devcgDevChar},
+				{Code: bpfMoveRegister, Registers: 0x30},
+				{Code: bpfAnd, Immediate: devcgAccMknod},
+				{Code: bpfJumpNotEqual, Offset: 3},
+				{Code: bpfJumpNotEqual, Registers: 0x04, Offset: 2, Immediate: 195},
+				{Code: bpfMove, Immediate: 1},
+				{Code: bpfExit},
+			},
+		},
+		{
+			description: "denied device",
+			rule:        specs.LinuxDeviceCgroup{Allow: false, Type: "b", Major: ptr(8), Access: "rwm"},
+			expected: []bpfInstruction{
+				{Code: bpfJumpNotEqual, Registers: 0x02, Offset: 3, Immediate: devcgDevBlock},
+				{Code: bpfJumpNotEqual, Registers: 0x04, Offset: 2, Immediate: 8},
+				{Code: bpfMove, Immediate: 0},
+				{Code: bpfExit},
+			},
+		},
+		{
+			description: "restricted access of denied device",
+			rule:        specs.LinuxDeviceCgroup{Allow: false, Type: "c", Major: ptr(195), Minor: ptr(0), Access: "w"},
+			expected: []bpfInstruction{
+				{Code: bpfJumpNotEqual, Registers: 0x02, Offset: 7, Immediate: devcgDevChar},
+				{Code: bpfMoveRegister, Registers: 0x30},
+				{Code: bpfAnd, Immediate: devcgAccWrite},
+				{Code: bpfJumpEqual, Offset: 4},
+				{Code: bpfJumpNotEqual, Registers: 0x04, Offset: 3, Immediate: 195},
+				{Code: bpfJumpNotEqual, Registers: 0x05, Offset: 2, Immediate: 0},
+				{Code: bpfMove, Immediate: 0},
+				{Code: bpfExit},
+			},
+		},
+		{
+			description: "all devices",
+			rule:        specs.LinuxDeviceCgroup{Allow: true, Type: "a"},
+			expected: []bpfInstruction{
+				{Code: bpfMove, Immediate: 1},
+				{Code: bpfExit},
+			},
+		},
+		{
+			description:   "invalid type",
+			rule:          specs.LinuxDeviceCgroup{Allow: true, Type: "x"},
+			expectedError: true,
+		},
+		{
+			description:   "invalid access",
+			rule:          specs.LinuxDeviceCgroup{Allow: true, Type: "c", Access: "rx"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			block, err := newDeviceRuleBlock(tc.rule)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, block)
+		})
+	}
+}
+
+func TestNewDeviceFilter(t *testing.T) {
+	rules := []specs.LinuxDeviceCgroup{
+		{Allow: false, Access: "rwm"},
+		{Allow: true, Type: "c", Major: ptr(195), Minor: ptr(0), Access: "rwm"},
+	}
+
+	filter, err := newDeviceFilter(rules)
+	require.NoError(t, err)
+
+	expected := []bpfInstruction{
+		{Code: bpfLoadWord, Registers: 0x12},
+		{Code: bpfAnd, Registers: 0x02, Immediate: 0xffff},
+		{Code: bpfLoadWord, Registers: 0x13},
+		{Code: bpfRightShift, Registers: 0x03, Immediate: 16},
+		{Code: bpfLoadWord, Registers: 0x14, Offset: 4},
+		{Code: bpfLoadWord, Registers: 0x15, Offset: 8},
+		// The last rule is checked first.
+		{Code: bpfJumpNotEqual, Registers: 0x02, Offset: 4, Immediate: devcgDevChar},
+		{Code: bpfJumpNotEqual, Registers: 0x04, Offset: 3, Immediate: 195},
+		{Code: bpfJumpNotEqual, Registers: 0x05, Offset: 2, Immediate: 0},
+		{Code: bpfMove, Immediate: 1},
+		{Code: bpfExit},
+		{Code: bpfMove, Immediate: 0},
+		{Code: bpfExit},
+		// Access to devices that match no rule is denied.
+		{Code: bpfMove, Immediate: 0},
+		{Code: bpfExit},
+	}
+	require.Equal(t, expected, filter)
+}
@@ -0,0 +1,108 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// getCgroupPath returns the path of the cgroup of a process for the specified
+// controller from the contents of /proc/PID/cgroup. If the controller is
+// empty, the path in the unified hierarchy is returned.
+func getCgroupPath(contents string, controller string) (string, error) {
+	for line := range strings.SplitSeq(strings.TrimSpace(contents), "\n") {
+		// Each line has the format hierarchy-ID:controller-list:cgroup-path.
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if controller == "" {
+			if parts[0] == "0" && parts[1] == "" {
+				return parts[2], nil
+			}
+			continue
+		}
+		for c := range strings.SplitSeq(parts[1], ",") {
+			if c == controller {
+				return parts[2], nil
+			}
+		}
+	}
+	if controller == "" {
+		return "", fmt.Errorf("no cgroup found in the unified hierarchy")
+	}
+	return "", fmt.Errorf("no cgroup found for controller %q", controller)
+}
+
+// defaultDeviceRules are the device cgroup rules that runc appends to the rules
+// of every container. These devices are accessible in a container even if the
+// rules in its specification deny access.
+var defaultDeviceRules = []specs.LinuxDeviceCgroup{
+	// mknod is allowed for all devices.
+	{Allow: true, Type: "c", Access: "m"},
+	{Allow: true, Type: "b", Access: "m"},
+	{Allow: true, Type: "c", Major: ptr(1), Minor: ptr(3), Access: "rwm"},    // /dev/null
+	{Allow: true, Type: "c", Major: ptr(1), Minor: ptr(8), Access: "rwm"},    // /dev/random
+	{Allow: true, Type: "c", Major: ptr(1), Minor: ptr(7), Access: "rwm"},    // /dev/full
+	{Allow: true, Type: "c", Major: ptr(5), Minor: ptr(0), Access: "rwm"},    // /dev/tty
+	{Allow: true, Type: "c", Major: ptr(1), Minor: ptr(5), Access: "rwm"},    // /dev/zero
+	{Allow: true, Type: "c", Major: ptr(1), Minor: ptr(9), Access: "rwm"},    // /dev/urandom
+	{Allow: true, Type: "c", Major: ptr(136), Access: "rwm"},                 // /dev/pts/*
+	{Allow: true, Type: "c", Major: ptr(5), Minor: ptr(2), Access: "rwm"},    // /dev/ptmx
+	{Allow: true, Type: "c", Major: ptr(10), Minor: ptr(200), Access: "rwm"}, // /dev/net/tun
+}
+
+// getContainerDeviceRules returns the device cgroup rules of a container with
+// the specified specification followed by the specified rules. These are the
+// rules from the specification followed by the default rules of the OCI
+// runtime.
+func getContainerDeviceRules(spec *specs.Spec, rules []specs.LinuxDeviceCgroup) []specs.LinuxDeviceCgroup {
+	var containerRules []specs.LinuxDeviceCgroup
+	if spec != nil && spec.Linux != nil && spec.Linux.Resources != nil {
+		containerRules = append(containerRules, spec.Linux.Resources.Devices...)
+	}
+	containerRules = append(containerRules, defaultDeviceRules...)
+	return append(containerRules, rules...)
+}
+
+// formatV1Rule returns the specified rule in the format expected by the
+// devices.allow file of a cgroup v1 devices controller.
+func formatV1Rule(rule specs.LinuxDeviceCgroup) string {
+	deviceType := rule.Type
+	if deviceType == "" {
+		deviceType = "a"
+	}
+	access := rule.Access
+	if access == "" {
+		access = "rwm"
+	}
+	return fmt.Sprintf("%s %s:%s %s", deviceType, formatDeviceNumber(rule.Major), formatDeviceNumber(rule.Minor), access)
+}
+
+func ptr(n int64) *int64 {
+	return &n
+}
+
+func formatDeviceNumber(n *int64) string {
+	if n == nil || *n < 0 {
+		return "*"
+	}
+	return fmt.Sprintf("%d", *n)
+}
@@ -0,0 +1,141 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/sys/mountinfo"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// allowDevices allows access to the devices matching the specified rules in
+// the devices cgroup of the container.
+func (i *Injector) allowDevices(rules []specs.LinuxDeviceCgroup) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	contents, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", i.state.Pid))
+	if err != nil {
+		return err
+	}
+
+	if isUnifiedCgroupHierarchy() {
+		path, err := getCgroupPath(string(contents), "")
+		if err != nil {
+			return err
+		}
+		return i.allowDevicesV2(filepath.Join(cgroupRoot, path), rules)
+	}
+
+	path, err := getCgroupPath(string(contents), "devices")
+	if err != nil {
+		return err
+	}
+	mount, err := getDevicesCgroupMount()
+	if err != nil {
+		return err
+	}
+	relativePath, err := filepath.Rel(mount.Root, path)
+	if err != nil {
+		return err
+	}
+	return i.allowDevicesV1(filepath.Join(mount.Mountpoint, relativePath), rules)
+}
+
+func isUnifiedCgroupHierarchy() bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(cgroupRoot, &st); err != nil {
+		return false
+	}
+	return st.Type == unix.CGROUP2_SUPER_MAGIC
+}
+
+// getDevicesCgroupMount returns the mount of the cgroup v1 devices controller.
+func getDevicesCgroupMount() (*mountinfo.Info, error) {
+	mounts, err := mountinfo.GetMounts(mountinfo.FSTypeFilter("cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mounts {
+		for option := range strings.SplitSeq(m.VFSOptions, ",") {
+			if option == "devices" {
+				return m, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no mount found for the devices cgroup")
+}
+
+// allowDevicesV1 writes the specified rules to the devices.allow file of the
+// cgroup at the specified path.
+func (i *Injector) allowDevicesV1(path string, rules []specs.LinuxDeviceCgroup) error {
+	allow := filepath.Join(path, "devices.allow")
+	for _, rule := range rules {
+		r := formatV1Rule(rule)
+		if err := os.WriteFile(allow, []byte(r), 0); err != nil {
+			return fmt.Errorf("failed to allow %q: %w", r, err)
+		}
+		i.logger.Debugf("Allowed %q in %v", r, path)
+	}
+	return nil
+}
+
+// allowDevicesV2 updates the device filters attached to the cgroup at the
+// specified path to allow access to the devices matching the specified rules.
+// Since a device is only accessible if all attached filters allow access, each
+// of the filters is replaced by a filter that is built from the device rules of
+// the container as is done by the OCI runtime, followed by the specified
+// rules.
+func (i *Injector) allowDevicesV2(path string, rules []specs.LinuxDeviceCgroup) error {
+	cgroup, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer cgroup.Close()
+
+	ids, attachFlags, err := queryDeviceFilters(int(cgroup.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to query device filters: %w", err)
+	}
+	if len(ids) == 0 {
+		i.logger.Debugf("No device filters attached to %v", path)
+		return nil
+	}
+
+	spec, err := i.loadContainerSpec()
+	if err != nil {
+		return err
+	}
+	filter, err := newDeviceFilter(getContainerDeviceRules(spec, rules))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := replaceDeviceFilter(int(cgroup.Fd()), id, attachFlags, filter); err != nil {
+			return fmt.Errorf("failed to replace device filter %d: %w", id, err)
+		}
+		i.logger.Debugf("Replaced device filter %d attached to %v", id, path)
+	}
+	return nil
+}
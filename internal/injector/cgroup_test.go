@@ -0,0 +1,145 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCgroupPath(t *testing.T) {
+	const v1 = `12:memory:/docker/abc
+11:devices:/docker/abc
+1:name=systemd:/docker/abc
+0::/`
+
+	testCases := []struct {
+		description   string
+		contents      string
+		controller    string
+		expectedPath  string
+		expectedError bool
+	}{
+		{
+			description:  "unified hierarchy",
+			contents:     "0::/system.slice/docker-abc.scope\n",
+			expectedPath: "/system.slice/docker-abc.scope",
+		},
+		{
+			description:  "v1 devices controller",
+			contents:     v1,
+			controller:   "devices",
+			expectedPath: "/docker/abc",
+		},
+		{
+			description:  "controller in list",
+			contents:     "4:cpu,cpuacct,devices:/a/b",
+			controller:   "devices",
+			expectedPath: "/a/b",
+		},
+		{
+			description:   "missing controller",
+			contents:      "0::/a/b",
+			controller:    "devices",
+			expectedError: true,
+		},
+		{
+			description:   "missing unified hierarchy",
+			contents:      "11:devices:/docker/abc",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			path, err := getCgroupPath(tc.contents, tc.controller)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedPath, path)
+		})
+	}
+}
+
+func TestFormatV1Rule(t *testing.T) {
+	testCases := []struct {
+		description string
+		rule        specs.LinuxDeviceCgroup
+		expected    string
+	}{
+		{
+			description: "char device",
+			rule:        specs.LinuxDeviceCgroup{Allow: true, Type: "c", Major: ptr(195), Minor: ptr(0), Access: "rw"},
+			expected:    "c 195:0 rw",
+		},
+		{
+			description: "wildcards",
+			rule:        specs.LinuxDeviceCgroup{Allow: true, Type: "c", Major: ptr(195)},
+			expected:    "c 195:* rwm",
+		},
+		{
+			description: "all devices",
+			rule:        specs.LinuxDeviceCgroup{Allow: true},
+			expected:    "a *:* rwm",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, formatV1Rule(tc.rule))
+		})
+	}
+}
+
+func TestGetContainerDeviceRules(t *testing.T) {
+	deny := specs.LinuxDeviceCgroup{Allow: false, Access: "rwm"}
+	gpu := specs.LinuxDeviceCgroup{Allow: true, Type: "c", Major: ptr(195), Minor: ptr(0), Access: "rw"}
+
+	testCases := []struct {
+		description string
+		spec        *specs.Spec
+		expected    []specs.LinuxDeviceCgroup
+	}{
+		{
+			description: "rules from spec come first",
+			spec: &specs.Spec{
+				Linux: &specs.Linux{
+					Resources: &specs.LinuxResources{
+						Devices: []specs.LinuxDeviceCgroup{deny},
+					},
+				},
+			},
+			expected: append(append([]specs.LinuxDeviceCgroup{deny}, defaultDeviceRules...), gpu),
+		},
+		{
+			description: "spec without resources",
+			spec:        &specs.Spec{},
+			expected:    append(append([]specs.LinuxDeviceCgroup{}, defaultDeviceRules...), gpu),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rules := getContainerDeviceRules(tc.spec, []specs.LinuxDeviceCgroup{gpu})
+			require.Equal(t, tc.expected, rules)
+		})
+	}
+}
@@ -0,0 +1,243 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+// An Injector applies the modifications defined in an OCI runtime
+// specification to a container that has already been created. This allows the
+// modifications that are made to the specification of a container in CDI mode
+// to be made from a prestart hook instead.
+//
+// Mounts and device nodes are created in the mount namespace of the container,
+// access to the device nodes is allowed in the devices cgroup of the
+// container, and the hooks that would be run before the container is started
+// are run. Other modifications, such as setting environment variables, cannot
+// be made once a container has been created and are ignored.
+type Injector struct {
+	logger    logger.Interface
+	state     specs.State
+	rootfs    string
+	noCgroups bool
+}
+
+// Option is a functional option for constructing an Injector.
+type Option func(*Injector)
+
+// New creates an Injector for the container with the specified state.
+func New(opts ...Option) (*Injector, error) {
+	i := &Injector{}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.logger == nil {
+		i.logger = logger.New()
+	}
+	if i.state.Pid <= 0 {
+		return nil, fmt.Errorf("a container pid must be specified")
+	}
+	if !filepath.IsAbs(i.rootfs) {
+		return nil, fmt.Errorf("the container rootfs must be an absolute path; got %q", i.rootfs)
+	}
+	return i, nil
+}
+
+// WithLogger sets the logger for the Injector.
+func WithLogger(logger logger.Interface) Option {
+	return func(i *Injector) {
+		i.logger = logger
+	}
+}
+
+// WithContainerState sets the state of the container to modify. The state is
+// also passed to the hooks that are run and the specification of the container
+// is loaded from its bundle.
+func WithContainerState(state specs.State) Option {
+	return func(i *Injector) {
+		i.state = state
+	}
+}
+
+// WithRootfs sets the absolute path to the root filesystem of the container.
+func WithRootfs(rootfs string) Option {
+	return func(i *Injector) {
+		i.rootfs = rootfs
+	}
+}
+
+// WithNoCgroups disables the updating of the devices cgroup of the container.
+func WithNoCgroups(noCgroups bool) Option {
+	return func(i *Injector) {
+		i.noCgroups = noCgroups
+	}
+}
+
+// Inject applies the specified modifications to the container.
+func (i *Injector) Inject(modifications *specs.Spec) error {
+	if modifications == nil {
+		return nil
+	}
+	i.warnIgnored(modifications)
+
+	if i.noCgroups {
+		i.logger.Debugf("Skipping update of devices cgroup")
+	} else if err := i.allowDevices(getDeviceRules(modifications)); err != nil {
+		return fmt.Errorf("failed to update devices cgroup: %w", err)
+	}
+
+	return withMountNamespace(i.state.Pid, func() error {
+		for _, m := range modifications.Mounts {
+			if err := i.createMount(m); err != nil {
+				return fmt.Errorf("failed to mount %v: %w", m.Destination, err)
+			}
+		}
+		for _, d := range getDeviceNodes(modifications) {
+			if err := i.createDeviceNode(d); err != nil {
+				return fmt.Errorf("failed to create device node %v: %w", d.Path, err)
+			}
+		}
+		return i.runHooks(getHooks(modifications))
+	})
+}
+
+// warnIgnored logs a warning for modifications that cannot be applied to a
+// created container.
+func (i *Injector) warnIgnored(modifications *specs.Spec) {
+	if modifications.Process != nil && len(modifications.Process.Env) > 0 {
+		i.logger.Warningf("Ignoring environment variables %v", modifications.Process.Env)
+	}
+	if modifications.Process != nil && len(modifications.Process.User.AdditionalGids) > 0 {
+		i.logger.Warningf("Ignoring additional GIDs %v", modifications.Process.User.AdditionalGids)
+	}
+	if h := modifications.Hooks; h != nil {
+		for _, hook := range append(append(h.StartContainer, h.Poststart...), h.Poststop...) {
+			i.logger.Warningf("Ignoring hook %v that is not run before the container is started", hook.Path)
+		}
+	}
+}
+
+// runHooks runs the specified hooks in order. The container state is passed
+// to each hook on STDIN.
+func (i *Injector) runHooks(hooks []specs.Hook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	state, err := json.Marshal(i.state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal container state: %w", err)
+	}
+	for _, hook := range hooks {
+		if err := i.runHook(hook, state); err != nil {
+			return fmt.Errorf("failed to run hook %v: %w", strings.Join(hook.Args, " "), err)
+		}
+	}
+	return nil
+}
+
+func (i *Injector) runHook(hook specs.Hook, state []byte) error {
+	ctx := context.Background()
+	if hook.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*hook.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	//nolint:gosec // The hooks are generated by the nvcdi package.
+	cmd := exec.CommandContext(ctx, hook.Path)
+	if len(hook.Args) > 0 {
+		cmd.Args = hook.Args
+	}
+	cmd.Env = hook.Env
+	cmd.Stdin = bytes.NewReader(state)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	i.logger.Debugf("Running hook %v", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(output.Bytes()))
+	}
+	return nil
+}
+
+// loadContainerSpec loads the OCI runtime specification of the container from
+// its bundle.
+func (i *Injector) loadContainerSpec() (*specs.Spec, error) {
+	if i.state.Bundle == "" {
+		return nil, fmt.Errorf("the bundle of the container is not known")
+	}
+	contents, err := os.ReadFile(filepath.Join(i.state.Bundle, "config.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container specification: %w", err)
+	}
+	var spec specs.Spec
+	if err := json.Unmarshal(contents, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse container specification: %w", err)
+	}
+	return &spec, nil
+}
+
+// getDeviceRules returns the device cgroup rules that allow access to devices.
+func getDeviceRules(modifications *specs.Spec) []specs.LinuxDeviceCgroup {
+	if modifications.Linux == nil || modifications.Linux.Resources == nil {
+		return nil
+	}
+	var rules []specs.LinuxDeviceCgroup
+	for _, rule := range modifications.Linux.Resources.Devices {
+		if !rule.Allow {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func getDeviceNodes(modifications *specs.Spec) []specs.LinuxDevice {
+	if modifications.Linux == nil {
+		return nil
+	}
+	return modifications.Linux.Devices
+}
+
+// getHooks returns the hooks that are run before the container is started in
+// the order in which these are run by an OCI runtime.
+func getHooks(modifications *specs.Spec) []specs.Hook {
+	h := modifications.Hooks
+	if h == nil {
+		return nil
+	}
+	var hooks []specs.Hook
+	//nolint:staticcheck // Prestart hooks are still used by some CDI specifications.
+	hooks = append(hooks, h.Prestart...)
+	hooks = append(hooks, h.CreateRuntime...)
+	hooks = append(hooks, h.CreateContainer...)
+	return hooks
+}
@@ -0,0 +1,41 @@
+//go:build !linux
+
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"fmt"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func withMountNamespace(pid int, fn func() error) error {
+	return fmt.Errorf("entering the mount namespace of a container is only supported on linux")
+}
+
+func (i *Injector) createMount(m specs.Mount) error {
+	return fmt.Errorf("creating mounts is only supported on linux")
+}
+
+func (i *Injector) createDeviceNode(d specs.LinuxDevice) error {
+	return fmt.Errorf("creating device nodes is only supported on linux")
+}
+
+func (i *Injector) allowDevices(rules []specs.LinuxDeviceCgroup) error {
+	return fmt.Errorf("updating the devices cgroup is only supported on linux")
+}
@@ -0,0 +1,79 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+func TestRunHooks(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "state.json")
+
+	i, err := New(
+		WithLogger(logger.New()),
+		WithContainerState(specs.State{
+			Version: specs.Version,
+			ID:      "container-id",
+			Status:  specs.StateCreating,
+			Pid:     1234,
+			Bundle:  "/bundle",
+		}),
+		WithRootfs("/bundle/rootfs"),
+	)
+	require.NoError(t, err)
+
+	modifications := &specs.Spec{
+		Hooks: &specs.Hooks{
+			CreateContainer: []specs.Hook{
+				{
+					Path: "/bin/sh",
+					Args: []string{"sh", "-c", "cat > " + output},
+				},
+			},
+		},
+	}
+	require.NoError(t, i.runHooks(getHooks(modifications)))
+
+	state, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{"ociVersion":"`+specs.Version+`","id":"container-id","status":"creating","pid":1234,"bundle":"/bundle"}`,
+		string(state),
+	)
+
+	modifications.Hooks.CreateContainer = append(modifications.Hooks.CreateContainer, specs.Hook{
+		Path: "/bin/sh",
+		Args: []string{"sh", "-c", "echo failed >&2; exit 1"},
+	})
+	err = i.runHooks(getHooks(modifications))
+	require.ErrorContains(t, err, "failed")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(WithRootfs("/rootfs"))
+	require.Error(t, err)
+
+	_, err = New(WithContainerState(specs.State{Pid: 1}), WithRootfs("rootfs"))
+	require.Error(t, err)
+}
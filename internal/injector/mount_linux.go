@@ -0,0 +1,238 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package injector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+// withMountNamespace calls the specified function from a thread in the mount
+// namespace of the specified process.
+//
+// The OS thread is intentionally not unlocked. This ensures that the thread is
+// terminated once the goroutine exits instead of a thread in the mount
+// namespace of the container being reused.
+func withMountNamespace(pid int, fn func() error) error {
+	errs := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		errs <- enterMountNamespace(pid, fn)
+	}()
+	return <-errs
+}
+
+func enterMountNamespace(pid int, fn func() error) error {
+	ns, err := os.Open(fmt.Sprintf("/proc/%d/ns/mnt", pid))
+	if err != nil {
+		return fmt.Errorf("failed to open mount namespace: %w", err)
+	}
+	defer ns.Close()
+
+	// The threads of a process share their filesystem attributes. These must be
+	// unshared before the mount namespace of a single thread can be changed.
+	if err := unix.Unshare(unix.CLONE_FS); err != nil {
+		return fmt.Errorf("failed to unshare filesystem attributes: %w", err)
+	}
+	if err := unix.Setns(int(ns.Fd()), unix.CLONE_NEWNS); err != nil {
+		return fmt.Errorf("failed to enter mount namespace: %w", err)
+	}
+	return fn()
+}
+
+// createMount creates the specified mount in the rootfs of the container.
+// Bind mounts are first mounted and then remounted to apply flags such as
+// MS_RDONLY.
+func (i *Injector) createMount(m specs.Mount) error {
+	target, err := securejoin.SecureJoin(i.rootfs, m.Destination)
+	if err != nil {
+		return err
+	}
+	options := parseMountOptions(m.Options)
+
+	if options.flags&unix.MS_BIND == 0 {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		if err := unix.Mount(m.Source, target, m.Type, options.flags, options.data); err != nil {
+			return err
+		}
+	} else {
+		if err := createMountTarget(m.Source, target); err != nil {
+			return err
+		}
+		if err := unix.Mount(m.Source, target, "", options.flags&(unix.MS_BIND|unix.MS_REC), ""); err != nil {
+			return err
+		}
+		if remountFlags := options.flags &^ (unix.MS_BIND | unix.MS_REC); remountFlags != 0 {
+			if err := unix.Mount("", target, "", unix.MS_BIND|unix.MS_REMOUNT|remountFlags, ""); err != nil {
+				return fmt.Errorf("failed to remount: %w", err)
+			}
+		}
+	}
+
+	for _, propagation := range options.propagation {
+		if err := unix.Mount("", target, "", propagation, ""); err != nil {
+			return fmt.Errorf("failed to set propagation: %w", err)
+		}
+	}
+	i.logger.Debugf("Mounted %v at %v", m.Source, m.Destination)
+	return nil
+}
+
+// createMountTarget creates a directory or an empty file at the target
+// depending on the type of the source of a bind mount.
+func createMountTarget(source string, target string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// createDeviceNode creates the specified device node in the rootfs of the
+// container. An existing device node is replaced.
+func (i *Injector) createDeviceNode(d specs.LinuxDevice) error {
+	path, err := securejoin.SecureJoin(i.rootfs, d.Path)
+	if err != nil {
+		return err
+	}
+
+	var mode uint32
+	switch d.Type {
+	case "c", "u":
+		mode = unix.S_IFCHR
+	case "b":
+		mode = unix.S_IFBLK
+	case "p":
+		mode = unix.S_IFIFO
+	default:
+		return fmt.Errorf("unsupported device type %q", d.Type)
+	}
+	perm := os.FileMode(0666)
+	if d.FileMode != nil {
+		perm = d.FileMode.Perm()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := unix.Mknod(path, mode|uint32(perm), int(unix.Mkdev(uint32(d.Major), uint32(d.Minor)))); err != nil { //nolint:gosec
+		return err
+	}
+	// The permissions are set explicitly since mknod is subject to the umask.
+	if err := os.Chmod(path, perm); err != nil {
+		return err
+	}
+
+	uid, gid := -1, -1
+	if d.UID != nil {
+		uid = int(*d.UID)
+	}
+	if d.GID != nil {
+		gid = int(*d.GID)
+	}
+	if err := os.Lchown(path, uid, gid); err != nil {
+		return err
+	}
+	i.logger.Debugf("Created device node %v", d.Path)
+	return nil
+}
+
+type mountOptions struct {
+	flags       uintptr
+	propagation []uintptr
+	data        string
+}
+
+var mountFlags = map[string]struct {
+	clear bool
+	flag  uintptr
+}{
+	"bind":        {false, unix.MS_BIND},
+	"rbind":       {false, unix.MS_BIND | unix.MS_REC},
+	"ro":          {false, unix.MS_RDONLY},
+	"rw":          {true, unix.MS_RDONLY},
+	"nosuid":      {false, unix.MS_NOSUID},
+	"suid":        {true, unix.MS_NOSUID},
+	"nodev":       {false, unix.MS_NODEV},
+	"dev":         {true, unix.MS_NODEV},
+	"noexec":      {false, unix.MS_NOEXEC},
+	"exec":        {true, unix.MS_NOEXEC},
+	"noatime":     {false, unix.MS_NOATIME},
+	"atime":       {true, unix.MS_NOATIME},
+	"relatime":    {false, unix.MS_RELATIME},
+	"norelatime":  {true, unix.MS_RELATIME},
+	"strictatime": {false, unix.MS_STRICTATIME},
+	"nosymfollow": {false, unix.MS_NOSYMFOLLOW},
+}
+
+var propagationFlags = map[string]uintptr{
+	"private":     unix.MS_PRIVATE,
+	"rprivate":    unix.MS_PRIVATE | unix.MS_REC,
+	"shared":      unix.MS_SHARED,
+	"rshared":     unix.MS_SHARED | unix.MS_REC,
+	"slave":       unix.MS_SLAVE,
+	"rslave":      unix.MS_SLAVE | unix.MS_REC,
+	"unbindable":  unix.MS_UNBINDABLE,
+	"runbindable": unix.MS_UNBINDABLE | unix.MS_REC,
+}
+
+// parseMountOptions converts the options of an OCI mount to mount flags.
+// Options that are not flags are passed to the filesystem as data.
+func parseMountOptions(options []string) mountOptions {
+	var m mountOptions
+	var data []string
+	for _, option := range options {
+		if f, ok := mountFlags[option]; ok {
+			if f.clear {
+				m.flags &^= f.flag
+			} else {
+				m.flags |= f.flag
+			}
+			continue
+		}
+		if p, ok := propagationFlags[option]; ok {
+			m.propagation = append(m.propagation, p)
+			continue
+		}
+		data = append(data, option)
+	}
+	m.data = strings.Join(data, ",")
+	return m
+}
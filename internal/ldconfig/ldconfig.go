@@ -67,6 +67,8 @@ type Ldconfig struct {
 }
 
 // NewRunner creates an exec.Cmd that can be used to run ldconfig.
+// The command must be run with the calling goroutine locked to its OS thread
+// since it is killed when the thread that started it exits.
 func NewRunner(id string, ldconfigPath string, containerRoot string, additionalargs ...string) (*exec.Cmd, error) {
 	args := []string{
 		id,
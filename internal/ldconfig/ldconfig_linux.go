@@ -238,7 +238,10 @@ func createTmpFs(target string, size int) error {
 
 // createReexecCommand creates a command that can be used to trigger the reexec
 // initializer.
-// On linux this command runs in new namespaces.
+// On linux this command runs in new namespaces and is killed when the thread
+// that started it exits. Since the Go runtime may terminate idle threads, the
+// command must be started and waited for with the calling goroutine locked to
+// its OS thread (see runtime.LockOSThread).
 func createReexecCommand(args []string) (*exec.Cmd, error) {
 	cmd := reexec.Command(args...)
 	cmd.Stdin = os.Stdin
@@ -251,6 +254,10 @@ func createReexecCommand(args []string) (*exec.Cmd, error) {
 			syscall.CLONE_NEWIPC |
 			syscall.CLONE_NEWPID |
 			syscall.CLONE_NEWNET,
+		// The command is killed if the hook exits, for example because
+		// the hook timed out. Note that the signal is sent when the thread
+		// that started the command exits, not the process.
+		Pdeathsig: syscall.SIGKILL,
 	}
 
 	return cmd, nil
@@ -0,0 +1,49 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// The following field names are included in structured log records. These
+// names are considered stable and can be relied upon by log processors.
+const (
+	FieldContainerID      = "containerID"
+	FieldRuntimeMode      = "runtimeMode"
+	FieldRequestedDevices = "requestedDevices"
+	FieldModifier         = "modifier"
+	FieldElapsed          = "elapsed"
+)
+
+// Fields defines a set of structured fields to include in log records.
+type Fields map[string]any
+
+// WithFields returns a logger that includes the specified fields in each log
+// record. If the logger does not support structured fields, the logger is
+// returned as is.
+func WithFields(l Interface, fields Fields) Interface {
+	switch l := l.(type) {
+	case interface{ WithFields(Fields) Interface }:
+		return l.WithFields(fields)
+	case interface {
+		WithFields(logrus.Fields) *logrus.Entry
+	}:
+		return l.WithFields(logrus.Fields(fields))
+	}
+	return l
+}
@@ -0,0 +1,57 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package logger
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatText is the default log format.
+	FormatText = "text"
+	// FormatJSON outputs a single JSON object per log record.
+	FormatJSON = "json"
+)
+
+// ValidateFormat checks whether the specified log format is supported. An
+// empty format is considered valid and selects the default format.
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatText, FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unsupported log format %q; one of [%v, %v] is expected", format, FormatText, FormatJSON)
+}
+
+// ConfigureFormat sets the format of the log records for the specified
+// logger. A format specified explicitly (e.g. on the command line) takes
+// precedence over the format returned by fromConfig which is only queried if
+// required.
+func ConfigureFormat(l *logrus.Logger, format string, fromConfig func() string) error {
+	if format == "" && fromConfig != nil {
+		format = fromConfig()
+	}
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+	if format == FormatJSON {
+		l.SetFormatter(new(logrus.JSONFormatter))
+	}
+	return nil
+}
@@ -21,12 +21,17 @@ import (
 	"strings"
 
 	"tags.cncf.io/container-device-interface/pkg/parser"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/modifier/cdi"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/oci"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/platform-support/tegra/csv"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/speccache"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
 )
 
@@ -70,11 +75,16 @@ func (f *Factory) newCDIModifier(isJitCDI bool) (oci.SpecModifier, error) {
 	}
 
 	f.logger.Debugf("Creating CDI modifier for devices: %v", devices)
-	return cdi.New(
+	modifier, err := cdi.New(
 		cdi.WithLogger(f.logger),
 		cdi.WithDevices(devices...),
 		cdi.WithSpecDirs(f.cfg.NVIDIAContainerRuntimeConfig.Modes.CDI.SpecDirs...),
 	)
+	if err != nil {
+		return nil, err
+	}
+	f.recordInjectedDevices(devices...)
+	return modifier, nil
 }
 
 // newJitCDIModifier creates a modifier that for a generated in-memory CDI spec for the specified CDI devices.
@@ -187,43 +197,103 @@ func (f *Factory) newAutomaticCDISpecModifier(devices []string) (oci.SpecModifie
 	f.logger.Debugf("Per-mode identifiers: %v", cdiModeIdentifiers)
 	var modifiers oci.SpecModifiers
 	for _, mode := range cdiModeIdentifiers.modes {
-		cdilib, err := nvcdi.New(
-			nvcdi.WithLogger(f.logger),
-			nvcdi.WithNVIDIACDIHookPath(f.cfg.NVIDIACTKConfig.Path),
-			nvcdi.WithDriverRoot(f.driver.Root),
-			nvcdi.WithDevRoot(f.driver.DevRoot),
-			nvcdi.WithEditsFactory(f.editsFactory),
-			nvcdi.WithVendor(automaticDeviceVendor),
-			nvcdi.WithClass(cdiModeIdentifiers.deviceClassByMode[mode]),
-			nvcdi.WithMode(mode),
-			nvcdi.WithFeatureFlags(f.cfg.NVIDIAContainerRuntimeConfig.Modes.JitCDI.NVCDIFeatureFlags...),
-			nvcdi.WithCSVCompatContainerRoot(f.cfg.NVIDIAContainerRuntimeConfig.Modes.CSV.CompatContainerRoot),
-			nvcdi.WithCSVFiles(csvFiles),
-			nvcdi.WithDisabledHooks(f.cfg.NVIDIAContainerRuntimeConfig.Modes.JitCDI.NVCDIDisableHooks...),
-		)
+		spec, err := f.getJitCDISpec(mode, cdiModeIdentifiers.deviceClassByMode[mode], cdiModeIdentifiers.idsByMode[mode], csvFiles)
 		if err != nil {
-			return nil, fmt.Errorf("failed to construct CDI library for mode %q: %w", mode, err)
-		}
-
-		spec, err := cdilib.GetSpec(cdiModeIdentifiers.idsByMode[mode]...)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate CDI spec for mode %q: %w", mode, err)
+			return nil, err
 		}
 
 		cdiDeviceRequestor, err := cdi.New(
 			cdi.WithLogger(f.logger),
-			cdi.WithSpec(spec.Raw()),
+			cdi.WithSpec(spec),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to construct CDI modifier for mode %q: %w", mode, err)
 		}
 
 		modifiers = append(modifiers, cdiDeviceRequestor)
+		for _, device := range spec.Devices {
+			f.recordInjectedDevices(spec.Kind + "=" + device.Name)
+		}
 	}
 
 	return modifiers, nil
 }
 
+// getJitCDISpec returns the CDI spec for the specified devices of the specified
+// mode. If a spec cache is configured, a cached spec is returned if available
+// and a generated spec is added to the cache.
+func (f *Factory) getJitCDISpec(mode string, class string, ids []string, csvFiles []string) (*specs.Spec, error) {
+	var key string
+	if f.specCache != nil {
+		var err error
+		key, err = speccache.Key(jitCDISpecCacheKey{
+			Version:  info.GetVersionString(),
+			Mode:     mode,
+			Class:    class,
+			IDs:      ids,
+			CSVFiles: csvFiles,
+			Config:   f.cfg,
+		})
+		if err != nil {
+			f.logger.Warningf("Not using cached CDI spec for mode %q: %v", mode, err)
+		}
+	}
+	if key != "" {
+		if spec := f.specCache.Get(key); spec != nil {
+			f.logger.Debugf("Using cached CDI spec for mode %q", mode)
+			return spec, nil
+		}
+	}
+
+	hookPolicies, err := f.cfg.NVIDIACTKConfig.HookPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("invalid hook config: %w", err)
+	}
+
+	cdilib, err := nvcdi.New(
+		nvcdi.WithLogger(f.logger),
+		nvcdi.WithNVIDIACDIHookPath(f.cfg.NVIDIACTKConfig.Path),
+		nvcdi.WithDriverRoot(f.driver.Root),
+		nvcdi.WithDevRoot(f.driver.DevRoot),
+		nvcdi.WithEditsFactory(f.editsFactory),
+		nvcdi.WithVendor(automaticDeviceVendor),
+		nvcdi.WithClass(class),
+		nvcdi.WithMode(mode),
+		nvcdi.WithFeatureFlags(f.cfg.NVIDIAContainerRuntimeConfig.Modes.JitCDI.NVCDIFeatureFlags...),
+		nvcdi.WithCSVCompatContainerRoot(f.cfg.NVIDIAContainerRuntimeConfig.Modes.CSV.CompatContainerRoot),
+		nvcdi.WithCSVFiles(csvFiles),
+		nvcdi.WithDisabledHooks(f.cfg.NVIDIAContainerRuntimeConfig.Modes.JitCDI.NVCDIDisableHooks...),
+		nvcdi.WithHookPolicies(hookPolicies),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct CDI library for mode %q: %w", mode, err)
+	}
+
+	spec, err := cdilib.GetSpec(ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CDI spec for mode %q: %w", mode, err)
+	}
+
+	if key != "" {
+		if err := f.specCache.Put(key, spec.Raw()); err != nil {
+			f.logger.Warningf("Failed to cache CDI spec for mode %q: %v", mode, err)
+		}
+	}
+	return spec.Raw(), nil
+}
+
+// jitCDISpecCacheKey defines the inputs that determine the contents of a
+// generated CDI spec. Changes to the driver and the devices on the system are
+// handled by the spec cache itself.
+type jitCDISpecCacheKey struct {
+	Version  string
+	Mode     string
+	Class    string
+	IDs      []string
+	CSVFiles []string
+	Config   *config.Config
+}
+
 type cdiModeIdentifiers struct {
 	modes             []string
 	idsByMode         map[string][]string
@@ -23,19 +23,24 @@ import (
 	"github.com/opencontainers/runtime-spec/specs-go"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/audit"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/edits"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/proc"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/oci"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/policy"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/speccache"
 )
 
 // factoryOptions define the set of options that must be set when constructing
 // a modifier factory.
 type factoryOptions struct {
 	logger      logger.Interface
+	auditor     audit.Interface
 	cfg         *config.Config
 	driver      *root.Driver
 	hookCreator discover.HookCreator
@@ -47,6 +52,14 @@ type Factory struct {
 	factoryOptions
 	// An editsFactory is created at construction.
 	editsFactory edits.Factory
+	// A policy is created from the configured rules at construction.
+	policy policy.Interface
+	// A specCache is created at construction if a jit-cdi spec cache
+	// directory is configured.
+	specCache speccache.Interface
+	// injectedDevices records the CDI devices injected by the created
+	// modifiers.
+	injectedDevices []string
 }
 
 // A Factory also implements the oci.SpecModifier interface.
@@ -73,6 +86,16 @@ func createFactory(opts ...Option) *Factory {
 		edits.WithLogger(f.logger),
 		edits.WithNoAdditionalGIDsForDeviceNodes(f.cfg.Features.NoAdditionalGIDsForDeviceNodes.IsEnabled()),
 	)
+	f.policy = policy.New(
+		policy.WithLogger(f.logger),
+		policy.WithRules(f.cfg.Policy.Rules...),
+		policy.WithDeviceCounter(countGPUs),
+	)
+	f.specCache = speccache.New(
+		speccache.WithLogger(f.logger),
+		speccache.WithDir(f.cfg.NVIDIAContainerRuntimeConfig.Modes.JitCDI.SpecCacheDir),
+		speccache.WithDriver(f.driver),
+	)
 
 	return f
 }
@@ -93,7 +116,23 @@ func (f *Factory) validate() error {
 
 // Modify creates the configured modifier and applies it to the supplied OCI
 // specification.
-func (f *Factory) Modify(s *specs.Spec) error {
+// In the CDI-based modes, the requirements of the container are checked
+// before the modifier is created.
+// If an audit sink is configured, a record of the device requests and the
+// injected CDI devices is written.
+func (f *Factory) Modify(s *specs.Spec) (rerr error) {
+	defer func() {
+		f.audit(rerr)
+	}()
+
+	if err := f.checkPolicy(s); err != nil {
+		return fmt.Errorf("device request denied: %w", err)
+	}
+
+	if err := f.assertRequirements(); err != nil {
+		return fmt.Errorf("requirements not met: %w", err)
+	}
+
 	m, err := f.create()
 	if err != nil {
 		return err
@@ -111,21 +150,21 @@ func (f *Factory) create() (oci.SpecModifier, error) {
 			if err != nil {
 				return nil, err
 			}
-			modifiers = append(modifiers, modeModifier)
+			modifiers = append(modifiers, f.withTiming(modifierType, modeModifier))
 		case "nvidia-hook-remover":
-			modifiers = append(modifiers, f.newNvidiaContainerRuntimeHookRemover())
+			modifiers = append(modifiers, f.withTiming(modifierType, f.newNvidiaContainerRuntimeHookRemover()))
 		case "graphics":
 			graphicsModifier, err := f.newGraphicsModifier()
 			if err != nil {
 				return nil, err
 			}
-			modifiers = append(modifiers, graphicsModifier)
+			modifiers = append(modifiers, f.withTiming(modifierType, graphicsModifier))
 		case "feature-gated":
 			featureGatedModifier, err := f.newFeatureGatedModifier()
 			if err != nil {
 				return nil, err
 			}
-			modifiers = append(modifiers, featureGatedModifier)
+			modifiers = append(modifiers, f.withTiming(modifierType, featureGatedModifier))
 		default:
 			f.logger.Debugf("Ignoring unknown modifier type %q", modifierType)
 		}
@@ -133,8 +172,76 @@ func (f *Factory) create() (oci.SpecModifier, error) {
 	return modifiers, nil
 }
 
+// checkPolicy checks the devices and IMEX channels requested by the container
+// against the configured policy rules. Device names that are not fully
+// qualified are qualified using the default kind for the runtime mode.
+func (f *Factory) checkPolicy(s *specs.Spec) error {
+	if f.image == nil {
+		return nil
+	}
+
+	defaultKind := f.cfg.NVIDIAContainerRuntimeConfig.Modes.CDI.DefaultKind
+	if f.runtimeMode == info.JitCDIRuntimeMode {
+		defaultKind = automaticDeviceKind
+	}
+	request := policy.Request{
+		Devices:      newCDIDeviceRequestor(f.logger, f.image, defaultKind).DeviceRequests(),
+		ImexChannels: f.image.ImexChannelRequests(),
+	}
+	return f.policy.Check(policy.NewContainerFromSpec(s), request)
+}
+
+// countGPUs returns the number of GPUs on the host as listed in
+// /proc/driver/nvidia/gpus. This is used to count a request for all devices
+// against the configured policy rules.
+func countGPUs() (int, error) {
+	gpus, err := proc.GetInformationFilePaths("/")
+	if err != nil {
+		return 0, err
+	}
+	return len(gpus), nil
+}
+
+// audit writes a record of the device injection decisions to the configured
+// audit sink. Failures to write the record are logged, but do not prevent the
+// container from being created.
+func (f *Factory) audit(modifyErr error) {
+	if f.auditor == nil {
+		return
+	}
+
+	record := &audit.Record{
+		RuntimeMode:     string(f.runtimeMode),
+		InjectedDevices: f.injectedDevices,
+	}
+	if f.image != nil {
+		record.Privileged = f.image.IsPrivileged()
+		record.Requests = f.image.DeviceRequests()
+	}
+	if modifyErr != nil {
+		record.Error = modifyErr.Error()
+	}
+
+	if err := f.auditor.Record(record); err != nil {
+		f.logger.Warningf("Failed to write audit record: %v", err)
+	}
+}
+
+// recordInjectedDevices records the specified CDI devices as injected.
+func (f *Factory) recordInjectedDevices(devices ...string) {
+	f.injectedDevices = append(f.injectedDevices, devices...)
+}
+
 type Option func(*factoryOptions)
 
+// WithAuditor sets the sink to which a record of the device injection
+// decisions is written.
+func WithAuditor(auditor audit.Interface) Option {
+	return func(f *factoryOptions) {
+		f.auditor = auditor
+	}
+}
+
 func WithConfig(cfg *config.Config) Option {
 	return func(f *factoryOptions) {
 		f.cfg = cfg
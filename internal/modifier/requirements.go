@@ -0,0 +1,143 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package modifier
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/pkg/parser"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements"
+)
+
+// assertRequirements checks the NVIDIA_REQUIRE_* requirements of the
+// container against the properties of the host and the requested devices.
+// This is only done in the CDI-based modes since the requirements are checked
+// by the NVIDIA Container Runtime Hook in legacy mode and when the CSV
+// modifier is created in csv mode.
+func (f *Factory) assertRequirements() error {
+	if f.runtimeMode != info.CDIRuntimeMode && f.runtimeMode != info.JitCDIRuntimeMode {
+		return nil
+	}
+	if f.image == nil || f.image.HasDisableRequire() {
+		f.logger.Debugf("NVIDIA_DISABLE_REQUIRE=%v; skipping requirement checks", true)
+		return nil
+	}
+
+	imageRequirements, err := f.image.GetRequirements()
+	if err != nil {
+		return fmt.Errorf("failed to get image requirements: %w", err)
+	}
+	// NVML is only loaded if there are requirements to check.
+	imageRequirements = slices.DeleteFunc(imageRequirements, func(r string) bool {
+		return strings.TrimSpace(r) == ""
+	})
+	if len(imageRequirements) == 0 {
+		return nil
+	}
+
+	defaultKind := f.cfg.NVIDIAContainerRuntimeConfig.Modes.CDI.DefaultKind
+	if f.runtimeMode == info.JitCDIRuntimeMode {
+		defaultKind = automaticDeviceKind
+	}
+	devices := newCDIDeviceRequestor(f.logger, f.image, defaultKind).DeviceRequests()
+	if len(devices) == 0 {
+		return nil
+	}
+
+	ids, err := requirementDeviceIDs(devices)
+	if err != nil {
+		return err
+	}
+
+	r := requirements.New(f.logger, imageRequirements)
+	host := requirements.NewHost(
+		requirements.WithLogger(f.logger),
+		requirements.WithDriver(f.driver),
+	)
+	host.AddProperties(r, ids...)
+	return r.Assert()
+}
+
+// requirementDeviceIDs returns the device identifiers for the requested NVIDIA
+// GPUs. Requests for other devices and for specific modes of the automatic
+// CDI spec generation are ignored.
+//
+// Devices named using the index, type-index or uuid naming strategies are
+// resolved. Since the requirements of devices with other names, such as
+// those generated from a naming template, cannot be checked, an error is
+// returned for these devices.
+func requirementDeviceIDs(devices []string) ([]string, error) {
+	var ids []string
+	for _, device := range devices {
+		vendor, class, name, err := parser.ParseQualifiedName(device)
+		if err != nil {
+			continue
+		}
+		if class != automaticDeviceClass {
+			continue
+		}
+		if vendor != "nvidia.com" && vendor != automaticDeviceVendor {
+			continue
+		}
+		if strings.Contains(name, "=") {
+			continue
+		}
+		id, ok := resolveRequirementDeviceID(name)
+		if !ok {
+			return nil, fmt.Errorf("cannot check requirements for device %v: only devices named by index or UUID are supported", device)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// resolveRequirementDeviceID returns the index, MIG index (i:j) or UUID of a
+// device with the specified name.
+func resolveRequirementDeviceID(name string) (string, bool) {
+	switch {
+	case name == "all", strings.HasPrefix(name, "GPU-"), strings.HasPrefix(name, "MIG-"):
+		return name, true
+	case isDeviceIndex(name):
+		return name, true
+	}
+	if id, ok := strings.CutPrefix(name, "gpu"); ok && !strings.Contains(id, ":") && isDeviceIndex(id) {
+		return id, true
+	}
+	if id, ok := strings.CutPrefix(name, "mig"); ok && strings.Contains(id, ":") && isDeviceIndex(id) {
+		return id, true
+	}
+	return "", false
+}
+
+// isDeviceIndex checks whether the specified value is a GPU index (i) or a
+// MIG device index (i:j).
+func isDeviceIndex(value string) bool {
+	gpu, mig, isMig := strings.Cut(value, ":")
+	if _, err := strconv.ParseUint(gpu, 10, 32); err != nil {
+		return false
+	}
+	if !isMig {
+		return true
+	}
+	_, err := strconv.ParseUint(mig, 10, 32)
+	return err == nil
+}
@@ -0,0 +1,146 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package modifier
+
+import (
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+)
+
+func TestRequirementDeviceIDs(t *testing.T) {
+	testCases := []struct {
+		description   string
+		devices       []string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			description: "no devices",
+		},
+		{
+			description: "nvidia.com gpus",
+			devices:     []string{"nvidia.com/gpu=0", "nvidia.com/gpu=GPU-0"},
+			expected:    []string{"0", "GPU-0"},
+		},
+		{
+			description: "automatic gpus",
+			devices:     []string{"runtime.nvidia.com/gpu=all"},
+			expected:    []string{"all"},
+		},
+		{
+			description: "other devices are ignored",
+			devices:     []string{"example.com/gpu=0", "nvidia.com/imex-channel=0", "nvidia.com/gpu=1"},
+			expected:    []string{"1"},
+		},
+		{
+			description: "type-index names are resolved",
+			devices:     []string{"nvidia.com/gpu=gpu0", "nvidia.com/gpu=mig1:2", "nvidia.com/gpu=1:2"},
+			expected:    []string{"0", "1:2", "1:2"},
+		},
+		{
+			description: "mig uuid",
+			devices:     []string{"nvidia.com/gpu=MIG-0"},
+			expected:    []string{"MIG-0"},
+		},
+		{
+			description:   "template names are rejected",
+			devices:       []string{"nvidia.com/gpu=0", "nvidia.com/gpu=A100-0"},
+			expectedError: true,
+		},
+		{
+			description:   "invalid mig index is rejected",
+			devices:       []string{"nvidia.com/gpu=mig1"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ids, err := requirementDeviceIDs(tc.devices)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestAssertRequirements(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	cfg, err := config.GetDefault()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description   string
+		envmap        map[string]string
+		expectedError bool
+	}{
+		{
+			description: "blank requirements are not checked",
+			envmap: map[string]string{
+				"NVIDIA_VISIBLE_DEVICES": "nvidia.com/gpu=A100-0",
+				"NVIDIA_REQUIRE_CUDA":    " ",
+			},
+		},
+		{
+			description: "requirements are not checked if disabled",
+			envmap: map[string]string{
+				"NVIDIA_VISIBLE_DEVICES": "nvidia.com/gpu=A100-0",
+				"NVIDIA_REQUIRE_CUDA":    "cuda>=12.0",
+				"NVIDIA_DISABLE_REQUIRE": "true",
+			},
+		},
+		{
+			description: "requirements of devices named from a template cannot be checked",
+			envmap: map[string]string{
+				"NVIDIA_VISIBLE_DEVICES": "nvidia.com/gpu=A100-0",
+				"NVIDIA_REQUIRE_CUDA":    "cuda>=12.0",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			image, err := image.New(
+				image.WithEnvMap(tc.envmap),
+			)
+			require.NoError(t, err)
+
+			f := createFactory(
+				WithLogger(logger),
+				WithConfig(cfg),
+				WithImage(&image),
+				WithRuntimeMode(info.CDIRuntimeMode),
+			)
+			err = f.assertRequirements()
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package modifier
+
+import (
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/oci"
+)
+
+// A timed modifier logs the name of the wrapped modifier and the time taken
+// to apply it.
+type timed struct {
+	logger logger.Interface
+	name   string
+	oci.SpecModifier
+}
+
+// withTiming wraps the specified modifier so that a log record is emitted
+// when it is applied. A nil modifier is returned as is.
+func (f *Factory) withTiming(name string, m oci.SpecModifier) oci.SpecModifier {
+	if m == nil {
+		return nil
+	}
+	return &timed{
+		logger:       f.logger,
+		name:         name,
+		SpecModifier: m,
+	}
+}
+
+// Modify applies the wrapped modifier and logs the elapsed time.
+func (m *timed) Modify(spec *specs.Spec) error {
+	start := time.Now()
+	err := m.SpecModifier.Modify(spec)
+	logger.WithFields(m.logger, logger.Fields{
+		logger.FieldModifier: m.name,
+		logger.FieldElapsed:  time.Since(start).String(),
+	}).Debugf("Applied %v modifier", m.name)
+	return err
+}
@@ -94,17 +94,79 @@ func IsBundleFlag(arg string) bool {
 
 // HasCreateSubcommand checks the supplied arguments for a 'create' subcommand
 func HasCreateSubcommand(args []string) bool {
+	return hasSubcommand(args, "create")
+}
+
+// GetContainerIDFromArgs returns the container ID for a create subcommand. As
+// is the case for runc, the container ID is expected to be the last argument.
+// If no container ID can be determined, an empty string is returned.
+func GetContainerIDFromArgs(args []string) string {
+	if !HasCreateSubcommand(args) {
+		return ""
+	}
+	last := args[len(args)-1]
+	if last == "create" || strings.HasPrefix(last, "-") {
+		return ""
+	}
+	if len(args) > 1 && IsBundleFlag(args[len(args)-2]) {
+		return ""
+	}
+	return last
+}
+
+// HasDryRunSubcommand checks whether the supplied arguments invoke the
+// 'dry-run' subcommand. In contrast to the 'create' subcommand, only the
+// argument in the command position is considered since a dry run replaces
+// the invocation of the low-level runtime. This ensures that a container ID or
+// a process argument named 'dry-run' is not mistaken for the subcommand.
+func HasDryRunSubcommand(args []string) bool {
+	return getSubcommand(args) == "dry-run"
+}
+
+// globalFlagsWithValues lists the global flags of the low-level runtime that
+// take a value as a separate argument.
+var globalFlagsWithValues = map[string]bool{
+	"criu":       true,
+	"log":        true,
+	"log-format": true,
+	"root":       true,
+}
+
+// getSubcommand returns the argument in the command position. The first
+// argument is the name of the executable and is skipped, as are global flags
+// and their values.
+func getSubcommand(args []string) string {
+	if len(args) < 2 {
+		return ""
+	}
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			return a
+		}
+		if strings.Contains(a, "=") {
+			continue
+		}
+		if globalFlagsWithValues[strings.TrimLeft(a, "-")] {
+			i++
+		}
+	}
+	return ""
+}
+
+// hasSubcommand checks the supplied arguments for the specified subcommand.
+func hasSubcommand(args []string, subcommand string) bool {
 	var previousWasBundle bool
 	for _, a := range args {
-		// We check for '--bundle create' explicitly to ensure that we
+		// We check for '--bundle {{SUBCOMMAND}}' explicitly to ensure that we
 		// don't inadvertently trigger a modification if the bundle directory
-		// is specified as `create`
+		// is specified as the subcommand.
 		if !previousWasBundle && IsBundleFlag(a) {
 			previousWasBundle = true
 			continue
 		}
 
-		if !previousWasBundle && a == "create" {
+		if !previousWasBundle && a == subcommand {
 			return true
 		}
 
@@ -182,3 +182,69 @@ func TestHasCreateSubcommand(t *testing.T) {
 		require.Equal(t, tc.shouldModify, HasCreateSubcommand(tc.args), "%d: %v", i, tc)
 	}
 }
+
+func TestGetContainerIDFromArgs(t *testing.T) {
+	testCases := []struct {
+		args                []string
+		expectedContainerID string
+	}{
+		{},
+		{
+			args: []string{"nvidia-container-runtime", "run", "--bundle", "/foo", "bar"},
+		},
+		{
+			args:                []string{"nvidia-container-runtime", "create", "--bundle", "/foo", "bar"},
+			expectedContainerID: "bar",
+		},
+		{
+			args: []string{"nvidia-container-runtime", "create", "--bundle", "/foo"},
+		},
+		{
+			args: []string{"nvidia-container-runtime", "create"},
+		},
+	}
+
+	for i, tc := range testCases {
+		require.Equal(t, tc.expectedContainerID, GetContainerIDFromArgs(tc.args), "%d: %v", i, tc)
+	}
+}
+
+func TestHasDryRunSubcommand(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected bool
+	}{
+		{},
+		{
+			args: []string{"nvidia-container-runtime"},
+		},
+		{
+			args:     []string{"nvidia-container-runtime", "dry-run", "--bundle", "/foo"},
+			expected: true,
+		},
+		{
+			args:     []string{"nvidia-container-runtime", "--debug", "--root", "/run/runc", "dry-run"},
+			expected: true,
+		},
+		{
+			args:     []string{"nvidia-container-runtime", "--log-format=json", "dry-run"},
+			expected: true,
+		},
+		{
+			args: []string{"nvidia-container-runtime", "--root", "dry-run", "create", "--bundle", "/foo", "bar"},
+		},
+		{
+			args: []string{"nvidia-container-runtime", "delete", "dry-run"},
+		},
+		{
+			args: []string{"nvidia-container-runtime", "exec", "ctr", "dry-run"},
+		},
+		{
+			args: []string{"nvidia-container-runtime", "create", "--bundle", "dry-run", "bar"},
+		},
+	}
+
+	for i, tc := range testCases {
+		require.Equal(t, tc.expected, HasDryRunSubcommand(tc.args), "%d: %v", i, tc)
+	}
+}
@@ -73,7 +73,7 @@ func (r *modifyingRuntimeWrapper) modify() error {
 
 	err = r.ociSpec.Modify(r.modifier)
 	if err != nil {
-		return fmt.Errorf("error modifying OCI spec: %v", err)
+		return fmt.Errorf("error modifying OCI spec: %w", err)
 	}
 
 	err = r.ociSpec.Flush()
@@ -22,6 +22,7 @@ import (
 
 	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/nvcaps"
@@ -125,9 +126,15 @@ func new(opts ...Option) (*options, error) {
 	if o.logger == nil {
 		o.logger = logger.New()
 	}
+	if o.hostRoot == "" {
+		o.hostRoot = "/"
+	}
+	if o.deviceslib == nil {
+		o.deviceslib = devices.New()
+	}
 
 	if o.migCaps == nil {
-		migCaps, err := nvcaps.NewMigCaps()
+		migCaps, err := nvcaps.NewMigCapsAtRoot(o.hostRoot)
 		if err != nil {
 			o.logger.Debugf("ignoring error getting MIG capability device paths: %v", err)
 			o.migCapsError = err
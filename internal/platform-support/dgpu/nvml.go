@@ -45,15 +45,16 @@ func (o *options) newNvmlDGPUDiscoverer(d requiredInfo) (discover.Discover, erro
 		return nil, fmt.Errorf("error getting PCI info for device: %w", err)
 	}
 
-	drmDeviceNodes, err := drm.GetDeviceNodesByBusID(pciBusID)
+	drmDeviceNodes, err := drm.GetDeviceNodesByBusIDAtRoot(o.hostRoot, pciBusID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine DRM devices for %v: %v", pciBusID, err)
 	}
 
 	deviceNodePaths := append([]string{path}, drmDeviceNodes...)
 
-	deviceNodes := discover.NewCharDeviceDiscoverer(
+	deviceNodes := discover.NewCharDeviceDiscovererWithDevicesLib(
 		o.logger,
+		o.deviceslib,
 		o.driver.DevRoot,
 		deviceNodePaths,
 	)
@@ -118,14 +119,15 @@ func (o *options) newNvmlMigDiscoverer(d requiredMigInfo) (discover.Discover, er
 			return nil, fmt.Errorf("error getting PCI info for device: %w", err)
 		}
 
-		drmDeviceNodes, err := drm.GetDeviceNodesByBusID(pciBusID)
+		drmDeviceNodes, err := drm.GetDeviceNodesByBusIDAtRoot(o.hostRoot, pciBusID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to determine DRM devices for %q: %w", pciBusID, err)
 		}
 
 		charDevicePaths = append(charDevicePaths, drmDeviceNodes...)
-		deviceNodes := discover.NewCharDeviceDiscoverer(
+		deviceNodes := discover.NewCharDeviceDiscovererWithDevicesLib(
 			o.logger,
+			o.deviceslib,
 			o.driver.DevRoot,
 			charDevicePaths,
 		)
@@ -142,8 +144,9 @@ func (o *options) newNvmlMigDiscoverer(d requiredMigInfo) (discover.Discover, er
 		), nil
 	}
 
-	deviceNodes := discover.NewCharDeviceDiscoverer(
+	deviceNodes := discover.NewCharDeviceDiscovererWithDevicesLib(
 		o.logger,
+		o.deviceslib,
 		o.driver.DevRoot,
 		[]string{
 			parentPath,
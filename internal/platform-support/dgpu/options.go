@@ -17,6 +17,7 @@
 package dgpu
 
 import (
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
@@ -28,6 +29,8 @@ type options struct {
 	logger      logger.Interface
 	driver      *root.Driver
 	hookCreator discover.HookCreator
+	// hostRoot is the root under which /proc and /sys are queried.
+	hostRoot string
 
 	isMigDevice   bool
 	migAttributes []string
@@ -38,6 +41,7 @@ type options struct {
 	migCapsError error
 
 	nvsandboxutilslib nvsandboxutils.Interface
+	deviceslib        devices.Interface
 }
 
 type Option func(*options)
@@ -49,6 +53,14 @@ func WithDriver(driver *root.Driver) Option {
 	}
 }
 
+// WithHostRoot sets the root under which the /proc and /sys filesystems are
+// queried.
+func WithHostRoot(hostRoot string) Option {
+	return func(l *options) {
+		l.hostRoot = hostRoot
+	}
+}
+
 // WithLogger sets the logger for the library
 func WithLogger(logger logger.Interface) Option {
 	return func(l *options) {
@@ -70,6 +82,14 @@ func WithMIGCaps(migCaps nvcaps.MigCaps) Option {
 	}
 }
 
+// WithDevicesLib sets the library used to check whether the located device
+// nodes are char devices.
+func WithDevicesLib(deviceslib devices.Interface) Option {
+	return func(l *options) {
+		l.deviceslib = deviceslib
+	}
+}
+
 // WithNvsandboxuitilsLib sets the nvsandboxutils library implementation.
 func WithNvsandboxuitilsLib(nvsandboxutilslib nvsandboxutils.Interface) Option {
 	return func(l *options) {
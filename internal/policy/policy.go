@@ -0,0 +1,247 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+// namespaceAnnotations lists the annotations that are used by container
+// engines to record the kubernetes namespace of a container.
+var namespaceAnnotations = []string{
+	"io.kubernetes.cri.sandbox-namespace",
+	"io.kubernetes.pod.namespace",
+}
+
+// crioLabelsAnnotation is the annotation used by CRI-O to record the labels of
+// a container as a JSON-encoded map.
+const crioLabelsAnnotation = "io.kubernetes.cri-o.Labels"
+
+// A Container describes the properties of a container that policy rules are
+// matched against.
+type Container struct {
+	Annotations map[string]string
+	Labels      map[string]string
+	Namespace   string
+	CgroupsPath string
+}
+
+// A Request describes the devices requested by a container.
+type Request struct {
+	// Devices lists the fully-qualified names of the requested CDI devices.
+	Devices []string
+	// ImexChannels lists the requested IMEX channels.
+	ImexChannels []string
+}
+
+// Interface defines the API for checking device requests against a policy.
+type Interface interface {
+	Check(Container, Request) error
+}
+
+type policy struct {
+	logger        logger.Interface
+	rules         []config.PolicyRule
+	deviceCounter func() (int, error)
+}
+
+var _ Interface = (*policy)(nil)
+
+// Option is a functional option for constructing a policy.
+type Option func(*policy)
+
+// New creates a policy from the specified rules.
+func New(opts ...Option) Interface {
+	p := &policy{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.logger == nil {
+		p.logger = logger.New()
+	}
+	return p
+}
+
+// WithLogger sets the logger for the policy.
+func WithLogger(logger logger.Interface) Option {
+	return func(p *policy) {
+		p.logger = logger
+	}
+}
+
+// WithRules sets the rules for the policy.
+func WithRules(rules ...config.PolicyRule) Option {
+	return func(p *policy) {
+		p.rules = rules
+	}
+}
+
+// WithDeviceCounter sets the function used to determine the number of devices
+// that are visible on the host. This is used to count a request for all
+// devices against the maximum number of devices allowed by a rule. If no
+// counter is set, requests for all devices are denied by rules that limit the
+// number of devices.
+func WithDeviceCounter(deviceCounter func() (int, error)) Option {
+	return func(p *policy) {
+		p.deviceCounter = deviceCounter
+	}
+}
+
+// NewContainerFromSpec creates a container description from the specified
+// OCI runtime specification.
+// Since the OCI runtime specification does not include container labels,
+// these are only populated if recorded in an annotation by the container
+// engine (as is the case for CRI-O).
+func NewContainerFromSpec(spec *specs.Spec) Container {
+	c := Container{
+		Annotations: spec.Annotations,
+	}
+	if labels := spec.Annotations[crioLabelsAnnotation]; labels != "" {
+		_ = json.Unmarshal([]byte(labels), &c.Labels)
+	}
+	if spec.Linux != nil {
+		c.CgroupsPath = spec.Linux.CgroupsPath
+	}
+	for _, annotation := range namespaceAnnotations {
+		if namespace := spec.Annotations[annotation]; namespace != "" {
+			c.Namespace = namespace
+			break
+		}
+	}
+	return c
+}
+
+// Check checks the specified request against the rules that apply to the
+// container. An error is returned if any of the rules is violated.
+func (p *policy) Check(c Container, r Request) error {
+	var errs []error
+	for i, rule := range p.rules {
+		if !appliesTo(rule, c) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		p.logger.Debugf("Checking request %+v against policy rule %v", r, name)
+		if err := p.checkRule(rule, r); err != nil {
+			errs = append(errs, fmt.Errorf("policy rule %v: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func appliesTo(rule config.PolicyRule, c Container) bool {
+	if !matches(rule.Match, c) {
+		return false
+	}
+	if !rule.Except.IsEmpty() && matches(rule.Except, c) {
+		return false
+	}
+	return true
+}
+
+// matches checks whether the container matches all properties of the
+// selector.
+func matches(s config.PolicySelector, c Container) bool {
+	if !matchesMap(s.Annotations, c.Annotations) {
+		return false
+	}
+	if !matchesMap(s.Labels, c.Labels) {
+		return false
+	}
+	if len(s.Namespaces) > 0 && !slices.Contains(s.Namespaces, c.Namespace) {
+		return false
+	}
+	if len(s.CgroupsPaths) > 0 && !matchesAny(s.CgroupsPaths, c.CgroupsPath) {
+		return false
+	}
+	return true
+}
+
+func matchesMap(patterns map[string]string, values map[string]string) bool {
+	for key, pattern := range patterns {
+		value, ok := values[key]
+		if !ok || !matchesAny([]string{pattern}, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *policy) checkRule(rule config.PolicyRule, r Request) error {
+	if rule.DenyImexChannels && len(r.ImexChannels) > 0 {
+		return fmt.Errorf("IMEX channels %v may not be requested", r.ImexChannels)
+	}
+
+	var numDevices int
+	for _, device := range r.Devices {
+		vendor, class, name := parser.ParseDevice(device)
+		if name == "none" || name == "void" {
+			continue
+		}
+		kind := vendor + "/" + class
+		if len(rule.AllowedKinds) > 0 && !matchesAny(rule.AllowedKinds, kind) {
+			return fmt.Errorf("device %v may not be requested; allowed kinds are %v", device, rule.AllowedKinds)
+		}
+		if matchesAny(rule.DeniedKinds, kind) {
+			return fmt.Errorf("device %v may not be requested; denied kinds are %v", device, rule.DeniedKinds)
+		}
+		if name == "all" && rule.MaxDevices != nil {
+			count, err := p.countAllDevices()
+			if err != nil {
+				return fmt.Errorf("device %v may not be requested; at most %d device(s) may be requested: %w", device, *rule.MaxDevices, err)
+			}
+			numDevices += count
+			continue
+		}
+		numDevices++
+	}
+
+	if rule.MaxDevices != nil && numDevices > *rule.MaxDevices {
+		return fmt.Errorf("%d devices requested; at most %d device(s) may be requested", numDevices, *rule.MaxDevices)
+	}
+	return nil
+}
+
+// countAllDevices returns the number of devices that a request for all
+// devices corresponds to.
+func (p *policy) countAllDevices() (int, error) {
+	if p.deviceCounter == nil {
+		return 0, fmt.Errorf("the number of visible devices is unknown")
+	}
+	return p.deviceCounter()
+}
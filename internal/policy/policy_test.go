@@ -0,0 +1,253 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/test/to"
+)
+
+func TestCheck(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description   string
+		rules         []config.PolicyRule
+		container     Container
+		deviceCount   *int
+		request       Request
+		expectedError string
+	}{
+		{
+			description: "no rules allows all requests",
+			request: Request{
+				Devices:      []string{"management.nvidia.com/gpu=all"},
+				ImexChannels: []string{"0"},
+			},
+		},
+		{
+			description: "denied kind is rejected",
+			rules: []config.PolicyRule{
+				{Name: "deny", DeniedKinds: []string{"management.nvidia.com/*"}},
+			},
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=0", "management.nvidia.com/gpu=0"},
+			},
+			expectedError: "policy rule deny: device management.nvidia.com/gpu=0 may not be requested; denied kinds are [management.nvidia.com/*]",
+		},
+		{
+			description: "kind not in allowed kinds is rejected",
+			rules: []config.PolicyRule{
+				{AllowedKinds: []string{"nvidia.com/gpu"}},
+			},
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=0", "example.com/device=0"},
+			},
+			expectedError: "policy rule 0: device example.com/device=0 may not be requested; allowed kinds are [nvidia.com/gpu]",
+		},
+		{
+			description: "except selector excludes container",
+			rules: []config.PolicyRule{
+				{
+					DeniedKinds: []string{"management.nvidia.com/gpu"},
+					Except: config.PolicySelector{
+						Namespaces: []string{"gpu-operator"},
+					},
+				},
+			},
+			container: Container{Namespace: "gpu-operator"},
+			request: Request{
+				Devices: []string{"management.nvidia.com/gpu=0"},
+			},
+		},
+		{
+			description: "rule only applies to matching containers",
+			rules: []config.PolicyRule{
+				{
+					MaxDevices: to.Ptr(1),
+					Match: config.PolicySelector{
+						Annotations:  map[string]string{"example.com/tenant": "team-*"},
+						CgroupsPaths: []string{"/kubepods/besteffort/*"},
+					},
+				},
+			},
+			container: Container{
+				Annotations: map[string]string{"example.com/tenant": "team-a"},
+				CgroupsPath: "/kubepods/burstable/pod0",
+			},
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=0", "nvidia.com/gpu=1"},
+			},
+		},
+		{
+			description: "max devices is exceeded",
+			rules: []config.PolicyRule{
+				{
+					MaxDevices: to.Ptr(1),
+					Match: config.PolicySelector{
+						Labels: map[string]string{"tier": "batch"},
+					},
+				},
+			},
+			container: Container{
+				Labels: map[string]string{"tier": "batch"},
+			},
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=0", "nvidia.com/gpu=1"},
+			},
+			expectedError: "policy rule 0: 2 devices requested; at most 1 device(s) may be requested",
+		},
+		{
+			description: "all devices denied if device count is unknown",
+			rules: []config.PolicyRule{
+				{MaxDevices: to.Ptr(8)},
+			},
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=all"},
+			},
+			expectedError: "policy rule 0: device nvidia.com/gpu=all may not be requested; at most 8 device(s) may be requested: the number of visible devices is unknown",
+		},
+		{
+			description: "all devices within max devices",
+			rules: []config.PolicyRule{
+				{MaxDevices: to.Ptr(8)},
+			},
+			deviceCount: to.Ptr(8),
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=all"},
+			},
+		},
+		{
+			description: "all devices exceeds max devices",
+			rules: []config.PolicyRule{
+				{MaxDevices: to.Ptr(4)},
+			},
+			deviceCount: to.Ptr(8),
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=all"},
+			},
+			expectedError: "policy rule 0: 8 devices requested; at most 4 device(s) may be requested",
+		},
+		{
+			description: "none device is not counted",
+			rules: []config.PolicyRule{
+				{MaxDevices: to.Ptr(0)},
+			},
+			request: Request{
+				Devices: []string{"nvidia.com/gpu=none"},
+			},
+		},
+		{
+			description: "IMEX channels are denied",
+			rules: []config.PolicyRule{
+				{DenyImexChannels: true},
+			},
+			request: Request{
+				ImexChannels: []string{"0"},
+			},
+			expectedError: "policy rule 0: IMEX channels [0] may not be requested",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			opts := []Option{
+				WithLogger(logger),
+				WithRules(tc.rules...),
+			}
+			if tc.deviceCount != nil {
+				opts = append(opts, WithDeviceCounter(func() (int, error) {
+					return *tc.deviceCount, nil
+				}))
+			}
+			p := New(opts...)
+
+			err := p.Check(tc.container, tc.request)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestNewContainerFromSpec(t *testing.T) {
+	testCases := []struct {
+		description string
+		spec        *specs.Spec
+		expected    Container
+	}{
+		{
+			description: "namespace and cgroups path",
+			spec: &specs.Spec{
+				Annotations: map[string]string{
+					"io.kubernetes.cri.sandbox-namespace": "gpu-operator",
+				},
+				Linux: &specs.Linux{
+					CgroupsPath: "/kubepods/pod0",
+				},
+			},
+			expected: Container{
+				Annotations: map[string]string{
+					"io.kubernetes.cri.sandbox-namespace": "gpu-operator",
+				},
+				Namespace:   "gpu-operator",
+				CgroupsPath: "/kubepods/pod0",
+			},
+		},
+		{
+			description: "labels from cri-o annotation",
+			spec: &specs.Spec{
+				Annotations: map[string]string{
+					"io.kubernetes.cri-o.Labels": `{"tier":"batch"}`,
+				},
+			},
+			expected: Container{
+				Annotations: map[string]string{
+					"io.kubernetes.cri-o.Labels": `{"tier":"batch"}`,
+				},
+				Labels: map[string]string{"tier": "batch"},
+			},
+		},
+		{
+			description: "invalid labels annotation is ignored",
+			spec: &specs.Spec{
+				Annotations: map[string]string{
+					"io.kubernetes.cri-o.Labels": `invalid`,
+				},
+			},
+			expected: Container{
+				Annotations: map[string]string{
+					"io.kubernetes.cri-o.Labels": `invalid`,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, NewContainerFromSpec(tc.spec))
+		})
+	}
+}
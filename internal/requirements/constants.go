@@ -16,10 +16,40 @@
 
 package requirements
 
+import "github.com/NVIDIA/nvidia-container-toolkit/internal/requirements/constraints"
+
 // A list of supported requirements / properties
 const (
 	ARCH   = "arch"
 	BRAND  = "brand"
 	CUDA   = "cuda"
 	DRIVER = "driver"
+
+	FABRICMANAGER = "fabric-manager"
+	GPUS          = "gpus"
+	IMEX          = "imex"
+	MEMORY        = "memory"
+	MIG           = "mig"
+	MIGPROFILE    = "mig-profile"
+	PRODUCT       = "product"
 )
+
+// legacyProperties are the properties that are also supported by the
+// nvidia-container-cli.
+var legacyProperties = map[string]bool{
+	ARCH:   true,
+	BRAND:  true,
+	CUDA:   true,
+	DRIVER: true,
+}
+
+// IsLegacy checks whether the specified requirement only references
+// properties that are also supported by the nvidia-container-cli.
+func IsLegacy(requirement string) bool {
+	for _, name := range constraints.PropertyNames(requirement) {
+		if !legacyProperties[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -25,6 +25,11 @@ const (
 	greaterEqual = ">="
 )
 
+const (
+	orSeparator  = " "
+	andSeparator = ","
+)
+
 // always is a constraint that is always met
 type always struct{}
 
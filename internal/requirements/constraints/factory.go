@@ -59,10 +59,6 @@ func New(logger logger.Interface, requirements []string, properties map[string]P
 // Each requirement can consist of multiple constraints, with space-separated constraints being ORed
 // together and comma-separated constraints being ANDed together.
 func (r factory) newConstraintFromRequirement(requirement string) (Constraint, error) {
-	const (
-		orSeparator  = " "
-		andSeparator = ","
-	)
 	if strings.TrimSpace(requirement) == "" {
 		return nil, nil
 	}
@@ -96,6 +92,22 @@ func (r factory) newConstraintFromRequirement(requirement string) (Constraint, e
 	return OR(terms), nil
 }
 
+// PropertyNames returns the names of the properties referenced by the
+// specified requirement.
+func PropertyNames(requirement string) []string {
+	var names []string
+	for term := range strings.SplitSeq(requirement, orSeparator) {
+		for factor := range strings.SplitSeq(term, andSeparator) {
+			propertyEnd := strings.IndexAny(factor, "<>=!")
+			if propertyEnd <= 0 {
+				continue
+			}
+			names = append(names, factor[:propertyEnd])
+		}
+	}
+	return names
+}
+
 // parse constructs a constraint from the specified string.
 // The string is expected to be of the form [PROPERTY][OPERATOR][VALUE]
 func (r factory) parse(condition string) (Constraint, error) {
@@ -185,3 +185,36 @@ func TestNewConstraintFromRequirement(t *testing.T) {
 	}
 
 }
+
+func TestPropertyNames(t *testing.T) {
+	testCases := []struct {
+		description string
+		requirement string
+		expected    []string
+	}{
+		{
+			description: "empty requirement has no properties",
+		},
+		{
+			description: "single property",
+			requirement: "cuda>=11.6",
+			expected:    []string{"cuda"},
+		},
+		{
+			description: "ored and anded properties",
+			requirement: "cuda>=12.4 brand=tesla,driver>=470 mig-profile!=1g.*",
+			expected:    []string{"cuda", "brand", "driver", "mig-profile"},
+		},
+		{
+			description: "missing operator is skipped",
+			requirement: "notvalid memory>=40G",
+			expected:    []string{"memory"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.EqualValues(t, tc.expected, PropertyNames(tc.requirement))
+		})
+	}
+}
@@ -17,7 +17,11 @@
 package constraints
 
 import (
+	"cmp"
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 )
@@ -130,3 +134,190 @@ func (p versionProperty) Validate(value string) error {
 
 	return nil
 }
+
+// NewIntegerProperty creates a property representing an integer based on the name-value pair
+func NewIntegerProperty(name string, value string) Property {
+	p := integerProperty{
+		stringProperty: stringProperty{
+			name:  name,
+			value: value,
+		},
+	}
+
+	return p
+}
+
+// NewSizeProperty creates a property representing a size based on the name-value pair.
+// Sizes without a unit are interpreted as MiB.
+func NewSizeProperty(name string, value string) Property {
+	p := sizeProperty{
+		stringProperty: stringProperty{
+			name:  name,
+			value: value,
+		},
+	}
+
+	return p
+}
+
+// NewBooleanProperty creates a property representing a boolean based on the name-value pair
+func NewBooleanProperty(name string, value string) Property {
+	p := booleanProperty{
+		stringProperty: stringProperty{
+			name:  name,
+			value: value,
+		},
+	}
+
+	return p
+}
+
+// NewPatternProperty creates a property whose value is matched against
+// shell patterns based on the name-value pair. The comparison is case
+// insensitive.
+func NewPatternProperty(name string, value string) Property {
+	p := patternProperty{
+		stringProperty: stringProperty{
+			name:  name,
+			value: value,
+		},
+	}
+
+	return p
+}
+
+type integerProperty struct {
+	stringProperty
+}
+
+type sizeProperty struct {
+	stringProperty
+}
+
+type booleanProperty struct {
+	stringProperty
+}
+
+type patternProperty struct {
+	stringProperty
+}
+
+// CompareTo compares two integers to each other
+func (p integerProperty) CompareTo(other string) (int, error) {
+	if err := p.Validate(other); err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+
+	value, err := strconv.ParseInt(p.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %w", p.name, err)
+	}
+	otherValue, _ := strconv.ParseInt(other, 10, 64)
+	return cmp.Compare(value, otherValue), nil
+}
+
+// Validate checks whether the supplied value is a valid integer
+func (p integerProperty) Validate(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("invalid integer: %w", err)
+	}
+	return nil
+}
+
+// CompareTo compares two sizes to each other
+func (p sizeProperty) CompareTo(other string) (int, error) {
+	if err := p.Validate(other); err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+
+	value, err := parseSize(p.value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %w", p.name, err)
+	}
+	otherValue, _ := parseSize(other)
+	return cmp.Compare(value, otherValue), nil
+}
+
+// Validate checks whether the supplied value is a valid size
+func (p sizeProperty) Validate(value string) error {
+	_, err := parseSize(value)
+	return err
+}
+
+// CompareTo compares two booleans to each other with false being less than true
+func (p booleanProperty) CompareTo(other string) (int, error) {
+	if err := p.Validate(other); err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+
+	value, err := strconv.ParseBool(p.value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %w", p.name, err)
+	}
+	otherValue, _ := strconv.ParseBool(other)
+	switch {
+	case value == otherValue:
+		return 0, nil
+	case otherValue:
+		return -1, nil
+	default:
+		return 1, nil
+	}
+}
+
+// Validate checks whether the supplied value is a valid boolean
+func (p booleanProperty) Validate(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("invalid boolean: %w", err)
+	}
+	return nil
+}
+
+// CompareTo matches the property's value against the supplied pattern. Zero
+// is returned if the value matches and one otherwise. Only the = and !=
+// operators are meaningful for patterns.
+func (p patternProperty) CompareTo(other string) (int, error) {
+	matched, err := path.Match(strings.ToLower(other), strings.ToLower(p.value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %w", p.name, err)
+	}
+	if matched {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// Validate checks whether the supplied value is a valid pattern
+func (p patternProperty) Validate(value string) error {
+	if _, err := path.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	return nil
+}
+
+// parseSize parses a size with an optional binary unit suffix (K, M, G, T),
+// returning the size in bytes. Sizes without a unit are interpreted as MiB.
+func parseSize(value string) (uint64, error) {
+	units := map[string]uint64{
+		"K": 1 << 10,
+		"M": 1 << 20,
+		"G": 1 << 30,
+		"T": 1 << 40,
+	}
+
+	number := strings.TrimRight(value, "KMGTkmgtiIbB")
+	multiplier := uint64(1 << 20)
+	if suffix := strings.ToUpper(value[len(number):]); suffix != "" {
+		unit := strings.TrimSuffix(strings.TrimSuffix(suffix, "B"), "I")
+		var ok bool
+		if multiplier, ok = units[unit]; !ok {
+			return 0, fmt.Errorf("invalid size %q", value)
+		}
+	}
+
+	size, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", value, err)
+	}
+	return size * multiplier, nil
+}
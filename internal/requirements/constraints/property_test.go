@@ -0,0 +1,142 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package constraints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertyCompareTo(t *testing.T) {
+	testCases := []struct {
+		description   string
+		property      Property
+		other         string
+		expectedError bool
+		expected      int
+	}{
+		{
+			description: "integer less than",
+			property:    NewIntegerProperty("gpus", "2"),
+			other:       "4",
+			expected:    -1,
+		},
+		{
+			description: "integer equal",
+			property:    NewIntegerProperty("gpus", "4"),
+			other:       "4",
+			expected:    0,
+		},
+		{
+			description:   "integer requires integer value",
+			property:      NewIntegerProperty("gpus", "4"),
+			other:         "four",
+			expectedError: true,
+		},
+		{
+			description:   "unset integer is an error",
+			property:      NewIntegerProperty("gpus", ""),
+			other:         "4",
+			expectedError: true,
+		},
+		{
+			description: "size without unit is MiB",
+			property:    NewSizeProperty("memory", "40960"),
+			other:       "40960",
+			expected:    0,
+		},
+		{
+			description: "size with binary unit",
+			property:    NewSizeProperty("memory", "40960"),
+			other:       "40G",
+			expected:    0,
+		},
+		{
+			description: "size with iB unit",
+			property:    NewSizeProperty("memory", "40960"),
+			other:       "80GiB",
+			expected:    -1,
+		},
+		{
+			description: "size with lower-case unit",
+			property:    NewSizeProperty("memory", "40960"),
+			other:       "16gb",
+			expected:    1,
+		},
+		{
+			description:   "size with invalid unit",
+			property:      NewSizeProperty("memory", "40960"),
+			other:         "16X",
+			expectedError: true,
+		},
+		{
+			description: "boolean equal",
+			property:    NewBooleanProperty("mig", "true"),
+			other:       "1",
+			expected:    0,
+		},
+		{
+			description: "boolean false is less than true",
+			property:    NewBooleanProperty("mig", "false"),
+			other:       "true",
+			expected:    -1,
+		},
+		{
+			description:   "boolean requires boolean value",
+			property:      NewBooleanProperty("mig", "false"),
+			other:         "yes",
+			expectedError: true,
+		},
+		{
+			description: "pattern matches case insensitively",
+			property:    NewPatternProperty("product", "NVIDIA A100-SXM4-40GB"),
+			other:       "*a100*",
+			expected:    0,
+		},
+		{
+			description: "pattern does not match",
+			property:    NewPatternProperty("product", "NVIDIA A100-SXM4-40GB"),
+			other:       "*H100*",
+			expected:    1,
+		},
+		{
+			description: "empty value does not match pattern",
+			property:    NewPatternProperty("mig-profile", ""),
+			other:       "1g.*",
+			expected:    1,
+		},
+		{
+			description:   "invalid pattern",
+			property:      NewPatternProperty("mig-profile", "1g.5gb"),
+			other:         "[1g",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c, err := tc.property.CompareTo(tc.other)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, c)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package constraints
+
+// A Result records the outcome of evaluating a constraint. For logical
+// constraints, the results of the operands are also recorded so that the
+// clause that caused a requirement to fail can be identified.
+type Result struct {
+	// Constraint is the string representation of the evaluated constraint.
+	Constraint string `json:"constraint"`
+	// Satisfied indicates whether the constraint is met.
+	Satisfied bool `json:"satisfied"`
+	// Detail describes the value that the constraint was compared against or
+	// the reason that it could not be evaluated.
+	Detail string `json:"detail,omitempty"`
+	// Operands are the results of the operands of an AND or OR constraint.
+	Operands []Result `json:"operands,omitempty"`
+}
+
+// Evaluate evaluates the specified constraint and records the results of all
+// of its clauses. In contrast to Assert, evaluation does not stop at the first
+// clause that determines the outcome.
+func Evaluate(c Constraint) Result {
+	switch c := c.(type) {
+	case nil:
+		return Result{Constraint: "true", Satisfied: true}
+	case binary:
+		return c.evaluate()
+	case and:
+		r := Result{Constraint: c.String(), Satisfied: true}
+		for _, o := range c {
+			operand := Evaluate(o)
+			r.Satisfied = r.Satisfied && operand.Satisfied
+			r.Operands = append(r.Operands, operand)
+		}
+		return r
+	case or:
+		r := Result{Constraint: c.String()}
+		for _, o := range c {
+			operand := Evaluate(o)
+			r.Satisfied = r.Satisfied || operand.Satisfied
+			r.Operands = append(r.Operands, operand)
+		}
+		return r
+	}
+
+	r := Result{Constraint: c.String(), Satisfied: true}
+	if err := c.Assert(); err != nil {
+		r.Satisfied = false
+		r.Detail = err.Error()
+	}
+	return r
+}
+
+func (c binary) evaluate() Result {
+	r := Result{Constraint: c.String()}
+	if c.left == nil {
+		r.Satisfied = true
+		return r
+	}
+
+	satisfied, err := c.eval()
+	if err != nil {
+		r.Detail = err.Error()
+		if value, _ := c.left.Value(); value == "" {
+			r.Detail = c.left.Name() + " could not be determined"
+		}
+		return r
+	}
+	r.Satisfied = satisfied
+	r.Detail = c.left.String()
+	return r
+}
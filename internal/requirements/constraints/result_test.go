@@ -0,0 +1,91 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package constraints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	cuda := NewVersionProperty("cuda", "12.2")
+	brand := NewStringProperty("brand", "nvidia")
+	memory := NewSizeProperty("memory", "")
+
+	testCases := []struct {
+		description string
+		constraint  Constraint
+		expected    Result
+	}{
+		{
+			description: "nil constraint is satisfied",
+			expected:    Result{Constraint: "true", Satisfied: true},
+		},
+		{
+			description: "always is satisfied",
+			constraint:  &always{},
+			expected:    Result{Constraint: "true", Satisfied: true},
+		},
+		{
+			description: "binary records property value",
+			constraint:  binary{cuda, greaterEqual, "12.4"},
+			expected: Result{
+				Constraint: "cuda>=12.4",
+				Detail:     "cuda=12.2",
+			},
+		},
+		{
+			description: "unset property could not be determined",
+			constraint:  binary{memory, greaterEqual, "40G"},
+			expected: Result{
+				Constraint: "memory>=40G",
+				Detail:     "memory could not be determined",
+			},
+		},
+		{
+			description: "all operands of or are evaluated",
+			constraint: or{
+				binary{cuda, greaterEqual, "12.0"},
+				and{
+					binary{brand, equal, "tesla"},
+					binary{cuda, less, "13.0"},
+				},
+			},
+			expected: Result{
+				Constraint: "cuda>=12.0||brand=tesla&&cuda<13.0",
+				Satisfied:  true,
+				Operands: []Result{
+					{Constraint: "cuda>=12.0", Satisfied: true, Detail: "cuda=12.2"},
+					{
+						Constraint: "brand=tesla&&cuda<13.0",
+						Operands: []Result{
+							{Constraint: "brand=tesla", Detail: "brand=nvidia"},
+							{Constraint: "cuda<13.0", Satisfied: true, Detail: "cuda=12.2"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.EqualValues(t, tc.expected, Evaluate(tc.constraint))
+		})
+	}
+}
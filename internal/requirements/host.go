@@ -0,0 +1,369 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package requirements
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/cuda"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements/constraints"
+)
+
+// brands maps the NVML brand types to the values used for the brand property.
+// These match the values used in the requirements of CUDA images.
+var brands = map[nvml.BrandType]string{
+	nvml.BRAND_UNKNOWN:             "unknown",
+	nvml.BRAND_QUADRO:              "quadro",
+	nvml.BRAND_TESLA:               "tesla",
+	nvml.BRAND_NVS:                 "nvs",
+	nvml.BRAND_GRID:                "grid",
+	nvml.BRAND_GEFORCE:             "geforce",
+	nvml.BRAND_TITAN:               "titan",
+	nvml.BRAND_NVIDIA_VAPPS:        "vapps",
+	nvml.BRAND_NVIDIA_VPC:          "vpc",
+	nvml.BRAND_NVIDIA_VCS:          "vcs",
+	nvml.BRAND_NVIDIA_VWS:          "vws",
+	nvml.BRAND_NVIDIA_CLOUD_GAMING: "cloudgaming",
+	nvml.BRAND_QUADRO_RTX:          "quadrortx",
+	nvml.BRAND_NVIDIA_RTX:          "nvidiartx",
+	nvml.BRAND_NVIDIA:              "nvidia",
+	nvml.BRAND_GEFORCE_RTX:         "geforcertx",
+	nvml.BRAND_TITAN_RTX:           "titanrtx",
+}
+
+// A Host determines the properties of the host and of the devices requested
+// by a container. These are used to check the requirements of the container.
+type Host struct {
+	logger    logger.Interface
+	driver    *root.Driver
+	nvmllib   nvml.Interface
+	devicelib device.Interface
+}
+
+// A HostOption is used to configure a Host.
+type HostOption func(*Host)
+
+// WithLogger sets the logger for the host.
+func WithLogger(logger logger.Interface) HostOption {
+	return func(h *Host) {
+		h.logger = logger
+	}
+}
+
+// WithDriver sets the driver used to locate the NVML library and the IMEX
+// channels.
+func WithDriver(driver *root.Driver) HostOption {
+	return func(h *Host) {
+		h.driver = driver
+	}
+}
+
+// WithNvmlLib sets the NVML library used to query the properties.
+func WithNvmlLib(nvmllib nvml.Interface) HostOption {
+	return func(h *Host) {
+		h.nvmllib = nvmllib
+	}
+}
+
+// NewHost creates a host with the specified options.
+func NewHost(opts ...HostOption) *Host {
+	h := &Host{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.logger == nil {
+		h.logger = logger.New()
+	}
+	if h.driver == nil {
+		h.driver = root.New(root.WithLogger(h.logger))
+	}
+	if h.nvmllib == nil {
+		h.nvmllib = h.getNvmlLib()
+	}
+	if h.devicelib == nil {
+		h.devicelib = device.New(h.nvmllib)
+	}
+	return h
+}
+
+func (h *Host) getNvmlLib() nvml.Interface {
+	var nvmlOpts []nvml.LibraryOption
+	candidates, err := h.driver.Libraries().Locate("libnvidia-ml.so.1")
+	if err != nil {
+		h.logger.Warningf("Ignoring error in locating libnvidia-ml.so.1: %v", err)
+	} else {
+		nvmlOpts = append(nvmlOpts, nvml.WithLibraryPath(candidates[0]))
+	}
+	return nvml.New(nvmlOpts...)
+}
+
+// AddProperties adds the properties of the host and of the specified devices
+// to the requirements. Devices are identified by index, UUID or MIG index
+// (i:j), or by the special value "all". Properties that cannot be determined
+// are not set and requirements that reference them are not met.
+func (h *Host) AddProperties(r *Requirements, ids ...string) {
+	if len(r.requirements) == 0 {
+		return
+	}
+
+	h.addImexProperty(r)
+
+	if ret := h.nvmllib.Init(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to initialize NVML: %v", ret)
+		h.addFallbackProperties(r)
+		return
+	}
+	defer func() {
+		_ = h.nvmllib.Shutdown()
+	}()
+
+	if version, ret := h.nvmllib.SystemGetCudaDriverVersion(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to get CUDA version: %v", ret)
+	} else {
+		r.AddVersionProperty(CUDA, fmt.Sprintf("%d.%d", version/1000, version%1000/10))
+	}
+	if version, ret := h.nvmllib.SystemGetDriverVersion(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to get driver version: %v", ret)
+	} else {
+		r.AddVersionProperty(DRIVER, version)
+	}
+
+	devices := h.getDevices(ids...)
+	r.AddIntegerProperty(GPUS, strconv.Itoa(len(devices)))
+	for _, d := range devices {
+		r.AddDevice(d.id, h.getDeviceProperties(d)...)
+	}
+}
+
+// MissingDevices returns the specified device identifiers that do not refer to
+// a device on the host. The special values "all", "none" and "void" are never
+// reported as missing.
+func (h *Host) MissingDevices(ids ...string) ([]string, error) {
+	if ret := h.nvmllib.Init(); ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to initialize NVML: %v", ret)
+	}
+	defer func() {
+		_ = h.nvmllib.Shutdown()
+	}()
+
+	var missing []string
+	for _, id := range ids {
+		switch id {
+		case "", "all", "none", "void":
+			continue
+		}
+		if _, err := h.getDevice(id); err != nil {
+			h.logger.Debugf("Device %v not found: %v", id, err)
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// addImexProperty sets whether IMEX channels are available on the host.
+func (h *Host) addImexProperty(r *Requirements) {
+	channels, err := filepath.Glob(filepath.Join(h.driver.DevRoot, "dev", "nvidia-caps-imex-channels", "channel*"))
+	if err != nil {
+		h.logger.Warningf("Failed to check for IMEX channels: %v", err)
+		return
+	}
+	r.AddBooleanProperty(IMEX, strconv.FormatBool(len(channels) > 0))
+}
+
+// addFallbackProperties sets the CUDA version, compute capability and driver
+// version without using NVML. This is the case on Tegra-based systems, for
+// example.
+func (h *Host) addFallbackProperties(r *Requirements) {
+	if cudaVersion, err := cuda.Version(); err != nil {
+		h.logger.Warningf("Failed to get CUDA version: %v", err)
+	} else {
+		r.AddVersionProperty(CUDA, cudaVersion)
+	}
+	if computeCapability, err := cuda.ComputeCapability(0); err != nil {
+		h.logger.Warningf("Failed to get CUDA Compute Capability: %v", err)
+	} else {
+		r.AddVersionProperty(ARCH, computeCapability)
+	}
+	if driverVersion, err := h.driver.Version(); err != nil {
+		h.logger.Warningf("Failed to get driver version: %v", err)
+	} else {
+		r.AddVersionProperty(DRIVER, driverVersion)
+	}
+}
+
+// A hostDevice is a device requested by a container. For MIG devices, the
+// parent refers to the full GPU.
+type hostDevice struct {
+	id     string
+	parent device.Device
+	mig    device.MigDevice
+	handle nvml.Device
+}
+
+// getDevices returns the devices for the specified identifiers. Identifiers
+// that do not refer to a device are ignored.
+func (h *Host) getDevices(ids ...string) []hostDevice {
+	var devices []hostDevice
+	for _, id := range ids {
+		switch id {
+		case "", "none", "void":
+			continue
+		case "all":
+			err := h.devicelib.VisitDevices(func(i int, d device.Device) error {
+				devices = append(devices, hostDevice{id: strconv.Itoa(i), parent: d, handle: d})
+				return nil
+			})
+			if err != nil {
+				h.logger.Warningf("Failed to get devices: %v", err)
+			}
+			continue
+		}
+
+		d, err := h.getDevice(id)
+		if err != nil {
+			h.logger.Warningf("Ignoring device %v: %v", id, err)
+			continue
+		}
+		devices = append(devices, *d)
+	}
+	return devices
+}
+
+func (h *Host) getDevice(id string) (*hostDevice, error) {
+	var handle nvml.Device
+	var ret nvml.Return
+	switch {
+	case strings.HasPrefix(id, "GPU-") || strings.HasPrefix(id, "MIG-"):
+		handle, ret = h.nvmllib.DeviceGetHandleByUUID(id)
+	case strings.Contains(id, ":"):
+		gpu, mig, _ := strings.Cut(id, ":")
+		i, err := strconv.Atoi(gpu)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GPU index: %w", err)
+		}
+		j, err := strconv.Atoi(mig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MIG device index: %w", err)
+		}
+		handle, ret = h.nvmllib.DeviceGetHandleByIndex(i)
+		if ret == nvml.SUCCESS {
+			handle, ret = handle.GetMigDeviceHandleByIndex(j)
+		}
+	default:
+		i, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized device identifier")
+		}
+		handle, ret = h.nvmllib.DeviceGetHandleByIndex(i)
+	}
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get device handle: %v", ret)
+	}
+
+	d := hostDevice{
+		id:     id,
+		handle: handle,
+	}
+
+	parentHandle := handle
+	if isMig, ret := handle.IsMigDeviceHandle(); ret == nvml.SUCCESS && isMig {
+		mig, err := h.devicelib.NewMigDevice(handle)
+		if err != nil {
+			return nil, err
+		}
+		d.mig = mig
+		parentHandle, ret = handle.GetDeviceHandleFromMigDeviceHandle()
+		if ret != nvml.SUCCESS {
+			return nil, fmt.Errorf("failed to get parent device handle: %v", ret)
+		}
+	}
+
+	parent, err := h.devicelib.NewDevice(parentHandle)
+	if err != nil {
+		return nil, err
+	}
+	d.parent = parent
+
+	return &d, nil
+}
+
+// getDeviceProperties returns the properties of the specified device.
+// Properties that cannot be determined are skipped.
+func (h *Host) getDeviceProperties(d hostDevice) []constraints.Property {
+	var properties []constraints.Property
+
+	if major, minor, ret := d.parent.GetCudaComputeCapability(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to get CUDA Compute Capability of device %v: %v", d.id, ret)
+	} else {
+		properties = append(properties, constraints.NewVersionProperty(ARCH, fmt.Sprintf("%d.%d", major, minor)))
+	}
+
+	if brand, ret := d.parent.GetBrand(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to get brand of device %v: %v", d.id, ret)
+	} else {
+		properties = append(properties, constraints.NewStringProperty(BRAND, brands[brand]))
+	}
+
+	if name, ret := d.parent.GetName(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to get name of device %v: %v", d.id, ret)
+	} else {
+		properties = append(properties, constraints.NewPatternProperty(PRODUCT, name))
+	}
+
+	if memory, ret := d.handle.GetMemoryInfo(); ret != nvml.SUCCESS {
+		h.logger.Warningf("Failed to get memory of device %v: %v", d.id, ret)
+	} else {
+		properties = append(properties, constraints.NewSizeProperty(MEMORY, strconv.FormatUint(memory.Total>>20, 10)))
+	}
+
+	migProfile := ""
+	isMig := d.mig != nil
+	if isMig {
+		profile, err := d.mig.GetProfile()
+		if err != nil {
+			h.logger.Warningf("Failed to get MIG profile of device %v: %v", d.id, err)
+		} else {
+			migProfile = profile.String()
+		}
+	} else {
+		migEnabled, err := d.parent.IsMigEnabled()
+		if err != nil {
+			h.logger.Warningf("Failed to check MIG mode of device %v: %v", d.id, err)
+		}
+		isMig = migEnabled
+	}
+	properties = append(properties,
+		constraints.NewBooleanProperty(MIG, strconv.FormatBool(isMig)),
+		constraints.NewPatternProperty(MIGPROFILE, migProfile),
+	)
+
+	if fabricAttached, err := d.parent.IsFabricAttached(); err != nil {
+		h.logger.Warningf("Failed to get fabric state of device %v: %v", d.id, err)
+	} else {
+		properties = append(properties, constraints.NewBooleanProperty(FABRICMANAGER, strconv.FormatBool(fabricAttached)))
+	}
+
+	return properties
+}
@@ -0,0 +1,144 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package requirements
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock/dgxa100"
+	mockserver "github.com/NVIDIA/go-nvml/pkg/nvml/mock/server"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+)
+
+func TestHostAddProperties(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description   string
+		requirements  []string
+		ids           []string
+		withImex      bool
+		expectedError string
+	}{
+		{
+			description:  "cuda version is satisfied",
+			requirements: []string{"cuda>=12.4"},
+			ids:          []string{"0"},
+		},
+		{
+			description:   "cuda version is not satisfied",
+			requirements:  []string{"cuda>=12.5"},
+			ids:           []string{"0"},
+			expectedError: "device 0: unsatisfied condition: cuda>=12.5 (cuda=12.4)",
+		},
+		{
+			description:  "device properties are satisfied",
+			requirements: []string{"memory>=40G,product=*a100*,brand=nvidia,arch>=8.0,mig=false"},
+			ids:          []string{"GPU-0"},
+		},
+		{
+			description:   "memory is checked for each device",
+			requirements:  []string{"memory>=80G"},
+			ids:           []string{"1", "2"},
+			expectedError: "device 1: unsatisfied condition: memory>=80G (memory=40960)",
+		},
+		{
+			description:  "number of gpus is satisfied for all",
+			requirements: []string{"gpus>=8"},
+			ids:          []string{"all"},
+		},
+		{
+			description:   "number of gpus is not satisfied",
+			requirements:  []string{"gpus>=2"},
+			ids:           []string{"0"},
+			expectedError: "device 0: unsatisfied condition: gpus>=2 (gpus=1)",
+		},
+		{
+			description:   "unknown devices are ignored",
+			requirements:  []string{"gpus>=1"},
+			ids:           []string{"42", "none"},
+			expectedError: "unsatisfied condition: gpus>=1 (gpus=0)",
+		},
+		{
+			description:   "fabric manager is not available",
+			requirements:  []string{"fabric-manager=true"},
+			ids:           []string{"0"},
+			expectedError: "device 0: unsatisfied condition: fabric-manager=true (fabric-manager=false)",
+		},
+		{
+			description:  "imex is available",
+			requirements: []string{"imex=true"},
+			withImex:     true,
+		},
+		{
+			description:   "imex is not available",
+			requirements:  []string{"imex=true"},
+			expectedError: "unsatisfied condition: imex=true (imex=false)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			devRoot := t.TempDir()
+			if tc.withImex {
+				channelsDir := filepath.Join(devRoot, "dev/nvidia-caps-imex-channels")
+				require.NoError(t, os.MkdirAll(channelsDir, 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(channelsDir, "channel0"), nil, 0600))
+			}
+
+			server := dgxa100.New()
+			mockOverrides(server)
+
+			host := NewHost(
+				WithLogger(logger),
+				WithDriver(root.New(root.WithLogger(logger), root.WithDriverRoot(devRoot))),
+				WithNvmlLib(server),
+			)
+
+			r := New(logger, tc.requirements)
+			host.AddProperties(r, tc.ids...)
+
+			err := r.Assert()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// mockOverrides sets predictable UUIDs and mocks the functions that are not
+// implemented by the mock server.
+func mockOverrides(server *mockserver.Server) {
+	for i, d := range server.Devices {
+		(d.(*mockserver.Device)).UUID = fmt.Sprintf("GPU-%d", i)
+		(d.(*mockserver.Device)).IsMigDeviceHandleFunc = func() (bool, nvml.Return) {
+			return false, nvml.SUCCESS
+		}
+		(d.(*mockserver.Device)).GetGpuFabricInfoFunc = func() (nvml.GpuFabricInfo, nvml.Return) {
+			return nvml.GpuFabricInfo{}, nvml.ERROR_NOT_SUPPORTED
+		}
+	}
+}
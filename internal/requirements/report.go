@@ -0,0 +1,190 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package requirements
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements/constraints"
+)
+
+// A Report describes the outcome of checking the requirements of a container
+// against the properties of the host and the requested devices.
+type Report struct {
+	// Requirements are the checked requirements.
+	Requirements []string `json:"requirements"`
+	// Properties are the known properties of the host.
+	Properties []string `json:"properties"`
+	// Checks hold the results of checking the requirements. There is one check
+	// for each requested device or a single check for the host if no devices
+	// were requested.
+	Checks []Check `json:"checks"`
+}
+
+// A Check holds the results of checking each of the requirements for a
+// device.
+type Check struct {
+	// Device is the identifier of the device. This is empty for the host.
+	Device string `json:"device,omitempty"`
+	// Properties are the known properties of the device.
+	Properties []string `json:"properties"`
+	// Results are the results of evaluating each requirement.
+	Results []constraints.Result `json:"results"`
+}
+
+// An UnsatisfiedError is returned if the requirements are not met. It
+// includes a report describing the failure.
+type UnsatisfiedError struct {
+	Err    error
+	Report *Report
+}
+
+func (e *UnsatisfiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UnsatisfiedError) Unwrap() error {
+	return e.Err
+}
+
+// Report evaluates the requirements against the properties of the host and
+// each of the devices.
+func (r Requirements) Report() (*Report, error) {
+	report := &Report{
+		Requirements: r.requirements,
+		Properties:   knownProperties(slices.Collect(maps.Values(r.properties))...),
+	}
+
+	devices := r.devices
+	if len(devices) == 0 {
+		devices = []deviceProperties{{}}
+	}
+	for _, d := range devices {
+		properties := maps.Clone(r.properties)
+		for _, p := range d.properties {
+			properties[p.Name()] = p
+		}
+		check := Check{
+			Device:     d.id,
+			Properties: knownProperties(d.properties...),
+		}
+		for _, requirement := range r.requirements {
+			c, err := constraints.New(r.logger, []string{requirement}, properties)
+			if err != nil {
+				return nil, err
+			}
+			check.Results = append(check.Results, constraints.Evaluate(c))
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// knownProperties returns the sorted string representations of the properties
+// whose values are known.
+func knownProperties(properties ...constraints.Property) []string {
+	var known []string
+	for _, p := range properties {
+		if value, err := p.Value(); err != nil || value == "" {
+			continue
+		}
+		known = append(known, p.String())
+	}
+	slices.Sort(known)
+	return known
+}
+
+// Satisfied returns true if all requirements are met for all devices.
+func (r *Report) Satisfied() bool {
+	for _, check := range r.Checks {
+		for _, result := range check.Results {
+			if !result.Satisfied {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns a human-readable representation of the report. For each
+// requirement that is not met, the results of all clauses are included.
+func (r *Report) String() string {
+	var b strings.Builder
+
+	if r.Satisfied() {
+		b.WriteString("The NVIDIA_REQUIRE_* requirements of the container are met.\n")
+	} else {
+		b.WriteString("The NVIDIA_REQUIRE_* requirements of the container are not met.\n")
+	}
+
+	b.WriteString("Requirements:\n")
+	for i, requirement := range r.Requirements {
+		fmt.Fprintf(&b, "  [%d] %s\n", i+1, requirement)
+	}
+
+	fmt.Fprintf(&b, "Host properties:\n  %s\n", formatProperties(r.Properties))
+
+	for _, check := range r.Checks {
+		if check.Device != "" {
+			fmt.Fprintf(&b, "Device %s:\n  properties: %s\n", check.Device, formatProperties(check.Properties))
+		} else {
+			b.WriteString("Host:\n")
+		}
+		for i, result := range check.Results {
+			fmt.Fprintf(&b, "  [%d] %s\n", i+1, formatResult(result))
+			if result.Satisfied {
+				continue
+			}
+			for _, operand := range result.Operands {
+				writeResult(&b, operand, 6)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// writeResult writes the result of a clause and its operands with the
+// specified indentation.
+func writeResult(b *strings.Builder, result constraints.Result, indent int) {
+	fmt.Fprintf(b, "%s%s\n", strings.Repeat(" ", indent), formatResult(result))
+	for _, operand := range result.Operands {
+		writeResult(b, operand, indent+2)
+	}
+}
+
+func formatResult(result constraints.Result) string {
+	outcome := "met"
+	if !result.Satisfied {
+		outcome = "not met"
+	}
+	s := fmt.Sprintf("%s: %s", outcome, result.Constraint)
+	if result.Detail != "" {
+		s += fmt.Sprintf(" (%s)", result.Detail)
+	}
+	return s
+}
+
+func formatProperties(properties []string) string {
+	if len(properties) == 0 {
+		return "none"
+	}
+	return strings.Join(properties, ", ")
+}
@@ -0,0 +1,99 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package requirements
+
+import (
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements/constraints"
+)
+
+func TestReport(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description       string
+		requirements      []string
+		devices           map[string][]constraints.Property
+		expectedSatisfied bool
+		expectedReport    string
+	}{
+		{
+			description:       "requirements are met for the host",
+			requirements:      []string{"cuda>=12.0"},
+			expectedSatisfied: true,
+			expectedReport: `The NVIDIA_REQUIRE_* requirements of the container are met.
+Requirements:
+  [1] cuda>=12.0
+Host properties:
+  cuda=12.2, driver=535.104.05
+Host:
+  [1] met: cuda>=12.0 (cuda=12.2)
+`,
+		},
+		{
+			description:  "failing clauses are reported for each device",
+			requirements: []string{"cuda>=12.4 brand=tesla,driver<471", "memory>=40G"},
+			devices: map[string][]constraints.Property{
+				"0": {constraints.NewStringProperty(BRAND, "tesla")},
+			},
+			expectedReport: `The NVIDIA_REQUIRE_* requirements of the container are not met.
+Requirements:
+  [1] cuda>=12.4 brand=tesla,driver<471
+  [2] memory>=40G
+Host properties:
+  cuda=12.2, driver=535.104.05
+Device 0:
+  properties: brand=tesla
+  [1] not met: cuda>=12.4||brand=tesla&&driver<471
+      not met: cuda>=12.4 (cuda=12.2)
+      not met: brand=tesla&&driver<471
+        met: brand=tesla (brand=tesla)
+        not met: driver<471 (driver=535.104.05)
+  [2] not met: memory>=40G (memory could not be determined)
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := New(logger, tc.requirements)
+			r.AddVersionProperty(CUDA, "12.2")
+			r.AddVersionProperty(DRIVER, "535.104.05")
+			for id, properties := range tc.devices {
+				r.AddDevice(id, properties...)
+			}
+
+			report, err := r.Report()
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSatisfied, report.Satisfied())
+			require.Equal(t, tc.expectedReport, report.String())
+
+			err = r.Assert()
+			if tc.expectedSatisfied {
+				require.NoError(t, err)
+				return
+			}
+			var unsatisfied *UnsatisfiedError
+			require.ErrorAs(t, err, &unsatisfied)
+			require.Equal(t, report, unsatisfied.Report)
+		})
+	}
+}
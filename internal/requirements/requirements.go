@@ -17,6 +17,9 @@
 package requirements
 
 import (
+	"fmt"
+	"maps"
+
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements/constraints"
 )
@@ -26,6 +29,14 @@ type Requirements struct {
 	logger       logger.Interface
 	requirements []string
 	properties   map[string]constraints.Property
+	devices      []deviceProperties
+}
+
+// deviceProperties represent the properties of a requested device.
+// Requirements are checked against the properties of each device.
+type deviceProperties struct {
+	id         string
+	properties []constraints.Property
 }
 
 // New creates a new set of requirements
@@ -39,6 +50,14 @@ func New(logger logger.Interface, requirements []string) *Requirements {
 			ARCH:   constraints.NewVersionProperty(ARCH, ""),
 			DRIVER: constraints.NewVersionProperty(DRIVER, ""),
 			BRAND:  constraints.NewStringProperty(BRAND, ""),
+
+			FABRICMANAGER: constraints.NewBooleanProperty(FABRICMANAGER, ""),
+			GPUS:          constraints.NewIntegerProperty(GPUS, ""),
+			IMEX:          constraints.NewBooleanProperty(IMEX, ""),
+			MEMORY:        constraints.NewSizeProperty(MEMORY, ""),
+			MIG:           constraints.NewBooleanProperty(MIG, ""),
+			MIGPROFILE:    constraints.NewPatternProperty(MIGPROFILE, ""),
+			PRODUCT:       constraints.NewPatternProperty(PRODUCT, ""),
 		},
 	}
 
@@ -55,14 +74,63 @@ func (r *Requirements) AddStringProperty(name string, value string) {
 	r.properties[name] = constraints.NewStringProperty(name, value)
 }
 
-// Assert checks the specified requirements
+// AddIntegerProperty adds the specified property (name, value pair) to the requirements
+func (r *Requirements) AddIntegerProperty(name string, value string) {
+	r.properties[name] = constraints.NewIntegerProperty(name, value)
+}
+
+// AddBooleanProperty adds the specified property (name, value pair) to the requirements
+func (r *Requirements) AddBooleanProperty(name string, value string) {
+	r.properties[name] = constraints.NewBooleanProperty(name, value)
+}
+
+// AddDevice adds a requested device with the specified properties. If devices
+// are added, the requirements are checked against the properties of each
+// device with the device properties overriding the global properties.
+func (r *Requirements) AddDevice(id string, properties ...constraints.Property) {
+	r.devices = append(r.devices, deviceProperties{id: id, properties: properties})
+}
+
+// Assert checks the specified requirements. If the requirements are not met,
+// an UnsatisfiedError including a report of the checked requirements is
+// returned.
 func (r Requirements) Assert() error {
 	if len(r.requirements) == 0 {
 		return nil
 	}
 
-	r.logger.Debugf("Checking properties %+v against requirements %v", r.properties, r.requirements)
-	c, err := constraints.New(r.logger, r.requirements, r.properties)
+	err := r.assertDevices()
+	if err == nil {
+		return nil
+	}
+	report, reportErr := r.Report()
+	if reportErr != nil {
+		r.logger.Warningf("Failed to create requirements report: %v", reportErr)
+		return err
+	}
+	return &UnsatisfiedError{Err: err, Report: report}
+}
+
+func (r Requirements) assertDevices() error {
+	if len(r.devices) == 0 {
+		return r.assert(r.properties)
+	}
+
+	for _, d := range r.devices {
+		properties := maps.Clone(r.properties)
+		for _, p := range d.properties {
+			properties[p.Name()] = p
+		}
+		if err := r.assert(properties); err != nil {
+			return fmt.Errorf("device %v: %w", d.id, err)
+		}
+	}
+	return nil
+}
+
+func (r Requirements) assert(properties map[string]constraints.Property) error {
+	r.logger.Debugf("Checking properties %+v against requirements %v", properties, r.requirements)
+	c, err := constraints.New(r.logger, r.requirements, properties)
 	if err != nil {
 		return err
 	}
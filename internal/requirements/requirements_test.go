@@ -0,0 +1,116 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package requirements
+
+import (
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements/constraints"
+)
+
+func TestRequirementsAssert(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description   string
+		requirements  []string
+		devices       map[string][]constraints.Property
+		expectedError string
+	}{
+		{
+			description: "no requirements are always met",
+		},
+		{
+			description:  "global properties are checked without devices",
+			requirements: []string{"cuda>=12.0"},
+		},
+		{
+			description:  "device properties override global properties",
+			requirements: []string{"arch>=8.0"},
+			devices: map[string][]constraints.Property{
+				"0": {constraints.NewVersionProperty(ARCH, "9.0")},
+			},
+		},
+		{
+			description:  "requirements are checked for each device",
+			requirements: []string{"arch>=8.0"},
+			devices: map[string][]constraints.Property{
+				"0": {constraints.NewVersionProperty(ARCH, "9.0")},
+				"1": {constraints.NewVersionProperty(ARCH, "7.5")},
+			},
+			expectedError: "device 1: unsatisfied condition: arch>=8.0 (arch=7.5)",
+		},
+		{
+			description:   "unset device properties are not met",
+			requirements:  []string{"memory>=40G"},
+			devices:       map[string][]constraints.Property{"0": nil},
+			expectedError: `device 0: invalid value for memory: invalid size "": strconv.ParseUint: parsing "": invalid syntax`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := New(logger, tc.requirements)
+			r.AddVersionProperty(CUDA, "12.4")
+			for _, id := range []string{"0", "1"} {
+				if properties, ok := tc.devices[id]; ok {
+					r.AddDevice(id, properties...)
+				}
+			}
+
+			err := r.Assert()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestIsLegacy(t *testing.T) {
+	testCases := []struct {
+		requirement string
+		expected    bool
+	}{
+		{
+			requirement: "cuda>=12.4 brand=tesla,driver>=470,driver<471",
+			expected:    true,
+		},
+		{
+			requirement: "arch>=8.0",
+			expected:    true,
+		},
+		{
+			requirement: "cuda>=12.4 memory>=40G",
+			expected:    false,
+		},
+		{
+			requirement: "product=*A100*",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.requirement, func(t *testing.T) {
+			require.Equal(t, tc.expected, IsLegacy(tc.requirement))
+		})
+	}
+}
@@ -0,0 +1,243 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/oci"
+)
+
+const (
+	// dryRunFormatConfig outputs the modified OCI runtime specification.
+	dryRunFormatConfig = "config"
+	// dryRunFormatDiff outputs a unified diff between the original and the
+	// modified OCI runtime specification.
+	dryRunFormatDiff = "diff"
+	// dryRunFormatJSONPatch outputs an RFC 6902 JSON patch that transforms
+	// the original OCI runtime specification to the modified one.
+	dryRunFormatJSONPatch = "json-patch"
+)
+
+// dryRun applies the modifications that would be made to the OCI runtime
+// specification of the bundle on create and writes the result to the
+// specified writer. The low-level runtime is not invoked and the config.json
+// file in the bundle is not updated.
+func dryRun(logger logger.Interface, driver *root.Driver, cfg *config.Config, argv []string, w io.Writer) error {
+	format, err := getDryRunFormatFromArgs(argv)
+	if err != nil {
+		return err
+	}
+
+	ociSpec, err := oci.NewSpec(argv,
+		oci.WithLogger(logger),
+		oci.WithAllowUnknownFields(cfg.Features.AllowUnknownOCISpecFields.IsEnabled()),
+	)
+	if err != nil {
+		return fmt.Errorf("error constructing OCI specification: %v", err)
+	}
+
+	rawSpec, err := ociSpec.Load()
+	if err != nil {
+		return fmt.Errorf("error loading OCI specification: %v", err)
+	}
+	original, err := json.MarshalIndent(rawSpec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling OCI specification: %v", err)
+	}
+
+	// We apply the modifications to an in-memory copy of the spec to ensure
+	// that the bundle is never updated. Since no container is created, no
+	// audit record is written.
+	memorySpec := oci.NewMemorySpec(rawSpec)
+	specModifier, err := newSpecModifier(logger, driver, cfg, memorySpec, nil)
+	if err != nil {
+		return fmt.Errorf("failed to construct OCI spec modifier: %v", err)
+	}
+	if specModifier != nil {
+		if err := memorySpec.Modify(specModifier); err != nil {
+			return fmt.Errorf("error modifying OCI spec: %v", err)
+		}
+	}
+
+	modifiedSpec, _ := memorySpec.Load()
+	modified, err := json.MarshalIndent(modifiedSpec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling modified OCI specification: %v", err)
+	}
+
+	var output []byte
+	switch format {
+	case dryRunFormatConfig:
+		output = append(modified, '\n')
+	case dryRunFormatDiff:
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(original)),
+			B:        difflib.SplitLines(string(modified)),
+			FromFile: "a/" + oci.GetSpecFilePath(""),
+			ToFile:   "b/" + oci.GetSpecFilePath(""),
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("error generating diff: %v", err)
+		}
+		output = []byte(diff)
+	case dryRunFormatJSONPatch:
+		patch, err := createJSONPatch(original, modified)
+		if err != nil {
+			return fmt.Errorf("error generating JSON patch: %v", err)
+		}
+		output = append(patch, '\n')
+	}
+
+	_, err = w.Write(output)
+	return err
+}
+
+// getDryRunFormatFromArgs returns the output format requested through the
+// --format flag. If no format is specified, the modified config is output.
+func getDryRunFormatFromArgs(args []string) (string, error) {
+	format := dryRunFormatConfig
+	for i := 0; i < len(args); i++ {
+		parts := strings.SplitN(args[i], "=", 2)
+		if strings.TrimLeft(parts[0], "-") != "format" || !strings.HasPrefix(parts[0], "-") {
+			continue
+		}
+		switch {
+		case len(parts) == 2:
+			format = parts[1]
+		case i+1 < len(args):
+			format = args[i+1]
+			i++
+		default:
+			return "", fmt.Errorf("format option requires an argument")
+		}
+	}
+
+	switch format {
+	case dryRunFormatConfig, dryRunFormatDiff, dryRunFormatJSONPatch:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported dry-run format %q; one of [%v, %v, %v] is expected", format, dryRunFormatConfig, dryRunFormatDiff, dryRunFormatJSONPatch)
+}
+
+type jsonPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON ensures that the value of an operation is always included --
+// even if it is null, false, or zero -- except for remove operations which do
+// not take a value.
+func (o jsonPatchOperation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type operation jsonPatchOperation
+	return json.Marshal(operation(o))
+}
+
+// createJSONPatch creates an RFC 6902 JSON patch that transforms the original
+// JSON document to the modified one.
+func createJSONPatch(original []byte, modified []byte) ([]byte, error) {
+	var a, b any
+	if err := json.Unmarshal(original, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(modified, &b); err != nil {
+		return nil, err
+	}
+
+	operations := []jsonPatchOperation{}
+	operations = appendJSONPatchOperations(operations, "", a, b)
+
+	return json.MarshalIndent(operations, "", "  ")
+}
+
+func appendJSONPatchOperations(operations []jsonPatchOperation, path string, a any, b any) []jsonPatchOperation {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, key := range slices.Sorted(maps.Keys(a)) {
+			keyPath := path + "/" + escapeJSONPointer(key)
+			if _, exists := b[key]; !exists {
+				operations = append(operations, jsonPatchOperation{Op: "remove", Path: keyPath})
+				continue
+			}
+			operations = appendJSONPatchOperations(operations, keyPath, a[key], b[key])
+		}
+		for _, key := range slices.Sorted(maps.Keys(b)) {
+			if _, exists := a[key]; exists {
+				continue
+			}
+			operations = append(operations, jsonPatchOperation{Op: "add", Path: path + "/" + escapeJSONPointer(key), Value: b[key]})
+		}
+		return operations
+	case []any:
+		b, ok := b.([]any)
+		if !ok {
+			break
+		}
+		common := min(len(a), len(b))
+		for i := 0; i < common; i++ {
+			operations = appendJSONPatchOperations(operations, path+"/"+strconv.Itoa(i), a[i], b[i])
+		}
+		for i := common; i < len(b); i++ {
+			operations = append(operations, jsonPatchOperation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: b[i]})
+		}
+		// Elements are removed from the end so that the indices of the
+		// remaining elements are not affected.
+		for i := len(a) - 1; i >= common; i-- {
+			operations = append(operations, jsonPatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		return operations
+	}
+
+	if jsonEqual(a, b) {
+		return operations
+	}
+	return append(operations, jsonPatchOperation{Op: "replace", Path: path, Value: b})
+}
+
+func jsonEqual(a any, b any) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
+// escapeJSONPointer escapes a reference token as per RFC 6901.
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
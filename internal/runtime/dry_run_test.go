@@ -0,0 +1,174 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+)
+
+func TestDryRun(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	driver := root.New(
+		root.WithDriverRoot("/nvidia/driver/root"),
+	)
+
+	testCases := []struct {
+		description    string
+		format         string
+		expectedError  bool
+		expectedOutput string
+	}{
+		{
+			description: "json-patch",
+			format:      "json-patch",
+			expectedOutput: `[
+  {
+    "op": "add",
+    "path": "/hooks",
+    "value": {
+      "prestart": [
+        {
+          "args": [
+            "nvidia-container-runtime-hook",
+            "prestart"
+          ],
+          "path": "/usr/bin/nvidia-container-runtime-hook"
+        }
+      ]
+    }
+  }
+]
+`,
+		},
+		{
+			description: "diff",
+			format:      "diff",
+			expectedOutput: `--- a/config.json
++++ b/config.json
+@@ -9,5 +9,16 @@
+       "NVIDIA_VISIBLE_DEVICES=all"
+     ],
+     "cwd": ""
++  },
++  "hooks": {
++    "prestart": [
++      {
++        "path": "/usr/bin/nvidia-container-runtime-hook",
++        "args": [
++          "nvidia-container-runtime-hook",
++          "prestart"
++        ]
++      }
++    ]
+   }
+ }
+`,
+		},
+		{
+			description:   "invalid format",
+			format:        "invalid",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			bundleDir := t.TempDir()
+			specFilePath := filepath.Join(bundleDir, "config.json")
+
+			spec := &specs.Spec{
+				Process: &specs.Process{
+					Env: []string{"NVIDIA_VISIBLE_DEVICES=all"},
+				},
+			}
+			specContents, err := json.Marshal(spec)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(specFilePath, specContents, 0600))
+
+			cfg := &config.Config{
+				NVIDIAContainerRuntimeConfig: config.RuntimeConfig{
+					Mode: "legacy",
+				},
+				NVIDIAContainerRuntimeHookConfig: config.RuntimeHookConfig{
+					Path: "/usr/bin/nvidia-container-runtime-hook",
+				},
+			}
+
+			output := &bytes.Buffer{}
+			argv := []string{"nvidia-container-runtime", "dry-run", "--bundle", bundleDir, "--format", tc.format}
+			err = dryRun(logger, driver, cfg, argv, output)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOutput, output.String())
+
+			// The bundle must not be modified.
+			contents, err := os.ReadFile(specFilePath)
+			require.NoError(t, err)
+			require.Equal(t, specContents, contents)
+		})
+	}
+}
+
+func TestCreateJSONPatch(t *testing.T) {
+	testCases := []struct {
+		description   string
+		original      string
+		modified      string
+		expectedPatch string
+	}{
+		{
+			description:   "no changes",
+			original:      `{"a": [1, 2]}`,
+			modified:      `{"a": [1, 2]}`,
+			expectedPatch: `[]`,
+		},
+		{
+			description:   "elements appended and removed",
+			original:      `{"a": [1, 2, 3], "b/c": "foo", "d": true}`,
+			modified:      `{"a": [1, 4], "b/c": "bar", "e": false}`,
+			expectedPatch: `[{"op":"replace","path":"/a/1","value":4},{"op":"remove","path":"/a/2"},{"op":"replace","path":"/b~1c","value":"bar"},{"op":"remove","path":"/d"},{"op":"add","path":"/e","value":false}]`,
+		},
+		{
+			description:   "null and zero values are included",
+			original:      `{"a": 1, "b": "foo"}`,
+			modified:      `{"a": 0, "b": null, "c": null}`,
+			expectedPatch: `[{"op":"replace","path":"/a","value":0},{"op":"replace","path":"/b","value":null},{"op":"add","path":"/c","value":null}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			patch, err := createJSONPatch([]byte(tc.original), []byte(tc.modified))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expectedPatch, string(patch))
+		})
+	}
+}
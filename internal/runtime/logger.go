@@ -45,8 +45,9 @@ func NewLogger() *Logger {
 	}
 }
 
-// Update constructs a Logger with a preddefined formatter
-func (l *Logger) Update(filename string, logLevel string, argv []string) {
+// Update constructs a Logger with a preddefined formatter.
+// A log format specified in argv takes precedence over the specified logFormat.
+func (l *Logger) Update(filename string, logLevel string, logFormat string, argv []string) {
 
 	configFromArgs := parseArgs(argv)
 
@@ -101,7 +102,11 @@ func (l *Logger) Update(filename string, logLevel string, argv []string) {
 		})
 	}
 
-	if configFromArgs.format == "json" {
+	format := configFromArgs.format
+	if format == "" {
+		format = logFormat
+	}
+	if format == logger.FormatJSON {
 		newLogger.SetFormatter(new(logrus.JSONFormatter))
 	}
 
@@ -125,6 +130,12 @@ func (l *Logger) Update(filename string, logLevel string, argv []string) {
 	}
 }
 
+// WithFields returns a logger that includes the specified fields in each log
+// record.
+func (l *Logger) WithFields(fields logger.Fields) logger.Interface {
+	return logger.WithFields(l.Interface, fields)
+}
+
 // Reset closes the log file (if any) and resets the logger output to what it
 // was before UpdateLogger was called.
 func (l *Logger) Reset() error {
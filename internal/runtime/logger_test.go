@@ -21,12 +21,14 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 )
 
 func TestLogger(t *testing.T) {
 	l := NewLogger()
 
-	l.Update("", "debug", nil)
+	l.Update("", "debug", "", nil)
 
 	ll := l.Interface.(*logrus.Logger)
 	require.Equal(t, logrus.DebugLevel, ll.Level)
@@ -34,3 +36,46 @@ func TestLogger(t *testing.T) {
 	lp := l.previousLogger.(*logrus.Logger)
 	require.Equal(t, logrus.InfoLevel, lp.Level)
 }
+
+func TestLoggerFormat(t *testing.T) {
+	testCases := []struct {
+		description string
+		logFormat   string
+		argv        []string
+		expectJSON  bool
+	}{
+		{
+			description: "default is text",
+		},
+		{
+			description: "json from config",
+			logFormat:   "json",
+			expectJSON:  true,
+		},
+		{
+			description: "argv takes precedence",
+			logFormat:   "json",
+			argv:        []string{"--log-format", "text"},
+		},
+		{
+			description: "json from argv",
+			argv:        []string{"--log-format=json"},
+			expectJSON:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			l := NewLogger()
+			l.Update("", "info", tc.logFormat, tc.argv)
+
+			ll := l.Interface.(*logrus.Logger)
+			_, isJSON := ll.Formatter.(*logrus.JSONFormatter)
+			require.Equal(t, tc.expectJSON, isJSON)
+
+			entry, ok := l.WithFields(logger.Fields{logger.FieldContainerID: "container-id"}).(*logrus.Entry)
+			require.True(t, ok)
+			require.Equal(t, "container-id", entry.Data[logger.FieldContainerID])
+		})
+	}
+}
@@ -19,6 +19,8 @@ package runtime
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -27,6 +29,8 @@ import (
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/oci"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/requirements"
 )
 
 // Run is an entry point that allows for idiomatic handling of errors
@@ -35,6 +39,7 @@ func (r rt) Run(argv []string) (rerr error) {
 	defer func() {
 		if rerr != nil {
 			r.logger.Errorf("%v", rerr)
+			writeRequirementsReport(os.Stderr, rerr)
 		}
 	}()
 
@@ -50,8 +55,14 @@ func (r rt) Run(argv []string) (rerr error) {
 	r.logger.Update(
 		cfg.NVIDIAContainerRuntimeConfig.DebugFilePath,
 		cfg.NVIDIAContainerRuntimeConfig.LogLevel,
+		cfg.NVIDIAContainerRuntimeConfig.LogFormat,
 		argv,
 	)
+	if containerID := oci.GetContainerIDFromArgs(argv); containerID != "" {
+		r.logger.Interface = r.logger.WithFields(logger.Fields{
+			logger.FieldContainerID: containerID,
+		})
+	}
 	defer func() {
 		if rerr != nil {
 			r.logger.Errorf("%v", rerr)
@@ -80,6 +91,10 @@ func (r rt) Run(argv []string) (rerr error) {
 	)
 
 	r.logger.Tracef("Command line arguments: %v", argv)
+	if oci.HasDryRunSubcommand(argv) {
+		return dryRun(r.logger, driver, cfg, argv, os.Stdout)
+	}
+
 	runtime, err := newNVIDIAContainerRuntime(r.logger, driver, cfg, argv)
 	if err != nil {
 		return fmt.Errorf("failed to create NVIDIA Container Runtime: %v", err)
@@ -95,6 +110,17 @@ func (r rt) Errorf(format string, args ...any) {
 	r.logger.Errorf(format, args...)
 }
 
+// writeRequirementsReport writes the report describing unmet container
+// requirements to the specified writer. This is shown to the user when the
+// container fails to start.
+func writeRequirementsReport(w io.Writer, err error) {
+	var unsatisfied *requirements.UnsatisfiedError
+	if !errors.As(err, &unsatisfied) {
+		return
+	}
+	fmt.Fprint(w, unsatisfied.Report)
+}
+
 // TODO: This should be refactored / combined with parseArgs in logger.
 func hasVersionFlag(args []string) bool {
 	for i := range args {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/api/config/v1"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/audit"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
@@ -50,7 +51,11 @@ func newNVIDIAContainerRuntime(logger logger.Interface, driver *root.Driver, cfg
 		return nil, fmt.Errorf("error constructing OCI specification: %v", err)
 	}
 
-	specModifier, err := newSpecModifier(logger, driver, cfg, ociSpec)
+	auditor := audit.New(
+		audit.WithPath(cfg.NVIDIAContainerRuntimeConfig.AuditLogFilePath),
+		audit.WithContainerID(oci.GetContainerIDFromArgs(argv)),
+	)
+	specModifier, err := newSpecModifier(logger, driver, cfg, ociSpec, auditor)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct OCI spec modifier: %v", err)
 	}
@@ -67,13 +72,23 @@ func newNVIDIAContainerRuntime(logger logger.Interface, driver *root.Driver, cfg
 }
 
 // newSpecModifier is a factory method that creates constructs an OCI spec modifer based on the provided config.
-func newSpecModifier(logger logger.Interface, driver *root.Driver, cfg *config.Config, ociSpec oci.Spec) (oci.SpecModifier, error) {
+// If an auditor is specified, a record of the device injection decisions is
+// written to it when the modifier is applied.
+func newSpecModifier(logger logger.Interface, driver *root.Driver, cfg *config.Config, ociSpec oci.Spec, auditor audit.Interface) (oci.SpecModifier, error) {
 	mode, image, err := initRuntimeModeAndImage(logger, cfg, ociSpec)
 	if err != nil {
 		return nil, err
 	}
+	logger = withModeAndDevices(logger, mode, image)
 
-	hookCreator := discover.NewHookCreator(discover.WithNVIDIACDIHookPath(cfg.NVIDIACTKConfig.Path))
+	hookPolicies, err := cfg.NVIDIACTKConfig.HookPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("invalid hook config: %w", err)
+	}
+	hookCreator := discover.NewHookCreator(
+		discover.WithNVIDIACDIHookPath(cfg.NVIDIACTKConfig.Path),
+		discover.WithHookPolicies(hookPolicies),
+	)
 	return modifier.New(
 		modifier.WithLogger(logger),
 		modifier.WithConfig(cfg),
@@ -81,6 +96,7 @@ func newSpecModifier(logger logger.Interface, driver *root.Driver, cfg *config.C
 		modifier.WithDriver(driver),
 		modifier.WithHookCreator(hookCreator),
 		modifier.WithRuntimeMode(mode),
+		modifier.WithAuditor(auditor),
 	)
 }
 
@@ -128,3 +144,12 @@ func initRuntimeModeAndImage(logger logger.Interface, cfg *config.Config, ociSpe
 
 	return initRuntimeModeAndImage(logger, cfg, ociSpec)
 }
+
+// withModeAndDevices returns a logger that includes the resolved runtime mode
+// and the requested devices in each log record.
+func withModeAndDevices(l logger.Interface, mode info.RuntimeMode, image *image.CUDA) logger.Interface {
+	return logger.WithFields(l, logger.Fields{
+		logger.FieldRuntimeMode:      string(mode),
+		logger.FieldRequestedDevices: image.VisibleDevices(),
+	})
+}
@@ -337,7 +337,7 @@ func TestNewSpecModifier(t *testing.T) {
 					return tc.spec, nil
 				},
 			}
-			m, err := newSpecModifier(logger, driver, tc.config, spec)
+			m, err := newSpecModifier(logger, driver, tc.config, spec, nil)
 			require.NoError(t, err)
 
 			err = m.Modify(tc.spec)
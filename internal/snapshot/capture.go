@@ -0,0 +1,415 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"golang.org/x/sys/unix"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/ldcache"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/lookup"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+// captureModes are the modes for which CDI specifications are generated to
+// determine the driver files that are recorded in a snapshot.
+var captureModes = []nvcdi.Mode{
+	nvcdi.ModeNvml,
+	nvcdi.ModeManagement,
+}
+
+// devRootPatterns are the patterns relative to the dev root for device nodes
+// that are always recorded in a snapshot.
+var devRootPatterns = []string{
+	"/dev/nvidia*",
+	"/dev/nvidia-caps/*",
+	"/dev/nvidia-caps-imex-channels/*",
+	"/dev/dri/*",
+	"/dev/dri/by-path/*",
+}
+
+// hostRootFiles are the files relative to the host root whose contents are
+// recorded in a snapshot.
+var hostRootFiles = []string{
+	"/proc/driver/nvidia-caps/mig-minors",
+	"/sys/module/firmware_class/parameters/path",
+}
+
+// Capture records a snapshot of the system. The NVML state of the system is
+// recorded along with the files that are required to generate CDI
+// specifications. These are the driver files and device nodes that are
+// referenced by the CDI specifications generated for the system, the ldcache,
+// and the relevant entries in /proc and /sys.
+func Capture(opts ...Option) (*Snapshot, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = logger.New()
+	}
+	if o.driverRoot == "" {
+		o.driverRoot = "/"
+	}
+	if o.devRoot == "" {
+		o.devRoot = o.driverRoot
+	}
+	if o.hostRoot == "" {
+		o.hostRoot = "/"
+	}
+	if o.nvmllib == nil {
+		o.nvmllib = o.getNvmlLib()
+	}
+
+	if ret := o.nvmllib.Init(); ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to initialize NVML: %v", ret)
+	}
+	defer func() {
+		_ = o.nvmllib.Shutdown()
+	}()
+
+	kernelRelease, err := getKernelRelease()
+	if err != nil {
+		return nil, err
+	}
+	o.kernelRelease = kernelRelease
+
+	s := &Snapshot{
+		Version:       Version,
+		DriverRoot:    o.driverRoot,
+		DevRoot:       o.devRoot,
+		HostRoot:      o.hostRoot,
+		KernelRelease: o.kernelRelease,
+	}
+
+	nvmlState, err := o.captureNVML()
+	if err != nil {
+		return nil, err
+	}
+	s.NVML = *nvmlState
+
+	files, err := o.captureFiles(nvmlState)
+	if err != nil {
+		return nil, err
+	}
+	s.Files = files
+
+	return s, nil
+}
+
+func (o *options) getNvmlLib() nvml.Interface {
+	var nvmlOpts []nvml.LibraryOption
+	locator := lookup.NewLibraryLocator(
+		lookup.WithLogger(o.logger),
+		lookup.WithRoot(o.driverRoot),
+	)
+	candidates, err := locator.Locate("libnvidia-ml.so.1")
+	if err != nil {
+		o.logger.Warningf("Ignoring error in locating libnvidia-ml.so.1: %v", err)
+	} else {
+		nvmlOpts = append(nvmlOpts, nvml.WithLibraryPath(candidates[0]))
+	}
+	return nvml.New(nvmlOpts...)
+}
+
+// captureNVML records the NVML state of the system.
+func (o *options) captureNVML() (*NVML, error) {
+	driverVersion, ret := o.nvmllib.SystemGetDriverVersion()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get driver version: %v", ret)
+	}
+	cudaDriverVersion, ret := o.nvmllib.SystemGetCudaDriverVersion()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get CUDA driver version: %v", ret)
+	}
+
+	n := &NVML{
+		DriverVersion:     driverVersion,
+		CUDADriverVersion: cudaDriverVersion,
+	}
+
+	devicelib := device.New(o.nvmllib)
+	var devices []nvml.Device
+	err := devicelib.VisitDevices(func(i int, d device.Device) error {
+		recorded, err := captureDevice(d)
+		if err != nil {
+			return fmt.Errorf("failed to record device %d: %w", i, err)
+		}
+		n.Devices = append(n.Devices, *recorded)
+		devices = append(devices, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range devices {
+		for j, other := range devices {
+			if i == j {
+				continue
+			}
+			level, ret := d.GetTopologyCommonAncestor(other)
+			if ret != nvml.SUCCESS {
+				continue
+			}
+			if n.Devices[i].TopologyCommonAncestors == nil {
+				n.Devices[i].TopologyCommonAncestors = make(map[string]nvml.GpuTopologyLevel)
+			}
+			n.Devices[i].TopologyCommonAncestors[n.Devices[j].UUID] = level
+		}
+	}
+
+	return n, nil
+}
+
+// captureDevice records the properties of the specified device. Errors are
+// only returned for properties that are required to generate CDI
+// specifications.
+func captureDevice(d device.Device) (*Device, error) {
+	uuid, ret := d.GetUUID()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get UUID: %v", ret)
+	}
+	name, ret := d.GetName()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get name: %v", ret)
+	}
+	minor, ret := d.GetMinorNumber()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get minor number: %v", ret)
+	}
+	pciInfo, ret := d.GetPciInfo()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get PCI info: %v", ret)
+	}
+
+	recorded := &Device{
+		UUID:        uuid,
+		Name:        name,
+		PCIBusID:    pciBusIDFromInfo(pciInfo),
+		MinorNumber: minor,
+	}
+
+	if brand, ret := d.GetBrand(); ret == nvml.SUCCESS {
+		recorded.Brand = brand
+	}
+	if architecture, ret := d.GetArchitecture(); ret == nvml.SUCCESS {
+		recorded.Architecture = architecture
+	}
+	if major, minor, ret := d.GetCudaComputeCapability(); ret == nvml.SUCCESS {
+		recorded.CUDAComputeCapability = ComputeCapability{Major: major, Minor: minor}
+	}
+	if memory, ret := d.GetMemoryInfo(); ret == nvml.SUCCESS {
+		recorded.MemoryTotal = memory.Total
+	}
+	if numaNode, ret := d.GetNumaNodeId(); ret == nvml.SUCCESS {
+		recorded.NUMANode = &numaNode
+	}
+	if mode, ret := d.GetAddressingMode(); ret == nvml.SUCCESS {
+		recorded.AddressingMode = &mode.Value
+	}
+	if fabricInfo, ret := d.GetGpuFabricInfo(); ret == nvml.SUCCESS {
+		recorded.Fabric = newFabricInfo(fabricInfo)
+	}
+
+	migMode, _, ret := d.GetMigMode()
+	if ret != nvml.SUCCESS {
+		return recorded, nil
+	}
+	recorded.MIGMode = &migMode
+
+	maxMIGDeviceCount, ret := d.GetMaxMigDeviceCount()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get max MIG device count: %v", ret)
+	}
+	recorded.MaxMIGDeviceCount = maxMIGDeviceCount
+
+	err := d.VisitMigDevices(func(j int, m device.MigDevice) error {
+		recordedMIGDevice, err := captureMIGDevice(j, m)
+		if err != nil {
+			return fmt.Errorf("failed to record MIG device %d: %w", j, err)
+		}
+		recorded.MIGDevices = append(recorded.MIGDevices, *recordedMIGDevice)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recorded, nil
+}
+
+func captureMIGDevice(index int, m device.MigDevice) (*MIGDevice, error) {
+	uuid, ret := m.GetUUID()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get UUID: %v", ret)
+	}
+	giID, ret := m.GetGpuInstanceId()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get GPU instance ID: %v", ret)
+	}
+	ciID, ret := m.GetComputeInstanceId()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get compute instance ID: %v", ret)
+	}
+	attributes, ret := m.GetAttributes()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get attributes: %v", ret)
+	}
+	profile, err := m.GetProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	info := profile.GetInfo()
+
+	recorded := &MIGDevice{
+		Index:                        index,
+		UUID:                         uuid,
+		GPUInstanceID:                giID,
+		ComputeInstanceID:            ciID,
+		GPUInstanceProfile:           info.GIProfileID,
+		ComputeInstanceProfile:       info.CIProfileID,
+		ComputeInstanceEngineProfile: info.CIEngProfileID,
+		MemorySizeMB:                 attributes.MemorySizeMB,
+	}
+	return recorded, nil
+}
+
+// captureFiles records the files required to generate CDI specifications.
+func (o *options) captureFiles(n *NVML) ([]File, error) {
+	r := newRecorder(o.logger)
+
+	ldcachePath := filepath.Join(o.driverRoot, "/etc/ld.so.cache")
+	if err := r.add(ldcachePath, true); err != nil {
+		return nil, err
+	}
+	if cache, err := ldcache.New(o.logger, o.driverRoot); err != nil {
+		o.logger.Warningf("Ignoring error loading ldcache: %v", err)
+	} else {
+		libs32, libs64 := cache.List()
+		for _, lib := range append(libs32, libs64...) {
+			if err := r.add(lib, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, mode := range captureModes {
+		if err := o.captureFilesForMode(r, mode); err != nil {
+			o.logger.Warningf("Ignoring error determining files for mode %v: %v", mode, err)
+		}
+	}
+
+	for _, pattern := range devRootPatterns {
+		if err := r.addGlob(filepath.Join(o.devRoot, pattern)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range hostRootFiles {
+		if err := r.add(filepath.Join(o.hostRoot, file), true); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range n.Devices {
+		drmDir := filepath.Join(o.hostRoot, "/sys/bus/pci/devices", normalizePCIBusID(d.PCIBusID), "drm")
+		if err := r.addGlob(filepath.Join(drmDir, "*")); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.files(), nil
+}
+
+// captureFilesForMode records the files that are referenced by the CDI
+// specifications generated for the specified mode. For each referenced file,
+// the files in the same directory that share its name up to the first '.'
+// are also recorded. This ensures that the symlinks that are created by the
+// CDI hooks are also included.
+func (o *options) captureFilesForMode(r *recorder, mode nvcdi.Mode) error {
+	cdilib, err := nvcdi.New(
+		nvcdi.WithLogger(o.logger),
+		nvcdi.WithNvmlLib(o.nvmllib),
+		nvcdi.WithDriverRoot(o.driverRoot),
+		nvcdi.WithDevRoot(o.devRoot),
+		nvcdi.WithHostRoot(o.hostRoot),
+		nvcdi.WithKernelRelease(o.kernelRelease),
+		nvcdi.WithMode(mode),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create CDI library: %w", err)
+	}
+
+	deviceSpecs, err := cdilib.GetDeviceSpecsByID("all")
+	if err != nil {
+		return fmt.Errorf("failed to get device specs: %w", err)
+	}
+	commonEdits, err := cdilib.GetCommonEdits()
+	if err != nil {
+		return fmt.Errorf("failed to get common edits: %w", err)
+	}
+
+	edits := []*specs.ContainerEdits{commonEdits.ContainerEdits}
+	for i := range deviceSpecs {
+		edits = append(edits, &deviceSpecs[i].ContainerEdits)
+	}
+
+	for _, e := range edits {
+		for _, mount := range e.Mounts {
+			if err := r.addWithSiblings(mount.HostPath); err != nil {
+				return err
+			}
+		}
+		for _, dn := range e.DeviceNodes {
+			hostPath := dn.HostPath
+			if hostPath == "" {
+				hostPath = dn.Path
+			}
+			if err := r.add(hostPath, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getKernelRelease returns the release of the running kernel.
+func getKernelRelease() (string, error) {
+	utsname := &unix.Utsname{}
+	if err := unix.Uname(utsname); err != nil {
+		return "", fmt.Errorf("failed to get kernel release: %w", err)
+	}
+	return unix.ByteSliceToString(utsname.Release[:]), nil
+}
+
+// normalizePCIBusID converts a PCI bus ID as returned by NVML to the form
+// used in sysfs.
+func normalizePCIBusID(busID string) string {
+	id := strings.ToLower(busID)
+	// NVML reports an 8-character domain, whereas sysfs uses 4 characters.
+	if parts := strings.SplitN(id, ":", 2); len(parts) == 2 && len(parts[0]) == 8 {
+		id = parts[0][4:] + ":" + parts[1]
+	}
+	return id
+}
@@ -0,0 +1,111 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+)
+
+// newELFStub returns the contents of a minimal ELF file whose dynamic section
+// only contains the specified SONAME. If the SONAME is empty, the dynamic
+// section is empty. This allows the SONAME of a recorded library to be queried
+// when a snapshot is replayed without recording the library itself.
+func newELFStub(soname string) []byte {
+	const (
+		headerSize  = 64
+		sectionSize = 64
+	)
+
+	dynstr := []byte{0}
+	var dynamic []elf.Dyn64
+	if soname != "" {
+		dynamic = append(dynamic, elf.Dyn64{Tag: int64(elf.DT_SONAME), Val: uint64(len(dynstr))})
+		dynstr = append(dynstr, append([]byte(soname), 0)...)
+	}
+	dynamic = append(dynamic, elf.Dyn64{Tag: int64(elf.DT_NULL)})
+	shstrtab := []byte("\x00.dynstr\x00.dynamic\x00.shstrtab\x00")
+
+	dynstrOffset := uint64(headerSize)
+	dynamicOffset := align(dynstrOffset+uint64(len(dynstr)), 8)
+	dynamicSize := uint64(len(dynamic) * 16)
+	shstrtabOffset := dynamicOffset + dynamicSize
+	sectionsOffset := align(shstrtabOffset+uint64(len(shstrtab)), 8)
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_DYN),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     sectionsOffset,
+		Ehsize:    headerSize,
+		Shentsize: sectionSize,
+		Shnum:     4,
+		Shstrndx:  3,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	sections := []elf.Section64{
+		{},
+		{
+			Name:      1,
+			Type:      uint32(elf.SHT_STRTAB),
+			Off:       dynstrOffset,
+			Size:      uint64(len(dynstr)),
+			Addralign: 1,
+		},
+		{
+			Name:      9,
+			Type:      uint32(elf.SHT_DYNAMIC),
+			Off:       dynamicOffset,
+			Size:      dynamicSize,
+			Link:      1,
+			Addralign: 8,
+			Entsize:   16,
+		},
+		{
+			Name:      18,
+			Type:      uint32(elf.SHT_STRTAB),
+			Off:       shstrtabOffset,
+			Size:      uint64(len(shstrtab)),
+			Addralign: 1,
+		},
+	}
+
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.LittleEndian, header)
+	b.Write(dynstr)
+	pad(&b, dynamicOffset)
+	_ = binary.Write(&b, binary.LittleEndian, dynamic)
+	b.Write(shstrtab)
+	pad(&b, sectionsOffset)
+	_ = binary.Write(&b, binary.LittleEndian, sections)
+	return b.Bytes()
+}
+
+func align(offset uint64, alignment uint64) uint64 {
+	return (offset + alignment - 1) / alignment * alignment
+}
+
+func pad(b *bytes.Buffer, offset uint64) {
+	for uint64(b.Len()) < offset {
+		b.WriteByte(0)
+	}
+}
@@ -0,0 +1,334 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/google/uuid"
+)
+
+// Interface returns an NVML interface that replays the recorded NVML state.
+// Only the subset of the NVML API that is used to generate CDI specifications
+// is supported.
+func (n *NVML) Interface() nvml.Interface {
+	devices := make([]*replayedDevice, len(n.Devices))
+	handlesByUUID := make(map[string]nvml.Device)
+	for i := range n.Devices {
+		devices[i] = n.Devices[i].newMock(i)
+		handlesByUUID[n.Devices[i].UUID] = devices[i]
+	}
+	for i := range n.Devices {
+		d := &n.Devices[i]
+		devices[i].GetTopologyCommonAncestorFunc = func(other nvml.Device) (nvml.GpuTopologyLevel, nvml.Return) {
+			otherUUID, ret := other.GetUUID()
+			if ret != nvml.SUCCESS {
+				return 0, ret
+			}
+			level, ok := d.TopologyCommonAncestors[otherUUID]
+			if !ok {
+				return 0, nvml.ERROR_NOT_SUPPORTED
+			}
+			return level, nvml.SUCCESS
+		}
+		for _, m := range devices[i].migDevices {
+			handlesByUUID[m.uuid] = m
+		}
+	}
+
+	return &mock.Interface{
+		InitFunc: func() nvml.Return {
+			return nvml.SUCCESS
+		},
+		ShutdownFunc: func() nvml.Return {
+			return nvml.SUCCESS
+		},
+		ExtensionsFunc: func() nvml.ExtendedInterface {
+			return &mock.ExtendedInterface{
+				LookupSymbolFunc: func(string) error {
+					return nil
+				},
+			}
+		},
+		ErrorStringFunc: func(r nvml.Return) string {
+			return r.Error()
+		},
+		SystemGetDriverVersionFunc: func() (string, nvml.Return) {
+			return n.DriverVersion, nvml.SUCCESS
+		},
+		SystemGetCudaDriverVersionFunc: func() (int, nvml.Return) {
+			return n.CUDADriverVersion, nvml.SUCCESS
+		},
+		DeviceGetCountFunc: func() (int, nvml.Return) {
+			return len(devices), nvml.SUCCESS
+		},
+		DeviceGetHandleByIndexFunc: func(index int) (nvml.Device, nvml.Return) {
+			if index < 0 || index >= len(devices) {
+				return nil, nvml.ERROR_INVALID_ARGUMENT
+			}
+			return devices[index], nvml.SUCCESS
+		},
+		DeviceGetHandleByUUIDFunc: func(uuid string) (nvml.Device, nvml.Return) {
+			d, ok := handlesByUUID[uuid]
+			if !ok {
+				return nil, nvml.ERROR_NOT_FOUND
+			}
+			return d, nvml.SUCCESS
+		},
+	}
+}
+
+// A replayedDevice is an NVML device that replays a recorded device.
+type replayedDevice struct {
+	*mock.Device
+	migDevices []*replayedMIGDevice
+}
+
+// A replayedMIGDevice is an NVML device that replays a recorded MIG device.
+type replayedMIGDevice struct {
+	*mock.Device
+	uuid string
+}
+
+func (d *Device) newMock(index int) *replayedDevice {
+	m := &replayedDevice{
+		Device: &mock.Device{
+			IsMigDeviceHandleFunc: func() (bool, nvml.Return) {
+				return false, nvml.SUCCESS
+			},
+			GetDeviceHandleFromMigDeviceHandleFunc: func() (nvml.Device, nvml.Return) {
+				return nil, nvml.ERROR_INVALID_ARGUMENT
+			},
+			GetIndexFunc: func() (int, nvml.Return) {
+				return index, nvml.SUCCESS
+			},
+			GetUUIDFunc: func() (string, nvml.Return) {
+				return d.UUID, nvml.SUCCESS
+			},
+			GetNameFunc: func() (string, nvml.Return) {
+				return d.Name, nvml.SUCCESS
+			},
+			GetBrandFunc: func() (nvml.BrandType, nvml.Return) {
+				return d.Brand, nvml.SUCCESS
+			},
+			GetArchitectureFunc: func() (nvml.DeviceArchitecture, nvml.Return) {
+				return d.Architecture, nvml.SUCCESS
+			},
+			GetCudaComputeCapabilityFunc: func() (int, int, nvml.Return) {
+				return d.CUDAComputeCapability.Major, d.CUDAComputeCapability.Minor, nvml.SUCCESS
+			},
+			GetPciInfoFunc: func() (nvml.PciInfo, nvml.Return) {
+				return newPciInfo(d.PCIBusID), nvml.SUCCESS
+			},
+			GetMinorNumberFunc: func() (int, nvml.Return) {
+				return d.MinorNumber, nvml.SUCCESS
+			},
+			GetMemoryInfoFunc: func() (nvml.Memory, nvml.Return) {
+				return nvml.Memory{Total: d.MemoryTotal}, nvml.SUCCESS
+			},
+			GetNumaNodeIdFunc: func() (int, nvml.Return) {
+				if d.NUMANode == nil {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return *d.NUMANode, nvml.SUCCESS
+			},
+			GetAddressingModeFunc: func() (nvml.DeviceAddressingMode, nvml.Return) {
+				if d.AddressingMode == nil {
+					return nvml.DeviceAddressingMode{}, nvml.ERROR_NOT_SUPPORTED
+				}
+				return nvml.DeviceAddressingMode{Value: *d.AddressingMode}, nvml.SUCCESS
+			},
+			GetMigModeFunc: func() (int, int, nvml.Return) {
+				if d.MIGMode == nil {
+					return 0, 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				return *d.MIGMode, *d.MIGMode, nvml.SUCCESS
+			},
+			GetGpuFabricInfoFunc: func() (nvml.GpuFabricInfo, nvml.Return) {
+				if d.Fabric == nil {
+					return nvml.GpuFabricInfo{}, nvml.ERROR_NOT_SUPPORTED
+				}
+				return d.Fabric.toNVML(), nvml.SUCCESS
+			},
+			GetMaxMigDeviceCountFunc: func() (int, nvml.Return) {
+				return d.MaxMIGDeviceCount, nvml.SUCCESS
+			},
+		},
+	}
+
+	for i := range d.MIGDevices {
+		m.migDevices = append(m.migDevices, d.MIGDevices[i].newMock(m, d))
+	}
+
+	m.GetMigDeviceHandleByIndexFunc = func(index int) (nvml.Device, nvml.Return) {
+		for i, mig := range d.MIGDevices {
+			if mig.Index == index {
+				return m.migDevices[i], nvml.SUCCESS
+			}
+		}
+		return nil, nvml.ERROR_NOT_FOUND
+	}
+	// The IDs of the GPU instance and compute instance profiles are not
+	// recorded. We use the profile indices as IDs instead.
+	m.GetGpuInstanceProfileInfoFunc = func(profile int) (nvml.GpuInstanceProfileInfo, nvml.Return) {
+		for _, mig := range d.MIGDevices {
+			if mig.GPUInstanceProfile == profile {
+				return nvml.GpuInstanceProfileInfo{Id: uint32(profile)}, nvml.SUCCESS
+			}
+		}
+		return nvml.GpuInstanceProfileInfo{}, nvml.ERROR_NOT_SUPPORTED
+	}
+	m.GetGpuInstanceByIdFunc = func(id int) (nvml.GpuInstance, nvml.Return) {
+		for _, mig := range d.MIGDevices {
+			if mig.GPUInstanceID == id {
+				return d.newGPUInstanceMock(m, mig), nvml.SUCCESS
+			}
+		}
+		return nil, nvml.ERROR_NOT_FOUND
+	}
+
+	return m
+}
+
+func (d *Device) newGPUInstanceMock(parent *replayedDevice, mig MIGDevice) nvml.GpuInstance {
+	gi := &mock.GpuInstance{}
+	gi.GetInfoFunc = func() (nvml.GpuInstanceInfo, nvml.Return) {
+		info := nvml.GpuInstanceInfo{
+			Device:    parent,
+			Id:        uint32(mig.GPUInstanceID),
+			ProfileId: uint32(mig.GPUInstanceProfile),
+		}
+		return info, nvml.SUCCESS
+	}
+	gi.GetComputeInstanceProfileInfoFunc = func(profile int, engineProfile int) (nvml.ComputeInstanceProfileInfo, nvml.Return) {
+		for _, other := range d.MIGDevices {
+			if other.GPUInstanceID != mig.GPUInstanceID {
+				continue
+			}
+			if other.ComputeInstanceProfile == profile && other.ComputeInstanceEngineProfile == engineProfile {
+				return nvml.ComputeInstanceProfileInfo{Id: computeInstanceProfileID(profile, engineProfile)}, nvml.SUCCESS
+			}
+		}
+		return nvml.ComputeInstanceProfileInfo{}, nvml.ERROR_NOT_SUPPORTED
+	}
+	gi.GetComputeInstanceByIdFunc = func(id int) (nvml.ComputeInstance, nvml.Return) {
+		for _, other := range d.MIGDevices {
+			if other.GPUInstanceID != mig.GPUInstanceID || other.ComputeInstanceID != id {
+				continue
+			}
+			ci := &mock.ComputeInstance{
+				GetInfoFunc: func() (nvml.ComputeInstanceInfo, nvml.Return) {
+					info := nvml.ComputeInstanceInfo{
+						Device:      parent,
+						GpuInstance: gi,
+						Id:          uint32(other.ComputeInstanceID),
+						ProfileId:   computeInstanceProfileID(other.ComputeInstanceProfile, other.ComputeInstanceEngineProfile),
+					}
+					return info, nvml.SUCCESS
+				},
+			}
+			return ci, nvml.SUCCESS
+		}
+		return nil, nvml.ERROR_NOT_FOUND
+	}
+	return gi
+}
+
+func computeInstanceProfileID(profile int, engineProfile int) uint32 {
+	return uint32(profile*nvml.COMPUTE_INSTANCE_ENGINE_PROFILE_COUNT + engineProfile)
+}
+
+func (m *MIGDevice) newMock(parent *replayedDevice, parentDevice *Device) *replayedMIGDevice {
+	return &replayedMIGDevice{
+		uuid: m.UUID,
+		Device: &mock.Device{
+			IsMigDeviceHandleFunc: func() (bool, nvml.Return) {
+				return true, nvml.SUCCESS
+			},
+			GetDeviceHandleFromMigDeviceHandleFunc: func() (nvml.Device, nvml.Return) {
+				return parent, nvml.SUCCESS
+			},
+			GetIndexFunc: func() (int, nvml.Return) {
+				return m.Index, nvml.SUCCESS
+			},
+			GetUUIDFunc: func() (string, nvml.Return) {
+				return m.UUID, nvml.SUCCESS
+			},
+			GetNameFunc: func() (string, nvml.Return) {
+				return parentDevice.Name, nvml.SUCCESS
+			},
+			GetMinorNumberFunc: func() (int, nvml.Return) {
+				return 0, nvml.ERROR_NOT_SUPPORTED
+			},
+			GetPciInfoFunc: func() (nvml.PciInfo, nvml.Return) {
+				return newPciInfo(parentDevice.PCIBusID), nvml.SUCCESS
+			},
+			GetGpuInstanceIdFunc: func() (int, nvml.Return) {
+				return m.GPUInstanceID, nvml.SUCCESS
+			},
+			GetComputeInstanceIdFunc: func() (int, nvml.Return) {
+				return m.ComputeInstanceID, nvml.SUCCESS
+			},
+			GetAttributesFunc: func() (nvml.DeviceAttributes, nvml.Return) {
+				return nvml.DeviceAttributes{MemorySizeMB: m.MemorySizeMB}, nvml.SUCCESS
+			},
+			GetMemoryInfoFunc: func() (nvml.Memory, nvml.Return) {
+				return nvml.Memory{Total: m.MemorySizeMB * 1024 * 1024}, nvml.SUCCESS
+			},
+		},
+	}
+}
+
+func (f *FabricInfo) toNVML() nvml.GpuFabricInfo {
+	info := nvml.GpuFabricInfo{
+		CliqueId: f.CliqueID,
+		State:    f.State,
+		Status:   f.Status,
+	}
+	if clusterUUID, err := uuid.Parse(f.ClusterUUID); err == nil {
+		info.ClusterUuid = clusterUUID
+	}
+	return info
+}
+
+func newFabricInfo(info nvml.GpuFabricInfo) *FabricInfo {
+	return &FabricInfo{
+		ClusterUUID: uuid.UUID(info.ClusterUuid).String(),
+		CliqueID:    info.CliqueId,
+		State:       info.State,
+		Status:      info.Status,
+	}
+}
+
+func newPciInfo(busID string) nvml.PciInfo {
+	var info nvml.PciInfo
+	for i := 0; i < len(busID) && i < len(info.BusId)-1; i++ {
+		info.BusId[i] = int8(busID[i])
+	}
+	return info
+}
+
+func pciBusIDFromInfo(info nvml.PciInfo) string {
+	var bytes []byte
+	for _, b := range info.BusId {
+		if byte(b) == 0 {
+			break
+		}
+		bytes = append(bytes, byte(b))
+	}
+	return string(bytes)
+}
@@ -0,0 +1,75 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+type options struct {
+	logger     logger.Interface
+	nvmllib    nvml.Interface
+	driverRoot string
+	devRoot    string
+	hostRoot   string
+
+	// kernelRelease is the release of the running kernel and is determined
+	// when the snapshot is captured.
+	kernelRelease string
+}
+
+// Option is a functional option for capturing a snapshot.
+type Option func(*options)
+
+// WithLogger sets the logger used when capturing a snapshot.
+func WithLogger(logger logger.Interface) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithNvmlLib sets the NVML library that is queried when capturing a
+// snapshot.
+func WithNvmlLib(nvmllib nvml.Interface) Option {
+	return func(o *options) {
+		o.nvmllib = nvmllib
+	}
+}
+
+// WithDriverRoot sets the driver root for which the snapshot is captured.
+func WithDriverRoot(driverRoot string) Option {
+	return func(o *options) {
+		o.driverRoot = driverRoot
+	}
+}
+
+// WithDevRoot sets the root where /dev is located.
+func WithDevRoot(devRoot string) Option {
+	return func(o *options) {
+		o.devRoot = devRoot
+	}
+}
+
+// WithHostRoot sets the root under which the /proc and /sys filesystems are
+// queried.
+func WithHostRoot(hostRoot string) Option {
+	return func(o *options) {
+		o.hostRoot = hostRoot
+	}
+}
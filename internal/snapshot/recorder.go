@@ -0,0 +1,243 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+const (
+	// maxContentsSize is the maximum size of a file whose contents are
+	// recorded.
+	maxContentsSize = 4 * 1024 * 1024
+	// maxSymlinkDepth is the maximum number of symlinks that are followed when
+	// resolving a path.
+	maxSymlinkDepth = 40
+)
+
+// A recorder records files for a snapshot. Symlinks in the path of a recorded
+// file are also recorded so that the path can be resolved when the snapshot
+// is replayed.
+type recorder struct {
+	logger   logger.Interface
+	recorded map[string]File
+	resolved map[string]string
+}
+
+func newRecorder(logger logger.Interface) *recorder {
+	return &recorder{
+		logger:   logger,
+		recorded: make(map[string]File),
+		resolved: make(map[string]string),
+	}
+}
+
+// files returns the recorded files sorted by path.
+func (r *recorder) files() []File {
+	var files []File
+	for _, f := range r.recorded {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// addGlob records the files matching the specified pattern.
+func (r *recorder) addGlob(pattern string) error {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to glob %v: %w", pattern, err)
+	}
+	for _, m := range matches {
+		if err := r.add(m, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addWithSiblings records the specified file as well as the files in the
+// same directory whose name starts with the name of the file up to the first
+// '.'.
+func (r *recorder) addWithSiblings(path string) error {
+	if err := r.add(path, false); err != nil {
+		return err
+	}
+	dir, name := filepath.Split(path)
+	stem, _, _ := strings.Cut(name, ".")
+	if stem == "" {
+		return nil
+	}
+	return r.addGlob(filepath.Join(dir, globEscape(stem)+"*"))
+}
+
+// add records the specified file. If withContents is true, the contents of a
+// regular file are also recorded. Files that do not exist are ignored.
+func (r *recorder) add(path string, withContents bool) error {
+	return r.addWithDepth(filepath.Clean(path), withContents, 0)
+}
+
+func (r *recorder) addWithDepth(path string, withContents bool, depth int) error {
+	if depth > maxSymlinkDepth {
+		return fmt.Errorf("too many levels of symlinks for %v", path)
+	}
+
+	resolvedDir, err := r.resolve(filepath.Dir(path), depth)
+	if err != nil {
+		return err
+	}
+	path = filepath.Join(resolvedDir, filepath.Base(path))
+
+	if existing, ok := r.recorded[path]; ok {
+		if !withContents || existing.Type != FileTypeRegular || existing.Contents != nil {
+			return nil
+		}
+	}
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get info for %v: %w", path, err)
+	}
+
+	f := File{
+		Path: path,
+		Mode: info.Mode().Perm(),
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("failed to read link %v: %w", path, err)
+		}
+		f.Type = FileTypeSymlink
+		f.Mode = 0
+		f.Target = target
+		r.recorded[path] = f
+		return r.addWithDepth(resolveTarget(path, target), withContents, depth+1)
+	case info.IsDir():
+		f.Type = FileTypeDirectory
+	case info.Mode()&os.ModeCharDevice != 0:
+		f.Type = FileTypeCharDev
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			f.Major = int64(unix.Major(uint64(stat.Rdev))) //nolint:unconvert
+			f.Minor = int64(unix.Minor(uint64(stat.Rdev))) //nolint:unconvert
+			f.UID = stat.Uid
+			f.GID = stat.Gid
+		}
+	case info.Mode()&os.ModeSocket != 0:
+		f.Type = FileTypeSocket
+	case info.Mode().IsRegular():
+		f.Type = FileTypeRegular
+		f.ELF, f.SONAME = getSONAME(path)
+		if withContents && info.Size() <= maxContentsSize {
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				r.logger.Warningf("Ignoring error reading %v: %v", path, err)
+			} else {
+				f.Contents = contents
+			}
+		}
+	default:
+		r.logger.Debugf("Ignoring %v with unsupported mode %v", path, info.Mode())
+		return nil
+	}
+	r.recorded[path] = f
+	return nil
+}
+
+// resolve resolves the symlinks in the specified path, recording each of the
+// symlinks encountered.
+func (r *recorder) resolve(path string, depth int) (string, error) {
+	if path == "/" || path == "." {
+		return path, nil
+	}
+	if resolved, ok := r.resolved[path]; ok {
+		return resolved, nil
+	}
+	if depth > maxSymlinkDepth {
+		return "", fmt.Errorf("too many levels of symlinks for %v", path)
+	}
+
+	parent, err := r.resolve(filepath.Dir(path), depth)
+	if err != nil {
+		return "", err
+	}
+	resolved := filepath.Join(parent, filepath.Base(path))
+
+	info, err := os.Lstat(resolved)
+	if err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(resolved)
+		if err != nil {
+			return "", fmt.Errorf("failed to read link %v: %w", resolved, err)
+		}
+		r.recorded[resolved] = File{
+			Path:   resolved,
+			Type:   FileTypeSymlink,
+			Target: target,
+		}
+		resolved, err = r.resolve(resolveTarget(resolved, target), depth+1)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	r.resolved[path] = resolved
+	return resolved, nil
+}
+
+// resolveTarget returns the absolute path of the target of a symlink.
+func resolveTarget(link string, target string) string {
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target)
+	}
+	return filepath.Join(filepath.Dir(link), target)
+}
+
+// getSONAME checks whether the specified file is an ELF file and returns its
+// SONAME if it has one.
+func getSONAME(path string) (bool, string) {
+	lib, err := elf.Open(path)
+	if err != nil {
+		return false, ""
+	}
+	defer lib.Close()
+
+	sonames, err := lib.DynString(elf.DT_SONAME)
+	if err != nil || len(sonames) != 1 {
+		return true, ""
+	}
+	return true, sonames[0]
+}
+
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(s)
+}
@@ -0,0 +1,234 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/cgroups/devices/config"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform"
+	transformroot "github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi/transform/root"
+)
+
+// A Replayer replays a snapshot so that CDI specifications can be generated
+// from it. The recorded files are materialized in a temporary directory with
+// device nodes being represented by regular files. Queries for device nodes
+// by the nvcdi library configured using Options are answered from the
+// snapshot.
+type Replayer struct {
+	logger   logger.Interface
+	snapshot *Snapshot
+	root     string
+
+	deviceNodes map[string]File
+}
+
+var _ devices.Interface = (*Replayer)(nil)
+
+// NewReplayer creates a replayer for the specified snapshot. Close must be
+// called to remove the materialized files.
+func NewReplayer(logger logger.Interface, s *Snapshot) (*Replayer, error) {
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	root, err := os.MkdirTemp("", "nvidia-ctk-snapshot-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for snapshot: %w", err)
+	}
+
+	r := &Replayer{
+		logger:      logger,
+		snapshot:    s,
+		root:        root,
+		deviceNodes: make(map[string]File),
+	}
+	if err := r.materialize(); err != nil {
+		_ = os.RemoveAll(root)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Options returns the options that configure the nvcdi library to generate
+// CDI specifications from the snapshot.
+func (r *Replayer) Options() []nvcdi.Option {
+	return []nvcdi.Option{
+		nvcdi.WithNvmlLib(r.snapshot.NVML.Interface()),
+		nvcdi.WithDriverRoot(r.join(r.snapshot.DriverRoot)),
+		nvcdi.WithDevRoot(r.join(r.snapshot.DevRoot)),
+		nvcdi.WithHostRoot(r.join(r.snapshot.HostRoot)),
+		nvcdi.WithKernelRelease(r.snapshot.KernelRelease),
+		nvcdi.WithDevicesLib(r),
+		nvcdi.WithFeatureFlags(nvcdi.FeatureDisableNvsandboxUtils),
+	}
+}
+
+// Transformer returns a transformer that maps the paths of the materialized
+// files in a generated CDI specification to the recorded paths.
+func (r *Replayer) Transformer() transform.Transformer {
+	return transform.Merge(
+		transformroot.New(
+			transformroot.WithRoot(r.root),
+			transformroot.WithTargetRoot("/"),
+		),
+		hostPathCleaner{},
+	)
+}
+
+// Close removes the materialized files.
+func (r *Replayer) Close() error {
+	return os.RemoveAll(r.root)
+}
+
+// DeviceFromPath returns the recorded device node for the specified path.
+func (r *Replayer) DeviceFromPath(path string, permissions string) (*devices.Device, error) {
+	f, ok := r.deviceNodes[filepath.Clean(path)]
+	if !ok {
+		return nil, fmt.Errorf("%v is not a recorded device node", path)
+	}
+	d := &devices.Device{
+		Rule: config.Rule{
+			Type:        config.CharDevice,
+			Major:       f.Major,
+			Minor:       f.Minor,
+			Permissions: config.Permissions(permissions),
+		},
+		Path:     path,
+		FileMode: f.Mode,
+		Uid:      f.UID,
+		Gid:      f.GID,
+	}
+	return d, nil
+}
+
+// AssertCharDevice returns an error if the specified path is not a recorded
+// device node.
+func (r *Replayer) AssertCharDevice(path string) error {
+	if _, ok := r.deviceNodes[filepath.Clean(path)]; !ok {
+		return fmt.Errorf("%v is not a char device", path)
+	}
+	return nil
+}
+
+// IsOverrideApplied returns false since the replayer only affects the
+// generation of CDI specifications.
+func (r *Replayer) IsOverrideApplied() bool {
+	return false
+}
+
+func (r *Replayer) join(path string) string {
+	return filepath.Join(r.root, path)
+}
+
+// materialize creates the recorded files in the root of the replayer.
+// Symlinks are created last so that no files are created through a symlink.
+func (r *Replayer) materialize() error {
+	var symlinks []File
+	for _, f := range r.snapshot.Files {
+		path := r.join(f.Path)
+		switch f.Type {
+		case FileTypeSymlink:
+			symlinks = append(symlinks, f)
+			continue
+		case FileTypeDirectory:
+			if err := os.MkdirAll(path, f.Mode|0700); err != nil {
+				return fmt.Errorf("failed to create directory %v: %w", path, err)
+			}
+			continue
+		}
+
+		var contents []byte
+		mode := f.Mode | 0600
+		switch f.Type {
+		case FileTypeRegular:
+			contents = f.Contents
+			if contents == nil && f.ELF {
+				contents = newELFStub(f.SONAME)
+			}
+		case FileTypeCharDev:
+			r.deviceNodes[path] = f
+			mode = 0600
+		case FileTypeSocket:
+			mode = 0600
+		default:
+			r.logger.Warningf("Ignoring %v with unsupported type %q", f.Path, f.Type)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %v: %w", path, err)
+		}
+		if err := os.WriteFile(path, contents, mode); err != nil {
+			return fmt.Errorf("failed to create %v: %w", path, err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return fmt.Errorf("failed to set mode of %v: %w", path, err)
+		}
+	}
+
+	for _, link := range symlinks {
+		path := r.join(link.Path)
+		if _, err := os.Lstat(path); err == nil {
+			r.logger.Debugf("Skipping symlink %v since a file exists at this path", link.Path)
+			continue
+		}
+		// Absolute targets are resolved relative to the root of the replayer.
+		target := link.Target
+		if filepath.IsAbs(target) {
+			target = r.join(target)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %v: %w", path, err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			return fmt.Errorf("failed to create symlink %v: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// A hostPathCleaner removes the host path of device nodes if this matches the
+// path in the container. This is consistent with the generated specifications
+// where the host path is only set if it differs from the path.
+type hostPathCleaner struct{}
+
+func (t hostPathCleaner) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+	t.applyToEdits(&spec.ContainerEdits)
+	for i := range spec.Devices {
+		t.applyToEdits(&spec.Devices[i].ContainerEdits)
+	}
+	return nil
+}
+
+func (t hostPathCleaner) applyToEdits(edits *specs.ContainerEdits) {
+	for _, dn := range edits.DeviceNodes {
+		if dn.HostPath == dn.Path {
+			dn.HostPath = ""
+		}
+	}
+}
@@ -0,0 +1,260 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Version is the current version of the snapshot format.
+	Version = "v1"
+
+	// FormatJSON indicates that a snapshot is stored as JSON.
+	FormatJSON = "json"
+	// FormatYAML indicates that a snapshot is stored as YAML.
+	FormatYAML = "yaml"
+)
+
+// A Snapshot is a record of the devices, MIG layout, and driver files of a
+// system. It contains the information required to generate the CDI
+// specifications for the system without access to NVML or the system itself.
+type Snapshot struct {
+	Version string `json:"version"`
+	// DriverRoot is the driver root that the snapshot was captured for.
+	DriverRoot string `json:"driverRoot"`
+	// DevRoot is the root where /dev was located when the snapshot was
+	// captured.
+	DevRoot string `json:"devRoot"`
+	// HostRoot is the root under which /proc and /sys were queried when the
+	// snapshot was captured.
+	HostRoot string `json:"hostRoot"`
+	// KernelRelease is the release of the kernel that was running when the
+	// snapshot was captured. This determines the paths of the GSP firmware
+	// files of the driver.
+	KernelRelease string `json:"kernelRelease,omitempty"`
+	// NVML records the NVML state of the system.
+	NVML NVML `json:"nvml"`
+	// Files records the driver files, device nodes, and /proc and /sys
+	// entries of the system.
+	Files []File `json:"files,omitempty"`
+}
+
+// NVML records the NVML state of a system.
+type NVML struct {
+	DriverVersion     string   `json:"driverVersion"`
+	CUDADriverVersion int      `json:"cudaDriverVersion"`
+	Devices           []Device `json:"devices,omitempty"`
+}
+
+// A Device records the properties of a GPU. The index of a device is its
+// index in the list of devices of the snapshot. Optional properties are nil
+// if these were not supported by the device.
+type Device struct {
+	UUID                  string                  `json:"uuid"`
+	Name                  string                  `json:"name"`
+	Brand                 nvml.BrandType          `json:"brand"`
+	Architecture          nvml.DeviceArchitecture `json:"architecture"`
+	CUDAComputeCapability ComputeCapability       `json:"cudaComputeCapability"`
+	PCIBusID              string                  `json:"pciBusID"`
+	MinorNumber           int                     `json:"minorNumber"`
+	MemoryTotal           uint64                  `json:"memoryTotal"`
+	NUMANode              *int                    `json:"numaNode,omitempty"`
+	AddressingMode        *uint32                 `json:"addressingMode,omitempty"`
+	MIGMode               *int                    `json:"migMode,omitempty"`
+	Fabric                *FabricInfo             `json:"fabric,omitempty"`
+	// TopologyCommonAncestors maps the UUIDs of the other devices in the
+	// snapshot to the topology level of their common ancestor.
+	TopologyCommonAncestors map[string]nvml.GpuTopologyLevel `json:"topologyCommonAncestors,omitempty"`
+	MaxMIGDeviceCount       int                              `json:"maxMIGDeviceCount,omitempty"`
+	MIGDevices              []MIGDevice                      `json:"migDevices,omitempty"`
+}
+
+// ComputeCapability records the CUDA compute capability of a device.
+type ComputeCapability struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+}
+
+// FabricInfo records the GPU fabric information of a device.
+type FabricInfo struct {
+	ClusterUUID string `json:"clusterUUID"`
+	CliqueID    uint32 `json:"cliqueID"`
+	State       uint8  `json:"state"`
+	Status      uint32 `json:"status"`
+}
+
+// A MIGDevice records the properties of a MIG device. The profiles are the
+// indices of the GPU instance and compute instance profiles as used by NVML.
+type MIGDevice struct {
+	// Index is the index of the MIG device on its parent.
+	Index                        int    `json:"index"`
+	UUID                         string `json:"uuid"`
+	GPUInstanceID                int    `json:"gpuInstanceID"`
+	ComputeInstanceID            int    `json:"computeInstanceID"`
+	GPUInstanceProfile           int    `json:"gpuInstanceProfile"`
+	ComputeInstanceProfile       int    `json:"computeInstanceProfile"`
+	ComputeInstanceEngineProfile int    `json:"computeInstanceEngineProfile"`
+	MemorySizeMB                 uint64 `json:"memorySizeMB"`
+}
+
+// FileType defines the type of a recorded file.
+type FileType string
+
+const (
+	FileTypeRegular   = FileType("file")
+	FileTypeDirectory = FileType("dir")
+	FileTypeSymlink   = FileType("symlink")
+	FileTypeCharDev   = FileType("char")
+	FileTypeSocket    = FileType("socket")
+)
+
+// A File records a file on the system. The path is the absolute path of the
+// file when the snapshot was captured.
+type File struct {
+	Path string      `json:"path"`
+	Type FileType    `json:"type"`
+	Mode os.FileMode `json:"mode,omitempty"`
+	// Target is the target of a symlink.
+	Target string `json:"target,omitempty"`
+	// Contents are only recorded for files whose contents are required to
+	// generate CDI specifications.
+	Contents []byte `json:"contents,omitempty"`
+	// ELF indicates whether a regular file is an ELF file. For ELF files
+	// the SONAME is also recorded.
+	ELF    bool   `json:"elf,omitempty"`
+	SONAME string `json:"soname,omitempty"`
+	// Major, Minor, UID, and GID are recorded for device nodes.
+	Major int64  `json:"major,omitempty"`
+	Minor int64  `json:"minor,omitempty"`
+	UID   uint32 `json:"uid,omitempty"`
+	GID   uint32 `json:"gid,omitempty"`
+}
+
+// Load reads a snapshot from the specified file. Both JSON and YAML files are
+// supported.
+func Load(path string) (*Snapshot, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var s Snapshot
+	if err := yaml.Unmarshal(contents, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %q", s.Version)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// validate checks that the roots and the paths of the files recorded in the
+// snapshot, including the targets of symlinks, do not refer to locations
+// outside of the root that the snapshot is materialized in. Since snapshots
+// are typically received from other systems, this ensures that replaying a
+// snapshot cannot create files elsewhere on the system.
+func (s *Snapshot) validate() error {
+	for _, root := range []string{s.DriverRoot, s.DevRoot, s.HostRoot} {
+		if !isLocal(root) {
+			return fmt.Errorf("root %q is outside of the snapshot", root)
+		}
+	}
+	for _, f := range s.Files {
+		if !isLocal(f.Path) {
+			return fmt.Errorf("path %q is outside of the snapshot", f.Path)
+		}
+		if f.Type != FileTypeSymlink {
+			continue
+		}
+		// Relative targets are resolved relative to the directory of the
+		// symlink. The leading slash of the path is removed so that a target
+		// that refers to the parent of the root is detected.
+		target := f.Target
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(strings.TrimLeft(filepath.Dir(f.Path), "/"), target)
+		}
+		if !isLocal(target) {
+			return fmt.Errorf("target %q of symlink %q is outside of the snapshot", f.Target, f.Path)
+		}
+	}
+	return nil
+}
+
+// isLocal returns whether the specified absolute or relative path refers to a
+// location within the root that it is resolved against.
+func isLocal(path string) bool {
+	path = strings.TrimLeft(path, "/")
+	return path == "" || filepath.IsLocal(path)
+}
+
+// Save writes the snapshot to the specified file using the specified format.
+// If no format is specified, the format is determined by the file extension
+// with YAML being used by default.
+func (s *Snapshot) Save(path string, format string) error {
+	contents, err := s.Marshal(formatOrDefault(path, format))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Marshal returns the snapshot in the specified format.
+func (s *Snapshot) Marshal(format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		contents, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		return append(contents, '\n'), nil
+	case FormatYAML, "":
+		contents, err := yaml.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		return contents, nil
+	default:
+		return nil, fmt.Errorf("unsupported snapshot format %q", format)
+	}
+}
+
+func formatOrDefault(path string, format string) string {
+	if format != "" {
+		return format
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return FormatJSON
+	}
+	return FormatYAML
+}
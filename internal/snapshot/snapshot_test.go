@@ -0,0 +1,328 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package snapshot
+
+import (
+	"bytes"
+	"debug/elf"
+	"path/filepath"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock/dgxa100"
+	mockserver "github.com/NVIDIA/go-nvml/pkg/nvml/mock/server"
+	"github.com/opencontainers/cgroups/devices/config"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/test"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/nvcdi"
+)
+
+func TestCaptureAndReplay(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	moduleRoot, err := test.GetModuleRoot()
+	require.NoError(t, err)
+	driverRoot := filepath.Join(moduleRoot, "testdata", "lookup", "rootfs-1")
+
+	server := newMockServer()
+
+	s, err := Capture(
+		WithLogger(logger),
+		WithNvmlLib(server),
+		WithDriverRoot(driverRoot),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "999.88.77", s.NVML.DriverVersion)
+	require.Len(t, s.NVML.Devices, 1)
+	require.NotEmpty(t, s.KernelRelease)
+
+	// The device nodes in the test root are regular files. We mark them as
+	// device nodes as they would be recorded on a real system.
+	deviceNodes := make(map[string]File)
+	for i, f := range s.Files {
+		if filepath.Dir(f.Path) != filepath.Join(driverRoot, "dev") || f.Type != FileTypeRegular {
+			continue
+		}
+		s.Files[i].Type = FileTypeCharDev
+		s.Files[i].Major = 195
+		s.Files[i].Minor = int64(i)
+		deviceNodes[f.Path] = s.Files[i]
+	}
+	require.Contains(t, deviceNodes, filepath.Join(driverRoot, "dev/nvidia0"))
+
+	// The snapshot is serialized to ensure that no information is lost.
+	snapshotFile := filepath.Join(t.TempDir(), "snapshot.yaml")
+	require.NoError(t, s.Save(snapshotFile, ""))
+	loaded, err := Load(snapshotFile)
+	require.NoError(t, err)
+
+	deviceslib := &devices.InterfaceMock{
+		AssertCharDeviceFunc: func(path string) error {
+			return nil
+		},
+		DeviceFromPathFunc: func(path string, permissions string) (*devices.Device, error) {
+			f := deviceNodes[path]
+			return &devices.Device{
+				Rule: config.Rule{
+					Type:        config.CharDevice,
+					Major:       f.Major,
+					Minor:       f.Minor,
+					Permissions: config.Permissions(permissions),
+				},
+				Path:     path,
+				FileMode: f.Mode,
+			}, nil
+		},
+		IsOverrideAppliedFunc: func() bool {
+			return false
+		},
+	}
+	expected := generateSpec(t,
+		nvcdi.WithLogger(logger),
+		nvcdi.WithNvmlLib(newMockServer()),
+		nvcdi.WithDriverRoot(driverRoot),
+		nvcdi.WithDevicesLib(deviceslib),
+		nvcdi.WithFeatureFlags(nvcdi.FeatureDisableNvsandboxUtils),
+	)
+	require.NotEmpty(t, expected.Devices)
+
+	replayer, err := NewReplayer(logger, loaded)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, replayer.Close())
+	}()
+
+	replayed := generateSpec(t,
+		append([]nvcdi.Option{nvcdi.WithLogger(logger)}, replayer.Options()...)...,
+	)
+	require.NoError(t, replayer.Transformer().Transform(replayed))
+
+	require.Equal(t, expected, replayed)
+}
+
+func TestReplayKernelRelease(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	moduleRoot, err := test.GetModuleRoot()
+	require.NoError(t, err)
+	driverRoot := filepath.Join(moduleRoot, "testdata", "lookup", "rootfs-1")
+
+	s, err := Capture(
+		WithLogger(logger),
+		WithNvmlLib(newMockServer()),
+		WithDriverRoot(driverRoot),
+	)
+	require.NoError(t, err)
+
+	// The firmware is only located for the kernel release of the snapshot
+	// and not for the running kernel.
+	s.KernelRelease = "0.0.0-recorded"
+	firmware := filepath.Join(driverRoot, "lib/firmware", s.KernelRelease, "nvidia/999.88.77/gsp_ga10x.bin")
+	s.Files = append(s.Files, File{Path: firmware, Type: FileTypeRegular, Mode: 0644})
+
+	replayer, err := NewReplayer(logger, s)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, replayer.Close())
+	}()
+
+	replayed := generateSpec(t,
+		append([]nvcdi.Option{nvcdi.WithLogger(logger)}, replayer.Options()...)...,
+	)
+	require.NoError(t, replayer.Transformer().Transform(replayed))
+
+	var hostPaths []string
+	for _, m := range replayed.ContainerEdits.Mounts {
+		hostPaths = append(hostPaths, m.HostPath)
+	}
+	require.Contains(t, hostPaths, firmware)
+}
+
+func TestReplayPathsOutsideSnapshot(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description   string
+		snapshot      Snapshot
+		expectedError string
+	}{
+		{
+			description: "files and symlinks within the snapshot",
+			snapshot: Snapshot{
+				Files: []File{
+					{Path: "/usr/lib/libcuda.so.999.88.77", Type: FileTypeRegular},
+					{Path: "/usr/lib/libcuda.so.1", Type: FileTypeSymlink, Target: "libcuda.so.999.88.77"},
+					{Path: "/usr/lib/libcuda.so", Type: FileTypeSymlink, Target: "/usr/lib/libcuda.so.1"},
+					{Path: "/lib", Type: FileTypeSymlink, Target: "usr/lib"},
+				},
+			},
+		},
+		{
+			description: "file outside of the snapshot",
+			snapshot: Snapshot{
+				Files: []File{
+					{Path: "/../../etc/cron.d/x", Type: FileTypeRegular},
+				},
+			},
+			expectedError: `invalid snapshot: path "/../../etc/cron.d/x" is outside of the snapshot`,
+		},
+		{
+			description: "symlink outside of the snapshot",
+			snapshot: Snapshot{
+				Files: []File{
+					{Path: "/usr/../../lib", Type: FileTypeSymlink, Target: "/usr/lib"},
+				},
+			},
+			expectedError: `invalid snapshot: path "/usr/../../lib" is outside of the snapshot`,
+		},
+		{
+			description: "absolute symlink target outside of the snapshot",
+			snapshot: Snapshot{
+				Files: []File{
+					{Path: "/usr/lib/libcuda.so.1", Type: FileTypeSymlink, Target: "/../../etc/shadow"},
+				},
+			},
+			expectedError: `invalid snapshot: target "/../../etc/shadow" of symlink "/usr/lib/libcuda.so.1" is outside of the snapshot`,
+		},
+		{
+			description: "relative symlink target outside of the snapshot",
+			snapshot: Snapshot{
+				Files: []File{
+					{Path: "/usr/lib/libcuda.so.1", Type: FileTypeSymlink, Target: "../../../etc/shadow"},
+				},
+			},
+			expectedError: `invalid snapshot: target "../../../etc/shadow" of symlink "/usr/lib/libcuda.so.1" is outside of the snapshot`,
+		},
+		{
+			description: "driver root outside of the snapshot",
+			snapshot: Snapshot{
+				DriverRoot: "/..",
+			},
+			expectedError: `invalid snapshot: root "/.." is outside of the snapshot`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.snapshot.Version = Version
+
+			replayer, err := NewReplayer(logger, &tc.snapshot)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				require.Nil(t, replayer)
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, replayer.Close())
+			}
+
+			snapshotFile := filepath.Join(t.TempDir(), "snapshot.yaml")
+			require.NoError(t, tc.snapshot.Save(snapshotFile, ""))
+			_, err = Load(snapshotFile)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestELFStub(t *testing.T) {
+	testCases := []struct {
+		description string
+		soname      string
+	}{
+		{
+			description: "with soname",
+			soname:      "libcuda.so.1",
+		},
+		{
+			description: "without soname",
+			soname:      "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			lib, err := elf.NewFile(bytes.NewReader(newELFStub(tc.soname)))
+			require.NoError(t, err)
+			defer lib.Close()
+
+			sonames, err := lib.DynString(elf.DT_SONAME)
+			require.NoError(t, err)
+			if tc.soname == "" {
+				require.Empty(t, sonames)
+			} else {
+				require.Equal(t, []string{tc.soname}, sonames)
+			}
+		})
+	}
+}
+
+func generateSpec(t *testing.T, opts ...nvcdi.Option) *specs.Spec {
+	cdilib, err := nvcdi.New(append(opts, nvcdi.WithMode(nvcdi.ModeNvml))...)
+	require.NoError(t, err)
+
+	deviceSpecs, err := cdilib.GetDeviceSpecsByID("all")
+	require.NoError(t, err)
+	commonEdits, err := cdilib.GetCommonEdits()
+	require.NoError(t, err)
+
+	return &specs.Spec{
+		Devices:        deviceSpecs,
+		ContainerEdits: *commonEdits.ContainerEdits,
+	}
+}
+
+// newMockServer returns a DGX A100 mock with a single device that matches the
+// driver in the test root.
+func newMockServer() *mockserver.Server {
+	server := dgxa100.New()
+	server.SystemGetDriverVersionFunc = func() (string, nvml.Return) {
+		return "999.88.77", nvml.SUCCESS
+	}
+	server.DeviceGetCountFunc = func() (int, nvml.Return) {
+		return 1, nvml.SUCCESS
+	}
+	for i, d := range server.Devices {
+		device := d.(*mockserver.Device)
+		// TODO: These are not implemented in the mock.
+		device.GetMaxMigDeviceCountFunc = func() (int, nvml.Return) {
+			return 0, nvml.SUCCESS
+		}
+		device.GetIndexFunc = func() (int, nvml.Return) {
+			return i, nvml.SUCCESS
+		}
+		device.GetUUIDFunc = func() (string, nvml.Return) {
+			return device.UUID, nvml.SUCCESS
+		}
+		device.GetNumaNodeIdFunc = func() (int, nvml.Return) {
+			return 1, nvml.SUCCESS
+		}
+		device.GetAddressingModeFunc = func() (nvml.DeviceAddressingMode, nvml.Return) {
+			return nvml.DeviceAddressingMode{}, nvml.ERROR_NOT_SUPPORTED
+		}
+		device.GetGpuFabricInfoFunc = func() (nvml.GpuFabricInfo, nvml.Return) {
+			return nvml.GpuFabricInfo{}, nvml.ERROR_NOT_SUPPORTED
+		}
+	}
+	return server
+}
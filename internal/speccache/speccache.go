@@ -0,0 +1,269 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package speccache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/atomicfile"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+)
+
+// Interface defines the API for a cache of generated CDI specs.
+type Interface interface {
+	// Get returns the cached spec for the specified key. If no valid spec is
+	// cached, nil is returned.
+	Get(key string) *specs.Spec
+	// Put stores the spec for the specified key.
+	Put(key string, spec *specs.Spec) error
+}
+
+type cache struct {
+	logger   logger.Interface
+	dir      string
+	driver   *root.Driver
+	procRoot string
+
+	// generationOnce ensures that the state of the system is only queried
+	// once per cache instance.
+	generationOnce sync.Once
+	generation     string
+	generationErr  error
+}
+
+var _ Interface = (*cache)(nil)
+
+// Option is a functional option for constructing a spec cache.
+type Option func(*cache)
+
+// New creates a spec cache that stores specs in the specified directory.
+// Cached specs are stored in a subdirectory that is determined by the driver
+// version, the driver libraries, and the NVIDIA devices on the system. This
+// means that cached specs are invalidated when the driver or the devices
+// change.
+// If no directory is specified, a nil cache is returned.
+func New(opts ...Option) Interface {
+	c := &cache{
+		procRoot: "/",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.dir == "" {
+		return nil
+	}
+	if c.logger == nil {
+		c.logger = logger.New()
+	}
+	if c.driver == nil {
+		c.driver = root.New(root.WithLogger(c.logger))
+	}
+	return c
+}
+
+// WithDir sets the directory in which specs are cached.
+func WithDir(dir string) Option {
+	return func(c *cache) {
+		c.dir = dir
+	}
+}
+
+// WithDriver sets the driver used to determine the state of the system.
+func WithDriver(driver *root.Driver) Option {
+	return func(c *cache) {
+		c.driver = driver
+	}
+}
+
+// WithLogger sets the logger for the cache.
+func WithLogger(logger logger.Interface) Option {
+	return func(c *cache) {
+		c.logger = logger
+	}
+}
+
+// WithProcRoot sets the root under which /proc/driver/nvidia is queried.
+func WithProcRoot(procRoot string) Option {
+	return func(c *cache) {
+		c.procRoot = procRoot
+	}
+}
+
+// Key returns a cache key for the specified value. The value is serialized
+// as JSON and hashed.
+func Key(v any) (string, error) {
+	contents, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cache key: %w", err)
+	}
+	return hash(string(contents)), nil
+}
+
+// Get returns the cached spec for the specified key.
+func (c *cache) Get(key string) *specs.Spec {
+	generationDir, err := c.getGenerationDir()
+	if err != nil {
+		c.logger.Debugf("Not using cached CDI spec: %v", err)
+		return nil
+	}
+
+	contents, err := os.ReadFile(filepath.Join(generationDir, key+".json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.logger.Warningf("Failed to read cached CDI spec: %v", err)
+		}
+		return nil
+	}
+
+	var spec specs.Spec
+	if err := json.Unmarshal(contents, &spec); err != nil {
+		c.logger.Warningf("Ignoring invalid cached CDI spec: %v", err)
+		return nil
+	}
+	return &spec
+}
+
+// Put stores the spec for the specified key. Specs cached for a different
+// state of the system are removed.
+func (c *cache) Put(key string, spec *specs.Spec) error {
+	generationDir, err := c.getGenerationDir()
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal CDI spec: %w", err)
+	}
+
+	if err := os.MkdirAll(generationDir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(generationDir, key+".json"), contents, 0600); err != nil {
+		return err
+	}
+
+	c.removeStaleGenerations(filepath.Base(generationDir))
+	return nil
+}
+
+// removeStaleGenerations removes the specs cached for other states of the
+// system.
+func (c *cache) removeStaleGenerations(current string) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		c.logger.Warningf("Failed to list cache directory: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == current {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+			c.logger.Warningf("Failed to remove stale cached CDI specs: %v", err)
+		}
+	}
+}
+
+func (c *cache) getGenerationDir() (string, error) {
+	c.generationOnce.Do(func() {
+		c.generation, c.generationErr = c.getGeneration()
+	})
+	if c.generationErr != nil {
+		return "", c.generationErr
+	}
+	return filepath.Join(c.dir, c.generation), nil
+}
+
+// getGeneration returns a hash of the state of the system that the generated
+// specs depend on. This includes:
+//   - the driver version
+//   - the size, modification time, and inode of the versioned driver
+//     libraries so that libraries that are replaced without a change in the
+//     driver version are detected
+//   - the NVIDIA device nodes
+//   - the GPUs and MIG capabilities in /proc/driver/nvidia
+func (c *cache) getGeneration() (string, error) {
+	version, err := c.driver.Version()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine driver version: %w", err)
+	}
+	entries := []string{"driver: " + version}
+
+	libraries, err := c.driver.Libraries().Locate("lib*.so." + version)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate driver libraries: %w", err)
+	}
+	for _, library := range libraries {
+		entries = append(entries, getFileState(library))
+	}
+
+	devRoot := c.driver.DevRoot
+	if devRoot == "" {
+		devRoot = "/"
+	}
+	for _, pattern := range []string{"dev/nvidia*", "dev/nvidia-caps/*", "dev/nvidia-caps-imex-channels/*"} {
+		deviceNodes, _ := filepath.Glob(filepath.Join(devRoot, pattern))
+		entries = append(entries, deviceNodes...)
+	}
+
+	procDriverPath := filepath.Join(c.procRoot, "proc/driver/nvidia")
+	gpus, _ := filepath.Glob(filepath.Join(procDriverPath, "gpus/*"))
+	entries = append(entries, gpus...)
+	_ = filepath.WalkDir(filepath.Join(procDriverPath, "capabilities"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, path)
+		return nil
+	})
+
+	slices.Sort(entries)
+	return hash(strings.Join(entries, "\n")), nil
+}
+
+// getFileState returns a description of the specified file that changes if
+// the file is modified or replaced.
+func getFileState(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path
+	}
+	state := fmt.Sprintf("%v: %d %d", path, info.Size(), info.ModTime().UnixNano())
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		state += fmt.Sprintf(" %d", stat.Ino)
+	}
+	return state
+}
+
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
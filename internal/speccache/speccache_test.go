@@ -0,0 +1,125 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package speccache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup/root"
+)
+
+type driverVersion string
+
+func (v driverVersion) Version() (string, error) {
+	return string(v), nil
+}
+
+func TestCache(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	cacheDir := t.TempDir()
+	testRoot := t.TempDir()
+
+	createFile := func(path string) {
+		path = filepath.Join(testRoot, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+	}
+	driverLibraries := []string{
+		"usr/lib64/libcuda.so.999.88.77",
+		"usr/lib64/libcuda.so.999.88.78",
+	}
+	for _, library := range driverLibraries {
+		createFile(library)
+	}
+
+	newCache := func(version string) Interface {
+		return New(
+			WithLogger(logger),
+			WithDir(cacheDir),
+			WithProcRoot(testRoot),
+			WithDriver(root.New(
+				root.WithLogger(logger),
+				root.WithDriverRoot(testRoot),
+				root.WithVersioner(driverVersion(version)),
+			)),
+		)
+	}
+
+	spec := &specs.Spec{
+		Version: "0.5.0",
+		Kind:    "runtime.nvidia.com/gpu",
+		Devices: []specs.Device{
+			{
+				Name: "0",
+				ContainerEdits: specs.ContainerEdits{
+					DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}},
+				},
+			},
+		},
+	}
+
+	key, err := Key([]string{"0"})
+	require.NoError(t, err)
+
+	createFile("dev/nvidia0")
+	createFile("proc/driver/nvidia/gpus/0000:01:00.0/information")
+
+	c := newCache("999.88.77")
+	require.Nil(t, c.Get(key))
+	require.NoError(t, c.Put(key, spec))
+	require.Equal(t, spec, c.Get(key))
+
+	// A new cache instance for the same system state reuses the cached spec.
+	require.Equal(t, spec, newCache("999.88.77").Get(key))
+
+	// A change in the driver version invalidates the cached spec.
+	require.Nil(t, newCache("999.88.78").Get(key))
+
+	// A change in the driver libraries invalidates the cached spec.
+	modified := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(testRoot, driverLibraries[0]), modified, modified))
+	c = newCache("999.88.77")
+	require.Nil(t, c.Get(key))
+	require.NoError(t, c.Put(key, spec))
+	require.Equal(t, spec, newCache("999.88.77").Get(key))
+
+	// The spec is not cached if the driver libraries cannot be located.
+	require.Nil(t, newCache("999.88.79").Get(key))
+	require.Error(t, newCache("999.88.79").Put(key, spec))
+
+	// A change in the devices invalidates the cached spec.
+	createFile("dev/nvidia1")
+	c = newCache("999.88.77")
+	require.Nil(t, c.Get(key))
+
+	// Storing a spec removes the specs cached for a different system state.
+	require.NoError(t, c.Put(key, spec))
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestNewWithoutDir(t *testing.T) {
+	require.Nil(t, New())
+}
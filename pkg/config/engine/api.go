@@ -34,6 +34,8 @@ type Interface interface {
 	DefaultRuntime() string
 	EnableCDI()
 	GetRuntimeConfig(string) (RuntimeConfig, error)
+	GetRuntimeNames() []string
+	IsCDIEnabled() bool
 	RemoveRuntime(string) error
 	UpdateDefaultRuntime(string, string) error
 	Save(string) (int64, error)
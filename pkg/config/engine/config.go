@@ -33,7 +33,9 @@ type Config struct {
 type RuntimeConfigSource interface {
 	DefaultRuntime() string
 	GetRuntimeConfig(string) (RuntimeConfig, error)
+	GetRuntimeNames() []string
 	GetDefaultRuntimeOptions() any
+	IsCDIEnabled() bool
 	String() string
 }
 
@@ -84,6 +86,16 @@ func (c *Config) GetRuntimeConfig(runtime string) (RuntimeConfig, error) {
 	return c.Source.GetRuntimeConfig(runtime)
 }
 
+// GetRuntimeNames returns the names of the runtimes defined in the source config.
+func (c *Config) GetRuntimeNames() []string {
+	return c.Source.GetRuntimeNames()
+}
+
+// IsCDIEnabled returns whether CDI is enabled in the source config.
+func (c *Config) IsCDIEnabled() bool {
+	return c.Source.IsCDIEnabled()
+}
+
 // Save saves the destination runtime to the specified path.
 func (c *Config) Save(path string) (int64, error) {
 	return c.Destination.Save(path)
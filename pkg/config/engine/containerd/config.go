@@ -18,6 +18,7 @@ package containerd
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/engine"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/toml"
@@ -127,6 +128,33 @@ func (c *Config) EnableCDI() {
 	*c.Tree = config
 }
 
+// IsCDIEnabled returns whether CDI is enabled in the containerd config.
+// If the enable_cdi field is not set, the default for the config version is
+// returned. CDI is enabled by default for version 3 configs (containerd 2.0).
+func (c *Config) IsCDIEnabled() bool {
+	if c == nil || c.Tree == nil {
+		return false
+	}
+	if enabled, ok := c.GetPath([]string{"plugins", c.CRIRuntimePluginName, "enable_cdi"}).(bool); ok {
+		return enabled
+	}
+	return c.Version >= 3
+}
+
+// GetRuntimeNames returns the names of the runtimes defined in the containerd config.
+func (c *Config) GetRuntimeNames() []string {
+	if c == nil || c.Tree == nil {
+		return nil
+	}
+	runtimes := c.GetSubtreeByPath([]string{"plugins", c.CRIRuntimePluginName, "containerd", "runtimes"})
+	if runtimes == nil {
+		return nil
+	}
+	names := runtimes.Keys()
+	slices.Sort(names)
+	return names
+}
+
 // RemoveRuntime removes a runtime from the containerd config
 func (c *Config) RemoveRuntime(name string) error {
 	if c == nil || c.Tree == nil {
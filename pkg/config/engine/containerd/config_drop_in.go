@@ -105,6 +105,13 @@ func (c *ConfigWithDropIn) UpdateDefaultRuntime(name string, action string) erro
 	return c.Interface.UpdateDefaultRuntime(name, action)
 }
 
+// IsDropInImported checks whether the specified drop-in file is included in
+// the imports of the top-level config. Relative imports are resolved relative
+// to the directory containing the top-level config.
+func (c *ConfigWithDropIn) IsDropInImported(dropInPath string) bool {
+	return c.topLevelConfig.isImported(dropInPath)
+}
+
 // flush saves the top-level config to its path.
 // If the config is empty, the file will be deleted.
 func (c *topLevelConfig) Save(dropInPath string) (int64, error) {
@@ -174,6 +181,25 @@ func (c *topLevelConfig) removeVersion() {
 	c.config.Delete("version")
 }
 
+func (c *topLevelConfig) isImported(dropInFilename string) bool {
+	if c.config == nil || c.config.Tree == nil {
+		return false
+	}
+	dropInHostPath := filepath.Join(c.asHostPath(filepath.Dir(dropInFilename)), filepath.Base(dropInFilename))
+	for _, currentImport := range c.getCurrentImports() {
+		if !filepath.IsAbs(currentImport) {
+			currentImport = filepath.Join(filepath.Dir(c.path), currentImport)
+		}
+		if currentImport == dropInHostPath {
+			return true
+		}
+		if matched, _ := filepath.Match(currentImport, dropInHostPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *topLevelConfig) getCurrentImports() []string {
 	rawImports := c.config.Get("imports")
 	if rawImports == nil {
@@ -72,3 +72,60 @@ func TestEnsureImports(t *testing.T) {
 	}
 
 }
+
+func TestIsImported(t *testing.T) {
+	testCases := []struct {
+		description      string
+		configMap        map[string]any
+		expectedImported bool
+	}{
+		{
+			description:      "no imports",
+			expectedImported: false,
+		},
+		{
+			description: "matching glob",
+			configMap: map[string]any{
+				"imports": []any{"/etc/containerd/conf.d/*.toml"},
+			},
+			expectedImported: true,
+		},
+		{
+			description: "matching file",
+			configMap: map[string]any{
+				"imports": []any{"/etc/containerd/conf.d/99-nvidia.toml"},
+			},
+			expectedImported: true,
+		},
+		{
+			description: "relative import",
+			configMap: map[string]any{
+				"imports": []any{"conf.d/*.toml"},
+			},
+			expectedImported: true,
+		},
+		{
+			description: "other directory",
+			configMap: map[string]any{
+				"imports": []any{"/foo/bar/*.toml"},
+			},
+			expectedImported: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cut := topLevelConfig{
+				path: "/etc/containerd/config.toml",
+				config: &Config{
+					Tree: func() *toml.Tree {
+						t, _ := toml.FromMap(tc.configMap).Load()
+						return t
+					}(),
+				},
+			}
+
+			require.Equal(t, tc.expectedImported, cut.isImported("/etc/containerd/conf.d/99-nvidia.toml"))
+		})
+	}
+}
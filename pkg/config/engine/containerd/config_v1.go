@@ -145,3 +145,17 @@ func (c *ConfigV1) EnableCDI() {
 	config.SetPath([]string{"plugins", "cri", "containerd", "enable_cdi"}, true)
 	*c.Tree = config
 }
+
+// IsCDIEnabled returns whether CDI is enabled in the containerd config.
+func (c *ConfigV1) IsCDIEnabled() bool {
+	if c == nil || c.Tree == nil {
+		return false
+	}
+	enabled, _ := c.GetPath([]string{"plugins", "cri", "containerd", "enable_cdi"}).(bool)
+	return enabled
+}
+
+// GetRuntimeNames returns the names of the runtimes defined in the containerd config.
+func (c *ConfigV1) GetRuntimeNames() []string {
+	return (*Config)(c).GetRuntimeNames()
+}
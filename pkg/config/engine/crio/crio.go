@@ -18,6 +18,7 @@ package crio
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config/engine"
@@ -219,9 +220,29 @@ func (c *Config) GetRuntimeConfig(name string) (engine.RuntimeConfig, error) {
 	}, nil
 }
 
+// GetRuntimeNames returns the names of the runtimes defined in the cri-o config.
+func (c *Config) GetRuntimeNames() []string {
+	if c == nil || c.Tree == nil {
+		return nil
+	}
+	runtimes := c.GetSubtreeByPath([]string{"crio", "runtime", "runtimes"})
+	if runtimes == nil {
+		return nil
+	}
+	names := runtimes.Keys()
+	slices.Sort(names)
+	return names
+}
+
 // EnableCDI is a no-op for CRI-O since it always enabled where supported.
 func (c *Config) EnableCDI() {}
 
+// IsCDIEnabled always returns true for CRI-O since it is always enabled where
+// supported.
+func (c *Config) IsCDIEnabled() bool {
+	return true
+}
+
 // CommandLineSource returns the CLI-based crio config loader
 func CommandLineSource(hostRoot string, executablePath string) toml.Loader {
 	if executablePath == "" {
@@ -19,6 +19,7 @@ package docker
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
 	"github.com/NVIDIA/nvidia-container-toolkit/pkg/config"
@@ -121,6 +122,19 @@ func (c *Config) EnableCDI() {
 	*c = config
 }
 
+// IsCDIEnabled returns whether features.cdi is set to true in the docker config.
+func (c Config) IsCDIEnabled() bool {
+	var enabled any
+	switch features := c["features"].(type) {
+	case map[string]bool:
+		enabled = features["cdi"]
+	case map[string]any:
+		enabled = features["cdi"]
+	}
+	isEnabled, _ := enabled.(bool)
+	return isEnabled
+}
+
 // RemoveRuntime removes a runtime from the docker config
 func (c *Config) RemoveRuntime(name string) error {
 	if c == nil {
@@ -213,6 +227,20 @@ func (c *Config) GetRuntimeConfig(name string) (engine.RuntimeConfig, error) {
 	return &dockerRuntime{}, nil
 }
 
+// GetRuntimeNames returns the names of the runtimes defined in the docker config.
+func (c Config) GetRuntimeNames() []string {
+	runtimes, ok := c["runtimes"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
 // String returns the string representation of the JSON config.
 func (c Config) String() string {
 	output, err := json.MarshalIndent(c, "", "    ")
@@ -21,17 +21,15 @@ import (
 )
 
 // NewCharDeviceLocator creates a Locator that can be used to find char devices at the specified root. A logger is
-// also specified.
+// also specified. A filter specified in the options replaces the default check for char devices.
 func NewCharDeviceLocator(opts ...Option) Locator {
-	filter := devices.AssertCharDevice
-
+	opts = append([]Option{WithFilter(devices.AssertCharDevice)}, opts...)
 	opts = append(opts,
 		// Device nodes can be specified by their full path e.g. /dev/nvidia0 or
 		// by the name of the device node e.g nvidia0.
 		// We thus set the search path to include "/" and "/dev" to cover both
 		// cases.
 		WithSearchPaths("/", "/dev"),
-		WithFilter(filter),
 	)
 	return NewFactory(opts...).NewFileLocator()
 }
@@ -51,6 +51,9 @@ const (
 	// AllHooks is a special hook name that allows all hooks to be matched.
 	AllHooks = discover.AllHooks
 
+	// An ApplicationProfileHook updates driver settings in the container
+	// through application profiles.
+	ApplicationProfileHook = discover.ApplicationProfileHook
 	// A CreateSymlinksHook is used to create symlinks in the container.
 	CreateSymlinksHook = discover.CreateSymlinksHook
 	// DisableDeviceNodeModificationHook refers to the hook used to ensure that
@@ -72,6 +75,23 @@ const (
 	HookUpdateLDCache = UpdateLDCacheHook
 )
 
+// A HookPolicy defines the timeout and failure policy of a hook.
+type HookPolicy = discover.HookPolicy
+
+// A HookFailurePolicy defines how a hook that fails or times out is handled.
+type HookFailurePolicy = discover.HookFailurePolicy
+
+const (
+	// HookFailurePolicyFail fails the creation of the container if the hook
+	// fails. This is the default.
+	HookFailurePolicyFail = discover.HookFailurePolicyFail
+	// HookFailurePolicyWarn logs a warning if the hook fails and allows the
+	// container to be created.
+	HookFailurePolicyWarn = discover.HookFailurePolicyWarn
+	// HookFailurePolicySkip skips the hook without running it.
+	HookFailurePolicySkip = discover.HookFailurePolicySkip
+)
+
 // A FeatureFlag refers to a specific feature that can be toggled in the CDI api.
 // All features are off by default.
 type FeatureFlag string
@@ -100,4 +120,13 @@ const (
 	// FeatureDisableIPCDiscoverer disables the inclusion of IPC sockets
 	// (nvidia-persistenced, nvidia-fabricmanager, MPS) in the CDI spec.
 	FeatureDisableIPCDiscoverer = FeatureFlag("disable-ipc-discoverer")
+
+	// FeatureEnableTopologyAnnotations enables the addition of annotations
+	// describing the NUMA node, NVLink clique, and PCIe switch of full GPUs.
+	FeatureEnableTopologyAnnotations = FeatureFlag("enable-topology-annotations")
+
+	// FeatureEnableDeviceInfoAnnotations enables the addition of annotations
+	// describing the properties of full GPUs and MIG devices such as the
+	// product name and memory size.
+	FeatureEnableDeviceInfoAnnotations = FeatureFlag("enable-device-info-annotations")
 )
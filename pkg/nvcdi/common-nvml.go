@@ -50,8 +50,9 @@ func (l *nvmllib) newCommonNVMLDiscoverer() (discover.Discover, error) {
 }
 
 func (l *nvmllib) controlDeviceNodeDiscoverer() discover.Discover {
-	return discover.NewCharDeviceDiscoverer(
+	return discover.NewCharDeviceDiscovererWithDevicesLib(
 		l.logger,
+		l.deviceslib,
 		l.driver.DevRoot,
 		[]string{
 			"/dev/nvidia-modeset",
@@ -0,0 +1,131 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package nvcdi
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// The following annotations are added to full GPU and MIG devices if the
+// FeatureEnableDeviceInfoAnnotations feature flag is set. The NUMA node of
+// the device is included as the AnnotationNUMANode annotation and the UUID of
+// the device as the AnnotationUUID annotation.
+const (
+	// AnnotationProduct is the product name of the GPU. For MIG devices this
+	// is the product name of the parent GPU.
+	AnnotationProduct = "gpu.nvidia.com/product"
+	// AnnotationArchitecture is the architecture of the GPU (e.g. Ampere).
+	AnnotationArchitecture = "gpu.nvidia.com/architecture"
+	// AnnotationComputeCapability is the CUDA compute capability of the GPU
+	// (e.g. 8.0).
+	AnnotationComputeCapability = "gpu.nvidia.com/compute-capability"
+	// AnnotationMemory is the total memory of the GPU or MIG device in MiB.
+	AnnotationMemory = "gpu.nvidia.com/memory-mib"
+	// AnnotationPCIBusID is the PCI bus ID of the GPU. For MIG devices this
+	// is the PCI bus ID of the parent GPU.
+	AnnotationPCIBusID = "gpu.nvidia.com/pci-bus-id"
+	// AnnotationMIGProfile is the profile of a MIG device (e.g. 1g.5gb).
+	AnnotationMIGProfile = "gpu.nvidia.com/mig-profile"
+	// AnnotationParentUUID is the UUID of the parent GPU of a MIG device.
+	AnnotationParentUUID = "gpu.nvidia.com/parent-uuid"
+)
+
+type memoryInfoGetter interface {
+	GetMemoryInfo() (nvml.Memory, nvml.Return)
+}
+
+// getDeviceInfoAnnotations returns the annotations describing the properties
+// of the full GPU.
+func (l *fullGPUDeviceSpecGenerator) getDeviceInfoAnnotations() (map[string]string, error) {
+	properties, err := l.getDeviceProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := l.device()
+	if err != nil {
+		return nil, err
+	}
+	architecture, err := d.GetArchitectureAsString()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get architecture: %w", err)
+	}
+	computeCapability, err := d.GetCudaComputeCapabilityAsString()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute capability: %w", err)
+	}
+	memory, err := getMemoryMiB(d)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{
+		AnnotationProduct:           properties.Product,
+		AnnotationArchitecture:      architecture,
+		AnnotationComputeCapability: computeCapability,
+		AnnotationMemory:            memory,
+		AnnotationPCIBusID:          properties.PCIBusID,
+		AnnotationUUID:              l.uuid,
+	}
+	if properties.NUMANode >= 0 {
+		annotations[AnnotationNUMANode] = fmt.Sprintf("%d", properties.NUMANode)
+	}
+	return annotations, nil
+}
+
+// getDeviceAnnotations returns the annotations for the MIG device. The
+// annotations of the parent GPU are included with the memory size replaced
+// by that of the MIG device.
+func (l *migDeviceSpecGenerator) getDeviceAnnotations() (map[string]string, error) {
+	if !l.featureFlags[FeatureEnableDeviceInfoAnnotations] {
+		return nil, nil
+	}
+
+	annotations, err := l.fullGPUDeviceSpecGenerator.getDeviceInfoAnnotations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device info: %w", err)
+	}
+
+	migDevice, err := l.migDevice()
+	if err != nil {
+		return nil, err
+	}
+	memory, err := getMemoryMiB(migDevice)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := migDevice.GetProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MIG profile: %w", err)
+	}
+
+	annotations[AnnotationMemory] = memory
+	annotations[AnnotationMIGProfile] = profile.String()
+	annotations[AnnotationParentUUID] = l.uuid
+	annotations[AnnotationUUID] = l.migUUID
+	return annotations, nil
+}
+
+func getMemoryMiB(d memoryInfoGetter) (string, error) {
+	memory, ret := d.GetMemoryInfo()
+	if ret != nvml.SUCCESS {
+		return "", fmt.Errorf("failed to get memory info: %v", ret)
+	}
+	return fmt.Sprintf("%d", memory.Total/(1024*1024)), nil
+}
@@ -0,0 +1,317 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package nvcdi
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
+)
+
+const (
+	// DeviceTypeGPU selects full GPUs in a device rule.
+	DeviceTypeGPU = "gpu"
+	// DeviceTypeMIG selects MIG devices in a device rule.
+	DeviceTypeMIG = "mig"
+)
+
+// perDeviceHooks are the hooks that can be enabled or disabled for specific
+// devices. Since the edits of a device are applied after the common edits,
+// the ldcache is updated again after the enable-cuda-compat hook for a device
+// so that the CUDA compat libraries are picked up.
+var perDeviceHooks = []HookName{
+	ApplicationProfileHook,
+	DisableDeviceNodeModificationHook,
+	EnableCudaCompatHook,
+}
+
+// perDeviceFeatureFlags are the feature flags that can be enabled for
+// specific devices.
+var perDeviceFeatureFlags = []FeatureFlag{
+	FeatureEnableCoherentAnnotations,
+	FeatureEnableDeviceInfoAnnotations,
+	FeatureEnableTopologyAnnotations,
+}
+
+// A DeviceRule customizes the CDI device specifications that are generated
+// for the devices matching its selector. Rules are applied in order with
+// later rules taking precedence.
+//
+// A hook that is enabled or disabled by any rule is no longer included in the
+// common edits of a specification. Instead, it is included in the edits of
+// each device for which it is enabled.
+type DeviceRule struct {
+	Selector DeviceSelector
+
+	EnabledHooks  []HookName
+	DisabledHooks []HookName
+	FeatureFlags  []FeatureFlag
+}
+
+// A DeviceSelector selects devices by their type, product name, or UUID. A
+// device matches the selector if it matches one of the values specified for
+// each property. An empty selector matches all devices.
+type DeviceSelector struct {
+	// Types are the types of devices to select. One of [gpu | mig].
+	Types []string
+	// Products are glob patterns for the product names of the devices to
+	// select. For MIG devices the product name of the parent GPU is used.
+	Products []string
+	// UUIDs are the UUIDs of the devices to select.
+	UUIDs []string
+}
+
+// ParseDeviceRule parses a device rule of the form SELECTOR:ACTIONS.
+//
+// The SELECTOR is either 'all' or a semicolon-separated list of
+// 'type=gpu|mig', 'product=GLOB', or 'uuid=UUID' terms. The ACTIONS are a
+// semicolon-separated list of 'enable-hook=HOOK', 'disable-hook=HOOK', or
+// 'feature-flag=FLAG' terms. For example:
+//
+//	type=gpu;product=*H100*:disable-hook=update-application-profile
+//
+// Commas are not used as separators so that multiple rules can be specified
+// as a comma-separated list.
+func ParseDeviceRule(rule string) (*DeviceRule, error) {
+	selector, actions, ok := strings.Cut(rule, ":")
+	if !ok || actions == "" {
+		return nil, fmt.Errorf("invalid device rule %q: expected SELECTOR:ACTIONS", rule)
+	}
+
+	r := &DeviceRule{}
+	if selector != "all" {
+		for _, term := range strings.Split(selector, ";") {
+			key, value, ok := strings.Cut(term, "=")
+			if !ok || value == "" {
+				return nil, fmt.Errorf("invalid device selector %q in rule %q", term, rule)
+			}
+			switch key {
+			case "type":
+				r.Selector.Types = append(r.Selector.Types, value)
+			case "product":
+				r.Selector.Products = append(r.Selector.Products, value)
+			case "uuid":
+				r.Selector.UUIDs = append(r.Selector.UUIDs, value)
+			default:
+				return nil, fmt.Errorf("unknown device selector %q in rule %q", key, rule)
+			}
+		}
+	}
+
+	for _, term := range strings.Split(actions, ";") {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid device rule action %q in rule %q", term, rule)
+		}
+		switch key {
+		case "enable-hook":
+			r.EnabledHooks = append(r.EnabledHooks, HookName(value))
+		case "disable-hook":
+			r.DisabledHooks = append(r.DisabledHooks, HookName(value))
+		case "feature-flag":
+			r.FeatureFlags = append(r.FeatureFlags, FeatureFlag(value))
+		default:
+			return nil, fmt.Errorf("unknown device rule action %q in rule %q", key, rule)
+		}
+	}
+
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid device rule %q: %w", rule, err)
+	}
+	return r, nil
+}
+
+func (r *DeviceRule) validate() error {
+	for _, t := range r.Selector.Types {
+		if t != DeviceTypeGPU && t != DeviceTypeMIG {
+			return fmt.Errorf("unsupported device type %q", t)
+		}
+	}
+	for _, p := range r.Selector.Products {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid product pattern %q: %w", p, err)
+		}
+	}
+	for _, h := range append(slices.Clone(r.EnabledHooks), r.DisabledHooks...) {
+		if !slices.Contains(perDeviceHooks, h) {
+			return fmt.Errorf("hook %q cannot be selected per device", h)
+		}
+	}
+	for _, f := range r.FeatureFlags {
+		if !slices.Contains(perDeviceFeatureFlags, f) {
+			return fmt.Errorf("feature flag %q cannot be enabled per device", f)
+		}
+	}
+	return nil
+}
+
+// matches checks whether the device with the specified properties is
+// selected.
+func (s *DeviceSelector) matches(p *DeviceProperties) bool {
+	deviceType := DeviceTypeGPU
+	if p.ParentUUID != "" {
+		deviceType = DeviceTypeMIG
+	}
+	if len(s.Types) > 0 && !slices.Contains(s.Types, deviceType) {
+		return false
+	}
+	if len(s.UUIDs) > 0 && !slices.Contains(s.UUIDs, p.UUID) {
+		return false
+	}
+	if len(s.Products) > 0 && !slices.ContainsFunc(s.Products, func(pattern string) bool {
+		matched, _ := path.Match(pattern, p.Product)
+		return matched
+	}) {
+		return false
+	}
+	return true
+}
+
+// deviceRules applies the configured device rules to the generated device
+// specifications.
+type deviceRules struct {
+	logger logger.Interface
+	rules  []DeviceRule
+	// defaultHookCreator creates hooks according to the hooks that are enabled
+	// or disabled for all devices.
+	defaultHookCreator discover.HookCreator
+	// hookCreator creates the hooks that are enabled for a device by a rule.
+	hookCreator discover.HookCreator
+}
+
+// newDeviceRules creates the device rules for the specified options. If no
+// rules are specified, nil is returned.
+func (o *options) newDeviceRules() (*deviceRules, error) {
+	if len(o.deviceRules) == 0 {
+		return nil, nil
+	}
+	if o.mode != ModeNvml {
+		o.logger.Warningf("Ignoring device rules for mode %v", o.mode)
+		return nil, nil
+	}
+	for i := range o.deviceRules {
+		if err := o.deviceRules[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid device rule: %w", err)
+		}
+	}
+
+	r := &deviceRules{
+		logger:             o.logger,
+		rules:              o.deviceRules,
+		defaultHookCreator: o.newHookCreator(nil),
+		hookCreator: discover.NewHookCreator(
+			discover.WithNVIDIACDIHookPath(o.nvidiaCDIHookPath),
+			discover.WithLdconfigPath(o.ldconfigPath),
+			discover.WithEnabledHooks(perDeviceHooks...),
+			discover.WithHookPolicies(o.hookPolicies),
+		),
+	}
+	return r, nil
+}
+
+// newHookCreator creates the hook creator for the common edits. The specified
+// hooks are excluded since these are added to the edits of specific devices.
+func (o *options) newHookCreator(excludedHooks []HookName) discover.HookCreator {
+	enabledHooks := slices.DeleteFunc(slices.Clone(o.enabledHooks), func(h HookName) bool {
+		return slices.Contains(excludedHooks, h)
+	})
+	return discover.NewHookCreator(
+		discover.WithNVIDIACDIHookPath(o.nvidiaCDIHookPath),
+		discover.WithEnabledHooks(enabledHooks...),
+		discover.WithLdconfigPath(o.ldconfigPath),
+		discover.WithDisabledHooks(append(slices.Clone(o.disabledHooks), excludedHooks...)...),
+		discover.WithHookPolicies(o.hookPolicies),
+	)
+}
+
+// hooks returns the hooks that are enabled or disabled by the rules.
+func (r *deviceRules) hooks() []HookName {
+	if r == nil {
+		return nil
+	}
+	var hooks []HookName
+	for _, rule := range r.rules {
+		for _, h := range append(slices.Clone(rule.EnabledHooks), rule.DisabledHooks...) {
+			if !slices.Contains(hooks, h) {
+				hooks = append(hooks, h)
+			}
+		}
+	}
+	return hooks
+}
+
+// forDevice returns the feature flags and the hooks that are enabled for the
+// device with the specified properties.
+func (r *deviceRules) forDevice(p *DeviceProperties, featureFlags map[FeatureFlag]bool) (map[FeatureFlag]bool, []HookName) {
+	enabled := make(map[HookName]bool)
+	for _, h := range r.hooks() {
+		enabled[h] = r.defaultHookCreator.Create(h) != nil
+	}
+
+	featureFlags = maps.Clone(featureFlags)
+	for _, rule := range r.rules {
+		if !rule.Selector.matches(p) {
+			continue
+		}
+		for _, h := range rule.EnabledHooks {
+			enabled[h] = true
+		}
+		for _, h := range rule.DisabledHooks {
+			enabled[h] = false
+		}
+		for _, f := range rule.FeatureFlags {
+			if featureFlags == nil {
+				featureFlags = make(map[FeatureFlag]bool)
+			}
+			featureFlags[f] = true
+		}
+	}
+
+	var hooks []HookName
+	for _, h := range r.hooks() {
+		if enabled[h] {
+			hooks = append(hooks, h)
+		}
+	}
+	return featureFlags, hooks
+}
+
+// newHookDiscoverer returns a discoverer for the specified hooks. If the
+// enable-cuda-compat hook is included, it is followed by an update-ldcache
+// hook for the specified driver libraries. This update-ldcache hook is subject
+// to the hooks that are enabled or disabled for all devices.
+func (r *deviceRules) newHookDiscoverer(hooks []HookName, driverVersion string, libraries discover.Discover) discover.Discover {
+	var discoverers []discover.Discover
+	for _, h := range hooks {
+		switch h {
+		case EnableCudaCompatHook:
+			updateLDCache, _ := discover.NewLDCacheUpdateHook(r.logger, libraries, r.defaultHookCreator)
+			discoverers = append(discoverers,
+				discover.NewCUDACompatHookDiscoverer(r.logger, r.hookCreator, &discover.EnableCUDACompatHookOptions{HostDriverVersion: driverVersion}),
+				updateLDCache,
+			)
+		default:
+			discoverers = append(discoverers, r.hookCreator.Create(h))
+		}
+	}
+	return discover.Merge(discoverers...)
+}
@@ -0,0 +1,240 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package nvcdi
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock/dgxa100"
+	mockserver "github.com/NVIDIA/go-nvml/pkg/nvml/mock/server"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/test"
+)
+
+func TestParseDeviceRule(t *testing.T) {
+	testCases := []struct {
+		description   string
+		rule          string
+		expectedRule  *DeviceRule
+		expectedError error
+	}{
+		{
+			description: "all devices",
+			rule:        "all:disable-hook=update-application-profile",
+			expectedRule: &DeviceRule{
+				DisabledHooks: []HookName{ApplicationProfileHook},
+			},
+		},
+		{
+			description: "multiple selectors and actions",
+			rule:        "type=gpu;product=*H100*;product=*H200*:enable-hook=enable-cuda-compat;feature-flag=enable-device-info-annotations",
+			expectedRule: &DeviceRule{
+				Selector: DeviceSelector{
+					Types:    []string{"gpu"},
+					Products: []string{"*H100*", "*H200*"},
+				},
+				EnabledHooks: []HookName{EnableCudaCompatHook},
+				FeatureFlags: []FeatureFlag{FeatureEnableDeviceInfoAnnotations},
+			},
+		},
+		{
+			description: "uuid selector",
+			rule:        "uuid=MIG-b1028956-cfa2-0990-bf4a-5da9abb51763:disable-hook=disable-device-node-modification",
+			expectedRule: &DeviceRule{
+				Selector: DeviceSelector{
+					UUIDs: []string{"MIG-b1028956-cfa2-0990-bf4a-5da9abb51763"},
+				},
+				DisabledHooks: []HookName{DisableDeviceNodeModificationHook},
+			},
+		},
+		{
+			description:   "missing actions",
+			rule:          "type=gpu",
+			expectedError: fmt.Errorf(`invalid device rule "type=gpu": expected SELECTOR:ACTIONS`),
+		},
+		{
+			description:   "unknown selector",
+			rule:          "index=0:disable-hook=enable-cuda-compat",
+			expectedError: fmt.Errorf(`unknown device selector "index" in rule "index=0:disable-hook=enable-cuda-compat"`),
+		},
+		{
+			description:   "unsupported device type",
+			rule:          "type=nvswitch:disable-hook=enable-cuda-compat",
+			expectedError: fmt.Errorf(`invalid device rule "type=nvswitch:disable-hook=enable-cuda-compat": unsupported device type "nvswitch"`),
+		},
+		{
+			description:   "hook that cannot be selected per device",
+			rule:          "all:disable-hook=update-ldcache",
+			expectedError: fmt.Errorf(`invalid device rule "all:disable-hook=update-ldcache": hook "update-ldcache" cannot be selected per device`),
+		},
+		{
+			description:   "feature flag that cannot be enabled per device",
+			rule:          "all:feature-flag=disable-nvsandboxutils",
+			expectedError: fmt.Errorf(`invalid device rule "all:feature-flag=disable-nvsandboxutils": feature flag "disable-nvsandboxutils" cannot be enabled per device`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rule, err := ParseDeviceRule(tc.rule)
+			if tc.expectedError != nil {
+				require.EqualError(t, err, tc.expectedError.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRule, rule)
+		})
+	}
+}
+
+func TestDeviceRules(t *testing.T) {
+	defer devices.SetAllForTest()()
+	logger, _ := testlog.NewNullLogger()
+
+	moduleRoot, err := test.GetModuleRoot()
+	require.NoError(t, err)
+	driverRoot := filepath.Join(moduleRoot, "testdata", "lookup", "rootfs-1")
+
+	testCases := []struct {
+		description         string
+		rules               []string
+		expectedCommonHooks []string
+		expectedDeviceHooks []string
+		// expectedHooks is the order in which the hooks are applied to a
+		// container. The common edits are applied before the device edits.
+		expectedHooks       []string
+		expectedAnnotations map[string]string
+	}{
+		{
+			description:         "no rules",
+			expectedCommonHooks: []string{"create-symlinks", "enable-cuda-compat", "update-ldcache", "disable-device-node-modification", "update-application-profile"},
+		},
+		{
+			description:         "hook disabled for matching device",
+			rules:               []string{"product=*A100*:disable-hook=enable-cuda-compat"},
+			expectedCommonHooks: []string{"create-symlinks", "update-ldcache", "disable-device-node-modification", "update-application-profile"},
+		},
+		{
+			description:         "hook disabled for other devices",
+			rules:               []string{"product=*H100*:disable-hook=update-application-profile"},
+			expectedCommonHooks: []string{"create-symlinks", "enable-cuda-compat", "update-ldcache", "disable-device-node-modification"},
+			expectedDeviceHooks: []string{"update-application-profile"},
+		},
+		{
+			description: "later rules take precedence",
+			rules: []string{
+				"all:disable-hook=enable-cuda-compat",
+				"type=gpu:enable-hook=enable-cuda-compat",
+				"type=mig:disable-hook=enable-cuda-compat",
+			},
+			expectedCommonHooks: []string{"create-symlinks", "update-ldcache", "disable-device-node-modification", "update-application-profile"},
+			expectedDeviceHooks: []string{"enable-cuda-compat", "update-ldcache"},
+		},
+		{
+			description: "ldcache is updated after enabling CUDA compat for a device",
+			rules: []string{
+				"all:disable-hook=enable-cuda-compat",
+				"type=gpu:enable-hook=enable-cuda-compat",
+			},
+			expectedCommonHooks: []string{"create-symlinks", "update-ldcache", "disable-device-node-modification", "update-application-profile"},
+			expectedDeviceHooks: []string{"enable-cuda-compat", "update-ldcache"},
+			expectedHooks: []string{
+				"create-symlinks",
+				"update-ldcache",
+				"disable-device-node-modification",
+				"update-application-profile",
+				"enable-cuda-compat",
+				"update-ldcache",
+			},
+		},
+		{
+			description:         "feature flag enabled for matching device",
+			rules:               []string{"type=gpu:feature-flag=enable-coherent-annotations"},
+			expectedCommonHooks: []string{"create-symlinks", "enable-cuda-compat", "update-ldcache", "disable-device-node-modification", "update-application-profile"},
+			expectedAnnotations: map[string]string{
+				"gpu.nvidia.com/coherent": "false",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var rules []DeviceRule
+			for _, r := range tc.rules {
+				rule, err := ParseDeviceRule(r)
+				require.NoError(t, err)
+				rules = append(rules, *rule)
+			}
+
+			server := dgxa100.New()
+			mockOverrides(server)
+			server.SystemGetDriverVersionFunc = func() (string, nvml.Return) {
+				return "999.88.77", nvml.SUCCESS
+			}
+			server.DeviceGetCountFunc = func() (int, nvml.Return) {
+				return 1, nvml.SUCCESS
+			}
+			for _, d := range server.Devices {
+				d.(*mockserver.Device).GetNumaNodeIdFunc = func() (int, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				}
+				d.(*mockserver.Device).GetAddressingModeFunc = func() (nvml.DeviceAddressingMode, nvml.Return) {
+					return nvml.DeviceAddressingMode{}, nvml.ERROR_NOT_SUPPORTED
+				}
+			}
+
+			lib, err := New(
+				WithLogger(logger),
+				WithMode(ModeNvml),
+				WithNvmlLib(server),
+				WithDriverRoot(driverRoot),
+				WithFeatureFlags(FeatureDisableNvsandboxUtils),
+				WithDeviceRules(rules...),
+			)
+			require.NoError(t, err)
+
+			deviceSpecs, err := lib.GetDeviceSpecsByID("all")
+			require.NoError(t, err)
+			require.Len(t, deviceSpecs, 1)
+			commonEdits, err := lib.GetCommonEdits()
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expectedCommonHooks, hookNames(commonEdits.ContainerEdits))
+			require.Equal(t, tc.expectedDeviceHooks, hookNames(&deviceSpecs[0].ContainerEdits))
+			if tc.expectedHooks != nil {
+				hooks := append(hookNames(commonEdits.ContainerEdits), hookNames(&deviceSpecs[0].ContainerEdits)...)
+				require.Equal(t, tc.expectedHooks, hooks)
+			}
+			require.Equal(t, tc.expectedAnnotations, deviceSpecs[0].Annotations)
+		})
+	}
+}
+
+func hookNames(edits *specs.ContainerEdits) []string {
+	var names []string
+	for _, h := range edits.Hooks {
+		names = append(names, h.Args[1])
+	}
+	return names
+}
@@ -26,8 +26,9 @@ const (
 
 // newDXGDeviceDiscoverer returns a Discoverer for DXG devices under WSL2.
 func (l *wsllib) newDXGDeviceDiscoverer() discover.Discover {
-	deviceNodes := discover.NewCharDeviceDiscoverer(
+	deviceNodes := discover.NewCharDeviceDiscovererWithDevicesLib(
 		l.logger,
+		l.deviceslib,
 		l.driver.DevRoot,
 		[]string{dxgDeviceNode},
 	)
@@ -77,25 +77,11 @@ func (l *nvcdilib) newIPCDiscoverer() (discover.Discover, error) {
 
 // NewDriverLibraryDiscoverer creates a discoverer for the libraries associated with the specified driver version.
 func (l *nvcdilib) NewDriverLibraryDiscoverer(version string) (discover.Discover, error) {
-	versionSuffixLibraryMounts, err := l.getVersionSuffixDriverLibraryMounts(version)
-	if err != nil {
-		return nil, err
-	}
-	legacyNVVMLibraryMounts, err := l.getLegacyNVVMLibraryMounts()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get legacy nvvm library mounts: %w", err)
-	}
-	explicitLibraryMounts, err := l.getExplicitDriverLibraryMounts()
+	libraries, err := l.getDriverLibraryMounts(version)
 	if err != nil {
 		return nil, err
 	}
 
-	libraries := discover.Merge(
-		versionSuffixLibraryMounts,
-		legacyNVVMLibraryMounts,
-		explicitLibraryMounts,
-	)
-
 	var discoverers []discover.Discover
 
 	driverDotSoSymlinksDiscoverer := discover.WithDriverDotSoSymlinks(
@@ -131,6 +117,30 @@ func (l *nvcdilib) NewDriverLibraryDiscoverer(version string) (discover.Discover
 	return d, nil
 }
 
+// getDriverLibraryMounts returns a discoverer for the mounts of the libraries
+// associated with the specified driver version.
+func (l *nvcdilib) getDriverLibraryMounts(version string) (discover.Discover, error) {
+	versionSuffixLibraryMounts, err := l.getVersionSuffixDriverLibraryMounts(version)
+	if err != nil {
+		return nil, err
+	}
+	legacyNVVMLibraryMounts, err := l.getLegacyNVVMLibraryMounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legacy nvvm library mounts: %w", err)
+	}
+	explicitLibraryMounts, err := l.getExplicitDriverLibraryMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	libraries := discover.Merge(
+		versionSuffixLibraryMounts,
+		legacyNVVMLibraryMounts,
+		explicitLibraryMounts,
+	)
+	return libraries, nil
+}
+
 func (l *nvcdilib) getVersionSuffixDriverLibraryMounts(version string) (discover.Discover, error) {
 	versionSuffixLibraryPaths, err := l.getVersionLibs(version)
 	if err != nil {
@@ -215,17 +225,24 @@ func getUTSRelease() (string, error) {
 	return unix.ByteSliceToString(utsname.Release[:]), nil
 }
 
-func getFirmwareSearchPaths(logger logger.Interface) ([]string, error) {
+// getFirmwareSearchPaths returns the paths that are searched for firmware
+// files. If no kernel release is specified, the release of the running kernel
+// is used.
+func getFirmwareSearchPaths(logger logger.Interface, hostRoot string, kernelRelease string) ([]string, error) {
 
 	var firmwarePaths []string
-	if p := getCustomFirmwareClassPath(logger); p != "" {
+	if p := getCustomFirmwareClassPath(logger, hostRoot); p != "" {
 		logger.Debugf("using custom firmware class path: %s", p)
 		firmwarePaths = append(firmwarePaths, p)
 	}
 
-	utsRelease, err := getUTSRelease()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get UTS_RELEASE: %v", err)
+	utsRelease := kernelRelease
+	if utsRelease == "" {
+		release, err := getUTSRelease()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get UTS_RELEASE: %v", err)
+		}
+		utsRelease = release
 	}
 
 	standardPaths := []string{
@@ -239,8 +256,8 @@ func getFirmwareSearchPaths(logger logger.Interface) ([]string, error) {
 }
 
 // getCustomFirmwareClassPath returns the custom firmware class path if it exists.
-func getCustomFirmwareClassPath(logger logger.Interface) string {
-	customFirmwareClassPath, err := os.ReadFile("/sys/module/firmware_class/parameters/path")
+func getCustomFirmwareClassPath(logger logger.Interface, hostRoot string) string {
+	customFirmwareClassPath, err := os.ReadFile(filepath.Join(hostRoot, "/sys/module/firmware_class/parameters/path"))
 	if err != nil {
 		logger.Warningf("failed to get custom firmware class path: %v", err)
 		return ""
@@ -251,7 +268,7 @@ func getCustomFirmwareClassPath(logger logger.Interface) string {
 
 // newDriverFirmwareDiscoverer creates a discoverer for GSP firmware associated with the specified driver version.
 func (l *nvcdilib) newDriverFirmwareDiscoverer(version string) (discover.Discover, error) {
-	gspFirmwareSearchPaths, err := getFirmwareSearchPaths(l.logger)
+	gspFirmwareSearchPaths, err := getFirmwareSearchPaths(l.logger, l.hostRoot, l.kernelRelease)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get firmware search paths: %v", err)
 	}
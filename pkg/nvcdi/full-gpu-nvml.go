@@ -18,6 +18,7 @@ package nvcdi
 
 import (
 	"fmt"
+	"maps"
 
 	"tags.cncf.io/container-device-interface/pkg/cdi"
 	"tags.cncf.io/container-device-interface/specs-go"
@@ -38,6 +39,9 @@ type fullGPUDeviceSpecGenerator struct {
 
 	featureFlags          map[FeatureFlag]bool
 	additionalDiscoverers []discover.Discover
+	// deviceRuleHooks are the hooks that are enabled for the device by the
+	// configured device rules.
+	deviceRuleHooks []HookName
 }
 
 var _ DeviceSpecGenerator = (*fullGPUDeviceSpecGenerator)(nil)
@@ -79,6 +83,10 @@ func (l *nvmllib) newFullGPUDeviceSpecGeneratorFromNVMLDevice(uuid string, nvmlD
 }
 
 func (l *fullGPUDeviceSpecGenerator) GetDeviceSpecs() ([]specs.Device, error) {
+	if err := l.applyDeviceRules(l); err != nil {
+		return nil, err
+	}
+
 	deviceEdits, err := l.getDeviceEdits()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get CDI device edits: %w", err)
@@ -89,7 +97,15 @@ func (l *fullGPUDeviceSpecGenerator) GetDeviceSpecs() ([]specs.Device, error) {
 		return nil, fmt.Errorf("failed to get device names: %w", err)
 	}
 
-	annotations, err := l.getDeviceAnnotations()
+	return l.newDeviceSpecs(names, deviceEdits, l.getDeviceAnnotations), nil
+}
+
+// newDeviceSpecs returns a device spec with the specified edits for each of
+// the specified names. This is shared by full GPUs and MIG devices to ensure
+// that errors getting the device annotations are handled consistently: such
+// errors are logged and the device specs are generated without annotations.
+func (l *fullGPUDeviceSpecGenerator) newDeviceSpecs(names []string, deviceEdits *cdi.ContainerEdits, getAnnotations func() (map[string]string, error)) []specs.Device {
+	annotations, err := getAnnotations()
 	if err != nil {
 		l.logger.Warningf("Ignoring error getting device annotations for device(s) %v: %v", names, err)
 		annotations = nil
@@ -103,8 +119,39 @@ func (l *fullGPUDeviceSpecGenerator) GetDeviceSpecs() ([]specs.Device, error) {
 		}
 		deviceSpecs = append(deviceSpecs, deviceSpec)
 	}
+	return deviceSpecs
+}
 
-	return deviceSpecs, nil
+// applyDeviceRules applies the configured device rules to the device whose
+// properties are returned by the specified getter. This updates the feature
+// flags and the hooks for the device.
+func (l *fullGPUDeviceSpecGenerator) applyDeviceRules(d devicePropertiesGetter) error {
+	if l.deviceRules == nil {
+		return nil
+	}
+	properties, err := d.getDeviceProperties()
+	if err != nil {
+		return fmt.Errorf("failed to get device properties for device rules: %w", err)
+	}
+	l.featureFlags, l.deviceRuleHooks = l.deviceRules.forDevice(properties, l.featureFlags)
+	return nil
+}
+
+// newDeviceRuleHookDiscoverer returns a discoverer for the hooks that are
+// enabled for the device by the configured device rules.
+func (l *fullGPUDeviceSpecGenerator) newDeviceRuleHookDiscoverer() (discover.Discover, error) {
+	if len(l.deviceRuleHooks) == 0 {
+		return nil, nil
+	}
+	version, err := l.driver.Version()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine driver version: %w", err)
+	}
+	libraries, err := (*nvcdilib)(l.nvmllib).getDriverLibraryMounts(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discoverer for driver libraries: %w", err)
+	}
+	return l.deviceRules.newHookDiscoverer(l.deviceRuleHooks, version, libraries), nil
 }
 
 func (l *fullGPUDeviceSpecGenerator) device() (device.Device, error) {
@@ -112,25 +159,41 @@ func (l *fullGPUDeviceSpecGenerator) device() (device.Device, error) {
 }
 
 func (l *fullGPUDeviceSpecGenerator) getDeviceAnnotations() (map[string]string, error) {
-	if !l.featureFlags[FeatureEnableCoherentAnnotations] {
-		return nil, nil
-	}
+	annotations := make(map[string]string)
 
-	device, err := l.device()
-	if err != nil {
-		return nil, err
+	if l.featureFlags[FeatureEnableCoherentAnnotations] {
+		device, err := l.device()
+		if err != nil {
+			return nil, err
+		}
+
+		// TODO: Should we distinguish between not-supported and disabled?
+		isCoherent, err := device.IsCoherent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check device coherence: %w", err)
+		}
+		annotations["gpu.nvidia.com/coherent"] = fmt.Sprintf("%v", isCoherent)
 	}
 
-	// TODO: Should we distinguish between not-supported and disabled?
-	isCoherent, err := device.IsCoherent()
-	if err != nil {
-		return nil, fmt.Errorf("failed to check device coherence: %w", err)
+	if l.featureFlags[FeatureEnableDeviceInfoAnnotations] {
+		deviceInfoAnnotations, err := l.getDeviceInfoAnnotations()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device info: %w", err)
+		}
+		maps.Copy(annotations, deviceInfoAnnotations)
 	}
 
-	annotations := map[string]string{
-		"gpu.nvidia.com/coherent": fmt.Sprintf("%v", isCoherent),
+	if l.featureFlags[FeatureEnableTopologyAnnotations] {
+		topologyAnnotations, err := l.getTopologyAnnotations()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device topology: %w", err)
+		}
+		maps.Copy(annotations, topologyAnnotations)
 	}
 
+	if len(annotations) == 0 {
+		return nil, nil
+	}
 	return annotations, nil
 }
 
@@ -153,6 +216,38 @@ func (l *fullGPUDeviceSpecGenerator) getDeviceEdits() (*cdi.ContainerEdits, erro
 	return editsForDevice, nil
 }
 
+// getDeviceProperties returns the properties of the GPU that can be used in
+// template-based device names.
+func (l *fullGPUDeviceSpecGenerator) getDeviceProperties() (*DeviceProperties, error) {
+	device, err := l.device()
+	if err != nil {
+		return nil, err
+	}
+
+	pciBusID, err := device.GetPCIBusID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PCI bus ID: %w", err)
+	}
+	product, ret := device.GetName()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get product name: %v", ret)
+	}
+	numaNode, ret := device.GetNumaNodeId()
+	if ret != nvml.SUCCESS {
+		l.logger.Debugf("Failed to get NUMA node for device %v: %v", l.uuid, ret)
+		numaNode = -1
+	}
+
+	properties := &DeviceProperties{
+		Index:    l.index,
+		UUID:     l.uuid,
+		PCIBusID: pciBusID,
+		Product:  product,
+		NUMANode: numaNode,
+	}
+	return properties, nil
+}
+
 func (l *fullGPUDeviceSpecGenerator) getNames() ([]string, error) {
 	return l.deviceNamers.GetDeviceNames(l.index, l)
 }
@@ -161,9 +256,11 @@ func (l *fullGPUDeviceSpecGenerator) getNames() ([]string, error) {
 func (l *fullGPUDeviceSpecGenerator) newFullGPUDiscoverer(d device.Device) (discover.Discover, error) {
 	deviceNodes, err := dgpu.NewForDevice(d,
 		dgpu.WithDriver(l.driver),
+		dgpu.WithHostRoot(l.hostRoot),
 		dgpu.WithLogger(l.logger),
 		dgpu.WithHookCreator(l.hookCreator),
 		dgpu.WithNvsandboxuitilsLib(l.nvsandboxutilslib),
+		dgpu.WithDevicesLib(l.deviceslib),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create device discoverer: %v", err)
@@ -173,11 +270,17 @@ func (l *fullGPUDeviceSpecGenerator) newFullGPUDiscoverer(d device.Device) (disc
 		deviceNodes,
 	)
 
+	deviceRuleHooks, err := l.newDeviceRuleHookDiscoverer()
+	if err != nil {
+		return nil, err
+	}
+
 	var discoverers []discover.Discover
 
 	discoverers = append(discoverers,
 		deviceNodes,
 		deviceFolderPermissionHooks,
+		deviceRuleHooks,
 	)
 
 	discoverers = append(discoverers, l.additionalDiscoverers...)
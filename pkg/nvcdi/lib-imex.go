@@ -93,8 +93,9 @@ func (l *imexlib) getChannelIDs(ids ...string) ([]string, error) {
 
 // getAllChannelIDs returns the device IDs for all available IMEX channels.
 func (l *imexlib) getAllChannelIDs() ([]string, error) {
-	channelsDiscoverer := discover.NewCharDeviceDiscoverer(
+	channelsDiscoverer := discover.NewCharDeviceDiscovererWithDevicesLib(
 		l.logger,
+		l.deviceslib,
 		l.driver.DevRoot,
 		[]string{"/dev/nvidia-caps-imex-channels/channel*"},
 	)
@@ -0,0 +1,175 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package nvcdi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
+)
+
+const (
+	// DefaultMPSPipeDirectory is the default pipe directory of an MPS control
+	// daemon.
+	DefaultMPSPipeDirectory = "/tmp/nvidia-mps"
+	// DefaultMPSLogDirectory is the default log directory of an MPS control
+	// daemon.
+	DefaultMPSLogDirectory = "/var/log/nvidia-mps"
+
+	defaultMPSDaemonName = "default"
+)
+
+// An MPSDaemon describes an MPS control daemon for which a CDI device is
+// generated.
+type MPSDaemon struct {
+	// Name is the name of the CDI device for the daemon.
+	Name string
+	// PipeDirectory is the pipe directory (CUDA_MPS_PIPE_DIRECTORY) of the
+	// daemon.
+	PipeDirectory string
+	// LogDirectory is the log directory (CUDA_MPS_LOG_DIRECTORY) of the
+	// daemon. This is optional.
+	LogDirectory string
+	// ActiveThreadPercentage optionally sets the
+	// CUDA_MPS_ACTIVE_THREAD_PERCENTAGE for clients of the daemon.
+	ActiveThreadPercentage string
+	// PinnedDeviceMemoryLimit optionally sets the
+	// CUDA_MPS_PINNED_DEVICE_MEM_LIMIT for clients of the daemon.
+	PinnedDeviceMemoryLimit string
+}
+
+type mpsOptions struct {
+	Daemons []MPSDaemon
+}
+
+type mpslib nvcdilib
+
+type mpsDaemon struct {
+	MPSDaemon
+	root string
+}
+
+var _ deviceSpecGeneratorFactory = (*mpslib)(nil)
+
+// GetCommonEdits returns an empty set of edits for MPS devices.
+func (l *mpslib) GetCommonEdits() (*cdi.ContainerEdits, error) {
+	return l.editsFactory.FromDiscoverer(discover.None{})
+}
+
+// DeviceSpecGenerators returns the CDI device spec generators for the MPS
+// control daemons with the specified names. The special ID 'all' selects all
+// daemons.
+func (l *mpslib) DeviceSpecGenerators(ids ...string) (DeviceSpecGenerator, error) {
+	daemons := l.getDaemons()
+
+	var deviceSpecGenerators DeviceSpecGenerators
+	for _, id := range ids {
+		if id == "all" {
+			deviceSpecGenerators = nil
+			for _, daemon := range daemons {
+				deviceSpecGenerators = append(deviceSpecGenerators, &mpsDaemon{MPSDaemon: daemon, root: l.driver.Root})
+			}
+			return deviceSpecGenerators, nil
+		}
+		daemon, err := getMPSDaemon(daemons, id)
+		if err != nil {
+			return nil, err
+		}
+		deviceSpecGenerators = append(deviceSpecGenerators, &mpsDaemon{MPSDaemon: *daemon, root: l.driver.Root})
+	}
+
+	return deviceSpecGenerators, nil
+}
+
+// getDaemons returns the configured MPS control daemons. If no daemons are
+// configured, a daemon using the default pipe and log directories is returned
+// if the default pipe directory exists.
+func (l *mpslib) getDaemons() []MPSDaemon {
+	if len(l.mps.Daemons) > 0 {
+		return l.mps.Daemons
+	}
+	if _, err := os.Stat(filepath.Join(l.driver.Root, DefaultMPSPipeDirectory)); err != nil {
+		l.logger.Warningf("No MPS control daemons found: %v", err)
+		return nil
+	}
+	return []MPSDaemon{
+		{
+			Name:          defaultMPSDaemonName,
+			PipeDirectory: DefaultMPSPipeDirectory,
+			LogDirectory:  DefaultMPSLogDirectory,
+		},
+	}
+}
+
+func getMPSDaemon(daemons []MPSDaemon, name string) (*MPSDaemon, error) {
+	for _, daemon := range daemons {
+		if daemon.Name == name {
+			return &daemon, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown MPS control daemon %q", name)
+}
+
+// GetDeviceSpecs returns the CDI device specs for the MPS control daemon.
+func (d *mpsDaemon) GetDeviceSpecs() ([]specs.Device, error) {
+	if d.PipeDirectory == "" {
+		return nil, fmt.Errorf("no pipe directory specified for MPS control daemon %q", d.Name)
+	}
+
+	var mounts []*specs.Mount
+	for _, dir := range []string{d.PipeDirectory, d.LogDirectory} {
+		if dir == "" {
+			continue
+		}
+		hostPath := filepath.Join(d.root, dir)
+		if _, err := os.Stat(hostPath); err != nil {
+			return nil, fmt.Errorf("directory for MPS control daemon %q not found at %s: %w", d.Name, hostPath, err)
+		}
+		mounts = append(mounts, &specs.Mount{
+			HostPath:      hostPath,
+			ContainerPath: dir,
+			Options:       discover.IPCMountOptions,
+		})
+	}
+
+	env := []string{
+		"CUDA_MPS_PIPE_DIRECTORY=" + d.PipeDirectory,
+	}
+	if d.LogDirectory != "" {
+		env = append(env, "CUDA_MPS_LOG_DIRECTORY="+d.LogDirectory)
+	}
+	if d.ActiveThreadPercentage != "" {
+		env = append(env, "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE="+d.ActiveThreadPercentage)
+	}
+	if d.PinnedDeviceMemoryLimit != "" {
+		env = append(env, "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT="+d.PinnedDeviceMemoryLimit)
+	}
+
+	deviceSpec := specs.Device{
+		Name: d.Name,
+		ContainerEdits: specs.ContainerEdits{
+			Env:    env,
+			Mounts: mounts,
+		},
+	}
+	return []specs.Device{deviceSpec}, nil
+}
@@ -0,0 +1,173 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package nvcdi
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMPSMode(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description   string
+		dirs          []string
+		daemons       []MPSDaemon
+		ids           []string
+		expectedError string
+		expectedSpec  string
+	}{
+		{
+			description: "default daemon",
+			dirs:        []string{"/tmp/nvidia-mps", "/var/log/nvidia-mps"},
+			ids:         []string{"all"},
+			expectedSpec: `---
+cdiVersion: 0.3.0
+kind: nvidia.com/mps
+devices:
+    - name: default
+      containerEdits:
+        env:
+            - CUDA_MPS_PIPE_DIRECTORY=/tmp/nvidia-mps
+            - CUDA_MPS_LOG_DIRECTORY=/var/log/nvidia-mps
+        mounts:
+            - hostPath: {{ .root }}/tmp/nvidia-mps
+              containerPath: /tmp/nvidia-mps
+              options:
+                - nosuid
+                - nodev
+                - rbind
+                - rprivate
+                - noexec
+            - hostPath: {{ .root }}/var/log/nvidia-mps
+              containerPath: /var/log/nvidia-mps
+              options:
+                - nosuid
+                - nodev
+                - rbind
+                - rprivate
+                - noexec
+containerEdits:
+    env:
+        - NVIDIA_VISIBLE_DEVICES=void
+`,
+		},
+		{
+			description: "selected daemon with limits",
+			dirs:        []string{"/mps/a/pipe", "/mps/b/pipe", "/mps/b/log"},
+			daemons: []MPSDaemon{
+				{Name: "a", PipeDirectory: "/mps/a/pipe"},
+				{
+					Name:                    "b",
+					PipeDirectory:           "/mps/b/pipe",
+					LogDirectory:            "/mps/b/log",
+					ActiveThreadPercentage:  "50",
+					PinnedDeviceMemoryLimit: "0=2G",
+				},
+			},
+			ids: []string{"b"},
+			expectedSpec: `---
+cdiVersion: 0.3.0
+kind: nvidia.com/mps
+devices:
+    - name: b
+      containerEdits:
+        env:
+            - CUDA_MPS_PIPE_DIRECTORY=/mps/b/pipe
+            - CUDA_MPS_LOG_DIRECTORY=/mps/b/log
+            - CUDA_MPS_ACTIVE_THREAD_PERCENTAGE=50
+            - CUDA_MPS_PINNED_DEVICE_MEM_LIMIT=0=2G
+        mounts:
+            - hostPath: {{ .root }}/mps/b/log
+              containerPath: /mps/b/log
+              options:
+                - nosuid
+                - nodev
+                - rbind
+                - rprivate
+                - noexec
+            - hostPath: {{ .root }}/mps/b/pipe
+              containerPath: /mps/b/pipe
+              options:
+                - nosuid
+                - nodev
+                - rbind
+                - rprivate
+                - noexec
+containerEdits:
+    env:
+        - NVIDIA_VISIBLE_DEVICES=void
+`,
+		},
+		{
+			description: "unknown daemon",
+			daemons: []MPSDaemon{
+				{Name: "a", PipeDirectory: "/mps/a/pipe"},
+			},
+			ids:           []string{"b"},
+			expectedError: `unknown MPS control daemon "b"`,
+		},
+		{
+			description: "missing pipe directory",
+			daemons: []MPSDaemon{
+				{Name: "a", PipeDirectory: "/mps/a/pipe"},
+			},
+			ids:           []string{"a"},
+			expectedError: `directory for MPS control daemon "a" not found`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			root := t.TempDir()
+			for _, dir := range tc.dirs {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+			}
+
+			lib, err := New(
+				WithLogger(logger),
+				WithMode(ModeMPS),
+				WithDriverRoot(root),
+				WithMPSDaemons(tc.daemons...),
+			)
+			require.NoError(t, err)
+
+			deviceSpecs, err := lib.GetDeviceSpecsByID(tc.ids...)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			spec, err := lib.GetSpec(tc.ids...)
+			require.NoError(t, err)
+			require.Len(t, spec.Raw().Devices, len(deviceSpecs))
+
+			var b bytes.Buffer
+			_, err = spec.WriteTo(&b)
+			require.NoError(t, err)
+			require.Equal(t, strings.ReplaceAll(tc.expectedSpec, "{{ .root }}", root), b.String())
+		})
+	}
+}
@@ -18,12 +18,15 @@
 package nvcdi
 
 import (
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 	mocknvml "github.com/NVIDIA/go-nvml/pkg/nvml/mock"
 	"github.com/NVIDIA/go-nvml/pkg/nvml/mock/dgxa100"
 	mockserver "github.com/NVIDIA/go-nvml/pkg/nvml/mock/server"
+	testlog "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
@@ -158,3 +161,305 @@ func mockOverrides(server *mockserver.Server) {
 		}
 	}
 }
+
+// newTestNVMLLib returns an nvmllib for a mocked DGX A100 system. The PCI bus
+// ID of the GPU at index i is 0000:{0x07+i*0x08}:00.0. The GPUs as well as the
+// specified MIG devices can be looked up by UUID.
+func newTestNVMLLib(t *testing.T, migDevices ...nvml.Device) (*nvmllib, *mockserver.Server) {
+	t.Helper()
+	logger, _ := testlog.NewNullLogger()
+
+	mockNvml := dgxa100.New()
+	mockOverrides(mockNvml)
+	for i, d := range mockNvml.Devices {
+		d.(*mockserver.Device).GetPciInfoFunc = func() (nvml.PciInfo, nvml.Return) {
+			var info nvml.PciInfo
+			for j, c := range fmt.Sprintf("00000000:%02x:00.0", 0x07+i*0x08) {
+				info.BusId[j] = int8(c)
+			}
+			return info, nvml.SUCCESS
+		}
+	}
+	mockNvml.DeviceGetHandleByUUIDFunc = func(uuid string) (nvml.Device, nvml.Return) {
+		for _, d := range append(slices.Clone(mockNvml.Devices), migDevices...) {
+			if u, _ := d.GetUUID(); u == uuid {
+				return d, nvml.SUCCESS
+			}
+		}
+		return nil, nvml.ERROR_NOT_FOUND
+	}
+
+	l := &nvmllib{
+		logger: logger,
+		platformlibs: platformlibs{
+			nvmllib:   mockNvml,
+			devicelib: device.New(mockNvml),
+		},
+	}
+	return l, mockNvml
+}
+
+// newTestFullGPUDeviceSpecGenerator returns the generator for the GPU at the
+// specified index of the mocked system.
+func newTestFullGPUDeviceSpecGenerator(l *nvmllib, server *mockserver.Server, index int, featureFlags map[FeatureFlag]bool) *fullGPUDeviceSpecGenerator {
+	return &fullGPUDeviceSpecGenerator{
+		nvmllib:      l,
+		uuid:         server.Devices[index].(*mockserver.Device).UUID,
+		index:        index,
+		featureFlags: featureFlags,
+	}
+}
+
+func TestFullGPUDeviceProperties(t *testing.T) {
+	l, mockNvml := newTestNVMLLib(t)
+	d := mockNvml.Devices[3].(*mockserver.Device)
+	d.GetNumaNodeIdFunc = func() (int, nvml.Return) {
+		return 0, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	generator := newTestFullGPUDeviceSpecGenerator(l, mockNvml, 3, nil)
+
+	properties, err := generator.getDeviceProperties()
+	require.NoError(t, err)
+	require.Equal(t, &DeviceProperties{
+		Index:    3,
+		UUID:     d.UUID,
+		PCIBusID: "0000:1f:00.0",
+		Product:  "Mock NVIDIA A100-SXM4-40GB",
+		NUMANode: -1,
+	}, properties)
+}
+
+func TestFullGPUTopologyAnnotations(t *testing.T) {
+	l, mockNvml := newTestNVMLLib(t)
+	indices := make(map[string]int)
+	for i, d := range mockNvml.Devices {
+		d := d.(*mockserver.Device)
+		indices[d.UUID] = i
+		d.GetNumaNodeIdFunc = func() (int, nvml.Return) {
+			return i / 4, nvml.SUCCESS
+		}
+		d.GetGpuFabricInfoFunc = func() (nvml.GpuFabricInfo, nvml.Return) {
+			info := nvml.GpuFabricInfo{
+				ClusterUuid: [16]uint8{0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x12, 0x34, 0x12, 0x34, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc},
+				CliqueId:    7,
+				State:       nvml.GPU_FABRIC_STATE_COMPLETED,
+				Status:      uint32(nvml.SUCCESS),
+			}
+			return info, nvml.SUCCESS
+		}
+		// Devices 2n and 2n+1 are connected to the same PCIe switch.
+		d.GetTopologyCommonAncestorFunc = func(other nvml.Device) (nvml.GpuTopologyLevel, nvml.Return) {
+			uuid, _ := other.GetUUID()
+			if indices[uuid]/2 == i/2 {
+				return nvml.TOPOLOGY_SINGLE, nvml.SUCCESS
+			}
+			return nvml.TOPOLOGY_SYSTEM, nvml.SUCCESS
+		}
+	}
+
+	generator := newTestFullGPUDeviceSpecGenerator(l, mockNvml, 3, map[FeatureFlag]bool{
+		FeatureEnableTopologyAnnotations: true,
+	})
+
+	annotations, err := generator.getDeviceAnnotations()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"gpu.nvidia.com/uuid":          generator.uuid,
+		"gpu.nvidia.com/numa-node":     "0",
+		"gpu.nvidia.com/nvlink-clique": "12345678-1234-1234-1234-123456789abc.7",
+		"gpu.nvidia.com/pcie-switch":   "0000:17:00.0",
+	}, annotations)
+}
+
+func TestFullGPUPCIeSwitchAnnotations(t *testing.T) {
+	l, mockNvml := newTestNVMLLib(t)
+	indices := make(map[string]int)
+	for i, d := range mockNvml.Devices {
+		d := d.(*mockserver.Device)
+		indices[d.UUID] = i
+		// Devices 1 and 3 are each connected to device 2 through a PCIe switch
+		// but not to each other. The three devices form a single group.
+		d.GetTopologyCommonAncestorFunc = func(other nvml.Device) (nvml.GpuTopologyLevel, nvml.Return) {
+			uuid, _ := other.GetUUID()
+			j := indices[uuid]
+			if (i == 2 && (j == 1 || j == 3)) || (j == 2 && (i == 1 || i == 3)) {
+				return nvml.TOPOLOGY_MULTIPLE, nvml.SUCCESS
+			}
+			return nvml.TOPOLOGY_SYSTEM, nvml.SUCCESS
+		}
+	}
+
+	expectedPCIeSwitches := map[int]string{
+		1: "0000:0f:00.0",
+		2: "0000:0f:00.0",
+		3: "0000:0f:00.0",
+	}
+	for i := range mockNvml.Devices {
+		generator := newTestFullGPUDeviceSpecGenerator(l, mockNvml, i, nil)
+		pcieSwitch, err := generator.getPCIeSwitch()
+		require.NoError(t, err)
+		require.Equal(t, expectedPCIeSwitches[i], pcieSwitch, "device %d", i)
+	}
+}
+
+func TestFullGPUDeviceInfoAnnotations(t *testing.T) {
+	l, mockNvml := newTestNVMLLib(t)
+	d := mockNvml.Devices[3].(*mockserver.Device)
+	d.GetNumaNodeIdFunc = func() (int, nvml.Return) {
+		return 1, nvml.SUCCESS
+	}
+
+	generator := newTestFullGPUDeviceSpecGenerator(l, mockNvml, 3, map[FeatureFlag]bool{
+		FeatureEnableDeviceInfoAnnotations: true,
+	})
+
+	annotations, err := generator.getDeviceAnnotations()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"gpu.nvidia.com/product":            "Mock NVIDIA A100-SXM4-40GB",
+		"gpu.nvidia.com/architecture":       "Ampere",
+		"gpu.nvidia.com/compute-capability": "8.0",
+		"gpu.nvidia.com/memory-mib":         "40960",
+		"gpu.nvidia.com/pci-bus-id":         "0000:1f:00.0",
+		"gpu.nvidia.com/numa-node":          "1",
+		"gpu.nvidia.com/uuid":               d.UUID,
+	}, annotations)
+}
+
+func TestMIGDeviceInfoAnnotations(t *testing.T) {
+	const (
+		gpuInstanceID     = 1
+		computeInstanceID = 2
+	)
+
+	ci := &mocknvml.ComputeInstance{
+		GetInfoFunc: func() (nvml.ComputeInstanceInfo, nvml.Return) {
+			return nvml.ComputeInstanceInfo{
+				ProfileId: nvml.COMPUTE_INSTANCE_PROFILE_1_SLICE,
+			}, nvml.SUCCESS
+		},
+	}
+	gi := &mocknvml.GpuInstance{
+		GetInfoFunc: func() (nvml.GpuInstanceInfo, nvml.Return) {
+			return nvml.GpuInstanceInfo{
+				ProfileId: nvml.GPU_INSTANCE_PROFILE_1_SLICE,
+			}, nvml.SUCCESS
+		},
+		GetComputeInstanceByIdFunc: func(id int) (nvml.ComputeInstance, nvml.Return) {
+			if id == computeInstanceID {
+				return ci, nvml.SUCCESS
+			}
+			return nil, nvml.ERROR_INVALID_ARGUMENT
+		},
+		GetComputeInstanceProfileInfoFunc: func(profile int, engineProfile int) (nvml.ComputeInstanceProfileInfo, nvml.Return) {
+			if profile == 0 && engineProfile == 0 {
+				return nvml.ComputeInstanceProfileInfo{
+					Id: nvml.COMPUTE_INSTANCE_PROFILE_1_SLICE,
+				}, nvml.SUCCESS
+			}
+			return nvml.ComputeInstanceProfileInfo{}, nvml.ERROR_NOT_SUPPORTED
+		},
+	}
+
+	var parent *mockserver.Device
+	mig := &mocknvml.Device{
+		IsMigDeviceHandleFunc: func() (bool, nvml.Return) {
+			return true, nvml.SUCCESS
+		},
+		GetDeviceHandleFromMigDeviceHandleFunc: func() (nvml.Device, nvml.Return) {
+			return parent, nvml.SUCCESS
+		},
+		GetIndexFunc: func() (int, nvml.Return) {
+			return 0, nvml.SUCCESS
+		},
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return "MIG-foo", nvml.SUCCESS
+		},
+		GetMemoryInfoFunc: func() (nvml.Memory, nvml.Return) {
+			return nvml.Memory{Total: 4864 * 1024 * 1024}, nvml.SUCCESS
+		},
+		GetAttributesFunc: func() (nvml.DeviceAttributes, nvml.Return) {
+			return nvml.DeviceAttributes{MemorySizeMB: 5120}, nvml.SUCCESS
+		},
+		GetGpuInstanceIdFunc: func() (int, nvml.Return) {
+			return gpuInstanceID, nvml.SUCCESS
+		},
+		GetComputeInstanceIdFunc: func() (int, nvml.Return) {
+			return computeInstanceID, nvml.SUCCESS
+		},
+	}
+
+	l, mockNvml := newTestNVMLLib(t, mig)
+	l.featureFlags = map[FeatureFlag]bool{
+		FeatureEnableDeviceInfoAnnotations: true,
+	}
+	parent = mockNvml.Devices[2].(*mockserver.Device)
+	parent.GetNumaNodeIdFunc = func() (int, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+	parent.GetGpuInstanceByIdFunc = func(id int) (nvml.GpuInstance, nvml.Return) {
+		if id == gpuInstanceID {
+			return gi, nvml.SUCCESS
+		}
+		return nil, nvml.ERROR_INVALID_ARGUMENT
+	}
+	parent.GetGpuInstanceProfileInfoFunc = func(profile int) (nvml.GpuInstanceProfileInfo, nvml.Return) {
+		if profile == 0 {
+			return nvml.GpuInstanceProfileInfo{
+				Id:           nvml.GPU_INSTANCE_PROFILE_1_SLICE,
+				MemorySizeMB: 5120,
+			}, nvml.SUCCESS
+		}
+		return nvml.GpuInstanceProfileInfo{}, nvml.ERROR_NOT_SUPPORTED
+	}
+
+	generator, err := l.newMIGDeviceSpecGeneratorFromNVMLDevice("MIG-foo", mig)
+	require.NoError(t, err)
+
+	annotations, err := generator.(*migDeviceSpecGenerator).getDeviceAnnotations()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"gpu.nvidia.com/product":            "Mock NVIDIA A100-SXM4-40GB",
+		"gpu.nvidia.com/architecture":       "Ampere",
+		"gpu.nvidia.com/compute-capability": "8.0",
+		"gpu.nvidia.com/memory-mib":         "4864",
+		"gpu.nvidia.com/pci-bus-id":         "0000:17:00.0",
+		"gpu.nvidia.com/numa-node":          "0",
+		"gpu.nvidia.com/mig-profile":        "1g.5gb",
+		"gpu.nvidia.com/parent-uuid":        parent.UUID,
+		"gpu.nvidia.com/uuid":               "MIG-foo",
+	}, annotations)
+}
+
+func TestMIGDeviceFeatureFlags(t *testing.T) {
+	l, mockNvml := newTestNVMLLib(t)
+	l.featureFlags = map[FeatureFlag]bool{
+		FeatureEnableCoherentAnnotations:   true,
+		FeatureEnableDeviceInfoAnnotations: true,
+		FeatureEnableTopologyAnnotations:   true,
+	}
+	mig := &mocknvml.Device{
+		IsMigDeviceHandleFunc: func() (bool, nvml.Return) {
+			return true, nvml.SUCCESS
+		},
+		GetDeviceHandleFromMigDeviceHandleFunc: func() (nvml.Device, nvml.Return) {
+			return mockNvml.Devices[0], nvml.SUCCESS
+		},
+		GetIndexFunc: func() (int, nvml.Return) {
+			return 0, nvml.SUCCESS
+		},
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return "MIG-foo", nvml.SUCCESS
+		},
+	}
+
+	generator, err := l.newMIGDeviceSpecGeneratorFromNVMLDevice("MIG-foo", mig)
+	require.NoError(t, err)
+
+	migGenerator, ok := generator.(*migDeviceSpecGenerator)
+	require.True(t, ok)
+	require.Equal(t, map[FeatureFlag]bool{
+		FeatureEnableDeviceInfoAnnotations: true,
+	}, migGenerator.featureFlags)
+}
@@ -35,13 +35,17 @@ type nvcdilib struct {
 	deviceNamers DeviceNamers
 	// TODO: We should use the devRoot associated with the driver.
 	devRoot            string
+	hostRoot           string
+	kernelRelease      string
 	librarySearchPaths []string
 
 	csv csvOptions
+	mps mpsOptions
 
 	driver *root.Driver
 
 	featureFlags map[FeatureFlag]bool
+	deviceRules  *deviceRules
 
 	hookCreator  discover.HookCreator
 	editsFactory edits.Factory
@@ -51,6 +55,11 @@ type nvcdilib struct {
 func New(opts ...Option) (Interface, error) {
 	o := populateOptions(opts...)
 
+	deviceRules, err := o.newDeviceRules()
+	if err != nil {
+		return nil, err
+	}
+
 	l := &nvcdilib{
 		logger:       o.logger,
 		platformlibs: o.platformlibs,
@@ -58,19 +67,21 @@ func New(opts ...Option) (Interface, error) {
 			o.getDriverOptions()...,
 		),
 		devRoot:      o.devRoot,
+		hostRoot:     o.hostRoot,
 		deviceNamers: o.deviceNamers,
 
+		kernelRelease: o.kernelRelease,
+
 		librarySearchPaths: slices.Clone(o.librarySearchPaths),
 		featureFlags:       o.featureFlags,
+		deviceRules:        deviceRules,
 
 		csv: o.csv,
+		mps: o.mps,
 
-		hookCreator: discover.NewHookCreator(
-			discover.WithNVIDIACDIHookPath(o.nvidiaCDIHookPath),
-			discover.WithEnabledHooks(o.enabledHooks...),
-			discover.WithLdconfigPath(o.ldconfigPath),
-			discover.WithDisabledHooks(o.disabledHooks...),
-		),
+		// Hooks that are selected by device rules are added to the edits
+		// of the matching devices instead of the common edits.
+		hookCreator:  o.newHookCreator(deviceRules.hooks()),
 		editsFactory: o.editsFactory,
 	}
 
@@ -91,6 +102,8 @@ func New(opts ...Option) (Interface, error) {
 		}
 	case ModeImex:
 		factory = (*imexlib)(l)
+	case ModeMPS:
+		factory = (*mpslib)(l)
 	default:
 		return nil, fmt.Errorf("unknown mode %q", o.mode)
 	}
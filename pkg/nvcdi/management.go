@@ -94,8 +94,9 @@ type managementDiscoverer struct {
 // newManagementDeviceDiscoverer returns a discover.Discover that discovers device nodes for use in managementlib containers.
 // NVML is not used to query devices and all device nodes are returned.
 func (l *managementlib) newManagementDeviceDiscoverer() (discover.Discover, error) {
-	deviceNodes := discover.NewCharDeviceDiscoverer(
+	deviceNodes := discover.NewCharDeviceDiscovererWithDevicesLib(
 		l.logger,
+		l.deviceslib,
 		l.driver.DevRoot,
 		[]string{
 			"/dev/nvidia*",
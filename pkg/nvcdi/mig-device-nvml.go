@@ -24,6 +24,7 @@ import (
 	"tags.cncf.io/container-device-interface/pkg/cdi"
 	"tags.cncf.io/container-device-interface/specs-go"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/platform-support/dgpu"
 )
 
@@ -40,7 +41,13 @@ func (l *migDeviceSpecGenerator) GetUUID() (string, error) {
 }
 
 func (l *nvmllib) newMIGDeviceSpecGeneratorFromDevice(i int, d device.Device, j int, m device.MigDevice) (*migDeviceSpecGenerator, error) {
-	parent, err := l.newFullGPUDeviceSpecGeneratorFromDevice(i, d, make(map[FeatureFlag]bool))
+	// Of the configured feature flags, only the generation of device info
+	// annotations applies to MIG devices. Other flags such as the coherent
+	// annotations only apply to full GPUs.
+	featureFlags := map[FeatureFlag]bool{
+		FeatureEnableDeviceInfoAnnotations: l.featureFlags[FeatureEnableDeviceInfoAnnotations],
+	}
+	parent, err := l.newFullGPUDeviceSpecGeneratorFromDevice(i, d, featureFlags)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +94,10 @@ func (l *nvmllib) newMIGDeviceSpecGeneratorFromNVMLDevice(uuid string, nvmlMIGDe
 }
 
 func (l *migDeviceSpecGenerator) GetDeviceSpecs() ([]specs.Device, error) {
+	if err := l.applyDeviceRules(l); err != nil {
+		return nil, err
+	}
+
 	deviceEdits, err := l.getDeviceEdits()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get CDI device edits: %w", err)
@@ -97,16 +108,7 @@ func (l *migDeviceSpecGenerator) GetDeviceSpecs() ([]specs.Device, error) {
 		return nil, fmt.Errorf("failed to get device names: %w", err)
 	}
 
-	var deviceSpecs []specs.Device
-	for _, name := range names {
-		deviceSpec := specs.Device{
-			Name:           name,
-			ContainerEdits: *deviceEdits.ContainerEdits,
-		}
-		deviceSpecs = append(deviceSpecs, deviceSpec)
-	}
-
-	return deviceSpecs, nil
+	return l.newDeviceSpecs(names, deviceEdits, l.getDeviceAnnotations), nil
 }
 
 func (l *migDeviceSpecGenerator) migDevice() (device.MigDevice, error) {
@@ -125,15 +127,22 @@ func (l *migDeviceSpecGenerator) getDeviceEdits() (*cdi.ContainerEdits, error) {
 	}
 	deviceNodes, err := dgpu.NewForMigDevice(device, migDevice,
 		dgpu.WithDriver(l.driver),
+		dgpu.WithHostRoot(l.hostRoot),
 		dgpu.WithLogger(l.logger),
 		dgpu.WithHookCreator(l.hookCreator),
 		dgpu.WithNvsandboxuitilsLib(l.nvsandboxutilslib),
+		dgpu.WithDevicesLib(l.deviceslib),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create device discoverer: %v", err)
 	}
 
-	editsForDevice, err := l.editsFactory.FromDiscoverer(deviceNodes)
+	deviceRuleHooks, err := l.newDeviceRuleHookDiscoverer()
+	if err != nil {
+		return nil, err
+	}
+
+	editsForDevice, err := l.editsFactory.FromDiscoverer(discover.Merge(deviceNodes, deviceRuleHooks))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container edits for Compute Instance: %v", err)
 	}
@@ -141,6 +150,41 @@ func (l *migDeviceSpecGenerator) getDeviceEdits() (*cdi.ContainerEdits, error) {
 	return editsForDevice, nil
 }
 
+// getDeviceProperties returns the properties of the MIG device that can be
+// used in template-based device names. The PCI bus ID, product name, and NUMA
+// node are those of the parent GPU.
+func (l *migDeviceSpecGenerator) getDeviceProperties() (*DeviceProperties, error) {
+	properties, err := l.fullGPUDeviceSpecGenerator.getDeviceProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	migDevice, err := l.migDevice()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := migDevice.GetProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MIG profile: %w", err)
+	}
+	giID, ret := migDevice.GetGpuInstanceId()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get GPU instance ID: %v", ret)
+	}
+	ciID, ret := migDevice.GetComputeInstanceId()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get compute instance ID: %v", ret)
+	}
+
+	properties.ParentUUID = properties.UUID
+	properties.UUID = l.migUUID
+	properties.MIGIndex = l.migIndex
+	properties.MIGProfile = profile.String()
+	properties.GIID = giID
+	properties.CIID = ciID
+	return properties, nil
+}
+
 func (l *migDeviceSpecGenerator) getNames() ([]string, error) {
 	return l.deviceNamers.GetMigDeviceNames(l.index, l.fullGPUDeviceSpecGenerator, l.migIndex, l)
 }
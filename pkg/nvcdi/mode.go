@@ -44,6 +44,9 @@ const (
 	ModeCSV = Mode("csv")
 	// ModeImex configures the CDI spec generator to generate a spec for the available IMEX channels.
 	ModeImex = Mode("imex")
+	// ModeMPS configures the CDI spec generator to generate a spec for the
+	// configured MPS control daemons.
+	ModeMPS = Mode("mps")
 	// ModeNvswitch configures the CDI spec generator to generate a spec for the available nvswitch devices.
 	ModeNvswitch = Mode("nvswitch")
 )
@@ -70,6 +73,7 @@ func getModes() modes {
 			ModeImex,
 			ModeManagement,
 			ModeMofed,
+			ModeMPS,
 			ModeNvml,
 			ModeNvswitch,
 			ModeWsl,
@@ -103,6 +107,19 @@ func IsValidMode[T modeConstraint](mode T) bool {
 	return getModes().lookup[Mode(mode)]
 }
 
+// DefaultClass returns the CDI class used for the specs generated in the
+// specified mode if no class is explicitly specified.
+func DefaultClass[T modeConstraint](mode T) string {
+	switch Mode(mode) {
+	case ModeImex:
+		return classImexChannel
+	case ModeGdrcopy, ModeGds, ModeMofed, ModeMPS, ModeNvswitch:
+		return string(mode)
+	default:
+		return "gpu"
+	}
+}
+
 // resolveMode resolves the mode for CDI spec generation based on the current system.
 func (o *options) resolveMode() (rmode Mode) {
 	if o.mode != ModeAuto {
@@ -17,10 +17,15 @@
 package nvcdi
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
 
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"tags.cncf.io/container-device-interface/pkg/parser"
 )
 
 // UUIDer is an interface for getting UUIDs.
@@ -47,14 +52,47 @@ const (
 	DeviceNameStrategyUUID = "uuid"
 )
 
+// DeviceProperties defines the properties of a device that can be referenced
+// in a template-based device naming strategy such as {{.Product}}-{{.Index}}.
+type DeviceProperties struct {
+	// Index is the index of the GPU or the parent GPU of a MIG device.
+	Index int
+	// UUID is the UUID of the GPU or MIG device.
+	UUID string
+	// PCIBusID is the PCI bus ID of the GPU or the parent GPU of a MIG device.
+	PCIBusID string
+	// Product is the product name of the GPU or the parent GPU of a MIG device.
+	Product string
+	// NUMANode is the NUMA node of the GPU or -1 if this is not known.
+	NUMANode int
+	// The following properties are only set for MIG devices.
+	MIGIndex   int
+	ParentUUID string
+	MIGProfile string
+	GIID       int
+	CIID       int
+}
+
+// devicePropertiesGetter is implemented by devices that can provide the
+// properties used for template-based device names.
+type devicePropertiesGetter interface {
+	getDeviceProperties() (*DeviceProperties, error)
+}
+
 type deviceNameIndex struct {
 	gpuPrefix string
 	migPrefix string
 }
 type deviceNameUUID struct{}
+type deviceNameTemplate struct {
+	strategy string
+	template *template.Template
+}
 
 // NewDeviceNamer creates a Device Namer based on the supplied strategy.
 // This namer can be used to construct the names for MIG and GPU devices when generating the CDI spec.
+// If the strategy contains a Go template action (e.g. {{.Index}}) the strategy
+// is treated as a template that is executed against the DeviceProperties.
 func NewDeviceNamer(strategy string) (DeviceNamer, error) {
 	switch strategy {
 	case DeviceNameStrategyIndex:
@@ -65,9 +103,45 @@ func NewDeviceNamer(strategy string) (DeviceNamer, error) {
 		return deviceNameUUID{}, nil
 	}
 
+	if strings.Contains(strategy, "{{") {
+		return newDeviceNameTemplate(strategy)
+	}
+
 	return nil, fmt.Errorf("invalid device name strategy: %v", strategy)
 }
 
+// JoinDeviceNameStrategies joins device name strategies that were split at a
+// comma in a template action when a comma-separated list of strategies was
+// parsed. For example, the values `{{printf "%s` and `%d" .Product .Index}}`
+// are joined to `{{printf "%s,%d" .Product .Index}}`. This allows templates
+// containing commas to be specified using the same flags and environment
+// variables as a list of strategies.
+func JoinDeviceNameStrategies(values []string) []string {
+	var strategies []string
+	for i := 0; i < len(values); i++ {
+		strategy := values[i]
+		for strings.Count(strategy, "{{") > strings.Count(strategy, "}}") && i+1 < len(values) {
+			i++
+			strategy += "," + values[i]
+		}
+		strategies = append(strategies, strategy)
+	}
+	return strategies
+}
+
+func newDeviceNameTemplate(strategy string) (DeviceNamer, error) {
+	t, err := template.New("device-name").Parse(strategy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device name template %q: %w", strategy, err)
+	}
+	// We execute the template once to detect references to properties that
+	// do not exist.
+	if err := t.Execute(&bytes.Buffer{}, DeviceProperties{}); err != nil {
+		return nil, fmt.Errorf("invalid device name template %q: %w", strategy, err)
+	}
+	return deviceNameTemplate{strategy: strategy, template: t}, nil
+}
+
 // GetDeviceName returns the name for the specified device based on the naming strategy
 func (s deviceNameIndex) GetDeviceName(i int, _ UUIDer) (string, error) {
 	return fmt.Sprintf("%s%d", s.gpuPrefix, i), nil
@@ -96,6 +170,66 @@ func (s deviceNameUUID) GetMigDeviceName(i int, _ UUIDer, j int, mig UUIDer) (st
 	return uuid, nil
 }
 
+// GetDeviceName returns the name for the specified device based on the naming strategy
+func (s deviceNameTemplate) GetDeviceName(i int, d UUIDer) (string, error) {
+	properties, err := getDeviceProperties(d)
+	if err != nil {
+		return "", err
+	}
+	properties.Index = i
+	return s.execute(properties)
+}
+
+// GetMigDeviceName returns the name for the specified device based on the naming strategy
+func (s deviceNameTemplate) GetMigDeviceName(i int, d UUIDer, j int, mig UUIDer) (string, error) {
+	properties, err := getDeviceProperties(mig)
+	if err != nil {
+		return "", err
+	}
+	properties.Index = i
+	properties.MIGIndex = j
+	if properties.ParentUUID == "" {
+		parentUUID, err := d.GetUUID()
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent device UUID: %v", err)
+		}
+		properties.ParentUUID = parentUUID
+	}
+	return s.execute(properties)
+}
+
+// invalidDeviceNameCharacters matches characters that are not allowed in CDI
+// device names.
+var invalidDeviceNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.:-]+`)
+
+// execute generates a device name from the template. Since properties such as
+// the product name may contain characters that are not allowed in CDI device
+// names, sequences of invalid characters are replaced by a single '-'.
+func (s deviceNameTemplate) execute(properties *DeviceProperties) (string, error) {
+	var name bytes.Buffer
+	if err := s.template.Execute(&name, properties); err != nil {
+		return "", fmt.Errorf("failed to generate device name: %w", err)
+	}
+	deviceName := invalidDeviceNameCharacters.ReplaceAllString(name.String(), "-")
+	if err := parser.ValidateDeviceName(deviceName); err != nil {
+		return "", fmt.Errorf("invalid device name %q generated from template %q: %w", deviceName, s.strategy, err)
+	}
+	return deviceName, nil
+}
+
+// getDeviceProperties returns the properties for the specified device. If the
+// device does not provide additional properties, only the UUID is set.
+func getDeviceProperties(d UUIDer) (*DeviceProperties, error) {
+	if getter, ok := d.(devicePropertiesGetter); ok {
+		return getter.getDeviceProperties()
+	}
+	uuid, err := d.GetUUID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device UUID: %v", err)
+	}
+	return &DeviceProperties{UUID: uuid, NUMANode: -1}, nil
+}
+
 //go:generate moq -rm -fmt=goimports -stub -out namer_nvml_mock.go . nvmlUUIDer
 type nvmlUUIDer interface {
 	GetUUID() (string, nvml.Return)
@@ -65,3 +65,130 @@ func TestConvert(t *testing.T) {
 		})
 	}
 }
+
+type testDevice DeviceProperties
+
+func (d testDevice) GetUUID() (string, error) {
+	return d.UUID, nil
+}
+
+func (d testDevice) getDeviceProperties() (*DeviceProperties, error) {
+	p := DeviceProperties(d)
+	return &p, nil
+}
+
+func TestDeviceNameTemplate(t *testing.T) {
+	gpu := testDevice{
+		UUID:     "GPU-1",
+		PCIBusID: "0000:07:00.0",
+		Product:  "NVIDIA A100-SXM4-40GB",
+		NUMANode: 1,
+	}
+	mig := testDevice{
+		UUID:       "MIG-1",
+		PCIBusID:   "0000:07:00.0",
+		Product:    "NVIDIA A100-SXM4-40GB",
+		NUMANode:   1,
+		ParentUUID: "GPU-1",
+		MIGProfile: "1g.5gb",
+		GIID:       7,
+		CIID:       0,
+	}
+
+	testCases := []struct {
+		description     string
+		strategy        string
+		expectedError   string
+		expectedName    string
+		expectedMigName string
+	}{
+		{
+			description:     "product and index",
+			strategy:        "{{.Product}}-{{.Index}}",
+			expectedName:    "NVIDIA-A100-SXM4-40GB-3",
+			expectedMigName: "NVIDIA-A100-SXM4-40GB-3",
+		},
+		{
+			description:     "numa node and index",
+			strategy:        "numa{{.NUMANode}}-gpu{{.Index}}",
+			expectedName:    "numa1-gpu3",
+			expectedMigName: "numa1-gpu3",
+		},
+		{
+			description:     "PCI bus ID and MIG properties",
+			strategy:        "{{.PCIBusID}}{{if .MIGProfile}}-{{.MIGProfile}}-gi{{.GIID}}-ci{{.CIID}}{{end}}",
+			expectedName:    "0000:07:00.0",
+			expectedMigName: "0000:07:00.0-1g.5gb-gi7-ci0",
+		},
+		{
+			description:     "UUIDs",
+			strategy:        "{{.UUID}}{{with .ParentUUID}}-{{.}}{{end}}",
+			expectedName:    "GPU-1",
+			expectedMigName: "MIG-1-GPU-1",
+		},
+		{
+			description:   "unknown property",
+			strategy:      "{{.Unknown}}",
+			expectedError: `invalid device name template "{{.Unknown}}": template: device-name:1:2: executing "device-name" at <.Unknown>: can't evaluate field Unknown in type nvcdi.DeviceProperties`,
+		},
+		{
+			description:   "invalid template",
+			strategy:      "{{.Index",
+			expectedError: `invalid device name template "{{.Index": template: device-name:1: unclosed action`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			namer, err := NewDeviceNamer(tc.strategy)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			name, err := namer.GetDeviceName(3, gpu)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedName, name)
+
+			migName, err := namer.GetMigDeviceName(3, gpu, 1, mig)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedMigName, migName)
+		})
+	}
+}
+
+func TestJoinDeviceNameStrategies(t *testing.T) {
+	testCases := []struct {
+		description        string
+		values             []string
+		expectedStrategies []string
+	}{
+		{
+			description:        "strategies are not joined",
+			values:             []string{"index", "uuid", "{{.PCIBusID}}"},
+			expectedStrategies: []string{"index", "uuid", "{{.PCIBusID}}"},
+		},
+		{
+			description:        "template split at a comma is joined",
+			values:             []string{"index", `{{printf "%s`, `%d" .Product .Index}}`, "uuid"},
+			expectedStrategies: []string{"index", `{{printf "%s,%d" .Product .Index}}`, "uuid"},
+		},
+		{
+			description:        "template split at multiple commas is joined",
+			values:             []string{`gpu{{printf "%d`, "", `%d" .NUMANode .Index}}`},
+			expectedStrategies: []string{`gpu{{printf "%d,,%d" .NUMANode .Index}}`},
+		},
+		{
+			description:        "unclosed template is returned as is",
+			values:             []string{"uuid", "{{.Index"},
+			expectedStrategies: []string{"uuid", "{{.Index"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expectedStrategies, JoinDeviceNameStrategies(tc.values))
+		})
+	}
+}
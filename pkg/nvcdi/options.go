@@ -21,6 +21,7 @@ import (
 	"github.com/NVIDIA/go-nvlib/pkg/nvlib/info"
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/devices"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/edits"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/logger"
@@ -37,12 +38,15 @@ type options struct {
 	deviceNamers       DeviceNamers
 	driverRoot         string
 	devRoot            string
+	hostRoot           string
+	kernelRelease      string
 	nvidiaCDIHookPath  string
 	ldconfigPath       string
 	configSearchPaths  []string
 	librarySearchPaths []string
 
 	csv csvOptions
+	mps mpsOptions
 
 	vendor string
 	class  string
@@ -53,6 +57,9 @@ type options struct {
 
 	disabledHooks []discover.HookName
 	enabledHooks  []discover.HookName
+	hookPolicies  map[HookName]HookPolicy
+
+	deviceRules []DeviceRule
 
 	editsFactory edits.Factory
 }
@@ -63,6 +70,7 @@ type platformlibs struct {
 	infolib   info.Interface
 
 	nvsandboxutilslib nvsandboxutils.Interface
+	deviceslib        devices.Interface
 }
 
 // populateOptions applies the functional options and resolves the required
@@ -71,6 +79,7 @@ func populateOptions(opts ...Option) *options {
 	o := &options{
 		mode:              ModeAuto,
 		driverRoot:        "/",
+		hostRoot:          "/",
 		nvidiaCDIHookPath: "/usr/bin/nvidia-cdi-hook",
 	}
 	for _, opt := range opts {
@@ -95,6 +104,9 @@ func populateOptions(opts ...Option) *options {
 	if o.devicelib == nil {
 		o.devicelib = device.New(o.nvmllib)
 	}
+	if o.deviceslib == nil {
+		o.deviceslib = devices.New()
+	}
 	if o.infolib == nil {
 		o.infolib = info.New(
 			info.WithRoot(o.driverRoot),
@@ -121,6 +133,7 @@ func populateOptions(opts ...Option) *options {
 	if o.editsFactory == nil {
 		o.editsFactory = edits.NewFactory(
 			edits.WithLogger(o.logger),
+			edits.WithDevicesLib(o.deviceslib),
 			edits.WithNoAdditionalGIDsForDeviceNodes(o.featureFlags[FeatureNoAdditionalGIDsForDeviceNodes]),
 		)
 	}
@@ -152,14 +165,7 @@ func (o *options) getClassOrDefault() string {
 	if o.class != "" {
 		return o.class
 	}
-	switch o.mode {
-	case ModeImex:
-		return classImexChannel
-	case ModeGdrcopy, ModeGds, ModeMofed, ModeNvswitch:
-		return string(o.mode)
-	default:
-		return "gpu"
-	}
+	return DefaultClass(o.mode)
 }
 
 // Option is a function that configures the nvcdi library options.
@@ -200,6 +206,14 @@ func WithDevRoot(root string) Option {
 	}
 }
 
+// WithHostRoot sets the root under which the /proc and /sys filesystems of
+// the host are queried.
+func WithHostRoot(root string) Option {
+	return func(l *options) {
+		l.hostRoot = root
+	}
+}
+
 func WithEditsFactory(editsFactory edits.Factory) Option {
 	return func(l *options) {
 		l.editsFactory = editsFactory
@@ -241,6 +255,23 @@ func WithNvmlLib(nvmllib nvml.Interface) Option {
 	}
 }
 
+// WithKernelRelease sets the kernel release that is used to locate the GSP
+// firmware files of the driver. If this is not specified, the release of the
+// running kernel is used.
+func WithKernelRelease(kernelRelease string) Option {
+	return func(l *options) {
+		l.kernelRelease = kernelRelease
+	}
+}
+
+// WithDevicesLib sets the library used to query the device nodes that are
+// included in the generated CDI specifications.
+func WithDevicesLib(deviceslib devices.Interface) Option {
+	return func(l *options) {
+		l.deviceslib = deviceslib
+	}
+}
+
 // WithMode sets the discovery mode for the library
 func WithMode[m modeConstraint](mode m) Option {
 	return func(l *options) {
@@ -292,6 +323,14 @@ func WithCSVCompatContainerRoot(csvCompatContainerRoot string) Option {
 	}
 }
 
+// WithMPSDaemons sets the MPS control daemons for which CDI devices are
+// generated in MPS mode.
+func WithMPSDaemons(daemons ...MPSDaemon) Option {
+	return func(o *options) {
+		o.mps.Daemons = daemons
+	}
+}
+
 // WithConfigSearchPaths sets the search paths for config files.
 func WithConfigSearchPaths(paths []string) Option {
 	return func(o *options) {
@@ -326,6 +365,24 @@ func WithEnabledHooks[T string | HookName](hooks ...T) Option {
 	}
 }
 
+// WithHookPolicies sets the timeout and failure policy of the specified hooks.
+// The policies are passed to the nvidia-cdi-hook through the environment of
+// the generated hooks. The policy for AllHooks applies to hooks without a
+// policy of their own.
+func WithHookPolicies(policies map[HookName]HookPolicy) Option {
+	return func(o *options) {
+		o.hookPolicies = policies
+	}
+}
+
+// WithDeviceRules sets the rules that enable or disable hooks and feature
+// flags for specific devices. This option can be specified multiple times.
+func WithDeviceRules(rules ...DeviceRule) Option {
+	return func(o *options) {
+		o.deviceRules = append(o.deviceRules, rules...)
+	}
+}
+
 // WithFeatureFlags allows the specified set of features to be toggled on.
 func WithFeatureFlags[T string | FeatureFlag](featureFlags ...T) Option {
 	return func(o *options) {
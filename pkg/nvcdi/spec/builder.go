@@ -36,6 +36,7 @@ type builder struct {
 	format      string
 
 	mergedDeviceOptions []transform.MergedDeviceOption
+	additionalMerged    [][]transform.MergedDeviceOption
 	noSimplify          bool
 	permissions         os.FileMode
 
@@ -113,6 +114,16 @@ func (o *builder) Build() (*spec, error) {
 		}
 	}
 
+	for _, opts := range o.additionalMerged {
+		merge, err := transform.NewMergedDevice(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create merged device transformer: %v", err)
+		}
+		if err := merge.Transform(raw); err != nil {
+			return nil, fmt.Errorf("failed to merge devices: %v", err)
+		}
+	}
+
 	s := spec{
 		Spec:            raw,
 		format:          o.format,
@@ -194,3 +205,12 @@ func WithMergedDeviceOptions(opts ...transform.MergedDeviceOption) Option {
 		o.mergedDeviceOptions = opts
 	}
 }
+
+// WithAdditionalMergedDevice adds a merged device with the specified options.
+// This can be specified multiple times and the additional merged devices are
+// generated after the merged device configured by WithMergedDeviceOptions.
+func WithAdditionalMergedDevice(opts ...transform.MergedDeviceOption) Option {
+	return func(o *builder) {
+		o.additionalMerged = append(o.additionalMerged, opts)
+	}
+}
@@ -0,0 +1,200 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package nvcdi
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/google/uuid"
+)
+
+// The following annotations are added to full GPU devices if the
+// FeatureEnableTopologyAnnotations feature flag is set. Devices with the same
+// value for an annotation belong to the same topology group.
+const (
+	// AnnotationUUID is the UUID of the GPU. This identifies the devices that
+	// refer to the same GPU if multiple device naming strategies are used.
+	// This is also added to full GPU and MIG devices if device info
+	// annotations are enabled.
+	AnnotationUUID = "gpu.nvidia.com/uuid"
+	// AnnotationNUMANode is the NUMA node of the GPU.
+	AnnotationNUMANode = "gpu.nvidia.com/numa-node"
+	// AnnotationNVLinkClique is the NVLink clique of the GPU and is formatted
+	// as {CLUSTER_UUID}.{CLIQUE_ID}. This is only set for GPUs that are
+	// attached to an NVLink fabric.
+	AnnotationNVLinkClique = "gpu.nvidia.com/nvlink-clique"
+	// AnnotationPCIeSwitch identifies the PCIe switch that the GPU is
+	// connected to by the lowest PCI bus ID of the GPUs connected to it. This
+	// is only set if other GPUs are connected to the same PCIe switch.
+	AnnotationPCIeSwitch = "gpu.nvidia.com/pcie-switch"
+)
+
+// getTopologyAnnotations returns the annotations describing the topology
+// groups that the GPU belongs to.
+func (l *fullGPUDeviceSpecGenerator) getTopologyAnnotations() (map[string]string, error) {
+	d, err := l.device()
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{
+		AnnotationUUID: l.uuid,
+	}
+
+	numaNode, ret := d.GetNumaNodeId()
+	switch {
+	case ret == nvml.SUCCESS && numaNode >= 0:
+		annotations[AnnotationNUMANode] = fmt.Sprintf("%d", numaNode)
+	case ret != nvml.SUCCESS && ret != nvml.ERROR_NOT_SUPPORTED:
+		return nil, fmt.Errorf("failed to get NUMA node: %v", ret)
+	}
+
+	clique, err := l.getNVLinkClique(d)
+	if err != nil {
+		return nil, err
+	}
+	if clique != "" {
+		annotations[AnnotationNVLinkClique] = clique
+	}
+
+	pcieSwitch, err := l.getPCIeSwitch()
+	if err != nil {
+		return nil, err
+	}
+	if pcieSwitch != "" {
+		annotations[AnnotationPCIeSwitch] = pcieSwitch
+	}
+
+	return annotations, nil
+}
+
+// getNVLinkClique returns the NVLink clique of the specified device. If the
+// device is not attached to an NVLink fabric, an empty string is returned.
+func (l *fullGPUDeviceSpecGenerator) getNVLinkClique(d device.Device) (string, error) {
+	isFabricAttached, err := d.IsFabricAttached()
+	if err != nil {
+		return "", fmt.Errorf("failed to check fabric attachment: %w", err)
+	}
+	if !isFabricAttached {
+		return "", nil
+	}
+
+	var clusterUUID [16]uint8
+	var cliqueID uint32
+	if l.platformlibs.nvmllib.Extensions().LookupSymbol("nvmlDeviceGetGpuFabricInfo") == nil {
+		info, ret := d.GetGpuFabricInfo()
+		if ret != nvml.SUCCESS {
+			return "", fmt.Errorf("failed to get GPU fabric info: %v", ret)
+		}
+		clusterUUID, cliqueID = info.ClusterUuid, info.CliqueId
+	} else {
+		info, ret := d.GetGpuFabricInfoV().V2()
+		if ret != nvml.SUCCESS {
+			return "", fmt.Errorf("failed to get GPU fabric info: %v", ret)
+		}
+		clusterUUID, cliqueID = info.ClusterUuid, info.CliqueId
+	}
+
+	return fmt.Sprintf("%s.%d", uuid.UUID(clusterUUID), cliqueID), nil
+}
+
+// getPCIeSwitch returns the identifier of the PCIe switch that the GPU shares
+// with other GPUs. GPUs share a PCIe switch if the path between them does not
+// traverse a PCIe host bridge. Since the common ancestors reported for pairs
+// of GPUs are not necessarily consistent, the GPUs are grouped transitively
+// and the lowest PCI bus ID in the group is used as the identifier. This
+// ensures that the identifier does not depend on the order of the GPUs. If
+// the GPU does not share a PCIe switch with any other GPU, an empty string is
+// returned.
+func (l *fullGPUDeviceSpecGenerator) getPCIeSwitch() (string, error) {
+	type gpu struct {
+		device   device.Device
+		uuid     string
+		pciBusID string
+	}
+	var gpus []gpu
+	err := l.devicelib.VisitDevices(func(i int, d device.Device) error {
+		uuid, ret := d.GetUUID()
+		if ret != nvml.SUCCESS {
+			return fmt.Errorf("failed to get device UUID: %v", ret)
+		}
+		pciBusID, err := d.GetPCIBusID()
+		if err != nil {
+			return fmt.Errorf("failed to get PCI bus ID of device %v: %w", uuid, err)
+		}
+		gpus = append(gpus, gpu{device: d, uuid: uuid, pciBusID: pciBusID})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// We use a union-find over the GPUs to determine the groups of GPUs that
+	// are connected through PCIe switches.
+	parents := make([]int, len(gpus))
+	for i := range parents {
+		parents[i] = i
+	}
+	find := func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+	for i := range gpus {
+		for j := i + 1; j < len(gpus); j++ {
+			level, ret := gpus[i].device.GetTopologyCommonAncestor(gpus[j].device)
+			if ret == nvml.ERROR_NOT_SUPPORTED {
+				continue
+			}
+			if ret != nvml.SUCCESS {
+				return "", fmt.Errorf("failed to get common ancestor of devices %v and %v: %v", gpus[i].uuid, gpus[j].uuid, ret)
+			}
+			if level > nvml.TOPOLOGY_MULTIPLE {
+				continue
+			}
+			parents[find(i)] = find(j)
+		}
+	}
+
+	self := slices.IndexFunc(gpus, func(g gpu) bool {
+		return g.uuid == l.uuid
+	})
+	if self < 0 {
+		return "", fmt.Errorf("device %v not found", l.uuid)
+	}
+
+	var pcieSwitch string
+	var members int
+	for i, g := range gpus {
+		if find(i) != find(self) {
+			continue
+		}
+		members++
+		if pcieSwitch == "" || g.pciBusID < pcieSwitch {
+			pcieSwitch = g.pciBusID
+		}
+	}
+	if members < 2 {
+		return "", nil
+	}
+	return pcieSwitch, nil
+}
@@ -0,0 +1,152 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"fmt"
+	"slices"
+
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/edits"
+)
+
+// CombineSpecs combines the specified specs of the same kind into a single
+// spec. The common edits of the combined spec are the edits that are common
+// to all specs. The remaining common edits of each spec are added to the
+// edits of the devices in that spec so that the edits applied for each device
+// are unchanged. The version of the combined spec is not set.
+func CombineSpecs(specsToCombine ...*specs.Spec) (*specs.Spec, error) {
+	if len(specsToCombine) == 0 {
+		return nil, fmt.Errorf("no specs specified")
+	}
+
+	kind := specsToCombine[0].Kind
+	for _, s := range specsToCombine[1:] {
+		if s.Kind != kind {
+			return nil, fmt.Errorf("cannot combine specs with different kinds: %q and %q", kind, s.Kind)
+		}
+	}
+
+	common, err := commonEditsIDs(specsToCombine)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := &specs.Spec{
+		Kind: kind,
+	}
+	combined.ContainerEdits, err = retainEdits(specsToCombine[0].ContainerEdits, common)
+	if err != nil {
+		return nil, err
+	}
+	combined.ContainerEdits.AdditionalGIDs = commonAdditionalGIDs(specsToCombine)
+
+	deviceNames := make(map[string]bool)
+	for _, s := range specsToCombine {
+		remaining, err := removeEdits(s.ContainerEdits, remove(common))
+		if err != nil {
+			return nil, err
+		}
+		if combined.ContainerEdits.AdditionalGIDs != nil {
+			remaining.AdditionalGIDs = nil
+		}
+		for _, device := range s.Devices {
+			if deviceNames[device.Name] {
+				return nil, fmt.Errorf("device %q is defined more than once", device.Name)
+			}
+			deviceNames[device.Name] = true
+
+			mergedEdits := edits.EmptyFactory.New()
+			mergedEdits.Append(&cdi.ContainerEdits{ContainerEdits: &remaining})
+			mergedEdits.Append(&cdi.ContainerEdits{ContainerEdits: &device.ContainerEdits})
+			device.ContainerEdits = *mergedEdits.ContainerEdits
+			combined.Devices = append(combined.Devices, device)
+		}
+	}
+
+	if err := NewSimplifier().Transform(combined); err != nil {
+		return nil, fmt.Errorf("failed to simplify combined spec: %w", err)
+	}
+	return combined, nil
+}
+
+// commonEditsIDs returns the IDs of the entities that are included in the
+// common edits of all the specified specs.
+func commonEditsIDs(specsToCombine []*specs.Spec) (map[string]bool, error) {
+	var common map[string]bool
+	for _, s := range specsToCombine {
+		ids, err := (*containerEdits)(&s.ContainerEdits).getEntityIds()
+		if err != nil {
+			return nil, err
+		}
+		if common == nil {
+			common = make(map[string]bool)
+			for _, id := range ids {
+				common[id] = true
+			}
+			continue
+		}
+		for id := range common {
+			if !slices.Contains(ids, id) {
+				delete(common, id)
+			}
+		}
+	}
+	return common, nil
+}
+
+// retainEdits returns a copy of the edits that only includes the entities
+// with the specified IDs.
+func retainEdits(e specs.ContainerEdits, retain map[string]bool) (specs.ContainerEdits, error) {
+	ids, err := (*containerEdits)(&e).getEntityIds()
+	if err != nil {
+		return specs.ContainerEdits{}, err
+	}
+	var toRemove []string
+	for _, id := range ids {
+		if !retain[id] {
+			toRemove = append(toRemove, id)
+		}
+	}
+	return removeEdits(e, newRemover(toRemove...))
+}
+
+// removeEdits returns a copy of the edits without the entities removed by
+// the specified remover.
+func removeEdits(e specs.ContainerEdits, remover Transformer) (specs.ContainerEdits, error) {
+	s := specs.Spec{
+		ContainerEdits: e,
+	}
+	if err := remover.Transform(&s); err != nil {
+		return specs.ContainerEdits{}, err
+	}
+	return s.ContainerEdits, nil
+}
+
+// commonAdditionalGIDs returns the additional GIDs of the common edits if
+// these are the same for all specs.
+func commonAdditionalGIDs(specsToCombine []*specs.Spec) []uint32 {
+	gids := specsToCombine[0].ContainerEdits.AdditionalGIDs
+	for _, s := range specsToCombine[1:] {
+		if !slices.Equal(gids, s.ContainerEdits.AdditionalGIDs) {
+			return nil
+		}
+	}
+	return gids
+}
@@ -0,0 +1,123 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestCombineSpecs(t *testing.T) {
+	testCases := []struct {
+		description   string
+		specs         []*specs.Spec
+		expectedError string
+		expectedSpec  *specs.Spec
+	}{
+		{
+			description:   "no specs",
+			expectedError: "no specs specified",
+		},
+		{
+			description: "different kinds",
+			specs: []*specs.Spec{
+				{Kind: "nvidia.com/gpu"},
+				{Kind: "example.com/gpu"},
+			},
+			expectedError: `cannot combine specs with different kinds: "nvidia.com/gpu" and "example.com/gpu"`,
+		},
+		{
+			description: "duplicate device",
+			specs: []*specs.Spec{
+				{Kind: "nvidia.com/gpu", Devices: []specs.Device{{Name: "0"}}},
+				{Kind: "nvidia.com/gpu", Devices: []specs.Device{{Name: "0"}}},
+			},
+			expectedError: `device "0" is defined more than once`,
+		},
+		{
+			description: "shared common edits are retained",
+			specs: []*specs.Spec{
+				{
+					Kind: "nvidia.com/gpu",
+					ContainerEdits: specs.ContainerEdits{
+						Env:         []string{"NVIDIA_VISIBLE_DEVICES=void"},
+						DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidiactl"}},
+					},
+					Devices: []specs.Device{
+						{
+							Name: "0",
+							ContainerEdits: specs.ContainerEdits{
+								DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}},
+							},
+						},
+					},
+				},
+				{
+					Kind: "nvidia.com/gpu",
+					ContainerEdits: specs.ContainerEdits{
+						Env:         []string{"NVIDIA_VISIBLE_DEVICES=void", "ONLY_ONE=1"},
+						DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidiactl"}},
+					},
+					Devices: []specs.Device{
+						{
+							Name: "1",
+							ContainerEdits: specs.ContainerEdits{
+								DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia1"}},
+							},
+						},
+					},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				Kind: "nvidia.com/gpu",
+				ContainerEdits: specs.ContainerEdits{
+					Env:         []string{"NVIDIA_VISIBLE_DEVICES=void"},
+					DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidiactl"}},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia0"}},
+						},
+					},
+					{
+						Name: "1",
+						ContainerEdits: specs.ContainerEdits{
+							Env:         []string{"ONLY_ONE=1"},
+							DeviceNodes: []*specs.DeviceNode{{Path: "/dev/nvidia1"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			combined, err := CombineSpecs(tc.specs...)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedSpec, combined)
+		})
+	}
+}
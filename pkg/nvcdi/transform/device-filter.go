@@ -0,0 +1,101 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"fmt"
+	"path"
+
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+type deviceFilter struct {
+	include []string
+	exclude []string
+}
+
+var _ Transformer = (*deviceFilter)(nil)
+
+// DeviceFilterOption is a function that configures a device filter.
+type DeviceFilterOption func(*deviceFilter)
+
+// WithIncludedDevices sets the names of the devices to keep. Names may be
+// glob patterns such as gpu*. If no included devices are specified, all
+// devices that are not excluded are kept.
+func WithIncludedDevices(patterns ...string) DeviceFilterOption {
+	return func(f *deviceFilter) {
+		f.include = patterns
+	}
+}
+
+// WithExcludedDevices sets the names of the devices to remove. Names may be
+// glob patterns such as mig*.
+func WithExcludedDevices(patterns ...string) DeviceFilterOption {
+	return func(f *deviceFilter) {
+		f.exclude = patterns
+	}
+}
+
+// NewDeviceFilter creates a transformer that removes devices from a spec by
+// name. Note that the edits of removed devices are not removed from merged
+// devices such as the 'all' device.
+func NewDeviceFilter(opts ...DeviceFilterOption) (Transformer, error) {
+	f := &deviceFilter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	for _, pattern := range append(f.include, f.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid device name pattern %q: %w", pattern, err)
+		}
+	}
+	return f, nil
+}
+
+// Transform removes the devices that are not included or are excluded.
+func (f deviceFilter) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+
+	var devices []specs.Device
+	for _, device := range spec.Devices {
+		if len(f.include) > 0 && !matchesAny(f.include, device.Name) {
+			continue
+		}
+		if matchesAny(f.exclude, device.Name) {
+			continue
+		}
+		devices = append(devices, device)
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("no devices remain after filtering")
+	}
+	spec.Devices = devices
+	return nil
+}
+
+// matchesAny checks whether the name matches any of the specified patterns.
+// The patterns are assumed to be valid.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestDeviceFilter(t *testing.T) {
+	devices := func(names ...string) []specs.Device {
+		var devices []specs.Device
+		for _, name := range names {
+			devices = append(devices, specs.Device{Name: name})
+		}
+		return devices
+	}
+
+	testCases := []struct {
+		description     string
+		options         []DeviceFilterOption
+		expectedError   string
+		expectedDevices []specs.Device
+	}{
+		{
+			description:     "no filters keeps all devices",
+			expectedDevices: devices("0", "1", "mig0:0", "all"),
+		},
+		{
+			description:     "included devices are kept",
+			options:         []DeviceFilterOption{WithIncludedDevices("0", "mig*")},
+			expectedDevices: devices("0", "mig0:0"),
+		},
+		{
+			description:     "excluded devices are removed",
+			options:         []DeviceFilterOption{WithExcludedDevices("mig*", "all")},
+			expectedDevices: devices("0", "1"),
+		},
+		{
+			description:     "exclude takes precedence",
+			options:         []DeviceFilterOption{WithIncludedDevices("*"), WithExcludedDevices("1")},
+			expectedDevices: devices("0", "mig0:0", "all"),
+		},
+		{
+			description:   "no remaining devices is an error",
+			options:       []DeviceFilterOption{WithIncludedDevices("gpu*")},
+			expectedError: "no devices remain after filtering",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			spec := &specs.Spec{Devices: devices("0", "1", "mig0:0", "all")}
+
+			f, err := NewDeviceFilter(tc.options...)
+			require.NoError(t, err)
+
+			err = f.Transform(spec)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedDevices, spec.Devices)
+		})
+	}
+}
+
+func TestDeviceFilterInvalidPattern(t *testing.T) {
+	_, err := NewDeviceFilter(WithIncludedDevices("gpu["))
+	require.Error(t, err)
+}
@@ -0,0 +1,74 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+type envSetter struct {
+	envs []string
+}
+
+var _ Transformer = (*envSetter)(nil)
+
+// NewEnvSetter creates a transformer that adds or overrides environment
+// variables in a spec. The environment variables are specified as KEY=VALUE.
+func NewEnvSetter(envs ...string) (Transformer, error) {
+	for _, env := range envs {
+		if key, _, ok := strings.Cut(env, "="); !ok || key == "" {
+			return nil, fmt.Errorf("invalid environment variable %q: expected KEY=VALUE", env)
+		}
+	}
+	return &envSetter{envs: envs}, nil
+}
+
+// Transform removes existing definitions of the environment variables from
+// the devices and the common container edits and adds the new definitions to
+// the common container edits.
+func (s envSetter) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+
+	keys := make(map[string]bool)
+	for _, env := range s.envs {
+		key, _, _ := strings.Cut(env, "=")
+		keys[key] = true
+	}
+
+	for i := range spec.Devices {
+		spec.Devices[i].ContainerEdits.Env = s.removeKeys(keys, spec.Devices[i].ContainerEdits.Env)
+	}
+	spec.ContainerEdits.Env = append(s.removeKeys(keys, spec.ContainerEdits.Env), s.envs...)
+	return nil
+}
+
+func (s envSetter) removeKeys(keys map[string]bool, envs []string) []string {
+	var filtered []string
+	for _, env := range envs {
+		key, _, _ := strings.Cut(env, "=")
+		if keys[key] {
+			continue
+		}
+		filtered = append(filtered, env)
+	}
+	return filtered
+}
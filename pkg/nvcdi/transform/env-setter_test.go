@@ -0,0 +1,98 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestEnvSetter(t *testing.T) {
+	testCases := []struct {
+		description   string
+		envs          []string
+		spec          *specs.Spec
+		expectedError string
+		expectedSpec  *specs.Spec
+	}{
+		{
+			description:   "invalid env",
+			envs:          []string{"FOO"},
+			expectedError: `invalid environment variable "FOO": expected KEY=VALUE`,
+		},
+		{
+			description: "env is added",
+			envs:        []string{"FOO=bar"},
+			spec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{"NVIDIA_VISIBLE_DEVICES=void"},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{"NVIDIA_VISIBLE_DEVICES=void", "FOO=bar"},
+				},
+			},
+		},
+		{
+			description: "env is overridden",
+			envs:        []string{"FOO=bar", "EMPTY="},
+			spec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{"FOO=baz", "OTHER=1"},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							Env: []string{"FOO=0", "EMPTY=1", "DEVICE=0"},
+						},
+					},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Env: []string{"OTHER=1", "FOO=bar", "EMPTY="},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							Env: []string{"DEVICE=0"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			s, err := NewEnvSetter(tc.envs...)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, s.Transform(tc.spec))
+			require.EqualValues(t, tc.expectedSpec, tc.spec)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"path/filepath"
+
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+type hookRemover map[string]bool
+
+var _ Transformer = (hookRemover)(nil)
+
+// NewHookRemover creates a transformer that removes hooks from a spec by
+// name. For hooks that invoke the nvidia-cdi-hook (or nvidia-ctk hook)
+// command, the name is the name of the hook subcommand such as
+// update-ldcache. For other hooks, the name is the base name of the hook
+// path.
+func NewHookRemover(names ...string) Transformer {
+	r := make(hookRemover)
+	for _, name := range names {
+		r[name] = true
+	}
+	return r
+}
+
+// Transform removes the matching hooks from the devices and the common
+// container edits.
+func (r hookRemover) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+
+	for i := range spec.Devices {
+		r.transformEdits(&spec.Devices[i].ContainerEdits)
+	}
+	r.transformEdits(&spec.ContainerEdits)
+	return nil
+}
+
+func (r hookRemover) transformEdits(edits *specs.ContainerEdits) {
+	var hooks []*specs.Hook
+	for _, h := range edits.Hooks {
+		if r[hookName(h)] {
+			continue
+		}
+		hooks = append(hooks, h)
+	}
+	edits.Hooks = hooks
+}
+
+// hookName returns the name used to select the specified hook.
+func hookName(h *specs.Hook) string {
+	switch filepath.Base(h.Path) {
+	case "nvidia-cdi-hook":
+		if len(h.Args) > 1 {
+			return h.Args[1]
+		}
+	case "nvidia-ctk":
+		if len(h.Args) > 2 && h.Args[1] == "hook" {
+			return h.Args[2]
+		}
+	}
+	return filepath.Base(h.Path)
+}
@@ -0,0 +1,106 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestHookRemover(t *testing.T) {
+	ldcacheHook := &specs.Hook{
+		HookName: "createContainer",
+		Path:     "/usr/bin/nvidia-cdi-hook",
+		Args:     []string{"nvidia-cdi-hook", "update-ldcache"},
+	}
+	legacyLdcacheHook := &specs.Hook{
+		HookName: "createContainer",
+		Path:     "/usr/bin/nvidia-ctk",
+		Args:     []string{"nvidia-ctk", "hook", "update-ldcache"},
+	}
+	symlinksHook := &specs.Hook{
+		HookName: "createContainer",
+		Path:     "/usr/bin/nvidia-cdi-hook",
+		Args:     []string{"nvidia-cdi-hook", "create-symlinks"},
+	}
+	otherHook := &specs.Hook{
+		HookName: "createRuntime",
+		Path:     "/usr/local/bin/custom-hook",
+	}
+
+	testCases := []struct {
+		description  string
+		names        []string
+		spec         *specs.Spec
+		expectedSpec *specs.Spec
+	}{
+		{
+			description: "nil spec",
+		},
+		{
+			description: "hooks are removed by subcommand name",
+			names:       []string{"update-ldcache"},
+			spec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{ldcacheHook, symlinksHook, legacyLdcacheHook},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							Hooks: []*specs.Hook{ldcacheHook},
+						},
+					},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{symlinksHook},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+					},
+				},
+			},
+		},
+		{
+			description: "other hooks are removed by path",
+			names:       []string{"custom-hook"},
+			spec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{ldcacheHook, otherHook},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{ldcacheHook},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := NewHookRemover(tc.names...).Transform(tc.spec)
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedSpec, tc.spec)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"fmt"
+
+	"tags.cncf.io/container-device-interface/pkg/parser"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+type kindRenamer struct {
+	kind string
+}
+
+var _ Transformer = (*kindRenamer)(nil)
+
+// NewKindRenamer creates a transformer that sets the kind of a spec. The kind
+// must be of the form VENDOR/CLASS.
+func NewKindRenamer(kind string) (Transformer, error) {
+	vendor, class := parser.ParseQualifier(kind)
+	if err := parser.ValidateVendorName(vendor); err != nil {
+		return nil, fmt.Errorf("invalid kind %q: %w", kind, err)
+	}
+	if err := parser.ValidateClassName(class); err != nil {
+		return nil, fmt.Errorf("invalid kind %q: %w", kind, err)
+	}
+	return &kindRenamer{kind: kind}, nil
+}
+
+// Transform sets the kind of the spec.
+func (r kindRenamer) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+	spec.Kind = r.kind
+	return nil
+}
@@ -0,0 +1,75 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestKindRenamer(t *testing.T) {
+	testCases := []struct {
+		description   string
+		kind          string
+		spec          *specs.Spec
+		expectedError bool
+		expectedSpec  *specs.Spec
+	}{
+		{
+			description:  "kind is replaced",
+			kind:         "example.com/gpu",
+			spec:         &specs.Spec{Kind: "nvidia.com/gpu"},
+			expectedSpec: &specs.Spec{Kind: "example.com/gpu"},
+		},
+		{
+			description:   "missing class is an error",
+			kind:          "example.com",
+			expectedError: true,
+		},
+		{
+			description:   "invalid vendor is an error",
+			kind:          "-example.com/gpu",
+			expectedError: true,
+		},
+		{
+			description:   "invalid class is an error",
+			kind:          "example.com/gpu!",
+			expectedError: true,
+		},
+		{
+			description: "nil spec is ignored",
+			kind:        "example.com/gpu",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := NewKindRenamer(tc.kind)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			err = r.Transform(tc.spec)
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expectedSpec, tc.spec)
+		})
+	}
+}
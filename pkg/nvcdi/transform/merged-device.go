@@ -33,6 +33,8 @@ const (
 type mergedDevice struct {
 	name         string
 	skipIfExists bool
+	members      []string
+	annotations  map[string]string
 	simplifier   Transformer
 }
 
@@ -55,6 +57,21 @@ func WithSkipIfExists(skipIfExists bool) MergedDeviceOption {
 	}
 }
 
+// WithMemberDevices sets the names of the devices that are merged. If no
+// member devices are specified, all devices in the spec are merged.
+func WithMemberDevices(names ...string) MergedDeviceOption {
+	return func(m *mergedDevice) {
+		m.members = names
+	}
+}
+
+// WithAnnotations sets the annotations for the merged device
+func WithAnnotations(annotations map[string]string) MergedDeviceOption {
+	return func(m *mergedDevice) {
+		m.annotations = annotations
+	}
+}
+
 // NewMergedDevice creates a transformer with the specified options
 func NewMergedDevice(opts ...MergedDeviceOption) (Transformer, error) {
 	m := &mergedDevice{}
@@ -79,7 +96,12 @@ func (m mergedDevice) Transform(spec *specs.Spec) error {
 		return nil
 	}
 
-	mergedDevice, err := mergeDeviceSpecs(spec.Devices, m.name)
+	deviceSpecs, err := m.memberDeviceSpecs(spec.Devices)
+	if err != nil {
+		return fmt.Errorf("failed to generate merged device %q: %v", m.name, err)
+	}
+
+	mergedDevice, err := mergeDeviceSpecs(deviceSpecs, m.name)
 	if err != nil {
 		return fmt.Errorf("failed to generate merged device %q: %v", m.name, err)
 	}
@@ -90,6 +112,9 @@ func (m mergedDevice) Transform(spec *specs.Spec) error {
 		return fmt.Errorf("device %q already exists", m.name)
 	}
 
+	if len(m.annotations) > 0 {
+		mergedDevice.Annotations = m.annotations
+	}
 	spec.Devices = append(spec.Devices, *mergedDevice)
 
 	if err := m.simplifier.Transform(spec); err != nil {
@@ -99,6 +124,33 @@ func (m mergedDevice) Transform(spec *specs.Spec) error {
 	return nil
 }
 
+// memberDeviceSpecs returns the specs for the devices that are to be merged.
+// If the merged device already exists, it is included so that this can be
+// detected when merging the devices.
+func (m mergedDevice) memberDeviceSpecs(deviceSpecs []specs.Device) ([]specs.Device, error) {
+	if len(m.members) == 0 {
+		return deviceSpecs, nil
+	}
+
+	deviceSpecsByName := make(map[string]specs.Device)
+	for _, d := range deviceSpecs {
+		deviceSpecsByName[d.Name] = d
+	}
+	if existing, ok := deviceSpecsByName[m.name]; ok {
+		return []specs.Device{existing}, nil
+	}
+
+	var members []specs.Device
+	for _, name := range m.members {
+		d, ok := deviceSpecsByName[name]
+		if !ok {
+			return nil, fmt.Errorf("member device %q does not exist", name)
+		}
+		members = append(members, d)
+	}
+	return members, nil
+}
+
 // mergeDeviceSpecs creates a device with the specified name which combines the edits from the previous devices.
 // If a device of the specified name already exists, no device is created and nil is returned.
 func mergeDeviceSpecs(deviceSpecs []specs.Device, mergedDeviceName string) (*specs.Device, error) {
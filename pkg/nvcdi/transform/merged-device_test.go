@@ -127,6 +127,7 @@ func TestMergeDeviceSpecs(t *testing.T) {
 func TestMergedDevice(t *testing.T) {
 	testCases := []struct {
 		description   string
+		options       []MergedDeviceOption
 		spec          *specs.Spec
 		expectedError error
 		expectedSpec  *specs.Spec
@@ -202,16 +203,56 @@ func TestMergedDevice(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "member devices are merged",
+			options: []MergedDeviceOption{
+				WithName("numa0"),
+				WithMemberDevices("gpu0", "gpu2"),
+				WithAnnotations(map[string]string{"example.com/group": "numa0"}),
+			},
+			spec: &specs.Spec{
+				Devices: []specs.Device{
+					{Name: "gpu0", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=0"}}},
+					{Name: "gpu1", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=1"}}},
+					{Name: "gpu2", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=2"}}},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				Devices: []specs.Device{
+					{Name: "gpu0", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=0"}}},
+					{Name: "gpu1", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=1"}}},
+					{Name: "gpu2", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=2"}}},
+					{
+						Name:           "numa0",
+						Annotations:    map[string]string{"example.com/group": "numa0"},
+						ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=0", "GPU=2"}},
+					},
+				},
+			},
+		},
+		{
+			description: "missing member device is an error",
+			options: []MergedDeviceOption{
+				WithName("numa0"),
+				WithMemberDevices("gpu0", "gpu1"),
+			},
+			spec: &specs.Spec{
+				Devices: []specs.Device{
+					{Name: "gpu0", ContainerEdits: specs.ContainerEdits{Env: []string{"GPU=0"}}},
+				},
+			},
+			expectedError: fmt.Errorf(`failed to generate merged device "numa0": member device "gpu1" does not exist`),
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			m, err := NewMergedDevice()
+			m, err := NewMergedDevice(tc.options...)
 			require.NoError(t, err)
 
 			err = m.Transform(tc.spec)
 			if tc.expectedError != nil {
-				require.Error(t, err)
+				require.EqualError(t, err, tc.expectedError.Error())
 				return
 			}
 			require.NoError(t, err)
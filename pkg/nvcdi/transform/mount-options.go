@@ -0,0 +1,111 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+type mountOptions struct {
+	pathPattern string
+	add         []string
+	remove      []string
+}
+
+var _ Transformer = (*mountOptions)(nil)
+
+// MountOptionsOption is a function that configures a mount options transformer.
+type MountOptionsOption func(*mountOptions)
+
+// WithContainerPathPattern sets the glob pattern that the container path of
+// a mount must match for its options to be updated. If no pattern is
+// specified, the options of all mounts are updated.
+func WithContainerPathPattern(pattern string) MountOptionsOption {
+	return func(m *mountOptions) {
+		m.pathPattern = pattern
+	}
+}
+
+// WithAddedMountOptions sets the options to add to matching mounts.
+func WithAddedMountOptions(options ...string) MountOptionsOption {
+	return func(m *mountOptions) {
+		m.add = options
+	}
+}
+
+// WithRemovedMountOptions sets the options to remove from matching mounts.
+func WithRemovedMountOptions(options ...string) MountOptionsOption {
+	return func(m *mountOptions) {
+		m.remove = options
+	}
+}
+
+// NewMountOptionsUpdater creates a transformer that updates the options of the
+// mounts in a spec.
+func NewMountOptionsUpdater(opts ...MountOptionsOption) (Transformer, error) {
+	m := &mountOptions{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.pathPattern != "" {
+		if _, err := path.Match(m.pathPattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid container path pattern %q: %w", m.pathPattern, err)
+		}
+	}
+	return m, nil
+}
+
+// Transform removes and then adds the configured options for matching
+// mounts in the devices and the common container edits.
+func (m mountOptions) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+
+	for i := range spec.Devices {
+		m.transformEdits(&spec.Devices[i].ContainerEdits)
+	}
+	m.transformEdits(&spec.ContainerEdits)
+	return nil
+}
+
+func (m mountOptions) transformEdits(edits *specs.ContainerEdits) {
+	for _, mount := range edits.Mounts {
+		if m.pathPattern != "" {
+			if matched, _ := path.Match(m.pathPattern, mount.ContainerPath); !matched {
+				continue
+			}
+		}
+		var options []string
+		for _, option := range mount.Options {
+			if slices.Contains(m.remove, option) {
+				continue
+			}
+			options = append(options, option)
+		}
+		for _, option := range m.add {
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		mount.Options = options
+	}
+}
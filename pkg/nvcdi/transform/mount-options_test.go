@@ -0,0 +1,79 @@
+/**
+# Copyright (c) NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+func TestMountOptionsUpdater(t *testing.T) {
+	testCases := []struct {
+		description     string
+		options         []MountOptionsOption
+		expectedOptions [][]string
+	}{
+		{
+			description: "options are added and removed for all mounts",
+			options: []MountOptionsOption{
+				WithRemovedMountOptions("nosuid"),
+				WithAddedMountOptions("nodev", "noexec"),
+			},
+			expectedOptions: [][]string{
+				{"ro", "nodev", "bind", "noexec"},
+				{"ro", "nodev", "bind", "noexec"},
+			},
+		},
+		{
+			description: "only mounts matching the container path are updated",
+			options: []MountOptionsOption{
+				WithContainerPathPattern("/usr/lib/*"),
+				WithRemovedMountOptions("ro"),
+				WithAddedMountOptions("rw"),
+			},
+			expectedOptions: [][]string{
+				{"nosuid", "nodev", "bind", "rw"},
+				{"ro", "nosuid", "nodev", "bind"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			spec := &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Mounts: []*specs.Mount{
+						{ContainerPath: "/usr/lib/libcuda.so.1", Options: []string{"ro", "nosuid", "nodev", "bind"}},
+						{ContainerPath: "/usr/bin/nvidia-smi", Options: []string{"ro", "nosuid", "nodev", "bind"}},
+					},
+				},
+			}
+
+			m, err := NewMountOptionsUpdater(tc.options...)
+			require.NoError(t, err)
+			require.NoError(t, m.Transform(spec))
+
+			var options [][]string
+			for _, mount := range spec.ContainerEdits.Mounts {
+				options = append(options, mount.Options)
+			}
+			require.EqualValues(t, tc.expectedOptions, options)
+		})
+	}
+}
@@ -195,7 +195,9 @@ func TestSortSpec(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			s := sorter{}
+			s := NewSorter()
+			require.NotNil(t, s)
+
 			err := s.Transform(tc.spec)
 			require.NoError(t, err)
 